@@ -219,6 +219,88 @@ spec:
 	}
 }
 
+func TestCompileMarkdownSources(t *testing.T) {
+	tmpDir := t.TempDir()
+	binaryPath := filepath.Join(tmpDir, "arm")
+
+	cmd := exec.Command("go", "build", "-o", binaryPath, ".")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Failed to build binary: %v\n%s", err, output)
+	}
+
+	workDir := t.TempDir()
+	rulesDir := filepath.Join(workDir, "rules")
+	if err := os.MkdirAll(rulesDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(rulesDir, "ruleset.yml"), []byte("metadata:\n  id: clean-code\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(rulesDir, "naming.rule.md"), []byte("---\nenforcement: must\n---\nUse descriptive names.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd = exec.Command(binaryPath, "compile", "--validate-only", "rules")
+	cmd.Dir = workDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("validate failed: %v\n%s", err, output)
+	}
+
+	cmd = exec.Command(binaryPath, "compile", "--tool", "cursor", "rules", "out")
+	cmd.Dir = workDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("compile failed: %v\n%s", err, output)
+	}
+	content, err := os.ReadFile(filepath.Join(workDir, "out", "clean-code_naming.mdc"))
+	if err != nil || !strings.Contains(string(content), "alwaysApply: true") {
+		t.Errorf("expected compiled markdown rule, got %s, %v", content, err)
+	}
+
+	// Broken markdown sources are reported like broken YAML
+	if err := os.WriteFile(filepath.Join(rulesDir, "bad.rule.md"), []byte("---\nenforcement: always\n---\nBody\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cmd = exec.Command(binaryPath, "compile", "--validate-only", "rules")
+	cmd.Dir = workDir
+	if output, err := cmd.CombinedOutput(); err == nil || !strings.Contains(string(output), "bad.rule.md") {
+		t.Errorf("expected validation error for bad.rule.md, got %v\n%s", err, output)
+	}
+}
+
+func TestCompileValidatesPromptArguments(t *testing.T) {
+	tmpDir := t.TempDir()
+	binaryPath := filepath.Join(tmpDir, "arm")
+
+	cmd := exec.Command("go", "build", "-o", binaryPath, ".")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Failed to build binary: %v\n%s", err, output)
+	}
+
+	workDir := t.TempDir()
+	promptset := `apiVersion: v1
+kind: Promptset
+metadata:
+  id: explain
+spec:
+  prompts:
+    explain:
+      arguments:
+        - name: topic
+          required: true
+      body: Explain {{args.topic}} as {{args.format}}.
+`
+	if err := os.WriteFile(filepath.Join(workDir, "explain.yml"), []byte(promptset), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd = exec.Command(binaryPath, "compile", "--validate-only", "explain.yml")
+	cmd.Dir = workDir
+	output, err := cmd.CombinedOutput()
+	if err == nil || !strings.Contains(string(output), "{{args.format}} refers to an undeclared argument") {
+		t.Errorf("expected undeclared argument error, got %v\n%s", err, output)
+	}
+}
+
 func TestCompileHelp(t *testing.T) {
 	tmpDir := t.TempDir()
 	binaryPath := filepath.Join(tmpDir, "arm")
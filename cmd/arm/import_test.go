@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestImport(t *testing.T) {
+	tmpDir := t.TempDir()
+	binaryPath := filepath.Join(tmpDir, "arm")
+
+	cmd := exec.Command("go", "build", "-o", binaryPath, ".")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Failed to build binary: %v\n%s", err, output)
+	}
+
+	workDir := t.TempDir()
+	rulesDir := filepath.Join(workDir, ".cursor", "rules")
+	if err := os.MkdirAll(rulesDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(rulesDir, "go-style.mdc"), []byte("---\nglobs: **/*.go\n---\nUse gofmt.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(workDir, ".cursorrules"), []byte("Be concise.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Import to a file, then compile the result to prove it is a valid ruleset
+	cmd = exec.Command(binaryPath, "import", "--tool", "cursor", "--output", "team.yml", ".cursor/rules", ".cursorrules")
+	cmd.Dir = workDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("import failed: %v\n%s", err, output)
+	}
+
+	content, err := os.ReadFile(filepath.Join(workDir, "team.yml"))
+	if err != nil {
+		t.Fatalf("expected team.yml: %v", err)
+	}
+	for _, want := range []string{"id: team", "go-style:", "cursorrules:", "enforcement: must", "'**/*.go'"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("imported ruleset missing %q:\n%s", want, content)
+		}
+	}
+
+	cmd = exec.Command(binaryPath, "compile", "--validate-only", "team.yml")
+	cmd.Dir = workDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("imported ruleset does not validate: %v\n%s", err, output)
+	}
+
+	// Existing output is only replaced with --force
+	cmd = exec.Command(binaryPath, "import", "--tool", "cursor", "--output", "team.yml", ".cursorrules")
+	cmd.Dir = workDir
+	if output, err := cmd.CombinedOutput(); err == nil || !strings.Contains(string(output), "--force") {
+		t.Errorf("expected overwrite error, got %v\n%s", err, output)
+	}
+
+	cmd = exec.Command(binaryPath, "import", "--tool", "cursor", ".cursorrules")
+	cmd.Dir = workDir
+	if output, err := cmd.CombinedOutput(); err == nil || !strings.Contains(string(output), "ruleset ID is required") {
+		t.Errorf("expected missing ID error, got %v\n%s", err, output)
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInitAndNew(t *testing.T) {
+	tmpDir := t.TempDir()
+	binaryPath := filepath.Join(tmpDir, "arm")
+	cmd := exec.Command("go", "build", "-o", binaryPath, ".")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to build binary: %v", err)
+	}
+
+	run := func(dir, input string, args ...string) (string, error) {
+		cmd := exec.Command(binaryPath, args...)
+		cmd.Dir = dir
+		cmd.Stdin = strings.NewReader(input)
+		output, err := cmd.CombinedOutput()
+		return string(output), err
+	}
+	readManifest := func(dir string) string {
+		data, err := os.ReadFile(filepath.Join(dir, "arm.json"))
+		if err != nil {
+			t.Fatalf("Failed to read arm.json: %v", err)
+		}
+		return compactJSON(t, string(data))
+	}
+
+	t.Run("InitDetectsTools", func(t *testing.T) {
+		projectDir := t.TempDir()
+		if err := os.MkdirAll(filepath.Join(projectDir, ".kiro", "steering"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		output, err := run(projectDir, "", "init", "--yes", "--registry", "ai-rules=https://github.com/my-org/ai-rules")
+		if err != nil {
+			t.Fatalf("init failed: %v\nOutput: %s", err, output)
+		}
+		want := `{"version":1,"registries":{"ai-rules":{"type":"git","url":"https://github.com/my-org/ai-rules"}},` +
+			`"sinks":{"kiro-prompts":{"directory":".kiro/prompts","tool":"kiro"},"kiro-steering":{"directory":".kiro/steering","tool":"kiro"}}}`
+		if got := readManifest(projectDir); got != want {
+			t.Errorf("Expected:\n%s\nGot:\n%s", want, got)
+		}
+
+		output, err = run(projectDir, "", "init", "--yes")
+		if err == nil || !strings.Contains(output, "arm.json already exists") {
+			t.Errorf("init should refuse to replace arm.json without --force: %v\nOutput: %s", err, output)
+		}
+	})
+
+	t.Run("InitInteractive", func(t *testing.T) {
+		projectDir := t.TempDir()
+		output, err := run(projectDir, "copilot\nhttps://github.com/my-org/team-rules.git\n\n", "init")
+		if err != nil {
+			t.Fatalf("init failed: %v\nOutput: %s", err, output)
+		}
+		if !strings.Contains(output, "No AI tools detected") {
+			t.Errorf("Expected no tools to be detected, got:\n%s", output)
+		}
+		want := `{"version":1,"registries":{"team-rules":{"type":"git","url":"https://github.com/my-org/team-rules.git"}},` +
+			`"sinks":{"copilot-rules":{"directory":".github/copilot","tool":"copilot"}}}`
+		if got := readManifest(projectDir); got != want {
+			t.Errorf("Expected:\n%s\nGot:\n%s", want, got)
+		}
+	})
+
+	t.Run("NewValidates", func(t *testing.T) {
+		packageDir := filepath.Join(t.TempDir(), "review-prompts")
+		output, err := run(tmpDir, "", "new", "promptset", "codeReview", "--output", packageDir)
+		if err != nil {
+			t.Fatalf("new failed: %v\nOutput: %s", err, output)
+		}
+		if !strings.Contains(output, "Created "+filepath.Join(packageDir, "code-review-promptset.yml")) {
+			t.Errorf("Expected the promptset to be created, got:\n%s", output)
+		}
+
+		output, err = run(tmpDir, "", "compile", "--validate-only", packageDir)
+		if err != nil || !strings.Contains(output, "Validation successful") {
+			t.Errorf("scaffold does not validate: %v\nOutput: %s", err, output)
+		}
+	})
+}
@@ -147,3 +147,92 @@ func TestUninstallNoManifest(t *testing.T) {
 		t.Errorf("Expected success message, got: %s", output)
 	}
 }
+
+func TestInstallAllDryRun(t *testing.T) {
+	// Build the binary
+	tmpDir := t.TempDir()
+	binaryPath := filepath.Join(tmpDir, "arm")
+	cmd := exec.Command("go", "build", "-o", binaryPath, ".")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to build binary: %v", err)
+	}
+
+	manifestPath := filepath.Join(tmpDir, "arm.json")
+	outputDir := filepath.Join(tmpDir, "output")
+	manifestContent := `{
+		"sinks": {
+			"test-sink": {
+				"tool": "markdown",
+				"directory": "` + outputDir + `"
+			}
+		}
+	}`
+	if err := os.WriteFile(manifestPath, []byte(manifestContent), 0o644); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+
+	cmd = exec.Command(binaryPath, "install", "--dry-run")
+	cmd.Env = append(os.Environ(), "ARM_MANIFEST_PATH="+manifestPath)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Command failed: %v\nOutput: %s", err, output)
+	}
+	if strings.TrimSpace(string(output)) != "No changes" {
+		t.Errorf("Expected no changes, got: %s", output)
+	}
+
+	cmd = exec.Command(binaryPath, "install", "--dry-run", "--output", "json")
+	cmd.Env = append(os.Environ(), "ARM_MANIFEST_PATH="+manifestPath)
+	output, err = cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Command failed: %v\nOutput: %s", err, output)
+	}
+	if strings.TrimSpace(string(output)) != "{}" {
+		t.Errorf("Expected empty JSON plan, got: %s", output)
+	}
+
+	if _, err := os.Stat(outputDir); !os.IsNotExist(err) {
+		t.Errorf("Dry run should not create the sink directory, stat err = %v", err)
+	}
+
+	// --output only applies to dry runs of commands without structured results
+	cmd = exec.Command(binaryPath, "update", "--output", "json")
+	cmd.Env = append(os.Environ(), "ARM_MANIFEST_PATH="+manifestPath)
+	output, err = cmd.CombinedOutput()
+	if err == nil || !strings.Contains(string(output), "--output requires --dry-run") {
+		t.Errorf("Expected --output error, got: %v\nOutput: %s", err, output)
+	}
+}
+
+func TestInstallAllStructuredOutput(t *testing.T) {
+	tmpDir := t.TempDir()
+	binaryPath := filepath.Join(tmpDir, "arm")
+	cmd := exec.Command("go", "build", "-o", binaryPath, ".")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to build binary: %v", err)
+	}
+
+	manifestPath := filepath.Join(tmpDir, "arm.json")
+	if err := os.WriteFile(manifestPath, []byte(`{"version": 1}`), 0o644); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+
+	tests := []struct {
+		output string
+		want   string
+	}{
+		{"json", "{\n  \"installed\": []\n}"},
+		{"yaml", "installed: []"},
+	}
+	for _, tt := range tests {
+		cmd = exec.Command(binaryPath, "install", "--output", tt.output)
+		cmd.Env = append(os.Environ(), "ARM_MANIFEST_PATH="+manifestPath)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("Command failed: %v\nOutput: %s", err, output)
+		}
+		if strings.TrimSpace(string(output)) != tt.want {
+			t.Errorf("install --output %s = %q, want %q", tt.output, output, tt.want)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestListInfoStructuredOutput(t *testing.T) {
+	// Build the binary once
+	tmpDir := t.TempDir()
+	binaryPath := filepath.Join(tmpDir, "arm")
+	cmd := exec.Command("go", "build", "-o", binaryPath, ".")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to build binary: %v", err)
+	}
+
+	manifestPath := filepath.Join(tmpDir, "arm.json")
+	manifestContent := `{
+		"registries": {
+			"ai-rules": {"type": "git", "url": "https://github.com/example/rules", "branches": ["main"]}
+		},
+		"sinks": {
+			"q-rules": {"directory": ".amazonq/rules", "tool": "amazonq"},
+			"cursor-rules": {"directory": ".cursor/rules", "tool": "cursor", "conflicts": "dedupe"}
+		},
+		"dependencies": {
+			"ai-rules/clean-code": {"type": "ruleset", "version": "^1.0.0", "priority": 200, "sinks": ["cursor-rules"]}
+		}
+	}`
+	if err := os.WriteFile(manifestPath, []byte(manifestContent), 0o644); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+	lockContent := `{"version": 1, "dependencies": {"ai-rules/clean-code@1.2.0": {"integrity": "sha256-abc"}}}`
+	if err := os.WriteFile(filepath.Join(tmpDir, "arm-lock.json"), []byte(lockContent), 0o644); err != nil {
+		t.Fatalf("Failed to write lockfile: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		args           []string
+		expectedOutput string
+	}{
+		{
+			name:           "list json",
+			args:           []string{"list", "--output", "json"},
+			expectedOutput: `{"registries":["ai-rules"],"sinks":["cursor-rules","q-rules"],"dependencies":[{"name":"ai-rules/clean-code","type":"ruleset"}]}`,
+		},
+		{
+			name:           "list sink json",
+			args:           []string{"list", "sink", "--output", "json"},
+			expectedOutput: `["cursor-rules","q-rules"]`,
+		},
+		{
+			name:           "list dependency json",
+			args:           []string{"list", "dependency", "--output", "json"},
+			expectedOutput: `[{"name":"ai-rules/clean-code","version":"1.2.0"}]`,
+		},
+		{
+			name:           "info registry json",
+			args:           []string{"info", "registry", "--output", "json", "ai-rules"},
+			expectedOutput: `[{"name":"ai-rules","type":"git","url":"https://github.com/example/rules","branches":["main"]}]`,
+		},
+		{
+			name:           "info sink json",
+			args:           []string{"info", "sink", "cursor-rules", "--output", "json"},
+			expectedOutput: `[{"name":"cursor-rules","directory":".cursor/rules","tool":"cursor","conflicts":"dedupe"}]`,
+		},
+		{
+			name:           "info dependency json",
+			args:           []string{"info", "dependency", "--output", "json"},
+			expectedOutput: `[{"name":"ai-rules/clean-code","type":"ruleset","version":"1.2.0","constraint":"^1.0.0","priority":200,"sinks":["cursor-rules"],"integrity":"sha256-abc"}]`,
+		},
+		{
+			name: "info dependency yaml",
+			args: []string{"info", "dependency", "ai-rules/clean-code", "--output", "yaml"},
+			expectedOutput: `- name: ai-rules/clean-code
+  type: ruleset
+  version: 1.2.0
+  constraint: ^1.0.0
+  priority: 200
+  sinks:
+    - cursor-rules
+  integrity: sha256-abc`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command(binaryPath, tt.args...)
+			cmd.Env = append(os.Environ(), "ARM_MANIFEST_PATH="+manifestPath)
+			output, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Fatalf("Command failed: %v\nOutput: %s", err, output)
+			}
+
+			got := strings.TrimSpace(string(output))
+			if strings.HasSuffix(tt.name, "json") {
+				got = compactJSON(t, got)
+			}
+			if got != tt.expectedOutput {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tt.expectedOutput, got)
+			}
+		})
+	}
+
+	// Unsupported formats are rejected
+	cmd = exec.Command(binaryPath, "list", "--output", "xml")
+	cmd.Env = append(os.Environ(), "ARM_MANIFEST_PATH="+manifestPath)
+	output, err := cmd.CombinedOutput()
+	if err == nil || !strings.Contains(string(output), "Invalid output format: xml") {
+		t.Errorf("Expected invalid format error, got: %v\nOutput: %s", err, output)
+	}
+}
+
+func compactJSON(t *testing.T, s string) string {
+	t.Helper()
+	var out bytes.Buffer
+	if err := json.Compact(&out, []byte(s)); err != nil {
+		t.Fatalf("invalid JSON %q: %v", s, err)
+	}
+	return out.String()
+}
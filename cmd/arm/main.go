@@ -1,11 +1,21 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"syscall"
+	"text/tabwriter"
 	"time"
 
 	"github.com/jomadu/ai-resource-manager/internal/arm/compiler"
@@ -14,6 +24,8 @@ import (
 	"github.com/jomadu/ai-resource-manager/internal/arm/packagelockfile"
 	"github.com/jomadu/ai-resource-manager/internal/arm/registry"
 	"github.com/jomadu/ai-resource-manager/internal/arm/service"
+	"github.com/jomadu/ai-resource-manager/internal/arm/sink"
+	"gopkg.in/yaml.v3"
 )
 
 func main() {
@@ -31,6 +43,10 @@ func main() {
 		} else {
 			printHelp()
 		}
+	case "init":
+		handleInit()
+	case "new":
+		handleNew()
 	case "add":
 		handleAdd()
 	case "remove":
@@ -51,10 +67,22 @@ func main() {
 		handleUpgrade()
 	case "outdated":
 		handleOutdated()
+	case "tree":
+		handleTree()
+	case "why":
+		handleWhy()
+	case "verify":
+		handleVerify()
+	case "diff":
+		handleDiff()
+	case "search":
+		handleSearch()
 	case "clean":
 		handleClean()
 	case "compile":
 		handleCompile()
+	case "import":
+		handleImport()
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", os.Args[1])
 		fmt.Fprintf(os.Stderr, "Run 'arm help' for usage.\n")
@@ -79,18 +107,26 @@ func printHelp() {
 	fmt.Println("Commands:")
 	fmt.Println("  version              Display version information")
 	fmt.Println("  help [command]       Display help for a command")
+	fmt.Println("  init                 Create arm.json with sinks for the tools in the project")
+	fmt.Println("  new                  Scaffold a ruleset or promptset package")
 	fmt.Println("  add                  Add registries or sinks")
 	fmt.Println("  remove               Remove registries or sinks")
 	fmt.Println("  set                  Configure registries or sinks")
 	fmt.Println("  list                 List registries or sinks")
 	fmt.Println("  info                 Show detailed information")
-	fmt.Println("  install              Install rulesets or promptsets")
+	fmt.Println("  install              Install rulesets, promptsets, mcpsets, agentsets, skillsets or hooksets")
 	fmt.Println("  uninstall            Uninstall packages")
 	fmt.Println("  update               Update packages within version constraints")
 	fmt.Println("  upgrade              Upgrade packages to latest versions")
 	fmt.Println("  outdated             Check for outdated dependencies")
+	fmt.Println("  tree                 Show what each package installed in each sink")
+	fmt.Println("  why                  Show which package installed a file")
+	fmt.Println("  verify               Check sinks and packages for drift")
+	fmt.Println("  diff                 Show rule and prompt changes between package versions")
+	fmt.Println("  search               Search configured registries for packages")
 	fmt.Println("  clean                Clean cache or sinks")
-	fmt.Println("  compile              Compile rulesets and promptsets")
+	fmt.Println("  compile              Compile rulesets, promptsets, mcpsets, agentsets and hooksets")
+	fmt.Println("  import               Import tool rule files into a ruleset")
 	fmt.Println()
 	fmt.Println("Run 'arm help <command>' for more information on a command.")
 }
@@ -125,7 +161,7 @@ func printCommandHelp(command string) {
 		fmt.Println("  --api-version  GitLab API version (gitlab only, optional)")
 		fmt.Println("  --owner        Cloudsmith owner (cloudsmith only, required)")
 		fmt.Println("  --repo         Cloudsmith repository (cloudsmith only, required)")
-		fmt.Println("  --tool         Sink tool: cursor, copilot, amazonq, markdown (required)")
+		fmt.Println("  --tool         Sink tool: cursor, copilot, amazonq, kiro, claude, markdown, or a user-defined tool (required)")
 		fmt.Println("  --force        Overwrite existing registry or sink")
 	case "remove":
 		fmt.Println("Remove registries or sinks")
@@ -139,13 +175,22 @@ func printCommandHelp(command string) {
 		fmt.Println()
 		fmt.Println("Usage:")
 		fmt.Println("  arm set registry NAME KEY VALUE")
+		fmt.Println("  arm set sink NAME KEY VALUE")
 		fmt.Println()
-		fmt.Println("Supported keys:")
+		fmt.Println("Supported registry keys:")
 		fmt.Println("  name           Rename the registry")
 		fmt.Println("  url            Update the registry URL")
 		fmt.Println()
+		fmt.Println("Supported sink keys:")
+		fmt.Println("  tool           Change the sink tool")
+		fmt.Println("  directory      Change the sink directory")
+		fmt.Println("  enforcement    Map enforcement levels to activations, e.g. should=agent-requested,may=omit")
+		fmt.Println("                 (always, auto-attached, agent-requested, manual, omit; empty value resets)")
+		fmt.Println("  conflicts      report (default) installs conflicting rules; dedupe drops lower-priority ones")
+		fmt.Println()
 		fmt.Println("Example:")
 		fmt.Println("  arm set registry my-registry url https://github.com/new/repo")
+		fmt.Println("  arm set sink cursor-rules enforcement may=agent-requested")
 	case "list":
 		fmt.Println("List registries or sinks")
 		fmt.Println()
@@ -155,6 +200,9 @@ func printCommandHelp(command string) {
 		fmt.Println("  arm list dependency")
 		fmt.Println("  arm list versions REGISTRY/PACKAGE")
 		fmt.Println()
+		fmt.Println("Flags:")
+		fmt.Println("  --output       Output format: table (default), json, yaml")
+		fmt.Println()
 		fmt.Println("Displays a simple list of configured registry names.")
 	case "info":
 		fmt.Println("Show detailed information")
@@ -164,52 +212,78 @@ func printCommandHelp(command string) {
 		fmt.Println("  arm info sink [NAME...]")
 		fmt.Println("  arm info dependency [REGISTRY/PACKAGE...]")
 		fmt.Println()
+		fmt.Println("Flags:")
+		fmt.Println("  --output       Output format: table (default), json, yaml")
+		fmt.Println()
 		fmt.Println("Displays detailed information about registries, sinks, or dependencies.")
 		fmt.Println("If no names are provided, shows all items of that type.")
 		fmt.Println()
 		fmt.Println("Examples:")
 		fmt.Println("  arm info registry")
+		fmt.Println("  arm info dependency --output json")
 		fmt.Println("  arm info registry my-registry")
 		fmt.Println("  arm info sink cursor-rules")
 		fmt.Println("  arm info dependency")
 		fmt.Println("  arm info dependency sample-registry/clean-code-ruleset")
 	case "install":
-		fmt.Println("Install rulesets or promptsets")
+		fmt.Println("Install rulesets, promptsets, mcpsets, agentsets, skillsets or hooksets")
 		fmt.Println()
 		fmt.Println("Usage:")
-		fmt.Println("  arm install                                                                    # Install all dependencies")
+		fmt.Println("  arm install [--jobs N]                                                         # Install all dependencies")
 		fmt.Println("  arm install ruleset [--priority N] [--include PATTERN] [--exclude PATTERN] REGISTRY/RULESET[@VERSION] SINK...")
 		fmt.Println("  arm install promptset [--include PATTERN] [--exclude PATTERN] REGISTRY/PROMPTSET[@VERSION] SINK...")
+		fmt.Println("  arm install mcpset [--include PATTERN] [--exclude PATTERN] REGISTRY/MCPSET[@VERSION] SINK...")
+		fmt.Println("  arm install agentset [--include PATTERN] [--exclude PATTERN] REGISTRY/AGENTSET[@VERSION] SINK...")
+		fmt.Println("  arm install skillset [--include PATTERN] [--exclude PATTERN] REGISTRY/SKILLSET[@VERSION] SINK...")
+		fmt.Println("  arm install hookset [--include PATTERN] [--exclude PATTERN] REGISTRY/HOOKSET[@VERSION] SINK...")
 		fmt.Println()
 		fmt.Println("Flags:")
 		fmt.Println("  --priority     Priority for ruleset (default: 100)")
 		fmt.Println("  --include      Include glob pattern (can be specified multiple times)")
 		fmt.Println("  --exclude      Exclude glob pattern (can be specified multiple times)")
+		fmt.Println("  --jobs         Packages to fetch concurrently when installing all (default: 4)")
+		fmt.Println("  --dry-run      Print the planned changes without writing anything")
+		fmt.Println("  --output       Result (or plan, with --dry-run) format: table (default), json, yaml")
 		fmt.Println()
 		fmt.Println("Examples:")
 		fmt.Println("  arm install")
+		fmt.Println("  arm install --jobs 8")
+		fmt.Println("  arm install --dry-run --output json")
 		fmt.Println("  arm install ruleset --priority 200 my-registry/clean-code@1.0.0 cursor-rules")
 		fmt.Println("  arm install promptset my-registry/code-review cursor-commands")
+		fmt.Println("  arm install mcpset my-registry/dev-servers cursor-mcp")
+		fmt.Println("  arm install agentset my-registry/reviewers claude-agents")
+		fmt.Println("  arm install skillset my-registry/pdf-skills claude-skills")
+		fmt.Println("  arm install hookset my-registry/format-on-edit claude-settings")
 	case "uninstall":
 		fmt.Println("Uninstall packages")
 		fmt.Println()
 		fmt.Println("Usage:")
-		fmt.Println("  arm uninstall [packages...]")
+		fmt.Println("  arm uninstall [--dry-run [--output FORMAT]] [packages...]")
 		fmt.Println()
 		fmt.Println("Arguments:")
 		fmt.Println("  packages       Package names in format registry/package (optional)")
 		fmt.Println()
+		fmt.Println("Flags:")
+		fmt.Println("  --dry-run      Print the planned changes without writing anything")
+		fmt.Println("  --output       Plan format with --dry-run: table (default), json, yaml")
+		fmt.Println()
 		fmt.Println("If no packages specified, uninstalls all packages.")
 		fmt.Println("Removes specified packages from sinks and dependency configuration.")
 	case "update":
 		fmt.Println("Update packages within version constraints")
 		fmt.Println()
 		fmt.Println("Usage:")
-		fmt.Println("  arm update [packages...]")
+		fmt.Println("  arm update [--jobs N] [--dry-run [--output FORMAT]] [packages...]")
 		fmt.Println()
 		fmt.Println("Arguments:")
 		fmt.Println("  packages       Package names in format registry/package (optional)")
 		fmt.Println()
+		fmt.Println("Flags:")
+		fmt.Println("  --jobs         Packages to fetch concurrently when updating all (default: 4)")
+		fmt.Println("  --dry-run      Print the planned changes without writing anything")
+		fmt.Println("  --output       Plan format with --dry-run: table (default), json, yaml")
+		fmt.Println()
 		fmt.Println("If no packages specified, updates all packages.")
 		fmt.Println("Updates packages to the latest versions that satisfy the version constraints")
 		fmt.Println("specified in the manifest file. Updates the lock file with new versions.")
@@ -217,34 +291,122 @@ func printCommandHelp(command string) {
 		fmt.Println("Upgrade packages to latest versions")
 		fmt.Println()
 		fmt.Println("Usage:")
-		fmt.Println("  arm upgrade [registry/package ...]")
+		fmt.Println("  arm upgrade [--interactive] [--jobs N] [--dry-run [--output FORMAT]] [registry/package ...]")
 		fmt.Println()
 		fmt.Println("Upgrades packages to the latest versions, ignoring version constraints.")
 		fmt.Println("Updates the manifest file with new major version constraints (^X.0.0).")
 		fmt.Println()
+		fmt.Println("With --interactive, lists the outdated packages with the changelog of each")
+		fmt.Println("latest version and asks whether to move each to its wanted version, which")
+		fmt.Println("keeps its constraint, to its latest version, or to skip it.")
+		fmt.Println()
 		fmt.Println("Arguments:")
 		fmt.Println("  registry/package  One or more packages to upgrade (omit to upgrade all)")
 		fmt.Println("Updates the lock file with new versions.")
+		fmt.Println()
+		fmt.Println("Flags:")
+		fmt.Println("  --interactive  Choose which outdated packages to upgrade and to which version (-i)")
+		fmt.Println("  --jobs         Packages to fetch concurrently when upgrading all (default: 4)")
+		fmt.Println("  --dry-run      Print the planned changes without writing anything")
+		fmt.Println("  --output       Plan format with --dry-run: table (default), json, yaml")
+
 	case "outdated":
 		fmt.Println("Check for outdated dependencies")
 		fmt.Println()
 		fmt.Println("Usage:")
-		fmt.Println("  arm outdated [--output FORMAT]")
+		fmt.Println("  arm outdated [--output FORMAT] [--jobs N]")
 		fmt.Println()
 		fmt.Println("Flags:")
-		fmt.Println("  --output       Output format: table (default), json, list")
+		fmt.Println("  --output       Output format: table (default), json, yaml, list")
+		fmt.Println("  --jobs         Packages to check concurrently (default: 4)")
 		fmt.Println()
 		fmt.Println("Displays packages with newer versions available, showing:")
 		fmt.Println("  - Constraint: version constraint from manifest")
 		fmt.Println("  - Current: currently installed version")
 		fmt.Println("  - Wanted: latest version satisfying constraint")
 		fmt.Println("  - Latest: latest available version")
+	case "tree":
+		fmt.Println("Show what each package installed in each sink")
+		fmt.Println()
+		fmt.Println("Usage:")
+		fmt.Println("  arm tree [--output FORMAT]")
+		fmt.Println()
+		fmt.Println("Flags:")
+		fmt.Println("  --output       Output format: table (default), json, yaml")
+		fmt.Println()
+		fmt.Println("Lists every configured package by registry with its locked version and")
+		fmt.Println("priority, its sinks, and the files it generated in each sink, along with")
+		fmt.Println("the rule and package file each one came from.")
+	case "why":
+		fmt.Println("Show which package installed a file")
+		fmt.Println()
+		fmt.Println("Usage:")
+		fmt.Println("  arm why PATH [--output FORMAT]")
+		fmt.Println()
+		fmt.Println("Flags:")
+		fmt.Println("  --output       Output format: table (default), json, yaml")
+		fmt.Println()
+		fmt.Println("Traces a file in a sink directory back to the package, version, source")
+		fmt.Println("file and rule ID that produced it.")
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  arm why .cursor/rules/arm/ai-rules/clean-code/1.2.0/cleanCode_naming.mdc")
+	case "diff":
+		fmt.Println("Show rule and prompt changes between package versions")
+		fmt.Println()
+		fmt.Println("Usage:")
+		fmt.Println("  arm diff REGISTRY/PACKAGE [FROM] [TO] [--output FORMAT]")
+		fmt.Println()
+		fmt.Println("Flags:")
+		fmt.Println("  --output       Output format: table (default), json, yaml")
+		fmt.Println()
+		fmt.Println("Fetches both versions and lists the rules and prompts that were added,")
+		fmt.Println("removed or changed, by ID, with changes to enforcement, scope, priority")
+		fmt.Println("and other fields, and a diff of the body. FROM defaults to the locked")
+		fmt.Println("version and TO to the latest available version; both accept versions or")
+		fmt.Println("constraints.")
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  arm diff ai-rules/clean-code")
+		fmt.Println("  arm diff ai-rules/clean-code 1.2.0 2.0.0")
+	case "search":
+		fmt.Println("Search configured registries for packages")
+		fmt.Println()
+		fmt.Println("Usage:")
+		fmt.Println("  arm search QUERY [--registry NAME]... [--output FORMAT] [--jobs N]")
+		fmt.Println()
+		fmt.Println("Flags:")
+		fmt.Println("  --registry     Only search this registry; repeat for several")
+		fmt.Println("  --output       Output format: table (default), json, yaml")
+		fmt.Println("  --jobs         Registries to search concurrently (default: 4)")
+		fmt.Println()
+		fmt.Println("Lists the packages whose name or description contains QUERY, ignoring")
+		fmt.Println("case, with their latest versions. GitLab and Cloudsmith registries list")
+		fmt.Println("their packages; git registries have no package list, so they never match.")
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  arm search security")
+		fmt.Println("  arm search review --registry team-prompts --output json")
+	case "verify":
+		fmt.Println("Check sinks and packages for drift")
+		fmt.Println()
+		fmt.Println("Usage:")
+		fmt.Println("  arm verify [--output FORMAT] [--jobs N]")
+		fmt.Println()
+		fmt.Println("Flags:")
+		fmt.Println("  --output       Output format: table (default), json, yaml")
+		fmt.Println("  --jobs         Packages to check concurrently (default: 4)")
+		fmt.Println()
+		fmt.Println("Reports files in sink directories that were edited, deleted or added by")
+		fmt.Println("hand since ARM installed them, and locked packages whose contents no")
+		fmt.Println("longer match the integrity in arm-lock.json. Exits with status 1 if")
+		fmt.Println("anything differs, so it can gate CI.")
 	case "clean":
 		fmt.Println("Clean cache or sinks")
 		fmt.Println()
 		fmt.Println("Usage:")
-		fmt.Println("  arm clean cache [--max-age DURATION] [--nuke]")
-		fmt.Println("  arm clean sinks [--nuke]")
+		fmt.Println("  arm clean cache [--max-age DURATION] [--nuke] [--dry-run [--output FORMAT]]")
+		fmt.Println("  arm clean sinks [--nuke] [--dry-run [--output FORMAT]]")
 		fmt.Println()
 		fmt.Println("Cache Flags:")
 		fmt.Println("  --max-age      Remove cache older than duration (default: 7d)")
@@ -254,6 +416,10 @@ func printCommandHelp(command string) {
 		fmt.Println("Sinks Flags:")
 		fmt.Println("  --nuke         Remove entire ARM directory from sinks")
 		fmt.Println()
+		fmt.Println("Common Flags:")
+		fmt.Println("  --dry-run      Print the planned changes without writing anything")
+		fmt.Println("  --output       Plan format with --dry-run: table (default), json, yaml")
+		fmt.Println()
 		fmt.Println("Removes cached data or orphaned files from sinks.")
 	case "compile":
 		fmt.Println("Compile rulesets and promptsets")
@@ -262,7 +428,7 @@ func printCommandHelp(command string) {
 		fmt.Println("  arm compile INPUT_PATH... [OUTPUT_PATH] [flags]")
 		fmt.Println()
 		fmt.Println("Flags:")
-		fmt.Println("  --tool         Target tool: markdown, cursor, amazonq, copilot")
+		fmt.Println("  --tool         Target tool: markdown, cursor, amazonq, kiro, copilot, claude, or a user-defined tool")
 		fmt.Println("  --namespace    Namespace for compiled resources")
 		fmt.Println("  --force        Overwrite existing files")
 		fmt.Println("  --recursive    Process directories recursively")
@@ -273,6 +439,62 @@ func printCommandHelp(command string) {
 		fmt.Println()
 		fmt.Println("Compiles ARM resources to tool-specific formats.")
 		fmt.Println("Supports files, directories, and mixed inputs.")
+	case "import":
+		fmt.Println("Import tool rule files into a ruleset")
+		fmt.Println()
+		fmt.Println("Usage:")
+		fmt.Println("  arm import --tool TOOL [--id ID] [--name NAME] [--output FILE] [--force] [--recursive] INPUT_PATH...")
+		fmt.Println()
+		fmt.Println("Flags:")
+		fmt.Println("  --tool         Tool the files were written for: cursor, copilot, kiro, amazonq, claude, or markdown")
+		fmt.Println("  --id           Ruleset ID (default: from ARM metadata in the files or the output file name)")
+		fmt.Println("  --name         Ruleset name")
+		fmt.Println("  --output       Write the ruleset to FILE instead of standard output")
+		fmt.Println("  --force        Overwrite an existing output file")
+		fmt.Println("  --recursive    Process directories recursively")
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  arm import --tool cursor --output clean-code.yml .cursor/rules")
+		fmt.Println("  arm import --tool cursor --id legacy .cursorrules")
+		fmt.Println("  arm import --tool claude --id project CLAUDE.md")
+	case "init":
+		fmt.Println("Create arm.json with sinks for the tools in the project")
+		fmt.Println()
+		fmt.Println("Usage:")
+		fmt.Println("  arm init [--tool TOOL]... [--registry NAME=URL]... [--yes] [--force]")
+		fmt.Println()
+		fmt.Println("Flags:")
+		fmt.Println("  --tool         Create sinks for this tool instead of the detected ones; repeat for several")
+		fmt.Println("  --registry     Add a git registry; repeat for several")
+		fmt.Println("  --yes          Accept the detected tools without asking (-y)")
+		fmt.Println("  --force        Replace an existing arm.json")
+		fmt.Println()
+		fmt.Println("Detects the AI tools the project already uses (.cursor, .github/copilot-")
+		fmt.Println("instructions.md, .amazonq, .kiro, .claude) and creates their rule and")
+		fmt.Println("prompt sinks. Without flags, asks which tools to set up and for a git")
+		fmt.Println("registry to add.")
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  arm init")
+		fmt.Println("  arm init --tool cursor --registry ai-rules=https://github.com/my-org/ai-rules")
+	case "new":
+		fmt.Println("Scaffold a ruleset or promptset package")
+		fmt.Println()
+		fmt.Println("Usage:")
+		fmt.Println("  arm new ruleset|promptset ID [--name NAME] [--output DIR] [--force]")
+		fmt.Println()
+		fmt.Println("Flags:")
+		fmt.Println("  --name         Resource name (default: from the ID)")
+		fmt.Println("  --output       Package directory (default: current directory)")
+		fmt.Println("  --force        Overwrite an existing resource file")
+		fmt.Println()
+		fmt.Println("Writes ID-ruleset.yml or ID-promptset.yml with one example to edit, and a")
+		fmt.Println("README.md and CHANGELOG.md if the directory has none. The result passes")
+		fmt.Println("arm compile --validate-only.")
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  arm new ruleset cleanCode")
+		fmt.Println("  arm new promptset codeReview --name \"Code Review\" --output review-prompts")
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", command)
 		os.Exit(1)
@@ -288,6 +510,425 @@ func deriveLockPath(manifestPath string) string {
 	return strings.TrimSuffix(manifestPath, ".json") + "-lock.json"
 }
 
+// dryRunOptions holds the --dry-run and --output flags of commands that change the project
+type dryRunOptions struct {
+	enabled bool
+	output  string
+}
+
+// takeDryRunFlags removes --dry-run and --output FORMAT from os.Args so the
+// command parses its remaining arguments as usual. With resultOutput set,
+// --output also formats the command's result when it is not a dry run.
+func takeDryRunFlags(resultOutput bool) dryRunOptions {
+	opts := dryRunOptions{output: "table"}
+	outputSet := false
+	args := os.Args[:2]
+	for i := 2; i < len(os.Args); i++ {
+		switch os.Args[i] {
+		case "--dry-run":
+			opts.enabled = true
+		case "--output":
+			if i+1 >= len(os.Args) {
+				fmt.Fprintf(os.Stderr, "--output requires a value\n")
+				os.Exit(1)
+			}
+			opts.output = os.Args[i+1]
+			outputSet = true
+			i++
+		default:
+			args = append(args, os.Args[i])
+		}
+	}
+	os.Args = args
+
+	if opts.output != "table" && opts.output != "json" && opts.output != "yaml" {
+		fmt.Fprintf(os.Stderr, "Invalid output format: %s (must be table, json or yaml)\n", opts.output)
+		os.Exit(1)
+	}
+	if outputSet && !opts.enabled && !resultOutput {
+		fmt.Fprintf(os.Stderr, "--output requires --dry-run\n")
+		os.Exit(1)
+	}
+	return opts
+}
+
+// takeJobsFlag removes --jobs N from os.Args and returns N, or
+// service.DefaultJobs when the flag is not given
+func takeJobsFlag() int {
+	jobs := service.DefaultJobs
+	args := os.Args[:2]
+	for i := 2; i < len(os.Args); i++ {
+		if os.Args[i] != "--jobs" {
+			args = append(args, os.Args[i])
+			continue
+		}
+		if i+1 >= len(os.Args) {
+			fmt.Fprintf(os.Stderr, "--jobs requires a value\n")
+			os.Exit(1)
+		}
+		n, err := strconv.Atoi(os.Args[i+1])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid --jobs value: %s\n", os.Args[i+1])
+			os.Exit(1)
+		}
+		jobs = n
+		i++
+	}
+	os.Args = args
+	return jobs
+}
+
+// changeContext returns a context that is cancelled on Ctrl-C or SIGTERM, so an
+// interrupted install rolls back instead of leaving the project half written
+func changeContext() context.Context {
+	ctx, _ := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	return ctx
+}
+
+// runChange applies a change to the manifest, lockfile and sinks as a single
+// transaction, or prints the plan instead when --dry-run is set. It reports
+// whether the change was applied.
+func runChange(ctx context.Context, svc *service.ArmService, manifestPath, lockfilePath string, opts dryRunOptions, apply func(ctx context.Context, svc *service.ArmService) error) bool {
+	if opts.enabled {
+		printPlan(ctx, svc, manifestPath, lockfilePath, opts, apply)
+		return false
+	}
+	if err := svc.Transact(ctx, manifestPath, lockfilePath, apply); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return true
+}
+
+// printPlan runs apply against a sandbox and prints the changes it would make
+func printPlan(ctx context.Context, svc *service.ArmService, manifestPath, lockfilePath string, opts dryRunOptions, apply func(ctx context.Context, svc *service.ArmService) error) {
+	plan, err := svc.PlanChanges(ctx, manifestPath, lockfilePath, apply)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	writePlan(plan, opts.output)
+}
+
+func writePlan(plan *service.Plan, output string) {
+	if output != "table" {
+		writeStructured(plan, output)
+		return
+	}
+
+	if len(plan.Packages) == 0 && len(plan.Files) == 0 && len(plan.Manifest) == 0 && len(plan.Lockfile) == 0 {
+		fmt.Println("No changes")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	section := func(title string) {
+		_ = w.Flush()
+		fmt.Printf("%s:\n", title)
+	}
+	if len(plan.Packages) > 0 {
+		section("Packages")
+		_, _ = fmt.Fprintln(w, "  Package\tFrom\tTo")
+		for _, pkg := range plan.Packages {
+			_, _ = fmt.Fprintf(w, "  %s\t%s\t%s\n", pkg.Package, dashIfEmpty(pkg.From), dashIfEmpty(pkg.To))
+		}
+	}
+	if len(plan.Files) > 0 {
+		section("Files")
+		_, _ = fmt.Fprintln(w, "  Action\tSink\tPath")
+		for _, file := range plan.Files {
+			_, _ = fmt.Fprintf(w, "  %s\t%s\t%s\n", file.Action, file.Sink, file.Path)
+		}
+	}
+	for _, doc := range []struct {
+		title   string
+		changes []service.PlannedChange
+	}{{"Manifest", plan.Manifest}, {"Lockfile", plan.Lockfile}} {
+		if len(doc.changes) == 0 {
+			continue
+		}
+		section(doc.title)
+		for _, change := range doc.changes {
+			switch change.Action {
+			case service.PlanCreate:
+				_, _ = fmt.Fprintf(w, "  + %s\t%s\n", change.Path, change.New)
+			case service.PlanDelete:
+				_, _ = fmt.Fprintf(w, "  - %s\t%s\n", change.Path, change.Old)
+			default:
+				_, _ = fmt.Fprintf(w, "  ~ %s\t%s -> %s\n", change.Path, change.Old, change.New)
+			}
+		}
+	}
+	_ = w.Flush()
+}
+
+func dashIfEmpty(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// takeOutputFlag removes --output FORMAT from os.Args and returns the format
+// (table when not given), exiting unless it is one of formats
+func takeOutputFlag(formats ...string) string {
+	output := "table"
+	args := os.Args[:2]
+	for i := 2; i < len(os.Args); i++ {
+		if os.Args[i] != "--output" {
+			args = append(args, os.Args[i])
+			continue
+		}
+		if i+1 >= len(os.Args) {
+			fmt.Fprintf(os.Stderr, "--output requires a value\n")
+			os.Exit(1)
+		}
+		output = os.Args[i+1]
+		i++
+	}
+	os.Args = args
+
+	for _, format := range formats {
+		if output == format {
+			return output
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Invalid output format: %s (must be %s)\n", output, strings.Join(formats, ", "))
+	os.Exit(1)
+	return ""
+}
+
+// sortedNames returns the keys of m in order, never nil so JSON output is a list
+func sortedNames[V any](m map[string]V) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// writeStructured prints value as indented JSON or as YAML. YAML is converted
+// from the JSON encoding, so both formats share field names and ordering.
+func writeStructured(value interface{}, output string) {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err == nil && output == "yaml" {
+		var node yaml.Node
+		if err = yaml.Unmarshal(data, &node); err == nil {
+			clearYAMLStyle(&node)
+			data, err = yaml.Marshal(&node)
+			data = bytes.TrimSuffix(data, []byte("\n"))
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(string(data))
+}
+
+// clearYAMLStyle drops the flow and quoting styles YAML parsing gives JSON input
+func clearYAMLStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		clearYAMLStyle(child)
+	}
+}
+
+// registryOutput is the structured output of a registry
+type registryOutput struct {
+	Name       string   `json:"name"`
+	Type       string   `json:"type"`
+	URL        string   `json:"url,omitempty"`
+	Branches   []string `json:"branches,omitempty"`
+	ProjectID  string   `json:"projectId,omitempty"`
+	GroupID    string   `json:"groupId,omitempty"`
+	APIVersion string   `json:"apiVersion,omitempty"`
+	Owner      string   `json:"owner,omitempty"`
+	Repository string   `json:"repository,omitempty"`
+}
+
+func newRegistryOutput(name string, config map[string]interface{}) (registryOutput, error) {
+	out := registryOutput{}
+	data, err := json.Marshal(config)
+	if err != nil {
+		return out, err
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return out, fmt.Errorf("invalid registry %s: %w", name, err)
+	}
+	out.Name = name
+	return out, nil
+}
+
+// sinkOutput is the structured output of a sink
+type sinkOutput struct {
+	Name string `json:"name"`
+	manifest.SinkConfig
+}
+
+// registryOutputs describes the named registries, reporting the ones that
+// cannot be read and skipping them
+func registryOutputs(ctx context.Context, svc *service.ArmService, names []string) []registryOutput {
+	outputs := []registryOutput{}
+	for _, name := range names {
+		config, err := svc.GetRegistryConfig(ctx, name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting registry '%s': %v\n", name, err)
+			continue
+		}
+		out, err := newRegistryOutput(name, config)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			continue
+		}
+		outputs = append(outputs, out)
+	}
+	return outputs
+}
+
+// sinkOutputs describes the named sinks, reporting the ones that cannot be read and skipping them
+func sinkOutputs(ctx context.Context, svc *service.ArmService, names []string) []sinkOutput {
+	outputs := []sinkOutput{}
+	for _, name := range names {
+		config, err := svc.GetSinkConfig(ctx, name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting sink '%s': %v\n", name, err)
+			continue
+		}
+		outputs = append(outputs, sinkOutput{Name: name, SinkConfig: config})
+	}
+	return outputs
+}
+
+// dependencyOutput is the structured output of a dependency; Version is the
+// locked version and Constraint the manifest's version constraint
+type dependencyOutput struct {
+	Name       string   `json:"name"`
+	Type       string   `json:"type"`
+	Version    string   `json:"version,omitempty"`
+	Constraint string   `json:"constraint,omitempty"`
+	Priority   *int     `json:"priority,omitempty"`
+	Sinks      []string `json:"sinks,omitempty"`
+	Include    []string `json:"include,omitempty"`
+	Exclude    []string `json:"exclude,omitempty"`
+	Integrity  string   `json:"integrity,omitempty"`
+}
+
+func newDependencyOutput(key string, info *service.DependencyInfo) (dependencyOutput, error) {
+	var config manifest.RulesetDependencyConfig
+	data, err := json.Marshal(info.Config)
+	if err != nil {
+		return dependencyOutput{}, err
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return dependencyOutput{}, fmt.Errorf("invalid dependency %s: %w", key, err)
+	}
+
+	out := dependencyOutput{
+		Name:       key,
+		Type:       string(config.Type),
+		Version:    info.Version,
+		Constraint: config.Version,
+		Sinks:      config.Sinks,
+		Include:    config.Include,
+		Exclude:    config.Exclude,
+		Integrity:  info.LockInfo.Integrity,
+	}
+	if config.Type == manifest.ResourceTypeRuleset {
+		priority := config.Priority
+		out.Priority = &priority
+	}
+	return out, nil
+}
+
+// dependencyOutputs describes the given dependencies, reporting the ones that
+// cannot be read and skipping them
+func dependencyOutputs(ctx context.Context, svc *service.ArmService, keys []string) []dependencyOutput {
+	outputs := []dependencyOutput{}
+	for _, key := range keys {
+		registryName, packageName := manifest.ParseDependencyKey(key)
+		if registryName == "" || packageName == "" {
+			fmt.Fprintf(os.Stderr, "Invalid dependency format '%s' (expected: registry/package)\n", key)
+			continue
+		}
+		info, err := svc.GetDependencyInfo(ctx, registryName, packageName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting dependency '%s': %v\n", key, err)
+			continue
+		}
+		out, err := newDependencyOutput(key, info)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			continue
+		}
+		outputs = append(outputs, out)
+	}
+	return outputs
+}
+
+// installOutput is the structured result of an install
+type installOutput struct {
+	Installed []dependencyOutput `json:"installed"`
+	Conflicts []conflictOutput   `json:"conflicts,omitempty"`
+}
+
+type conflictOutput struct {
+	Sink       string        `json:"sink"`
+	Kind       string        `json:"kind"`
+	Winner     ruleRefOutput `json:"winner"`
+	Loser      ruleRefOutput `json:"loser"`
+	Suppressed bool          `json:"suppressed"`
+}
+
+type ruleRefOutput struct {
+	Package  string `json:"package"`
+	Ruleset  string `json:"ruleset"`
+	Rule     string `json:"rule"`
+	Priority int    `json:"priority"`
+}
+
+func newRuleRefOutput(ref sink.RuleRef) ruleRefOutput {
+	return ruleRefOutput{Package: ref.Package, Ruleset: ref.Ruleset, Rule: ref.Rule, Priority: ref.Priority}
+}
+
+// writeInstallResult prints the installed dependencies and the rule conflicts across sinks
+func writeInstallResult(ctx context.Context, svc *service.ArmService, keys []string, output string) {
+	result := installOutput{Installed: dependencyOutputs(ctx, svc, keys)}
+	reports, err := svc.RuleConflicts(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to detect rule conflicts: %v\n", err)
+	}
+	for _, report := range reports {
+		for _, conflict := range report.Conflicts {
+			result.Conflicts = append(result.Conflicts, conflictOutput{
+				Sink:       report.Sink,
+				Kind:       string(conflict.Kind),
+				Winner:     newRuleRefOutput(conflict.Winner),
+				Loser:      newRuleRefOutput(conflict.Loser),
+				Suppressed: conflict.Suppressed,
+			})
+		}
+	}
+	writeStructured(result, output)
+}
+
+// validateTool exits unless the tool is built in or defined in the manifest or tools/ directory
+func validateTool(ctx context.Context, manifestMgr manifest.Manager, tool compiler.Tool) {
+	if compiler.IsBuiltinTool(tool) {
+		return
+	}
+	tools, err := manifestMgr.GetAllToolsConfig(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if _, ok := tools[tool]; !ok {
+		fmt.Fprintf(os.Stderr, "Invalid tool: %s (must be cursor, copilot, amazonq, kiro, claude, markdown, or a user-defined tool)\n", tool)
+		os.Exit(1)
+	}
+}
+
 func handleAdd() {
 	if len(os.Args) < 3 {
 		fmt.Fprintf(os.Stderr, "Usage: arm add <registry|sink> ...\n")
@@ -605,23 +1246,7 @@ func handleAddSink() {
 		os.Exit(1)
 	}
 
-	// Validate tool
-	var compilerTool compiler.Tool
-	switch tool {
-	case "cursor":
-		compilerTool = compiler.Cursor
-	case "copilot":
-		compilerTool = compiler.Copilot
-	case "amazonq":
-		compilerTool = compiler.AmazonQ
-	case "kiro":
-		compilerTool = compiler.Kiro
-	case "markdown":
-		compilerTool = compiler.Markdown
-	default:
-		fmt.Fprintf(os.Stderr, "Invalid tool: %s (must be cursor, copilot, amazonq, kiro, or markdown)\n", tool)
-		os.Exit(1)
-	}
+	compilerTool := compiler.Tool(tool)
 
 	// Get manifest path from env or use default
 	manifestPath := os.Getenv("ARM_MANIFEST_PATH")
@@ -635,6 +1260,7 @@ func handleAddSink() {
 	svc := service.NewArmService(manifestMgr, lockfileMgr, registryFactory)
 
 	ctx := context.Background()
+	validateTool(ctx, manifestMgr, compilerTool)
 	if err := svc.AddSink(ctx, name, path, compilerTool, force); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
@@ -803,25 +1429,21 @@ func handleSetSink() {
 
 	switch key {
 	case "tool":
-		var tool compiler.Tool
-		switch value {
-		case "cursor":
-			tool = compiler.Cursor
-		case "copilot":
-			tool = compiler.Copilot
-		case "amazonq":
-			tool = compiler.AmazonQ
-		case "markdown":
-			tool = compiler.Markdown
-		default:
-			fmt.Fprintf(os.Stderr, "Invalid tool: %s (valid: cursor, copilot, amazonq, markdown)\n", value)
-			os.Exit(1)
-		}
+		tool := compiler.Tool(value)
+		validateTool(ctx, manifestMgr, tool)
 		err = svc.SetSinkTool(ctx, name, tool)
 	case "directory":
 		err = svc.SetSinkDirectory(ctx, name, value)
+	case "enforcement":
+		var policy compiler.EnforcementPolicy
+		policy, err = compiler.ParseEnforcementPolicy(value)
+		if err == nil {
+			err = svc.SetSinkEnforcement(ctx, name, policy)
+		}
+	case "conflicts":
+		err = svc.SetSinkConflicts(ctx, name, sink.ConflictMode(value))
 	default:
-		fmt.Fprintf(os.Stderr, "Unknown key: %s (valid: tool, directory)\n", key)
+		fmt.Fprintf(os.Stderr, "Unknown key: %s (valid: tool, directory, enforcement, conflicts)\n", key)
 		os.Exit(1)
 	}
 
@@ -954,46 +1576,101 @@ func handleSetPromptset() {
 }
 
 func handleList() {
+	output := takeOutputFlag("table", "json", "yaml")
 	if len(os.Args) < 3 {
-		handleListAll()
+		handleListAll(output)
 		return
 	}
 
 	switch os.Args[2] {
 	case "registry":
-		handleListRegistry()
+		handleListRegistry(output)
 	case "sink":
-		handleListSink()
+		handleListSink(output)
 	case "dependency":
-		handleListDependency()
+		handleListDependency(output)
 	case "versions":
-		handleListVersions()
+		handleListVersions(output)
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown list target: %s\n", os.Args[2])
 		os.Exit(1)
 	}
 }
 
-func handleListAll() {
-	manifestPath := os.Getenv("ARM_MANIFEST_PATH")
-	if manifestPath == "" {
-		manifestPath = "arm.json"
-	}
+// listOutput is the structured output of arm list
+type listOutput struct {
+	Registries   []string        `json:"registries"`
+	Sinks        []string        `json:"sinks"`
+	Dependencies []dependencyRef `json:"dependencies"`
+}
 
-	manifestMgr := manifest.NewFileManagerWithPath(manifestPath)
-	lockfileMgr := packagelockfile.NewFileManagerWithPath(deriveLockPath(manifestPath))
-	registryFactory := &registry.DefaultFactory{}
-	svc := service.NewArmService(manifestMgr, lockfileMgr, registryFactory)
+type dependencyRef struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
 
-	ctx := context.Background()
+func dependencyNames(refs []dependencyRef) []string {
+	names := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		names = append(names, ref.Name)
+	}
+	return names
+}
 
-	// List registries
+func listAll(ctx context.Context, svc *service.ArmService) listOutput {
 	registries, err := svc.GetAllRegistriesConfig(ctx)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
-
+	sinks, err := svc.GetAllSinkConfigs(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	deps, err := svc.GetAllDependenciesConfig(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	result := listOutput{
+		Registries:   sortedNames(registries),
+		Sinks:        sortedNames(sinks),
+		Dependencies: []dependencyRef{},
+	}
+	for _, key := range sortedNames(deps) {
+		depType, _ := deps[key]["type"].(string)
+		result.Dependencies = append(result.Dependencies, dependencyRef{Name: key, Type: depType})
+	}
+	return result
+}
+
+func handleListAll(output string) {
+	manifestPath := os.Getenv("ARM_MANIFEST_PATH")
+	if manifestPath == "" {
+		manifestPath = "arm.json"
+	}
+
+	manifestMgr := manifest.NewFileManagerWithPath(manifestPath)
+	lockfileMgr := packagelockfile.NewFileManagerWithPath(deriveLockPath(manifestPath))
+	registryFactory := &registry.DefaultFactory{}
+	svc := service.NewArmService(manifestMgr, lockfileMgr, registryFactory)
+
+	ctx := context.Background()
+
+	if output != "table" {
+		writeStructured(listAll(ctx, svc), output)
+		return
+	}
+
+	// List registries
+	registries, err := svc.GetAllRegistriesConfig(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
 	fmt.Println("Registries:")
 	if len(registries) == 0 {
 		fmt.Println("  (none)")
@@ -1032,8 +1709,32 @@ func handleListAll() {
 		os.Exit(1)
 	}
 
+	mcpsets, err := manifestMgr.GetAllMcpsetDependenciesConfig(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	agentsets, err := manifestMgr.GetAllAgentsetDependenciesConfig(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	skillsets, err := manifestMgr.GetAllSkillsetDependenciesConfig(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	hooksets, err := manifestMgr.GetAllHooksetDependenciesConfig(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
 	fmt.Println("\nDependencies:")
-	if len(rulesets) == 0 && len(promptsets) == 0 {
+	if len(rulesets) == 0 && len(promptsets) == 0 && len(mcpsets) == 0 && len(agentsets) == 0 && len(skillsets) == 0 && len(hooksets) == 0 {
 		fmt.Println("  (none)")
 	} else {
 		for key := range rulesets {
@@ -1042,10 +1743,22 @@ func handleListAll() {
 		for key := range promptsets {
 			fmt.Printf("  %s (promptset)\n", key)
 		}
+		for key := range mcpsets {
+			fmt.Printf("  %s (mcpset)\n", key)
+		}
+		for key := range agentsets {
+			fmt.Printf("  %s (agentset)\n", key)
+		}
+		for key := range skillsets {
+			fmt.Printf("  %s (skillset)\n", key)
+		}
+		for key := range hooksets {
+			fmt.Printf("  %s (hookset)\n", key)
+		}
 	}
 }
 
-func handleListRegistry() {
+func handleListRegistry(output string) {
 	// Get manifest path from env or use default
 	manifestPath := os.Getenv("ARM_MANIFEST_PATH")
 	if manifestPath == "" {
@@ -1064,6 +1777,11 @@ func handleListRegistry() {
 		os.Exit(1)
 	}
 
+	if output != "table" {
+		writeStructured(sortedNames(registries), output)
+		return
+	}
+
 	if len(registries) == 0 {
 		fmt.Println("No registries configured")
 		return
@@ -1080,25 +1798,33 @@ func handleListRegistry() {
 }
 
 func handleInfo() {
+	output := takeOutputFlag("table", "json", "yaml")
 	if len(os.Args) < 3 {
-		handleInfoAll()
+		handleInfoAll(output)
 		return
 	}
 
 	switch os.Args[2] {
 	case "registry":
-		handleInfoRegistry()
+		handleInfoRegistry(output)
 	case "sink":
-		handleInfoSink()
+		handleInfoSink(output)
 	case "dependency":
-		handleInfoDependency()
+		handleInfoDependency(output)
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown info target: %s\n", os.Args[2])
 		os.Exit(1)
 	}
 }
 
-func handleInfoAll() {
+// infoOutput is the structured output of arm info
+type infoOutput struct {
+	Registries   []registryOutput   `json:"registries"`
+	Sinks        []sinkOutput       `json:"sinks"`
+	Dependencies []dependencyOutput `json:"dependencies"`
+}
+
+func handleInfoAll(output string) {
 	manifestPath := os.Getenv("ARM_MANIFEST_PATH")
 	if manifestPath == "" {
 		manifestPath = "arm.json"
@@ -1111,6 +1837,17 @@ func handleInfoAll() {
 
 	ctx := context.Background()
 
+	if output != "table" {
+		names := listAll(ctx, svc)
+		result := infoOutput{
+			Registries:   registryOutputs(ctx, svc, names.Registries),
+			Sinks:        sinkOutputs(ctx, svc, names.Sinks),
+			Dependencies: dependencyOutputs(ctx, svc, dependencyNames(names.Dependencies)),
+		}
+		writeStructured(result, output)
+		return
+	}
+
 	// Show registries
 	registries, err := svc.GetAllRegistriesConfig(ctx)
 	if err != nil {
@@ -1186,8 +1923,32 @@ func handleInfoAll() {
 		os.Exit(1)
 	}
 
+	mcpsets, err := manifestMgr.GetAllMcpsetDependenciesConfig(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	agentsets, err := manifestMgr.GetAllAgentsetDependenciesConfig(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	skillsets, err := manifestMgr.GetAllSkillsetDependenciesConfig(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	hooksets, err := manifestMgr.GetAllHooksetDependenciesConfig(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
 	fmt.Println("\nDependencies:")
-	if len(rulesets) == 0 && len(promptsets) == 0 {
+	if len(rulesets) == 0 && len(promptsets) == 0 && len(mcpsets) == 0 && len(agentsets) == 0 && len(skillsets) == 0 && len(hooksets) == 0 {
 		fmt.Println("  (none)")
 	} else {
 		for key, config := range rulesets {
@@ -1219,10 +1980,66 @@ func handleInfoAll() {
 				fmt.Printf("    exclude: %v\n", config.Exclude)
 			}
 		}
+		for key, config := range mcpsets {
+			fmt.Printf("\n  %s:\n", key)
+			fmt.Printf("    type: mcpset\n")
+			fmt.Printf("    version: %s\n", config.Version)
+			if len(config.Sinks) > 0 {
+				fmt.Printf("    sinks: %v\n", config.Sinks)
+			}
+			if len(config.Include) > 0 {
+				fmt.Printf("    include: %v\n", config.Include)
+			}
+			if len(config.Exclude) > 0 {
+				fmt.Printf("    exclude: %v\n", config.Exclude)
+			}
+		}
+		for key, config := range agentsets {
+			fmt.Printf("\n  %s:\n", key)
+			fmt.Printf("    type: agentset\n")
+			fmt.Printf("    version: %s\n", config.Version)
+			if len(config.Sinks) > 0 {
+				fmt.Printf("    sinks: %v\n", config.Sinks)
+			}
+			if len(config.Include) > 0 {
+				fmt.Printf("    include: %v\n", config.Include)
+			}
+			if len(config.Exclude) > 0 {
+				fmt.Printf("    exclude: %v\n", config.Exclude)
+			}
+		}
+		for key, config := range skillsets {
+			fmt.Printf("\n  %s:\n", key)
+			fmt.Printf("    type: skillset\n")
+			fmt.Printf("    version: %s\n", config.Version)
+			if len(config.Sinks) > 0 {
+				fmt.Printf("    sinks: %v\n", config.Sinks)
+			}
+			if len(config.Include) > 0 {
+				fmt.Printf("    include: %v\n", config.Include)
+			}
+			if len(config.Exclude) > 0 {
+				fmt.Printf("    exclude: %v\n", config.Exclude)
+			}
+		}
+		for key, config := range hooksets {
+			fmt.Printf("\n  %s:\n", key)
+			fmt.Printf("    type: hookset\n")
+			fmt.Printf("    version: %s\n", config.Version)
+			if len(config.Sinks) > 0 {
+				fmt.Printf("    sinks: %v\n", config.Sinks)
+			}
+			if len(config.Include) > 0 {
+				fmt.Printf("    include: %v\n", config.Include)
+			}
+			if len(config.Exclude) > 0 {
+				fmt.Printf("    exclude: %v\n", config.Exclude)
+			}
+		}
 	}
 }
 
-func handleInfoRegistry() {
+func handleInfoRegistry(output string) {
 	// Get manifest path from env or use default
 	manifestPath := os.Getenv("ARM_MANIFEST_PATH")
 	if manifestPath == "" {
@@ -1252,6 +2069,14 @@ func handleInfoRegistry() {
 		}
 	}
 
+	if output != "table" {
+		if len(os.Args) <= 3 {
+			sort.Strings(names)
+		}
+		writeStructured(registryOutputs(ctx, svc, names), output)
+		return
+	}
+
 	if len(names) == 0 {
 		fmt.Println("No registries configured")
 		return
@@ -1315,7 +2140,7 @@ func handleInfoRegistry() {
 	}
 }
 
-func handleListSink() {
+func handleListSink(output string) {
 	manifestPath := os.Getenv("ARM_MANIFEST_PATH")
 	if manifestPath == "" {
 		manifestPath = "arm.json"
@@ -1333,6 +2158,11 @@ func handleListSink() {
 		os.Exit(1)
 	}
 
+	if output != "table" {
+		writeStructured(sortedNames(sinks), output)
+		return
+	}
+
 	if len(sinks) == 0 {
 		fmt.Println("No sinks configured")
 		return
@@ -1348,7 +2178,13 @@ func handleListSink() {
 	}
 }
 
-func handleListDependency() {
+// lockedDependency is the structured output of a dependency in arm list dependency
+type lockedDependency struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+func handleListDependency(output string) {
 	manifestPath := os.Getenv("ARM_MANIFEST_PATH")
 	if manifestPath == "" {
 		manifestPath = "arm.json"
@@ -1358,30 +2194,43 @@ func handleListDependency() {
 
 	ctx := context.Background()
 	lockFile, err := lockfileMgr.GetLockFile(ctx)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return
-		}
+	if err != nil && !os.IsNotExist(err) {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	if lockFile == nil || lockFile.Dependencies == nil || len(lockFile.Dependencies) == 0 {
-		return
+	var keys []string
+	if lockFile != nil {
+		keys = sortedNames(lockFile.Dependencies)
 	}
 
-	keys := make([]string, 0, len(lockFile.Dependencies))
-	for key := range lockFile.Dependencies {
-		keys = append(keys, key)
+	if output != "table" {
+		locked := []lockedDependency{}
+		for _, key := range keys {
+			at := strings.LastIndex(key, "@")
+			locked = append(locked, lockedDependency{Name: key[:at], Version: key[at+1:]})
+		}
+		writeStructured(locked, output)
+		return
 	}
-	sort.Strings(keys)
 
 	for _, key := range keys {
 		fmt.Printf("- %s\n", key)
 	}
 }
 
-func handleListVersions() {
+// versionsOutput is the structured output of arm list versions
+type versionsOutput struct {
+	Package  string          `json:"package"`
+	Versions []versionOutput `json:"versions"`
+}
+
+type versionOutput struct {
+	Version string `json:"version"`
+	Branch  bool   `json:"branch,omitempty"`
+}
+
+func handleListVersions(output string) {
 	if len(os.Args) < 4 {
 		fmt.Fprintf(os.Stderr, "Usage: arm list versions REGISTRY/PACKAGE\n")
 		os.Exit(1)
@@ -1427,6 +2276,15 @@ func handleListVersions() {
 		os.Exit(1)
 	}
 
+	if output != "table" {
+		result := versionsOutput{Package: packageKey, Versions: []versionOutput{}}
+		for _, version := range versions {
+			result.Versions = append(result.Versions, versionOutput{Version: version.Version, Branch: !version.IsSemver})
+		}
+		writeStructured(result, output)
+		return
+	}
+
 	if len(versions) == 0 {
 		fmt.Printf("%s:\n  (no versions found)\n", packageKey)
 		return
@@ -1442,7 +2300,7 @@ func handleListVersions() {
 	}
 }
 
-func handleInfoSink() {
+func handleInfoSink(output string) {
 	manifestPath := os.Getenv("ARM_MANIFEST_PATH")
 	if manifestPath == "" {
 		manifestPath = "arm.json"
@@ -1471,6 +2329,14 @@ func handleInfoSink() {
 		}
 	}
 
+	if output != "table" {
+		if len(os.Args) <= 3 {
+			sort.Strings(names)
+		}
+		writeStructured(sinkOutputs(ctx, svc, names), output)
+		return
+	}
+
 	if len(names) == 0 {
 		fmt.Println("No sinks configured")
 		return
@@ -1491,10 +2357,27 @@ func handleInfoSink() {
 		fmt.Printf("Sink: %s\n", name)
 		fmt.Printf("  Tool: %s\n", config.Tool)
 		fmt.Printf("  Directory: %s\n", config.Directory)
+		if len(config.Enforcement) > 0 {
+			fmt.Printf("  Enforcement: %s\n", formatEnforcementPolicy(config.Enforcement))
+		}
+		if config.Conflicts != "" {
+			fmt.Printf("  Conflicts: %s\n", config.Conflicts)
+		}
+	}
+}
+
+// formatEnforcementPolicy renders a policy as level=activation pairs in must, should, may order
+func formatEnforcementPolicy(policy compiler.EnforcementPolicy) string {
+	var pairs []string
+	for _, level := range []string{"must", "should", "may"} {
+		if activation, ok := policy[level]; ok {
+			pairs = append(pairs, fmt.Sprintf("%s=%s", level, activation))
+		}
 	}
+	return strings.Join(pairs, ",")
 }
 
-func handleInfoDependency() {
+func handleInfoDependency(output string) {
 	manifestPath := os.Getenv("ARM_MANIFEST_PATH")
 	if manifestPath == "" {
 		manifestPath = "arm.json"
@@ -1525,6 +2408,14 @@ func handleInfoDependency() {
 		}
 	}
 
+	if output != "table" {
+		if len(os.Args) <= 3 {
+			sort.Strings(dependencyKeys)
+		}
+		writeStructured(dependencyOutputs(ctx, svc, dependencyKeys), output)
+		return
+	}
+
 	if len(dependencyKeys) == 0 {
 		fmt.Println("No dependencies configured")
 		return
@@ -1597,7 +2488,7 @@ func handleInfoDependency() {
 }
 
 func handleInstall() {
-	if len(os.Args) < 3 {
+	if len(os.Args) < 3 || strings.HasPrefix(os.Args[2], "--") {
 		handleInstallAll()
 		return
 	}
@@ -1607,6 +2498,14 @@ func handleInstall() {
 		handleInstallRuleset()
 	case "promptset":
 		handleInstallPromptset()
+	case "mcpset":
+		handleInstallMcpset()
+	case "agentset":
+		handleInstallAgentset()
+	case "skillset":
+		handleInstallSkillset()
+	case "hookset":
+		handleInstallHookset()
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown install target: %s\n", os.Args[2])
 		os.Exit(1)
@@ -1614,6 +2513,9 @@ func handleInstall() {
 }
 
 func handleInstallAll() {
+	dryRun := takeDryRunFlags(true)
+	jobs := takeJobsFlag()
+
 	manifestPath := os.Getenv("ARM_MANIFEST_PATH")
 	if manifestPath == "" {
 		manifestPath = "arm.json"
@@ -1625,17 +2527,54 @@ func handleInstallAll() {
 	lockfileMgr := packagelockfile.NewFileManagerWithPath(lockfilePath)
 
 	svc := service.NewArmService(manifestMgr, lockfileMgr, nil)
-	ctx := context.Background()
-
-	if err := svc.InstallAll(ctx); err != nil {
+	if err := svc.SetJobs(jobs); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+	ctx := changeContext()
+
+	if !runChange(ctx, svc, manifestPath, lockfilePath, dryRun, func(ctx context.Context, svc *service.ArmService) error {
+		return svc.InstallAll(ctx)
+	}) {
+		return
+	}
+
+	if dryRun.output != "table" {
+		deps, err := svc.GetAllDependenciesConfig(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		writeInstallResult(ctx, svc, sortedNames(deps), dryRun.output)
+		return
+	}
 
 	fmt.Println("All dependencies installed successfully")
+	printRuleConflicts(ctx, svc)
+}
+
+// printRuleConflicts reports rules that conflict across the rulesets installed in each sink
+func printRuleConflicts(ctx context.Context, svc *service.ArmService) {
+	reports, err := svc.RuleConflicts(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to detect rule conflicts: %v\n", err)
+		return
+	}
+
+	for _, report := range reports {
+		fmt.Printf("\nRule conflicts in sink %s:\n", report.Sink)
+		for _, conflict := range report.Conflicts {
+			outcome := "both installed"
+			if conflict.Suppressed {
+				outcome = "lower-priority rule not installed"
+			}
+			fmt.Printf("  %s: %s over %s (%s)\n", conflict.Kind, conflict.Winner, conflict.Loser, outcome)
+		}
+	}
 }
 
 func handleInstallRuleset() {
+	dryRun := takeDryRunFlags(true)
 	var priority int = 100
 	var include []string
 	var exclude []string
@@ -1722,18 +2661,49 @@ func handleInstallRuleset() {
 	lockfileMgr := packagelockfile.NewFileManagerWithPath(lockfilePath)
 
 	svc := service.NewArmService(manifestMgr, lockfileMgr, nil)
-	ctx := context.Background()
+	ctx := changeContext()
 
-	// Call service
-	if err := svc.InstallRuleset(ctx, registryName, ruleset, version, priority, include, exclude, sinks); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+	if !runChange(ctx, svc, manifestPath, lockfilePath, dryRun, func(ctx context.Context, svc *service.ArmService) error {
+		return svc.InstallRuleset(ctx, registryName, ruleset, version, priority, include, exclude, sinks)
+	}) {
+		return
+	}
+
+	if dryRun.output != "table" {
+		writeInstallResult(ctx, svc, []string{manifest.DependencyKey(registryName, ruleset)}, dryRun.output)
+		return
 	}
 
 	fmt.Printf("Installed %s/%s to sinks: %s\n", registryName, ruleset, strings.Join(sinks, ", "))
+	printRuleConflicts(ctx, svc)
 }
 
 func handleInstallPromptset() {
+	handleInstallPackage("PROMPTSET", (*service.ArmService).InstallPromptset)
+}
+
+func handleInstallMcpset() {
+	handleInstallPackage("MCPSET", (*service.ArmService).InstallMcpset)
+}
+
+func handleInstallAgentset() {
+	handleInstallPackage("AGENTSET", (*service.ArmService).InstallAgentset)
+}
+
+func handleInstallSkillset() {
+	handleInstallPackage("SKILLSET", (*service.ArmService).InstallSkillset)
+}
+
+func handleInstallHookset() {
+	handleInstallPackage("HOOKSET", (*service.ArmService).InstallHookset)
+}
+
+// installFunc installs a package that supports include/exclude patterns to the given sinks
+type installFunc func(svc *service.ArmService, ctx context.Context, registryName, name, version string, include, exclude, sinks []string) error
+
+// handleInstallPackage parses the shared install flags for a package kind and runs install
+func handleInstallPackage(kind string, install installFunc) {
+	dryRun := takeDryRunFlags(true)
 	var include []string
 	var exclude []string
 	var packageSpec string
@@ -1772,7 +2742,7 @@ func handleInstallPromptset() {
 	}
 
 	if packageSpec == "" {
-		fmt.Fprintf(os.Stderr, "Package spec required (REGISTRY/PROMPTSET[@VERSION])\n")
+		fmt.Fprintf(os.Stderr, "Package spec required (REGISTRY/%s[@VERSION])\n", kind)
 		os.Exit(1)
 	}
 
@@ -1788,7 +2758,7 @@ func handleInstallPromptset() {
 		os.Exit(1)
 	}
 
-	promptset, err := parsePackage(packageSpec)
+	name, err := parsePackage(packageSpec)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
@@ -1812,15 +2782,20 @@ func handleInstallPromptset() {
 	lockfileMgr := packagelockfile.NewFileManagerWithPath(lockfilePath)
 
 	svc := service.NewArmService(manifestMgr, lockfileMgr, nil)
-	ctx := context.Background()
+	ctx := changeContext()
 
-	// Call service
-	if err := svc.InstallPromptset(ctx, registryName, promptset, version, include, exclude, sinks); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+	if !runChange(ctx, svc, manifestPath, lockfilePath, dryRun, func(ctx context.Context, svc *service.ArmService) error {
+		return install(svc, ctx, registryName, name, version, include, exclude, sinks)
+	}) {
+		return
+	}
+
+	if dryRun.output != "table" {
+		writeInstallResult(ctx, svc, []string{manifest.DependencyKey(registryName, name)}, dryRun.output)
+		return
 	}
 
-	fmt.Printf("Installed %s/%s to sinks: %s\n", registryName, promptset, strings.Join(sinks, ", "))
+	fmt.Printf("Installed %s/%s to sinks: %s\n", registryName, name, strings.Join(sinks, ", "))
 }
 
 func parseRegistry(input string) (string, error) {
@@ -1875,6 +2850,8 @@ func parseVersion(input string) (string, error) {
 }
 
 func handleUninstall() {
+	dryRun := takeDryRunFlags(false)
+
 	// Parse package arguments (everything after "uninstall")
 	packages := os.Args[2:]
 
@@ -1889,28 +2866,29 @@ func handleUninstall() {
 	lockfileMgr := packagelockfile.NewFileManagerWithPath(lockfilePath)
 
 	svc := service.NewArmService(manifestMgr, lockfileMgr, nil)
-	ctx := context.Background()
+	ctx := changeContext()
 
-	// If no packages specified, uninstall all
-	if len(packages) == 0 {
-		if err := svc.UninstallAll(ctx); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+	if !runChange(ctx, svc, manifestPath, lockfilePath, dryRun, func(ctx context.Context, svc *service.ArmService) error {
+		// If no packages specified, uninstall all
+		if len(packages) == 0 {
+			return svc.UninstallAll(ctx)
 		}
-		fmt.Println("All packages uninstalled successfully")
+		return svc.UninstallPackages(ctx, packages)
+	}) {
 		return
 	}
 
-	// Uninstall specific packages
-	if err := svc.UninstallPackages(ctx, packages); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+	if len(packages) == 0 {
+		fmt.Println("All packages uninstalled successfully")
+		return
 	}
-
 	fmt.Println("Packages uninstalled successfully")
 }
 
 func handleUpdate() {
+	dryRun := takeDryRunFlags(false)
+	jobs := takeJobsFlag()
+
 	// Parse package arguments (everything after "update")
 	packages := os.Args[2:]
 
@@ -1925,28 +2903,33 @@ func handleUpdate() {
 	lockfileMgr := packagelockfile.NewFileManagerWithPath(lockfilePath)
 
 	svc := service.NewArmService(manifestMgr, lockfileMgr, nil)
-	ctx := context.Background()
+	if err := svc.SetJobs(jobs); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	ctx := changeContext()
 
-	// If no packages specified, update all
-	if len(packages) == 0 {
-		if err := svc.UpdateAll(ctx); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+	if !runChange(ctx, svc, manifestPath, lockfilePath, dryRun, func(ctx context.Context, svc *service.ArmService) error {
+		// If no packages specified, update all
+		if len(packages) == 0 {
+			return svc.UpdateAll(ctx)
 		}
-		fmt.Println("All packages updated successfully")
+		return svc.UpdatePackages(ctx, packages)
+	}) {
 		return
 	}
 
-	// Update specific packages
-	if err := svc.UpdatePackages(ctx, packages); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+	if len(packages) == 0 {
+		fmt.Println("All packages updated successfully")
+		return
 	}
-
 	fmt.Println("Packages updated successfully")
 }
 
 func handleUpgrade() {
+	dryRun := takeDryRunFlags(false)
+	jobs := takeJobsFlag()
+
 	manifestPath := os.Getenv("ARM_MANIFEST_PATH")
 	if manifestPath == "" {
 		manifestPath = "arm.json"
@@ -1958,46 +2941,164 @@ func handleUpgrade() {
 	lockfileMgr := packagelockfile.NewFileManagerWithPath(lockfilePath)
 
 	svc := service.NewArmService(manifestMgr, lockfileMgr, nil)
-	ctx := context.Background()
+	if err := svc.SetJobs(jobs); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	ctx := changeContext()
 
-	packages := os.Args[2:]
-	if len(packages) == 0 {
-		if err := svc.UpgradeAll(ctx); err != nil {
+	var packages []string
+	interactive := false
+	for _, arg := range os.Args[2:] {
+		if arg == "--interactive" || arg == "-i" {
+			interactive = true
+		} else {
+			packages = append(packages, arg)
+		}
+	}
+
+	if interactive {
+		outdated, err := svc.ListOutdated(ctx)
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
+		if len(packages) > 0 {
+			outdated = slices.DeleteFunc(outdated, func(dep *service.OutdatedDependency) bool {
+				return !slices.Contains(packages, dep.Current.RegistryName+"/"+dep.Current.Name)
+			})
+		}
+		if len(outdated) == 0 {
+			fmt.Println("All packages are up to date")
+			return
+		}
+
+		updates, upgrades := selectUpgrades(ctx, svc, outdated)
+		if len(updates) == 0 && len(upgrades) == 0 {
+			fmt.Println("No packages selected")
+			return
+		}
+		if !runChange(ctx, svc, manifestPath, lockfilePath, dryRun, func(ctx context.Context, svc *service.ArmService) error {
+			if len(updates) > 0 {
+				if err := svc.UpdatePackages(ctx, updates); err != nil {
+					return err
+				}
+			}
+			if len(upgrades) > 0 {
+				return svc.UpgradePackages(ctx, upgrades)
+			}
+			return nil
+		}) {
+			return
+		}
+		fmt.Println("Selected packages upgraded successfully")
+		return
+	}
+
+	if !runChange(ctx, svc, manifestPath, lockfilePath, dryRun, func(ctx context.Context, svc *service.ArmService) error {
+		if len(packages) == 0 {
+			return svc.UpgradeAll(ctx)
+		}
+		return svc.UpgradePackages(ctx, packages)
+	}) {
+		return
+	}
+	if len(packages) == 0 {
 		fmt.Println("All packages upgraded successfully")
 	} else {
-		if err := svc.UpgradePackages(ctx, packages); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
-		}
 		fmt.Println("Specified packages upgraded successfully")
 	}
 }
 
-func handleOutdated() {
-	outputFormat := "table"
+// changelogLines is how much of a changelog upgrade --interactive shows
+const changelogLines = 20
 
-	// Parse flags
-	i := 2
-	for i < len(os.Args) {
-		arg := os.Args[i]
-		if arg == "--output" {
-			if i+1 >= len(os.Args) {
-				fmt.Fprintf(os.Stderr, "--output requires a value\n")
-				os.Exit(1)
+// selectUpgrades shows each outdated package with the changelog of its latest
+// version and asks whether to move it to the wanted version, the latest
+// version or neither. Packages moving to the wanted version are returned as
+// updates, so their manifest constraints are kept.
+func selectUpgrades(ctx context.Context, svc *service.ArmService, outdated []*service.OutdatedDependency) (updates, upgrades []string) {
+	reader := bufio.NewReader(os.Stdin)
+	for _, dep := range outdated {
+		key := dep.Current.RegistryName + "/" + dep.Current.Name
+		current := dep.Current.Version.Version
+		wanted := dep.Wanted.Version.Version
+		latest := dep.Latest.Version.Version
+
+		fmt.Printf("%s  current %s  wanted %s  latest %s\n", key, current, wanted, latest)
+		printChangelog(ctx, svc, dep.Latest)
+
+		// The wanted version is only a separate choice when it is neither the
+		// current nor the latest version
+		offerWanted := wanted != current && wanted != latest
+		choices := "[l]atest " + latest + ", [s]kip"
+		if offerWanted {
+			choices = "[w]anted " + wanted + ", " + choices
+		}
+
+		for {
+			fmt.Printf("Upgrade %s to %s? ", key, choices)
+			answer, err := reader.ReadString('\n')
+			answer = strings.ToLower(strings.TrimSpace(answer))
+			if err != nil && answer == "" {
+				// Input ended, so skip the remaining packages
+				fmt.Println()
+				return updates, upgrades
 			}
-			outputFormat = os.Args[i+1]
-			if outputFormat != "table" && outputFormat != "json" && outputFormat != "list" {
-				fmt.Fprintf(os.Stderr, "Invalid output format: %s (must be table, json, or list)\n", outputFormat)
-				os.Exit(1)
+
+			valid := true
+			switch {
+			case answer == "" || answer == "s" || answer == "skip":
+			case offerWanted && (answer == "w" || answer == "wanted"):
+				updates = append(updates, key)
+			case answer == "l" || answer == "latest":
+				if wanted == latest {
+					updates = append(updates, key)
+				} else {
+					upgrades = append(upgrades, key)
+				}
+			default:
+				fmt.Printf("Unknown choice: %s\n", answer)
+				valid = false
+			}
+			if valid {
+				break
 			}
-			i += 2
-		} else {
-			fmt.Fprintf(os.Stderr, "Unknown flag: %s\n", arg)
-			os.Exit(1)
 		}
+		fmt.Println()
+	}
+	return updates, upgrades
+}
+
+// printChangelog prints the first lines of the release notes for a version
+func printChangelog(ctx context.Context, svc *service.ArmService, pkg core.PackageMetadata) {
+	changelog, err := svc.Changelog(ctx, pkg.RegistryName, pkg.Name, pkg.Version.Version)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return
+	}
+	if changelog == "" {
+		fmt.Printf("    No changelog for %s\n", pkg.Version.Version)
+		return
+	}
+
+	lines := strings.Split(changelog, "\n")
+	if len(lines) > changelogLines {
+		lines = append(lines[:changelogLines], "...")
+	}
+	for _, line := range lines {
+		fmt.Printf("    %s\n", line)
+	}
+}
+
+func handleOutdated() {
+	jobs := takeJobsFlag()
+	outputFormat := takeOutputFlag("table", "json", "yaml", "list")
+
+	// Parse flags
+	for _, arg := range os.Args[2:] {
+		fmt.Fprintf(os.Stderr, "Unknown flag: %s\n", arg)
+		os.Exit(1)
 	}
 
 	manifestPath := os.Getenv("ARM_MANIFEST_PATH")
@@ -2011,6 +3112,10 @@ func handleOutdated() {
 	lockfileMgr := packagelockfile.NewFileManagerWithPath(lockfilePath)
 
 	svc := service.NewArmService(manifestMgr, lockfileMgr, nil)
+	if err := svc.SetJobs(jobs); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 	ctx := context.Background()
 
 	outdated, err := svc.ListOutdated(ctx)
@@ -2019,14 +3124,17 @@ func handleOutdated() {
 		os.Exit(1)
 	}
 
+	if outputFormat == "json" || outputFormat == "yaml" {
+		writeOutdated(outdated, outputFormat)
+		return
+	}
+
 	if len(outdated) == 0 {
 		fmt.Println("All packages are up to date")
 		return
 	}
 
 	switch outputFormat {
-	case "json":
-		printOutdatedJSON(outdated)
 	case "list":
 		printOutdatedList(outdated)
 	default:
@@ -2103,23 +3211,27 @@ func printOutdatedTable(outdated []*service.OutdatedDependency) {
 	}
 }
 
-func printOutdatedJSON(outdated []*service.OutdatedDependency) {
-	fmt.Println("[")
-	for i, dep := range outdated {
-		pkgName := dep.Current.RegistryName + "/" + dep.Current.Name
-		fmt.Printf("  {\n")
-		fmt.Printf("    \"package\": \"%s\",\n", pkgName)
-		fmt.Printf("    \"constraint\": \"%s\",\n", dep.Constraint)
-		fmt.Printf("    \"current\": \"%s\",\n", dep.Current.Version.Version)
-		fmt.Printf("    \"wanted\": \"%s\",\n", dep.Wanted.Version.Version)
-		fmt.Printf("    \"latest\": \"%s\"\n", dep.Latest.Version.Version)
-		if i < len(outdated)-1 {
-			fmt.Printf("  },\n")
-		} else {
-			fmt.Printf("  }\n")
-		}
-	}
-	fmt.Println("]")
+// outdatedOutput is the structured output of an outdated dependency
+type outdatedOutput struct {
+	Package    string `json:"package"`
+	Constraint string `json:"constraint"`
+	Current    string `json:"current"`
+	Wanted     string `json:"wanted"`
+	Latest     string `json:"latest"`
+}
+
+func writeOutdated(outdated []*service.OutdatedDependency, output string) {
+	result := []outdatedOutput{}
+	for _, dep := range outdated {
+		result = append(result, outdatedOutput{
+			Package:    dep.Current.RegistryName + "/" + dep.Current.Name,
+			Constraint: dep.Constraint,
+			Current:    dep.Current.Version.Version,
+			Wanted:     dep.Wanted.Version.Version,
+			Latest:     dep.Latest.Version.Version,
+		})
+	}
+	writeStructured(result, output)
 }
 
 func printOutdatedList(outdated []*service.OutdatedDependency) {
@@ -2133,6 +3245,449 @@ func printOutdatedList(outdated []*service.OutdatedDependency) {
 	}
 }
 
+func handleDiff() {
+	output := takeOutputFlag("table", "json", "yaml")
+	args := os.Args[2:]
+	if len(args) < 1 || len(args) > 3 || !strings.Contains(args[0], "/") {
+		fmt.Fprintf(os.Stderr, "Usage: arm diff REGISTRY/PACKAGE [FROM] [TO] [--output FORMAT]\n")
+		os.Exit(1)
+	}
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "--") {
+			fmt.Fprintf(os.Stderr, "Unknown flag: %s\n", arg)
+			os.Exit(1)
+		}
+	}
+	registryName, packageName, _ := strings.Cut(args[0], "/")
+	var from, to string
+	if len(args) > 1 {
+		from = args[1]
+	}
+	if len(args) > 2 {
+		to = args[2]
+	}
+
+	manifestPath := os.Getenv("ARM_MANIFEST_PATH")
+	if manifestPath == "" {
+		manifestPath = "arm.json"
+	}
+	lockfilePath := strings.TrimSuffix(manifestPath, ".json") + "-lock.json"
+
+	svc := service.NewArmService(manifest.NewFileManagerWithPath(manifestPath), packagelockfile.NewFileManagerWithPath(lockfilePath), nil)
+	diff, err := svc.DiffPackage(context.Background(), registryName, packageName, from, to)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if output != "table" {
+		writeStructured(diff, output)
+		return
+	}
+
+	fmt.Printf("%s %s -> %s\n", diff.Package, diff.From, diff.To)
+	if len(diff.Rules) == 0 && len(diff.Prompts) == 0 {
+		fmt.Println("No rule or prompt changes")
+		return
+	}
+	for _, section := range []struct {
+		title string
+		diffs []service.ResourceDiff
+	}{{"Rules", diff.Rules}, {"Prompts", diff.Prompts}} {
+		if len(section.diffs) == 0 {
+			continue
+		}
+		fmt.Printf("%s:\n", section.title)
+		for _, item := range section.diffs {
+			printResourceDiff(item)
+		}
+	}
+}
+
+// diffContext is the number of unchanged body lines shown around a change
+const diffContext = 2
+
+func printResourceDiff(item service.ResourceDiff) {
+	switch item.Action {
+	case service.DiffAdded:
+		fmt.Printf("  + %s\n", item.ID)
+	case service.DiffRemoved:
+		fmt.Printf("  - %s\n", item.ID)
+	default:
+		fmt.Printf("  ~ %s\n", item.ID)
+	}
+	for _, field := range item.Fields {
+		fmt.Printf("      %s: %s -> %s\n", field.Field, dashIfEmpty(field.Old), dashIfEmpty(field.New))
+	}
+
+	// Unchanged lines far from any change are elided
+	shown := make([]bool, len(item.Body))
+	for i, line := range item.Body {
+		if line.Op == core.DiffEqual {
+			continue
+		}
+		for j := max(0, i-diffContext); j <= min(len(item.Body)-1, i+diffContext); j++ {
+			shown[j] = true
+		}
+	}
+	elided := false
+	for i, line := range item.Body {
+		if !shown[i] {
+			if !elided {
+				fmt.Println("      ...")
+				elided = true
+			}
+			continue
+		}
+		elided = false
+		op := " "
+		if line.Op != core.DiffEqual {
+			op = line.Op
+		}
+		fmt.Printf("      %s %s\n", op, line.Text)
+	}
+}
+
+func handleSearch() {
+	jobs := takeJobsFlag()
+	output := takeOutputFlag("table", "json", "yaml")
+
+	var query string
+	var registries []string
+	args := os.Args[2:]
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--registry":
+			if i+1 >= len(args) {
+				fmt.Fprintf(os.Stderr, "--registry requires a value\n")
+				os.Exit(1)
+			}
+			registries = append(registries, args[i+1])
+			i++
+		case strings.HasPrefix(args[i], "--"):
+			fmt.Fprintf(os.Stderr, "Unknown flag: %s\n", args[i])
+			os.Exit(1)
+		case query == "":
+			query = args[i]
+		default:
+			fmt.Fprintf(os.Stderr, "Usage: arm search QUERY [--registry NAME]... [--output FORMAT] [--jobs N]\n")
+			os.Exit(1)
+		}
+	}
+	if query == "" {
+		fmt.Fprintf(os.Stderr, "Usage: arm search QUERY [--registry NAME]... [--output FORMAT] [--jobs N]\n")
+		os.Exit(1)
+	}
+
+	manifestPath := os.Getenv("ARM_MANIFEST_PATH")
+	if manifestPath == "" {
+		manifestPath = "arm.json"
+	}
+	lockfilePath := strings.TrimSuffix(manifestPath, ".json") + "-lock.json"
+
+	svc := service.NewArmService(manifest.NewFileManagerWithPath(manifestPath), packagelockfile.NewFileManagerWithPath(lockfilePath), nil)
+	if err := svc.SetJobs(jobs); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	results, err := svc.Search(context.Background(), query, registries)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if output != "table" {
+		writeStructured(results, output)
+		return
+	}
+	if len(results) == 0 {
+		fmt.Printf("No packages found matching %q\n", query)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "Package\tLatest\tDescription")
+	for _, result := range results {
+		_, _ = fmt.Fprintf(w, "%s/%s\t%s\t%s\n", result.Registry, result.Package, dashIfEmpty(result.Latest), dashIfEmpty(result.Description))
+	}
+	_ = w.Flush()
+}
+
+func handleVerify() {
+	jobs := takeJobsFlag()
+	output := takeOutputFlag("table", "json", "yaml")
+	for _, arg := range os.Args[2:] {
+		fmt.Fprintf(os.Stderr, "Unknown flag: %s\n", arg)
+		os.Exit(1)
+	}
+
+	manifestPath := os.Getenv("ARM_MANIFEST_PATH")
+	if manifestPath == "" {
+		manifestPath = "arm.json"
+	}
+	lockfilePath := strings.TrimSuffix(manifestPath, ".json") + "-lock.json"
+
+	svc := service.NewArmService(manifest.NewFileManagerWithPath(manifestPath), packagelockfile.NewFileManagerWithPath(lockfilePath), nil)
+	if err := svc.SetJobs(jobs); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	report, err := svc.Verify(context.Background())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if output != "table" {
+		writeStructured(report, output)
+	} else {
+		printVerifyReport(report)
+	}
+	if !report.Clean() {
+		os.Exit(1)
+	}
+}
+
+func printVerifyReport(report *service.VerifyReport) {
+	if report.Clean() {
+		fmt.Println("No drift detected")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if len(report.Files) > 0 {
+		fmt.Println("Files:")
+		_, _ = fmt.Fprintln(w, "  Kind\tSink\tPath\tPackage")
+		for _, file := range report.Files {
+			_, _ = fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", file.Kind, file.Sink, file.Path, dashIfEmpty(file.Package))
+		}
+		_ = w.Flush()
+	}
+	if len(report.Packages) > 0 {
+		fmt.Println("Packages:")
+		_, _ = fmt.Fprintln(w, "  Package\tLocked\tActual")
+		for _, pkg := range report.Packages {
+			actual := pkg.Actual
+			if pkg.Error != "" {
+				actual = "error: " + pkg.Error
+			}
+			_, _ = fmt.Fprintf(w, "  %s\t%s\t%s\n", pkg.Package, pkg.Locked, actual)
+		}
+		_ = w.Flush()
+	}
+}
+
+func handleTree() {
+	output := takeOutputFlag("table", "json", "yaml")
+	for _, arg := range os.Args[2:] {
+		fmt.Fprintf(os.Stderr, "Unknown flag: %s\n", arg)
+		os.Exit(1)
+	}
+
+	manifestPath := os.Getenv("ARM_MANIFEST_PATH")
+	if manifestPath == "" {
+		manifestPath = "arm.json"
+	}
+	lockfilePath := strings.TrimSuffix(manifestPath, ".json") + "-lock.json"
+
+	svc := service.NewArmService(manifest.NewFileManagerWithPath(manifestPath), packagelockfile.NewFileManagerWithPath(lockfilePath), nil)
+	trees, err := svc.Tree(context.Background())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if output != "table" {
+		result := []registryTreeOutput{}
+		for _, registryTree := range trees {
+			result = append(result, newRegistryTreeOutput(registryTree))
+		}
+		writeStructured(result, output)
+		return
+	}
+
+	for _, registryTree := range trees {
+		fmt.Println(registryTree.Name)
+		for _, pkg := range registryTree.Packages {
+			if pkg.Version == "" {
+				fmt.Printf("    %s  %s  not installed\n", pkg.Name, pkg.Type)
+				continue
+			}
+			if pkg.Type == "ruleset" {
+				fmt.Printf("    %s@%s  %s  priority %d\n", pkg.Name, pkg.Version, pkg.Type, pkg.Priority)
+			} else {
+				fmt.Printf("    %s@%s  %s\n", pkg.Name, pkg.Version, pkg.Type)
+			}
+			for _, sinkTree := range pkg.Sinks {
+				fmt.Printf("        %s  %s\n", sinkTree.Name, sinkTree.Directory)
+				for _, file := range sinkTree.Files {
+					fmt.Printf("            %s%s\n", filepath.Join(sinkTree.Directory, file.Path), describeOrigin(file))
+				}
+			}
+		}
+	}
+}
+
+func handleWhy() {
+	output := takeOutputFlag("table", "json", "yaml")
+	if len(os.Args) != 3 || strings.HasPrefix(os.Args[2], "--") {
+		fmt.Fprintf(os.Stderr, "Usage: arm why PATH [--output FORMAT]\n")
+		os.Exit(1)
+	}
+	path := os.Args[2]
+
+	manifestPath := os.Getenv("ARM_MANIFEST_PATH")
+	if manifestPath == "" {
+		manifestPath = "arm.json"
+	}
+
+	svc := service.NewArmService(manifest.NewFileManagerWithPath(manifestPath), nil, nil)
+	origins, err := svc.Why(context.Background(), path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if output != "table" {
+		result := []originOutput{}
+		for _, origin := range origins {
+			result = append(result, newOriginOutput(origin))
+		}
+		writeStructured(result, output)
+		return
+	}
+
+	fmt.Println(path)
+	for _, origin := range origins {
+		fmt.Printf("    package:  %s\n", origin.Package)
+		fmt.Printf("    type:     %s\n", origin.Type)
+		if origin.Type == "ruleset" {
+			fmt.Printf("    priority: %d\n", origin.Priority)
+		}
+		fmt.Printf("    sink:     %s\n", origin.Sink)
+		if origin.Source != "" {
+			fmt.Printf("    source:   %s\n", origin.Source)
+		}
+		if origin.Rule != "" {
+			fmt.Printf("    rule:     %s/%s\n", origin.Ruleset, origin.Rule)
+		}
+		if origin.Shared {
+			fmt.Println("    shared configuration file; other packages and your own settings may also write to it")
+		}
+		if origin.Suppressed {
+			fmt.Println("    suppressed: withheld in favour of a conflicting higher-priority rule")
+		}
+	}
+}
+
+// describeOrigin returns the rule and source of an installed file for tree output
+func describeOrigin(file sink.InstalledFile) string {
+	var parts []string
+	if file.Rule != "" {
+		parts = append(parts, "rule "+file.Ruleset+"/"+file.Rule)
+	}
+	if file.Source != "" {
+		parts = append(parts, "from "+file.Source)
+	}
+	if file.Shared {
+		parts = append(parts, "shared")
+	}
+	if file.Suppressed {
+		parts = append(parts, "suppressed")
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "  (" + strings.Join(parts, ", ") + ")"
+}
+
+// registryTreeOutput is the structured output of arm tree
+type registryTreeOutput struct {
+	Name     string              `json:"name"`
+	Packages []packageTreeOutput `json:"packages"`
+}
+
+type packageTreeOutput struct {
+	Name     string           `json:"name"`
+	Type     string           `json:"type"`
+	Version  string           `json:"version,omitempty"`
+	Priority *int             `json:"priority,omitempty"`
+	Sinks    []sinkTreeOutput `json:"sinks"`
+}
+
+type sinkTreeOutput struct {
+	Name      string       `json:"name"`
+	Directory string       `json:"directory"`
+	Files     []fileOutput `json:"files"`
+}
+
+type fileOutput struct {
+	Path       string `json:"path"`
+	Source     string `json:"source,omitempty"`
+	Ruleset    string `json:"ruleset,omitempty"`
+	Rule       string `json:"rule,omitempty"`
+	Shared     bool   `json:"shared,omitempty"`
+	Suppressed bool   `json:"suppressed,omitempty"`
+}
+
+func newRegistryTreeOutput(registryTree *service.RegistryTree) registryTreeOutput {
+	result := registryTreeOutput{Name: registryTree.Name, Packages: []packageTreeOutput{}}
+	for _, pkg := range registryTree.Packages {
+		pkgOutput := packageTreeOutput{Name: pkg.Name, Type: pkg.Type, Version: pkg.Version, Sinks: []sinkTreeOutput{}}
+		if pkg.Type == "ruleset" {
+			priority := pkg.Priority
+			pkgOutput.Priority = &priority
+		}
+		for _, sinkTree := range pkg.Sinks {
+			sinkOutput := sinkTreeOutput{Name: sinkTree.Name, Directory: sinkTree.Directory, Files: []fileOutput{}}
+			for _, file := range sinkTree.Files {
+				sinkOutput.Files = append(sinkOutput.Files, fileOutput{
+					Path:       filepath.Join(sinkTree.Directory, file.Path),
+					Source:     file.Source,
+					Ruleset:    file.Ruleset,
+					Rule:       file.Rule,
+					Shared:     file.Shared,
+					Suppressed: file.Suppressed,
+				})
+			}
+			pkgOutput.Sinks = append(pkgOutput.Sinks, sinkOutput)
+		}
+		result.Packages = append(result.Packages, pkgOutput)
+	}
+	return result
+}
+
+// originOutput is the structured output of arm why
+type originOutput struct {
+	Package    string `json:"package"`
+	Type       string `json:"type"`
+	Priority   *int   `json:"priority,omitempty"`
+	Sink       string `json:"sink"`
+	Source     string `json:"source,omitempty"`
+	Ruleset    string `json:"ruleset,omitempty"`
+	Rule       string `json:"rule,omitempty"`
+	Shared     bool   `json:"shared,omitempty"`
+	Suppressed bool   `json:"suppressed,omitempty"`
+}
+
+func newOriginOutput(origin *service.FileOrigin) originOutput {
+	result := originOutput{
+		Package:    origin.Package,
+		Type:       origin.Type,
+		Sink:       origin.Sink,
+		Source:     origin.Source,
+		Ruleset:    origin.Ruleset,
+		Rule:       origin.Rule,
+		Shared:     origin.Shared,
+		Suppressed: origin.Suppressed,
+	}
+	if origin.Type == "ruleset" {
+		priority := origin.Priority
+		result.Priority = &priority
+	}
+	return result
+}
+
 func handleClean() {
 	if len(os.Args) < 3 {
 		fmt.Fprintf(os.Stderr, "Error: clean requires a subcommand (cache, sinks)\n")
@@ -2153,6 +3708,7 @@ func handleClean() {
 }
 
 func handleCleanCache() {
+	dryRun := takeDryRunFlags(false)
 	var maxAge string
 	var nuke bool
 
@@ -2189,6 +3745,27 @@ func handleCleanCache() {
 	svc := service.NewArmService(nil, nil, nil)
 	ctx := context.Background()
 
+	if dryRun.enabled {
+		var plan *service.Plan
+		var err error
+		if nuke {
+			plan, err = svc.PlanNukeCache(ctx)
+		} else {
+			var duration time.Duration
+			if duration, err = parseDuration(maxAge); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: invalid duration format: %v\n", err)
+				os.Exit(1)
+			}
+			plan, err = svc.PlanCleanCacheByAge(ctx, duration)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		writePlan(plan, dryRun.output)
+		return
+	}
+
 	if nuke {
 		if err := svc.NukeCache(ctx); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -2227,6 +3804,7 @@ func parseDuration(s string) (time.Duration, error) {
 }
 
 func handleCleanSinks() {
+	dryRun := takeDryRunFlags(false)
 	var nuke bool
 
 	// Parse flags
@@ -2245,6 +3823,16 @@ func handleCleanSinks() {
 	svc := service.NewArmService(manifestMgr, nil, nil)
 	ctx := context.Background()
 
+	if dryRun.enabled {
+		printPlan(ctx, svc, "arm.json", deriveLockPath("arm.json"), dryRun, func(ctx context.Context, svc *service.ArmService) error {
+			if nuke {
+				return svc.NukeSinks(ctx)
+			}
+			return svc.CleanSinks(ctx)
+		})
+		return
+	}
+
 	if nuke {
 		if err := svc.NukeSinks(ctx); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -2261,6 +3849,169 @@ func handleCleanSinks() {
 	fmt.Println("Sinks cleaned successfully")
 }
 
+func handleInit() {
+	var tools []compiler.Tool
+	registries := make(map[string]string)
+	var yes, force bool
+
+	args := os.Args[2:]
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--tool", "--registry":
+			if i+1 >= len(args) {
+				fmt.Fprintf(os.Stderr, "Error: %s requires a value\n", args[i])
+				os.Exit(1)
+			}
+			if args[i] == "--tool" {
+				tools = append(tools, compiler.Tool(args[i+1]))
+			} else {
+				name, url, ok := strings.Cut(args[i+1], "=")
+				if !ok || name == "" || url == "" {
+					fmt.Fprintf(os.Stderr, "Error: --registry must be NAME=URL, got %s\n", args[i+1])
+					os.Exit(1)
+				}
+				registries[name] = url
+			}
+			i++
+		case "--yes", "-y":
+			yes = true
+		case "--force":
+			force = true
+		default:
+			fmt.Fprintf(os.Stderr, "Unknown flag: %s\n", args[i])
+			os.Exit(1)
+		}
+	}
+
+	manifestPath := os.Getenv("ARM_MANIFEST_PATH")
+	if manifestPath == "" {
+		manifestPath = "arm.json"
+	}
+	if _, err := os.Stat(manifestPath); err == nil && !force {
+		fmt.Fprintf(os.Stderr, "Error: %s already exists (use --force to replace it)\n", manifestPath)
+		os.Exit(1)
+	}
+
+	// Ask only when no choices were given on the command line
+	if len(tools) == 0 {
+		tools = service.DetectTools(".")
+		if !yes && len(registries) == 0 {
+			tools, registries = promptInit(tools)
+		}
+	}
+
+	svc := service.NewArmService(manifest.NewFileManagerWithPath(manifestPath), nil, nil)
+	if err := svc.Init(context.Background(), &service.InitRequest{Tools: tools, GitRegistries: registries}); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Created %s\n", manifestPath)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, tool := range tools {
+		for _, sink := range service.DefaultSinks(tool) {
+			_, _ = fmt.Fprintf(w, "  sink\t%s\t%s\t%s\n", sink.Name, sink.Directory, sink.Tool)
+		}
+	}
+	for _, name := range sortedNames(registries) {
+		_, _ = fmt.Fprintf(w, "  registry\t%s\t%s\tgit\n", name, registries[name])
+	}
+	_ = w.Flush()
+	if len(tools) == 0 {
+		fmt.Println("No sinks created; add them with 'arm add sink'")
+	}
+	fmt.Println("Install packages with 'arm install ruleset REGISTRY/PACKAGE SINK'")
+}
+
+// promptInit asks which tools to create sinks for, defaulting to the detected
+// ones, and for a git registry to add
+func promptInit(detected []compiler.Tool) ([]compiler.Tool, map[string]string) {
+	reader := bufio.NewReader(os.Stdin)
+	ask := func(question string) string {
+		fmt.Print(question)
+		answer, _ := reader.ReadString('\n')
+		return strings.TrimSpace(answer)
+	}
+
+	var defaults []string
+	for _, tool := range detected {
+		defaults = append(defaults, string(tool))
+	}
+	if len(defaults) == 0 {
+		fmt.Println("No AI tools detected")
+	} else {
+		fmt.Printf("Detected: %s\n", strings.Join(defaults, ", "))
+	}
+
+	tools := detected
+	answer := ask(fmt.Sprintf("Tools to create sinks for (cursor, copilot, amazonq, kiro, claude or none) [%s]: ", strings.Join(defaults, ", ")))
+	if answer != "" {
+		tools = nil
+		for _, name := range strings.FieldsFunc(answer, func(r rune) bool { return r == ',' || r == ' ' }) {
+			if name != "none" {
+				tools = append(tools, compiler.Tool(name))
+			}
+		}
+	}
+
+	registries := make(map[string]string)
+	if url := ask("Git registry URL (leave empty to skip): "); url != "" {
+		defaultName := strings.TrimSuffix(path.Base(strings.TrimRight(url, "/")), ".git")
+		name := ask(fmt.Sprintf("Registry name [%s]: ", defaultName))
+		if name == "" {
+			name = defaultName
+		}
+		registries[name] = url
+	}
+	return tools, registries
+}
+
+func handleNew() {
+	req := &service.NewPackageRequest{Dir: "."}
+	var positional []string
+
+	args := os.Args[2:]
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--name", "--output":
+			if i+1 >= len(args) {
+				fmt.Fprintf(os.Stderr, "Error: %s requires a value\n", args[i])
+				os.Exit(1)
+			}
+			if args[i] == "--name" {
+				req.Name = args[i+1]
+			} else {
+				req.Dir = args[i+1]
+			}
+			i++
+		case "--force":
+			req.Force = true
+		default:
+			if strings.HasPrefix(args[i], "--") {
+				fmt.Fprintf(os.Stderr, "Unknown flag: %s\n", args[i])
+				os.Exit(1)
+			}
+			positional = append(positional, args[i])
+		}
+	}
+	if len(positional) != 2 {
+		fmt.Fprintf(os.Stderr, "Usage: arm new ruleset|promptset ID [--name NAME] [--output DIR] [--force]\n")
+		os.Exit(1)
+	}
+	req.Kind, req.ID = positional[0], positional[1]
+
+	svc := service.NewArmService(nil, nil, nil)
+	written, err := svc.NewPackage(context.Background(), req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	for _, file := range written {
+		fmt.Printf("Created %s\n", file)
+	}
+	fmt.Printf("Edit the example %s, then check it with 'arm compile --validate-only %s'\n", strings.TrimSuffix(req.Kind, "set"), req.Dir)
+}
+
 func handleCompile() {
 	var tool string
 	var namespace string
@@ -2340,8 +4091,12 @@ func handleCompile() {
 		paths = paths[:len(paths)-1]
 	}
 
-	// Create service and compile
-	svc := service.NewArmService(nil, nil, nil)
+	// Create service and compile; the manifest supplies user-defined tools
+	manifestPath := os.Getenv("ARM_MANIFEST_PATH")
+	if manifestPath == "" {
+		manifestPath = "arm.json"
+	}
+	svc := service.NewArmService(manifest.NewFileManagerWithPath(manifestPath), nil, nil)
 	ctx := context.Background()
 
 	req := &service.CompileRequest{
@@ -2368,3 +4123,62 @@ func handleCompile() {
 		fmt.Println("Compilation successful")
 	}
 }
+
+func handleImport() {
+	req := &service.ImportRequest{}
+
+	// Parse flags and arguments
+	args := os.Args[2:]
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--tool", "--id", "--name", "--output":
+			if i+1 >= len(args) {
+				fmt.Fprintf(os.Stderr, "Error: %s requires a value\n", args[i])
+				os.Exit(1)
+			}
+			switch args[i] {
+			case "--tool":
+				req.Tool = args[i+1]
+			case "--id":
+				req.ID = args[i+1]
+			case "--name":
+				req.Name = args[i+1]
+			case "--output":
+				req.Output = args[i+1]
+			}
+			i++
+		case "--force":
+			req.Force = true
+		case "--recursive":
+			req.Recursive = true
+		default:
+			if strings.HasPrefix(args[i], "--") {
+				fmt.Fprintf(os.Stderr, "Unknown flag: %s\n", args[i])
+				os.Exit(1)
+			}
+			req.Paths = append(req.Paths, args[i])
+		}
+	}
+
+	if req.Tool == "" || len(req.Paths) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: --tool and at least one INPUT_PATH are required\n")
+		fmt.Fprintf(os.Stderr, "Run 'arm help import' for usage.\n")
+		os.Exit(1)
+	}
+	if req.ID == "" && req.Output != "" {
+		req.ID = strings.TrimSuffix(filepath.Base(req.Output), filepath.Ext(req.Output))
+	}
+
+	svc := service.NewArmService(nil, nil, nil)
+	content, err := svc.ImportRuleset(context.Background(), req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if req.Output == "" {
+		fmt.Print(string(content))
+		return
+	}
+	fmt.Printf("Imported ruleset to %s\n", req.Output)
+}
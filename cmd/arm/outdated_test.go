@@ -74,8 +74,27 @@ func TestOutdated(t *testing.T) {
 				"rulesets": {},
 				"promptsets": {}
 			}`,
-			args:         []string{"outdated", "--output", "json"},
-			wantContains: []string{"All packages are up to date"},
+			args:           []string{"outdated", "--output", "json"},
+			wantContains:   []string{"[]"},
+			wantNotContain: []string{"All packages are up to date"},
+		},
+		{
+			name: "yaml output format",
+			setupManifest: `{
+				"version": 1,
+				"registries": {},
+				"sinks": {},
+				"rulesets": {},
+				"promptsets": {}
+			}`,
+			setupLockfile: `{
+				"version": 1,
+				"rulesets": {},
+				"promptsets": {}
+			}`,
+			args:           []string{"outdated", "--output", "yaml"},
+			wantContains:   []string{"[]"},
+			wantNotContain: []string{"All packages are up to date"},
 		},
 		{
 			name: "list output format",
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSearch(t *testing.T) {
+	tmpDir := t.TempDir()
+	binaryPath := filepath.Join(tmpDir, "arm")
+	cmd := exec.Command("go", "build", "-o", binaryPath, ".")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to build binary: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[
+			{"name": "security", "version": "1.0.0", "format": "raw", "summary": "Rules for secure code"},
+			{"name": "security", "version": "1.2.0", "format": "raw"},
+			{"name": "review", "version": "0.3.0", "format": "raw", "description": "Code review prompts"}
+		]`))
+	}))
+	defer server.Close()
+
+	projectDir := filepath.Join(tmpDir, "project")
+	files := map[string]string{
+		"arm.json": `{
+  "version": 1,
+  "registries": {
+    "team": {"type": "cloudsmith", "url": "` + server.URL + `", "owner": "org", "repository": "team"},
+    "rules": {"type": "git", "url": "https://github.com/org/rules"}
+  }
+}`,
+		".armrc": "[registry " + server.URL + "/org/team]\ntoken = test-token\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(projectDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run := func(args ...string) string {
+		cmd := exec.Command(binaryPath, args...)
+		cmd.Dir = projectDir
+		cmd.Env = append(os.Environ(), "HOME="+tmpDir)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("arm %v failed: %v\nOutput: %s", args, err, output)
+		}
+		return strings.TrimSpace(string(output))
+	}
+
+	output := run("search", "CODE", "--registry", "team")
+	want := "Package        Latest  Description\n" +
+		"team/review    0.3.0   Code review prompts\n" +
+		"team/security  1.2.0   Rules for secure code"
+	if output != want {
+		t.Errorf("Expected:\n%s\nGot:\n%s", want, output)
+	}
+
+	output = run("search", "review", "--output", "json")
+	wantJSON := `[{"registry":"team","package":"review","latest":"0.3.0","description":"Code review prompts"}]`
+	if got := compactJSON(t, output); got != wantJSON {
+		t.Errorf("Expected:\n%s\nGot:\n%s", wantJSON, got)
+	}
+
+	output = run("search", "python")
+	if output != `No packages found matching "python"` {
+		t.Errorf("Expected no matches, got:\n%s", output)
+	}
+}
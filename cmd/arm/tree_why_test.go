@@ -0,0 +1,121 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTreeAndWhy(t *testing.T) {
+	tmpDir := t.TempDir()
+	binaryPath := filepath.Join(tmpDir, "arm")
+	cmd := exec.Command("go", "build", "-o", binaryPath, ".")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to build binary: %v", err)
+	}
+
+	projectDir := filepath.Join(tmpDir, "project")
+	ruleFile := filepath.Join(".cursor", "rules", "arm", "ai-rules", "clean-code", "1.2.0", "cleanCode_naming.mdc")
+	files := map[string]string{
+		"arm.json": `{
+  "version": 1,
+  "registries": {"ai-rules": {"type": "git", "url": "https://github.com/example/rules"}},
+  "sinks": {"cursor-rules": {"directory": ".cursor/rules", "tool": "cursor"}},
+  "dependencies": {
+    "ai-rules/clean-code": {"type": "ruleset", "version": "^1.0.0", "priority": 200, "sinks": ["cursor-rules"]}
+  }
+}`,
+		"arm-lock.json": `{"version": 1, "dependencies": {"ai-rules/clean-code@1.2.0": {"integrity": "sha256-abc"}}}`,
+		filepath.Join(".cursor", "rules", "arm", "arm-index.json"): `{
+  "version": 1,
+  "rulesets": {
+    "ai-rules/clean-code@1.2.0": {
+      "priority": 200,
+      "files": ["arm/ai-rules/clean-code/1.2.0/cleanCode_naming.mdc"],
+      "rules": [{"ruleset": "cleanCode", "id": "naming", "bodyHash": "x", "files": ["arm/ai-rules/clean-code/1.2.0/cleanCode_naming.mdc"]}],
+      "sources": {"arm/ai-rules/clean-code/1.2.0/cleanCode_naming.mdc": "rules/clean-code.yml"}
+    }
+  }
+}`,
+		ruleFile: "Use descriptive names.",
+	}
+	for name, content := range files {
+		path := filepath.Join(projectDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name           string
+		args           []string
+		wantErr        bool
+		expectedOutput string
+	}{
+		{
+			name: "tree",
+			args: []string{"tree"},
+			expectedOutput: `ai-rules
+    clean-code@1.2.0  ruleset  priority 200
+        cursor-rules  .cursor/rules
+            ` + ruleFile + `  (rule cleanCode/naming, from rules/clean-code.yml)`,
+		},
+		{
+			name:           "tree json",
+			args:           []string{"tree", "--output", "json"},
+			expectedOutput: `[{"name":"ai-rules","packages":[{"name":"clean-code","type":"ruleset","version":"1.2.0","priority":200,"sinks":[{"name":"cursor-rules","directory":".cursor/rules","files":[{"path":"` + ruleFile + `","source":"rules/clean-code.yml","ruleset":"cleanCode","rule":"naming"}]}]}]}]`,
+		},
+		{
+			name: "why",
+			args: []string{"why", ruleFile},
+			expectedOutput: ruleFile + `
+    package:  ai-rules/clean-code@1.2.0
+    type:     ruleset
+    priority: 200
+    sink:     cursor-rules
+    source:   rules/clean-code.yml
+    rule:     cleanCode/naming`,
+		},
+		{
+			name:           "why json",
+			args:           []string{"why", "--output", "json", ruleFile},
+			expectedOutput: `[{"package":"ai-rules/clean-code@1.2.0","type":"ruleset","priority":200,"sink":"cursor-rules","source":"rules/clean-code.yml","ruleset":"cleanCode","rule":"naming"}]`,
+		},
+		{
+			name:           "why untracked file",
+			args:           []string{"why", filepath.Join(".cursor", "rules", "mine.mdc")},
+			wantErr:        true,
+			expectedOutput: "Error: " + filepath.Join(".cursor", "rules", "mine.mdc") + " was not installed by ARM",
+		},
+		{
+			name:           "why without path",
+			args:           []string{"why"},
+			wantErr:        true,
+			expectedOutput: "Usage: arm why PATH [--output FORMAT]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command(binaryPath, tt.args...)
+			cmd.Dir = projectDir
+			output, err := cmd.CombinedOutput()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v\nOutput: %s", err, tt.wantErr, output)
+			}
+
+			got := strings.TrimSpace(string(output))
+			if strings.HasSuffix(tt.name, "json") {
+				got = compactJSON(t, got)
+			}
+			if got != tt.expectedOutput {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tt.expectedOutput, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	tmpDir := t.TempDir()
+	binaryPath := filepath.Join(tmpDir, "arm")
+	cmd := exec.Command("go", "build", "-o", binaryPath, ".")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to build binary: %v", err)
+	}
+
+	projectDir := filepath.Join(tmpDir, "project")
+	ruleFile := filepath.Join(".cursor", "rules", "arm", "ai-rules", "clean-code", "1.2.0", "cleanCode_naming.mdc")
+	files := map[string]string{
+		"arm.json": `{
+  "version": 1,
+  "sinks": {"cursor-rules": {"directory": ".cursor/rules", "tool": "cursor"}}
+}`,
+		filepath.Join(".cursor", "rules", "arm", "arm-index.json"): `{
+  "version": 1,
+  "rulesets": {
+    "ai-rules/clean-code@1.2.0": {"priority": 200, "files": ["arm/ai-rules/clean-code/1.2.0/cleanCode_naming.mdc"]}
+  },
+  "hashes": {"arm/ai-rules/clean-code/1.2.0/cleanCode_naming.mdc": "sha256-97d6d9ff51decc57d7fb893ae7721334ea0bbca2a2fd3aed5fd739720b2f7d1d"}
+}`,
+		ruleFile: "Use descriptive names.",
+	}
+	for name, content := range files {
+		path := filepath.Join(projectDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run := func(args ...string) (string, error) {
+		cmd := exec.Command(binaryPath, args...)
+		cmd.Dir = projectDir
+		output, err := cmd.CombinedOutput()
+		return strings.TrimSpace(string(output)), err
+	}
+
+	output, err := run("verify")
+	if err != nil || output != "No drift detected" {
+		t.Fatalf("verify on a clean project: %v\nOutput: %s", err, output)
+	}
+
+	if err := os.WriteFile(filepath.Join(projectDir, ruleFile), []byte("Use short names."), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	output, err = run("verify")
+	if err == nil {
+		t.Errorf("verify should fail when a file was edited\nOutput: %s", output)
+	}
+	if !strings.Contains(output, "modified  cursor-rules  "+ruleFile+"  ai-rules/clean-code@1.2.0") {
+		t.Errorf("Expected the edited file in the report, got:\n%s", output)
+	}
+
+	output, err = run("verify", "--output", "json")
+	if err == nil {
+		t.Errorf("verify --output json should fail when a file was edited")
+	}
+	want := `{"files":[{"sink":"cursor-rules","path":"` + ruleFile + `","kind":"modified","package":"ai-rules/clean-code@1.2.0"}],"packages":[]}`
+	if got := compactJSON(t, output); got != want {
+		t.Errorf("Expected:\n%s\nGot:\n%s", want, got)
+	}
+}
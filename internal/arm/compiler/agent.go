@@ -0,0 +1,117 @@
+package compiler
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jomadu/ai-resource-manager/internal/arm/resource"
+)
+
+// MarkdownAgentGenerator generates markdown agents with YAML frontmatter,
+// the subagent format read by Claude Code
+type MarkdownAgentGenerator struct{}
+
+func (g *MarkdownAgentGenerator) GenerateAgent(agentset *resource.AgentsetResource, agentID string) (string, error) {
+	agent, exists := agentset.Spec.Agents[agentID]
+	if !exists {
+		return "", fmt.Errorf("agent %s not found in agentset", agentID)
+	}
+
+	parts := []string{
+		"---",
+		"name: " + agentID,
+		fmt.Sprintf("description: %q", agent.Description),
+	}
+	if len(agent.Tools) > 0 {
+		parts = append(parts, "tools: "+strings.Join(agent.Tools, ", "))
+	}
+	if agent.Model != "" {
+		parts = append(parts, "model: "+agent.Model)
+	}
+	parts = append(parts, "---")
+
+	return strings.Join(parts, "\n") + "\n\n" + agent.Body, nil
+}
+
+func (g *MarkdownAgentGenerator) GenerateAgentFilename(agentsetID, agentID string) (string, error) {
+	return agentFilename(agentsetID, agentID, ".md")
+}
+
+// CopilotAgentGenerator generates copilot chat modes
+type CopilotAgentGenerator struct{}
+
+func (g *CopilotAgentGenerator) GenerateAgent(agentset *resource.AgentsetResource, agentID string) (string, error) {
+	agent, exists := agentset.Spec.Agents[agentID]
+	if !exists {
+		return "", fmt.Errorf("agent %s not found in agentset", agentID)
+	}
+
+	parts := []string{
+		"---",
+		fmt.Sprintf("description: %q", agent.Description),
+	}
+	if len(agent.Tools) > 0 {
+		tools := make([]string, len(agent.Tools))
+		for i, tool := range agent.Tools {
+			tools[i] = fmt.Sprintf("%q", tool)
+		}
+		parts = append(parts, "tools: ["+strings.Join(tools, ", ")+"]")
+	}
+	if agent.Model != "" {
+		parts = append(parts, fmt.Sprintf("model: %q", agent.Model))
+	}
+	parts = append(parts, "---")
+
+	return strings.Join(parts, "\n") + "\n\n" + agent.Body, nil
+}
+
+func (g *CopilotAgentGenerator) GenerateAgentFilename(agentsetID, agentID string) (string, error) {
+	return agentFilename(agentsetID, agentID, ".chatmode.md")
+}
+
+// JSONAgentGenerator generates JSON agent configurations used by Kiro CLI and Amazon Q
+type JSONAgentGenerator struct{}
+
+// jsonAgent is the subset of the Kiro/Amazon Q agent configuration ARM writes
+type jsonAgent struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Prompt      string   `json:"prompt"`
+	Tools       []string `json:"tools,omitempty"`
+	Model       string   `json:"model,omitempty"`
+}
+
+func (g *JSONAgentGenerator) GenerateAgent(agentset *resource.AgentsetResource, agentID string) (string, error) {
+	agent, exists := agentset.Spec.Agents[agentID]
+	if !exists {
+		return "", fmt.Errorf("agent %s not found in agentset", agentID)
+	}
+
+	data, err := json.MarshalIndent(jsonAgent{
+		Name:        agentID,
+		Description: agent.Description,
+		Prompt:      agent.Body,
+		Tools:       agent.Tools,
+		Model:       agent.Model,
+	}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
+
+func (g *JSONAgentGenerator) GenerateAgentFilename(agentsetID, agentID string) (string, error) {
+	return agentFilename(agentsetID, agentID, ".json")
+}
+
+func agentFilename(agentsetID, agentID, ext string) (string, error) {
+	if agentsetID == "" {
+		return "", fmt.Errorf("agentsetID cannot be empty")
+	}
+	if agentID == "" {
+		return "", fmt.Errorf("agentID cannot be empty")
+	}
+
+	return agentsetID + "_" + agentID + ext, nil
+}
@@ -0,0 +1,95 @@
+package compiler
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-manager/internal/arm/resource"
+)
+
+func newTestAgentset() *resource.AgentsetResource {
+	return &resource.AgentsetResource{
+		APIVersion: "v1",
+		Kind:       "Agentset",
+		Metadata:   resource.ResourceMetadata{ID: "reviewers"},
+		Spec: resource.AgentsetSpec{
+			Agents: map[string]resource.Agent{
+				"code-reviewer": {
+					Description: "Reviews code for quality",
+					Model:       "sonnet",
+					Tools:       []string{"Read", "Grep"},
+					Body:        "You are a senior code reviewer.",
+				},
+			},
+		},
+	}
+}
+
+func TestMarkdownAgentGenerator(t *testing.T) {
+	gen := &MarkdownAgentGenerator{}
+	content, err := gen.GenerateAgent(newTestAgentset(), "code-reviewer")
+	if err != nil {
+		t.Fatalf("GenerateAgent() error = %v", err)
+	}
+
+	expected := "---\nname: code-reviewer\ndescription: \"Reviews code for quality\"\ntools: Read, Grep\nmodel: sonnet\n---\n\nYou are a senior code reviewer."
+	if content != expected {
+		t.Errorf("GenerateAgent() = %q, want %q", content, expected)
+	}
+
+	filename, _ := gen.GenerateAgentFilename("reviewers", "code-reviewer")
+	if filename != "reviewers_code-reviewer.md" {
+		t.Errorf("GenerateAgentFilename() = %s", filename)
+	}
+
+	if _, err := gen.GenerateAgent(newTestAgentset(), "missing"); err == nil {
+		t.Error("Expected error for missing agent")
+	}
+}
+
+func TestCopilotAgentGenerator(t *testing.T) {
+	gen := &CopilotAgentGenerator{}
+	content, err := gen.GenerateAgent(newTestAgentset(), "code-reviewer")
+	if err != nil {
+		t.Fatalf("GenerateAgent() error = %v", err)
+	}
+	if !strings.Contains(content, `tools: ["Read", "Grep"]`) || !strings.Contains(content, `model: "sonnet"`) {
+		t.Errorf("unexpected chat mode:\n%s", content)
+	}
+
+	filename, _ := gen.GenerateAgentFilename("reviewers", "code-reviewer")
+	if filename != "reviewers_code-reviewer.chatmode.md" {
+		t.Errorf("GenerateAgentFilename() = %s", filename)
+	}
+}
+
+func TestJSONAgentGenerator(t *testing.T) {
+	gen := &JSONAgentGenerator{}
+	content, err := gen.GenerateAgent(newTestAgentset(), "code-reviewer")
+	if err != nil {
+		t.Fatalf("GenerateAgent() error = %v", err)
+	}
+
+	var agent map[string]interface{}
+	if err := json.Unmarshal([]byte(content), &agent); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if agent["name"] != "code-reviewer" || agent["prompt"] != "You are a senior code reviewer." || agent["model"] != "sonnet" {
+		t.Errorf("unexpected agent config %v", agent)
+	}
+}
+
+func TestCompileAgentset(t *testing.T) {
+	files, err := CompileAgentset(Kiro, newTestAgentset())
+	if err != nil {
+		t.Fatalf("CompileAgentset() error = %v", err)
+	}
+	if len(files) != 1 || files[0].Path != "reviewers_code-reviewer.json" {
+		t.Fatalf("unexpected files %v", files)
+	}
+
+	if _, err := CompileAgentset(Cursor, newTestAgentset()); err == nil {
+		t.Error("Expected error compiling agents for cursor")
+	}
+}
@@ -0,0 +1,45 @@
+package compiler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jomadu/ai-resource-manager/internal/arm/resource"
+)
+
+// ClaudePromptGenerator generates Claude Code slash command content
+type ClaudePromptGenerator struct{}
+
+func (g *ClaudePromptGenerator) GeneratePrompt(namespace string, promptset *resource.PromptsetResource, promptID string) (string, error) {
+	prompt, exists := promptset.Spec.Prompts[promptID]
+	if !exists {
+		return "", fmt.Errorf("prompt %s not found in promptset", promptID)
+	}
+	if len(prompt.Arguments) == 0 {
+		return prompt.Body, nil
+	}
+
+	// A single argument takes the whole input as $ARGUMENTS; several are positional
+	positions := make(map[string]string, len(prompt.Arguments))
+	hints := make([]string, 0, len(prompt.Arguments))
+	for i, arg := range prompt.Arguments {
+		positions[arg.Name] = "$" + strconv.Itoa(i+1)
+		switch {
+		case arg.Required:
+			hints = append(hints, "<"+arg.Name+">")
+		case arg.Default != "":
+			hints = append(hints, "["+arg.Name+"="+arg.Default+"]")
+		default:
+			hints = append(hints, "["+arg.Name+"]")
+		}
+	}
+	if len(prompt.Arguments) == 1 {
+		positions[prompt.Arguments[0].Name] = "$ARGUMENTS"
+	}
+
+	body := prompt.RenderBody(func(arg *resource.PromptArgument) string {
+		return positions[arg.Name]
+	})
+	return fmt.Sprintf("---\nargument-hint: %q\n---\n\n%s", strings.Join(hints, " "), body), nil
+}
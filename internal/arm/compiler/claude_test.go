@@ -0,0 +1,52 @@
+package compiler
+
+import (
+	"testing"
+
+	"github.com/jomadu/ai-resource-manager/internal/arm/resource"
+)
+
+func TestClaudePromptGenerator_GeneratePrompt(t *testing.T) {
+	generator := &ClaudePromptGenerator{}
+
+	tests := []struct {
+		name   string
+		prompt resource.Prompt
+		want   string
+	}{
+		{
+			name:   "no arguments",
+			prompt: resource.Prompt{Body: "Review the code."},
+			want:   "Review the code.",
+		},
+		{
+			name: "single argument",
+			prompt: resource.Prompt{
+				Arguments: []resource.PromptArgument{{Name: "topic", Required: true}},
+				Body:      "Explain {{args.topic}}.",
+			},
+			want: "---\nargument-hint: \"<topic>\"\n---\n\nExplain $ARGUMENTS.",
+		},
+		{
+			name: "positional arguments",
+			prompt: resource.Prompt{
+				Arguments: []resource.PromptArgument{{Name: "topic", Required: true}, {Name: "format", Default: "markdown"}, {Name: "audience"}},
+				Body:      "Explain {{args.topic}} to {{args.audience}} as {{args.format}}.",
+			},
+			want: "---\nargument-hint: \"<topic> [format=markdown] [audience]\"\n---\n\nExplain $1 to $3 as $2.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			promptset := &resource.PromptsetResource{Spec: resource.PromptsetSpec{Prompts: map[string]resource.Prompt{"explain": tt.prompt}}}
+			got, err := generator.GeneratePrompt("test-namespace", promptset, "explain")
+			if err != nil {
+				t.Fatalf("GeneratePrompt() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("GeneratePrompt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -10,8 +10,24 @@ import (
 
 // CompileRuleset compiles a ruleset to the tool format
 func CompileRuleset(tool Tool, namespace string, ruleset *resource.RulesetResource) ([]*core.File, error) {
+	return CompileRulesetWithTools(tool, nil, namespace, ruleset)
+}
+
+// CompileRulesetWithTools compiles a ruleset to a built-in or user-defined tool format
+func CompileRulesetWithTools(tool Tool, tools map[Tool]*ToolDefinition, namespace string, ruleset *resource.RulesetResource) ([]*core.File, error) {
 	var compiledFiles []*core.File
 
+	// Create rule generator
+	ruleFactory := NewRuleGeneratorFactoryWithTools(tools)
+	ruleGen, err := ruleFactory.NewRuleGenerator(tool)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rule generator: %w", err)
+	}
+	ruleset, _, err = SplitRuleset(ruleGen, ruleset)
+	if err != nil {
+		return nil, err
+	}
+
 	// Sort rule IDs for consistent ordering
 	ruleIDs := make([]string, 0, len(ruleset.Spec.Rules))
 	for ruleID := range ruleset.Spec.Rules {
@@ -20,19 +36,12 @@ func CompileRuleset(tool Tool, namespace string, ruleset *resource.RulesetResour
 	sort.Strings(ruleIDs)
 
 	// Create rule filename generator
-	ruleFilenameFactory := NewRuleFilenameGeneratorFactory()
+	ruleFilenameFactory := NewRuleFilenameGeneratorFactoryWithTools(tools)
 	ruleFilenameGen, err := ruleFilenameFactory.NewRuleFilenameGenerator(tool)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create rule filename generator: %w", err)
 	}
 
-	// Create rule generator
-	ruleFactory := NewRuleGeneratorFactory()
-	ruleGen, err := ruleFactory.NewRuleGenerator(tool)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create rule generator: %w", err)
-	}
-
 	for _, ruleID := range ruleIDs {
 		// Generate filename
 		filename, err := ruleFilenameGen.GenerateRuleFilename(ruleset.Metadata.ID, ruleID)
@@ -58,6 +67,11 @@ func CompileRuleset(tool Tool, namespace string, ruleset *resource.RulesetResour
 
 // CompilePromptset compiles a promptset to the tool format
 func CompilePromptset(tool Tool, namespace string, promptset *resource.PromptsetResource) ([]*core.File, error) {
+	return CompilePromptsetWithTools(tool, nil, namespace, promptset)
+}
+
+// CompilePromptsetWithTools compiles a promptset to a built-in or user-defined tool format
+func CompilePromptsetWithTools(tool Tool, tools map[Tool]*ToolDefinition, namespace string, promptset *resource.PromptsetResource) ([]*core.File, error) {
 	var compiledFiles []*core.File
 
 	// Sort prompt IDs for consistent ordering
@@ -68,14 +82,14 @@ func CompilePromptset(tool Tool, namespace string, promptset *resource.Promptset
 	sort.Strings(promptIDs)
 
 	// Create prompt filename generator
-	promptFilenameFactory := NewPromptFilenameGeneratorFactory()
+	promptFilenameFactory := NewPromptFilenameGeneratorFactoryWithTools(tools)
 	promptFilenameGen, err := promptFilenameFactory.NewPromptFilenameGenerator(tool)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create prompt filename generator: %w", err)
 	}
 
 	// Create prompt generator
-	promptFactory := NewPromptGeneratorFactory()
+	promptFactory := NewPromptGeneratorFactoryWithTools(tools)
 	promptGen, err := promptFactory.NewPromptGenerator(tool)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create prompt generator: %w", err)
@@ -103,3 +117,130 @@ func CompilePromptset(tool Tool, namespace string, promptset *resource.Promptset
 
 	return compiledFiles, nil
 }
+
+// CompileMcpset compiles an mcpset to the tool's MCP configuration format
+func CompileMcpset(tool Tool, mcpset *resource.McpsetResource) ([]*core.File, error) {
+	return CompileMcpsetWithTools(tool, nil, mcpset)
+}
+
+// CompileMcpsetWithTools compiles an mcpset to a built-in or user-defined tool's MCP configuration
+func CompileMcpsetWithTools(tool Tool, tools map[Tool]*ToolDefinition, mcpset *resource.McpsetResource) ([]*core.File, error) {
+	mcpFactory := NewMcpGeneratorFactoryWithTools(tools)
+	mcpGen, err := mcpFactory.NewMcpGenerator(tool)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create mcp generator: %w", err)
+	}
+
+	content, err := MarshalMcpConfig(mcpGen, mcpset.Spec.Servers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate mcp configuration: %w", err)
+	}
+
+	return []*core.File{{
+		Path:    mcpGen.McpFilename(),
+		Content: content,
+		Size:    int64(len(content)),
+	}}, nil
+}
+
+// CompileAgentset compiles an agentset to the tool's agent format
+func CompileAgentset(tool Tool, agentset *resource.AgentsetResource) ([]*core.File, error) {
+	return CompileAgentsetWithTools(tool, nil, agentset)
+}
+
+// CompileAgentsetWithTools compiles an agentset to a built-in or user-defined tool's agent format
+func CompileAgentsetWithTools(tool Tool, tools map[Tool]*ToolDefinition, agentset *resource.AgentsetResource) ([]*core.File, error) {
+	var compiledFiles []*core.File
+
+	// Sort agent IDs for consistent ordering
+	agentIDs := make([]string, 0, len(agentset.Spec.Agents))
+	for agentID := range agentset.Spec.Agents {
+		agentIDs = append(agentIDs, agentID)
+	}
+	sort.Strings(agentIDs)
+
+	agentFactory := NewAgentGeneratorFactoryWithTools(tools)
+	agentGen, err := agentFactory.NewAgentGenerator(tool)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create agent generator: %w", err)
+	}
+
+	for _, agentID := range agentIDs {
+		filename, err := agentGen.GenerateAgentFilename(agentset.Metadata.ID, agentID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate filename for agent %s: %w", agentID, err)
+		}
+
+		content, err := agentGen.GenerateAgent(agentset, agentID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate content for agent %s: %w", agentID, err)
+		}
+
+		compiledFiles = append(compiledFiles, &core.File{
+			Path:    filename,
+			Content: []byte(content),
+			Size:    int64(len(content)),
+		})
+	}
+
+	return compiledFiles, nil
+}
+
+// CompileHookset compiles a hookset to the tool's hook configuration
+func CompileHookset(tool Tool, hookset *resource.HooksetResource) ([]*core.File, error) {
+	hookFactory := NewHookGeneratorFactory()
+	hookGen, err := hookFactory.NewHookGenerator(tool)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create hook generator: %w", err)
+	}
+
+	// Sort hook IDs for consistent ordering
+	hookIDs := make([]string, 0, len(hookset.Spec.Hooks))
+	for hookID := range hookset.Spec.Hooks {
+		hookIDs = append(hookIDs, hookID)
+	}
+	sort.Strings(hookIDs)
+
+	var compiledFiles []*core.File
+	events := make(map[string]interface{})
+	for _, hookID := range hookIDs {
+		event, entry, err := hookGen.GenerateHook(hookID, hookset.Spec.Hooks[hookID])
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate hook %s: %w", hookID, err)
+		}
+
+		if hookGen.HookFilename() != "" {
+			entries, _ := events[event].([]interface{})
+			events[event] = append(entries, entry)
+			continue
+		}
+
+		filename, err := hookGen.GenerateHookFilename(hookset.Metadata.ID, hookID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate filename for hook %s: %w", hookID, err)
+		}
+		content, err := MarshalJSONConfig(entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate content for hook %s: %w", hookID, err)
+		}
+		compiledFiles = append(compiledFiles, &core.File{
+			Path:    filename,
+			Content: content,
+			Size:    int64(len(content)),
+		})
+	}
+
+	if hookGen.HookFilename() != "" {
+		content, err := MarshalJSONConfig(map[string]interface{}{"hooks": events})
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate hook configuration: %w", err)
+		}
+		compiledFiles = append(compiledFiles, &core.File{
+			Path:    hookGen.HookFilename(),
+			Content: content,
+			Size:    int64(len(content)),
+		})
+	}
+
+	return compiledFiles, nil
+}
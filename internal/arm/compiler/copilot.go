@@ -2,12 +2,16 @@ package compiler
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/jomadu/ai-resource-manager/internal/arm/resource"
 )
 
 // CopilotRuleGenerator generates copilot rule content
-type CopilotRuleGenerator struct{}
+type CopilotRuleGenerator struct {
+	// Enforcement maps enforcement levels to copilot applyTo behaviour
+	Enforcement EnforcementPolicy
+}
 
 func (g *CopilotRuleGenerator) GenerateRule(namespace string, ruleset *resource.RulesetResource, ruleID string) (string, error) {
 	rule, exists := ruleset.Spec.Rules[ruleID]
@@ -15,9 +19,28 @@ func (g *CopilotRuleGenerator) GenerateRule(namespace string, ruleset *resource.
 		return "", fmt.Errorf("rule %s not found in ruleset", ruleID)
 	}
 
-	// Copilot format: metadata + content (same as markdown)
+	// Copilot format: applyTo/description frontmatter (when activated) + metadata + content
 	metadata := GenerateRuleMetadata(namespace, ruleset, ruleID, &rule)
-	return metadata + "\n\n" + rule.Body, nil
+	var frontmatter string
+	switch g.Enforcement.Activation(&rule) {
+	case ActivationAlways:
+		frontmatter = fmt.Sprintf("---\napplyTo: %q\n---", "**")
+	case ActivationAgentRequested:
+		if description := withDefault(rule.Description, rule.Name); description != "" {
+			frontmatter = fmt.Sprintf("---\ndescription: %q\n---", description)
+		}
+	case ActivationManual:
+		// Instructions without applyTo are only attached by hand
+	default:
+		if globs := resource.ScopeGlobs(rule.Scope); len(globs) > 0 {
+			frontmatter = fmt.Sprintf("---\napplyTo: %q\n---", strings.Join(globs, ","))
+		}
+	}
+
+	if frontmatter == "" {
+		return metadata + "\n\n" + rule.Body, nil
+	}
+	return frontmatter + "\n\n" + metadata + "\n\n" + rule.Body, nil
 }
 
 // CopilotPromptGenerator generates copilot prompt content
@@ -29,8 +52,14 @@ func (g *CopilotPromptGenerator) GeneratePrompt(namespace string, promptset *res
 		return "", fmt.Errorf("prompt %s not found in promptset", promptID)
 	}
 
-	// Prompts are just content, no metadata
-	return prompt.Body, nil
+	// Prompts are just content, no metadata; arguments become ${input:name:hint} variables
+	return prompt.RenderBody(func(arg *resource.PromptArgument) string {
+		hint := strings.NewReplacer("}", "", "\n", " ").Replace(withDefault(arg.Description, arg.Default))
+		if hint == "" {
+			return "${input:" + arg.Name + "}"
+		}
+		return "${input:" + arg.Name + ":" + hint + "}"
+	}), nil
 }
 
 // CopilotRuleFilenameGenerator generates copilot rule filenames
@@ -32,6 +32,10 @@ func TestCopilotRuleGenerator_GenerateRule(t *testing.T) {
 	}
 
 	expected := `---
+applyTo: "**/*.ts,**/*.tsx"
+---
+
+---
 namespace: test-namespace
 ruleset:
   id: 
@@ -95,6 +99,34 @@ func TestCopilotPromptGenerator_GeneratePrompt(t *testing.T) {
 	}
 }
 
+func TestCopilotPromptGenerator_GeneratePromptWithArguments(t *testing.T) {
+	generator := &CopilotPromptGenerator{}
+
+	promptset := &resource.PromptsetResource{
+		Spec: resource.PromptsetSpec{
+			Prompts: map[string]resource.Prompt{
+				"explain": {
+					Arguments: []resource.PromptArgument{
+						{Name: "topic", Description: "What to explain"},
+						{Name: "format"},
+					},
+					Body: "Explain {{args.topic}} as {{args.format}}.",
+				},
+			},
+		},
+	}
+
+	result, err := generator.GeneratePrompt("test-namespace", promptset, "explain")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := "Explain ${input:topic:What to explain} as ${input:format}."
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
 func TestCopilotPromptGenerator_GeneratePrompt_NotFound(t *testing.T) {
 	generator := &CopilotPromptGenerator{}
 	promptset := &resource.PromptsetResource{
@@ -8,7 +8,10 @@ import (
 )
 
 // CursorRuleGenerator generates cursor rule content
-type CursorRuleGenerator struct{}
+type CursorRuleGenerator struct {
+	// Enforcement maps enforcement levels to cursor rule types
+	Enforcement EnforcementPolicy
+}
 
 func (g *CursorRuleGenerator) GenerateRule(namespace string, ruleset *resource.RulesetResource, ruleID string) (string, error) {
 	rule, exists := ruleset.Spec.Rules[ruleID]
@@ -23,23 +26,45 @@ func (g *CursorRuleGenerator) GenerateRule(namespace string, ruleset *resource.R
 }
 
 func (g *CursorRuleGenerator) generateCursorFrontmatter(rule *resource.Rule) string {
+	globs := resource.ScopeGlobs(rule.Scope)
+
+	// Default: must rules always apply, scoped rules auto-attach, described
+	// rules are agent-requested and the rest are manual
+	activation := g.Enforcement.Activation(rule)
+	if activation == "" {
+		switch {
+		case rule.Enforcement == "must":
+			activation = ActivationAlways
+		case len(globs) > 0:
+			activation = ActivationAutoAttached
+		case rule.Description != "":
+			activation = ActivationAgentRequested
+		default:
+			activation = ActivationManual
+		}
+	}
+
 	var parts []string
 
 	parts = append(parts, "---")
 
-	if rule.Description != "" {
-		parts = append(parts, fmt.Sprintf(`description: %q`, rule.Description))
-	}
-
-	// Add globs if scope is defined
-	if len(rule.Scope) > 0 && len(rule.Scope[0].Files) > 0 {
-		globs := strings.Join(rule.Scope[0].Files, ", ")
-		parts = append(parts, fmt.Sprintf("globs: %s", globs))
-	}
-
-	// Add alwaysApply for must enforcement
-	if rule.Enforcement == "must" {
-		parts = append(parts, "alwaysApply: true")
+	switch activation {
+	case ActivationAlways, ActivationAutoAttached:
+		if rule.Description != "" {
+			parts = append(parts, fmt.Sprintf(`description: %q`, rule.Description))
+		}
+		// cursor attaches the rule when any glob matches
+		if len(globs) > 0 {
+			parts = append(parts, fmt.Sprintf("globs: %s", strings.Join(globs, ", ")))
+		}
+		if activation == ActivationAlways {
+			parts = append(parts, "alwaysApply: true")
+		}
+	case ActivationAgentRequested:
+		// The agent decides from the description, so fall back to the name
+		if description := withDefault(rule.Description, rule.Name); description != "" {
+			parts = append(parts, fmt.Sprintf(`description: %q`, description))
+		}
 	}
 
 	parts = append(parts, "---")
@@ -56,8 +81,8 @@ func (g *CursorPromptGenerator) GeneratePrompt(namespace string, promptset *reso
 		return "", fmt.Errorf("prompt %s not found in promptset", promptID)
 	}
 
-	// Prompts are just content, no metadata
-	return prompt.Body, nil
+	// Cursor commands take no arguments, so they are documented like markdown
+	return documentPromptArguments(&prompt), nil
 }
 
 // CursorRuleFilenameGenerator generates cursor rule filenames
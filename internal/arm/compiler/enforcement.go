@@ -0,0 +1,130 @@
+package compiler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jomadu/ai-resource-manager/internal/arm/resource"
+)
+
+// Activation describes when a tool loads a rule into the agent's context
+type Activation string
+
+const (
+	// ActivationAlways loads the rule into every context
+	ActivationAlways Activation = "always"
+	// ActivationAutoAttached loads the rule when a file matching its scope is in context
+	ActivationAutoAttached Activation = "auto-attached"
+	// ActivationAgentRequested lets the agent load the rule based on its description
+	ActivationAgentRequested Activation = "agent-requested"
+	// ActivationManual loads the rule only when the user references it
+	ActivationManual Activation = "manual"
+	// ActivationOmit does not install the rule at all
+	ActivationOmit Activation = "omit"
+)
+
+// Enforcement levels a rule may declare
+var enforcementLevels = []string{"must", "should", "may"}
+
+var activations = []Activation{
+	ActivationAlways,
+	ActivationAutoAttached,
+	ActivationAgentRequested,
+	ActivationManual,
+	ActivationOmit,
+}
+
+// EnforcementPolicy maps rule enforcement levels (must, should, may) to the
+// activation a sink uses for them. Levels missing from the policy keep the
+// tool's default behaviour.
+type EnforcementPolicy map[string]Activation
+
+// Validate checks that the policy only maps known enforcement levels to known activations
+func (p EnforcementPolicy) Validate() error {
+	for level, activation := range p {
+		if !isEnforcementLevel(level) {
+			return fmt.Errorf("invalid enforcement level %q (must be %s)", level, strings.Join(enforcementLevels, ", "))
+		}
+		if !isActivation(activation) {
+			return fmt.Errorf("invalid activation %q for %s (must be %s)", activation, level, joinActivations())
+		}
+	}
+	return nil
+}
+
+// Activation returns the activation configured for the rule's enforcement level,
+// or an empty activation when the tool default applies
+func (p EnforcementPolicy) Activation(rule *resource.Rule) Activation {
+	return p[rule.Enforcement]
+}
+
+// ParseEnforcementPolicy parses a policy written as comma-separated level=activation pairs,
+// e.g. "should=agent-requested,may=omit"
+func ParseEnforcementPolicy(value string) (EnforcementPolicy, error) {
+	policy := EnforcementPolicy{}
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		level, activation, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid enforcement mapping %q (expected level=activation)", pair)
+		}
+		policy[strings.TrimSpace(level)] = Activation(strings.TrimSpace(activation))
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// FilterRuleset returns the ruleset without the rules the policy omits.
+// The original ruleset is returned unchanged when nothing is omitted.
+func FilterRuleset(policy EnforcementPolicy, ruleset *resource.RulesetResource) *resource.RulesetResource {
+	omitted := false
+	for _, rule := range ruleset.Spec.Rules {
+		if policy.Activation(&rule) == ActivationOmit {
+			omitted = true
+			break
+		}
+	}
+	if !omitted {
+		return ruleset
+	}
+
+	filtered := *ruleset
+	filtered.Spec.Rules = make(map[string]resource.Rule, len(ruleset.Spec.Rules))
+	for ruleID, rule := range ruleset.Spec.Rules {
+		if policy.Activation(&rule) != ActivationOmit {
+			filtered.Spec.Rules[ruleID] = rule
+		}
+	}
+	return &filtered
+}
+
+func isEnforcementLevel(level string) bool {
+	for _, l := range enforcementLevels {
+		if l == level {
+			return true
+		}
+	}
+	return false
+}
+
+func isActivation(activation Activation) bool {
+	for _, a := range activations {
+		if a == activation {
+			return true
+		}
+	}
+	return false
+}
+
+func joinActivations() string {
+	names := make([]string, len(activations))
+	for i, a := range activations {
+		names[i] = string(a)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,128 @@
+package compiler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-manager/internal/arm/resource"
+)
+
+func TestParseEnforcementPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    EnforcementPolicy
+		wantErr bool
+	}{
+		{
+			name:  "multiple levels",
+			value: "should=agent-requested, may=omit",
+			want:  EnforcementPolicy{"should": ActivationAgentRequested, "may": ActivationOmit},
+		},
+		{name: "empty resets", value: "", want: EnforcementPolicy{}},
+		{name: "missing separator", value: "may", wantErr: true},
+		{name: "unknown level", value: "could=always", wantErr: true},
+		{name: "unknown activation", value: "may=sometimes", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseEnforcementPolicy(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseEnforcementPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseEnforcementPolicy() = %v, want %v", got, tt.want)
+			}
+			for level, activation := range tt.want {
+				if got[level] != activation {
+					t.Errorf("level %s = %s, want %s", level, got[level], activation)
+				}
+			}
+		})
+	}
+}
+
+func newEnforcementTestRuleset() *resource.RulesetResource {
+	return &resource.RulesetResource{
+		Metadata: resource.ResourceMetadata{ID: "rs"},
+		Spec: resource.RulesetSpec{
+			Rules: map[string]resource.Rule{
+				"mustRule":   {Name: "Must", Enforcement: "must", Body: "must body"},
+				"shouldRule": {Name: "Should", Enforcement: "should", Scope: []resource.Scope{{Files: []string{"**/*.go"}}}, Body: "should body"},
+				"mayRule":    {Name: "May", Enforcement: "may", Body: "may body"},
+			},
+		},
+	}
+}
+
+func TestFilterRuleset(t *testing.T) {
+	ruleset := newEnforcementTestRuleset()
+
+	if got := FilterRuleset(nil, ruleset); got != ruleset {
+		t.Error("Expected ruleset unchanged without a policy")
+	}
+
+	filtered := FilterRuleset(EnforcementPolicy{"may": ActivationOmit}, ruleset)
+	if _, ok := filtered.Spec.Rules["mayRule"]; ok {
+		t.Error("Expected may rule to be omitted")
+	}
+	if len(filtered.Spec.Rules) != 2 {
+		t.Errorf("Expected 2 rules after filtering, got %d", len(filtered.Spec.Rules))
+	}
+	if len(ruleset.Spec.Rules) != 3 {
+		t.Error("Expected original ruleset to be left untouched")
+	}
+}
+
+func TestEnforcementPolicy_Generators(t *testing.T) {
+	ruleset := newEnforcementTestRuleset()
+	policy := EnforcementPolicy{
+		"must":   ActivationAlways,
+		"should": ActivationManual,
+		"may":    ActivationAgentRequested,
+	}
+
+	tests := []struct {
+		name   string
+		gen    RuleGenerator
+		ruleID string
+		prefix string
+	}{
+		{"cursor always", &CursorRuleGenerator{Enforcement: policy}, "mustRule", "---\nalwaysApply: true\n---"},
+		{"cursor manual drops globs", &CursorRuleGenerator{Enforcement: policy}, "shouldRule", "---\n---"},
+		{"cursor agent-requested uses name", &CursorRuleGenerator{Enforcement: policy}, "mayRule", "---\ndescription: \"May\"\n---"},
+		{"copilot always", &CopilotRuleGenerator{Enforcement: policy}, "mustRule", "---\napplyTo: \"**\"\n---"},
+		{"copilot manual", &CopilotRuleGenerator{Enforcement: policy}, "shouldRule", "---\nnamespace: ns"},
+		{"copilot agent-requested", &CopilotRuleGenerator{Enforcement: policy}, "mayRule", "---\ndescription: \"May\"\n---"},
+		{"kiro always", &KiroRuleGenerator{Enforcement: policy}, "mustRule", "---\ninclusion: always\n---"},
+		{"kiro manual", &KiroRuleGenerator{Enforcement: policy}, "shouldRule", "---\ninclusion: manual\n---"},
+		{"kiro agent-requested", &KiroRuleGenerator{Enforcement: policy}, "mayRule", "---\ninclusion: manual\n---"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.gen.GenerateRule("ns", ruleset, tt.ruleID)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !strings.HasPrefix(result, tt.prefix) {
+				t.Errorf("Expected prefix %q, got:\n%s", tt.prefix, result)
+			}
+		})
+	}
+}
+
+func TestKiroRuleGenerator_SplitScopesFollowsPolicy(t *testing.T) {
+	rule := &resource.Rule{Enforcement: "should"}
+
+	if !(&KiroRuleGenerator{}).SplitScopes(rule) {
+		t.Error("Expected default kiro activation to split scopes")
+	}
+	if (&KiroRuleGenerator{Enforcement: EnforcementPolicy{"should": ActivationAlways}}).SplitScopes(rule) {
+		t.Error("Expected always-included kiro rules not to split scopes")
+	}
+}
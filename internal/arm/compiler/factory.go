@@ -3,81 +3,211 @@ package compiler
 import "fmt"
 
 // DefaultRuleGeneratorFactory creates rule generators
-type DefaultRuleGeneratorFactory struct{}
+type DefaultRuleGeneratorFactory struct {
+	// Tools holds user-defined tools resolved alongside the built-in ones
+	Tools map[Tool]*ToolDefinition
+	// Enforcement maps enforcement levels to tool activations
+	Enforcement EnforcementPolicy
+}
 
 func NewRuleGeneratorFactory() RuleGeneratorFactory {
 	return &DefaultRuleGeneratorFactory{}
 }
 
+// NewRuleGeneratorFactoryWithTools creates a factory that also resolves user-defined tools
+func NewRuleGeneratorFactoryWithTools(tools map[Tool]*ToolDefinition) RuleGeneratorFactory {
+	return &DefaultRuleGeneratorFactory{Tools: tools}
+}
+
 func (f *DefaultRuleGeneratorFactory) NewRuleGenerator(tool Tool) (RuleGenerator, error) {
 	switch tool {
 	case Cursor:
-		return &CursorRuleGenerator{}, nil
-	case Markdown, AmazonQ, Kiro:
+		return &CursorRuleGenerator{Enforcement: f.Enforcement}, nil
+	case Markdown, AmazonQ, Claude:
 		return &MarkdownRuleGenerator{}, nil
+	case Kiro:
+		return &KiroRuleGenerator{Enforcement: f.Enforcement}, nil
 	case Copilot:
-		return &CopilotRuleGenerator{}, nil
+		return &CopilotRuleGenerator{Enforcement: f.Enforcement}, nil
 	default:
+		if def, ok := f.Tools[tool]; ok {
+			return &TemplateRuleGenerator{Definition: def, Enforcement: f.Enforcement}, nil
+		}
 		return nil, fmt.Errorf("unsupported tool: %s", tool)
 	}
 }
 
 // DefaultPromptGeneratorFactory creates prompt generators
-type DefaultPromptGeneratorFactory struct{}
+type DefaultPromptGeneratorFactory struct {
+	// Tools holds user-defined tools resolved alongside the built-in ones
+	Tools map[Tool]*ToolDefinition
+}
 
 func NewPromptGeneratorFactory() PromptGeneratorFactory {
 	return &DefaultPromptGeneratorFactory{}
 }
 
+// NewPromptGeneratorFactoryWithTools creates a factory that also resolves user-defined tools
+func NewPromptGeneratorFactoryWithTools(tools map[Tool]*ToolDefinition) PromptGeneratorFactory {
+	return &DefaultPromptGeneratorFactory{Tools: tools}
+}
+
 func (f *DefaultPromptGeneratorFactory) NewPromptGenerator(tool Tool) (PromptGenerator, error) {
 	switch tool {
 	case Cursor:
 		return &CursorPromptGenerator{}, nil
+	case Claude:
+		return &ClaudePromptGenerator{}, nil
 	case Markdown, AmazonQ, Kiro:
 		return &MarkdownPromptGenerator{}, nil
 	case Copilot:
 		return &CopilotPromptGenerator{}, nil
 	default:
+		if def, ok := f.Tools[tool]; ok {
+			return &TemplatePromptGenerator{Definition: def}, nil
+		}
 		return nil, fmt.Errorf("unsupported tool: %s", tool)
 	}
 }
 
 // DefaultRuleFilenameGeneratorFactory creates rule filename generators
-type DefaultRuleFilenameGeneratorFactory struct{}
+type DefaultRuleFilenameGeneratorFactory struct {
+	// Tools holds user-defined tools resolved alongside the built-in ones
+	Tools map[Tool]*ToolDefinition
+}
 
 func NewRuleFilenameGeneratorFactory() RuleFilenameGeneratorFactory {
 	return &DefaultRuleFilenameGeneratorFactory{}
 }
 
+// NewRuleFilenameGeneratorFactoryWithTools creates a factory that also resolves user-defined tools
+func NewRuleFilenameGeneratorFactoryWithTools(tools map[Tool]*ToolDefinition) RuleFilenameGeneratorFactory {
+	return &DefaultRuleFilenameGeneratorFactory{Tools: tools}
+}
+
 func (f *DefaultRuleFilenameGeneratorFactory) NewRuleFilenameGenerator(tool Tool) (RuleFilenameGenerator, error) {
 	switch tool {
 	case Cursor:
 		return &CursorRuleFilenameGenerator{}, nil
-	case Markdown, AmazonQ, Kiro:
+	case Markdown, AmazonQ, Kiro, Claude:
 		return &MarkdownRuleFilenameGenerator{}, nil
 	case Copilot:
 		return &CopilotRuleFilenameGenerator{}, nil
 	default:
+		if def, ok := f.Tools[tool]; ok {
+			return &TemplateRuleFilenameGenerator{Definition: def}, nil
+		}
 		return nil, fmt.Errorf("unsupported rule filename tool: %s", tool)
 	}
 }
 
 // DefaultPromptFilenameGeneratorFactory creates prompt filename generators
-type DefaultPromptFilenameGeneratorFactory struct{}
+type DefaultPromptFilenameGeneratorFactory struct {
+	// Tools holds user-defined tools resolved alongside the built-in ones
+	Tools map[Tool]*ToolDefinition
+}
 
 func NewPromptFilenameGeneratorFactory() PromptFilenameGeneratorFactory {
 	return &DefaultPromptFilenameGeneratorFactory{}
 }
 
+// NewPromptFilenameGeneratorFactoryWithTools creates a factory that also resolves user-defined tools
+func NewPromptFilenameGeneratorFactoryWithTools(tools map[Tool]*ToolDefinition) PromptFilenameGeneratorFactory {
+	return &DefaultPromptFilenameGeneratorFactory{Tools: tools}
+}
+
 func (f *DefaultPromptFilenameGeneratorFactory) NewPromptFilenameGenerator(tool Tool) (PromptFilenameGenerator, error) {
 	switch tool {
 	case Cursor:
 		return &CursorPromptFilenameGenerator{}, nil
-	case Markdown, AmazonQ, Kiro:
+	case Markdown, AmazonQ, Kiro, Claude:
 		return &MarkdownPromptFilenameGenerator{}, nil
 	case Copilot:
 		return &CopilotPromptFilenameGenerator{}, nil
 	default:
+		if def, ok := f.Tools[tool]; ok {
+			return &TemplatePromptFilenameGenerator{Definition: def}, nil
+		}
 		return nil, fmt.Errorf("unsupported prompt filename tool: %s", tool)
 	}
 }
+
+// DefaultMcpGeneratorFactory creates MCP generators
+type DefaultMcpGeneratorFactory struct {
+	// Tools holds user-defined tools resolved alongside the built-in ones
+	Tools map[Tool]*ToolDefinition
+}
+
+func NewMcpGeneratorFactory() McpGeneratorFactory {
+	return &DefaultMcpGeneratorFactory{}
+}
+
+// NewMcpGeneratorFactoryWithTools creates a factory that also resolves user-defined tools
+func NewMcpGeneratorFactoryWithTools(tools map[Tool]*ToolDefinition) McpGeneratorFactory {
+	return &DefaultMcpGeneratorFactory{Tools: tools}
+}
+
+func (f *DefaultMcpGeneratorFactory) NewMcpGenerator(tool Tool) (McpGenerator, error) {
+	switch tool {
+	case Cursor, AmazonQ, Kiro:
+		return &McpJSONGenerator{Filename: "mcp.json", ServersKey: "mcpServers"}, nil
+	case Copilot:
+		return &McpJSONGenerator{Filename: "mcp.json", ServersKey: "servers", Typed: true}, nil
+	case Markdown, Claude:
+		return &McpJSONGenerator{Filename: ".mcp.json", ServersKey: "mcpServers", Typed: true}, nil
+	default:
+		if _, ok := f.Tools[tool]; ok {
+			return &McpJSONGenerator{Filename: "mcp.json", ServersKey: "mcpServers"}, nil
+		}
+		return nil, fmt.Errorf("unsupported mcp tool: %s", tool)
+	}
+}
+
+// DefaultAgentGeneratorFactory creates agent generators
+type DefaultAgentGeneratorFactory struct {
+	// Tools holds user-defined tools resolved alongside the built-in ones
+	Tools map[Tool]*ToolDefinition
+}
+
+func NewAgentGeneratorFactory() AgentGeneratorFactory {
+	return &DefaultAgentGeneratorFactory{}
+}
+
+// NewAgentGeneratorFactoryWithTools creates a factory that also resolves user-defined tools
+func NewAgentGeneratorFactoryWithTools(tools map[Tool]*ToolDefinition) AgentGeneratorFactory {
+	return &DefaultAgentGeneratorFactory{Tools: tools}
+}
+
+func (f *DefaultAgentGeneratorFactory) NewAgentGenerator(tool Tool) (AgentGenerator, error) {
+	switch tool {
+	case Claude, Markdown:
+		return &MarkdownAgentGenerator{}, nil
+	case Copilot:
+		return &CopilotAgentGenerator{}, nil
+	case Kiro, AmazonQ:
+		return &JSONAgentGenerator{}, nil
+	default:
+		if _, ok := f.Tools[tool]; ok {
+			return &MarkdownAgentGenerator{}, nil
+		}
+		return nil, fmt.Errorf("unsupported agent tool: %s", tool)
+	}
+}
+
+// DefaultHookGeneratorFactory creates hook generators
+type DefaultHookGeneratorFactory struct{}
+
+func NewHookGeneratorFactory() HookGeneratorFactory {
+	return &DefaultHookGeneratorFactory{}
+}
+
+func (f *DefaultHookGeneratorFactory) NewHookGenerator(tool Tool) (HookGenerator, error) {
+	switch tool {
+	case Claude:
+		return &ClaudeHookGenerator{}, nil
+	case Kiro:
+		return &KiroHookGenerator{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported hook tool: %s", tool)
+	}
+}
@@ -5,7 +5,7 @@ import "testing"
 func TestRuleGeneratorFactory(t *testing.T) {
 	factory := NewRuleGeneratorFactory()
 
-	validTools := []Tool{Cursor, Markdown, AmazonQ, Copilot}
+	validTools := []Tool{Cursor, Markdown, AmazonQ, Copilot, Claude}
 
 	for _, tool := range validTools {
 		t.Run(string(tool), func(t *testing.T) {
@@ -29,7 +29,7 @@ func TestRuleGeneratorFactory(t *testing.T) {
 func TestPromptGeneratorFactory(t *testing.T) {
 	factory := NewPromptGeneratorFactory()
 
-	validTools := []Tool{Cursor, Markdown, AmazonQ, Copilot}
+	validTools := []Tool{Cursor, Markdown, AmazonQ, Copilot, Claude}
 
 	for _, tool := range validTools {
 		t.Run(string(tool), func(t *testing.T) {
@@ -53,7 +53,7 @@ func TestPromptGeneratorFactory(t *testing.T) {
 func TestRuleFilenameGeneratorFactory(t *testing.T) {
 	factory := NewRuleFilenameGeneratorFactory()
 
-	validTools := []Tool{Cursor, Markdown, AmazonQ, Copilot}
+	validTools := []Tool{Cursor, Markdown, AmazonQ, Copilot, Claude}
 
 	for _, tool := range validTools {
 		t.Run(string(tool), func(t *testing.T) {
@@ -77,7 +77,7 @@ func TestRuleFilenameGeneratorFactory(t *testing.T) {
 func TestPromptFilenameGeneratorFactory(t *testing.T) {
 	factory := NewPromptFilenameGeneratorFactory()
 
-	validTools := []Tool{Cursor, Markdown, AmazonQ, Copilot}
+	validTools := []Tool{Cursor, Markdown, AmazonQ, Copilot, Claude}
 
 	for _, tool := range validTools {
 		t.Run(string(tool), func(t *testing.T) {
@@ -97,3 +97,42 @@ func TestPromptFilenameGeneratorFactory(t *testing.T) {
 		t.Error("Expected error for invalid tool, got nil")
 	}
 }
+
+func TestAgentGeneratorFactory(t *testing.T) {
+	factory := NewAgentGeneratorFactory()
+
+	validTools := []Tool{Claude, Copilot, Kiro, AmazonQ, Markdown}
+
+	for _, tool := range validTools {
+		t.Run(string(tool), func(t *testing.T) {
+			generator, err := factory.NewAgentGenerator(tool)
+			if err != nil {
+				t.Fatalf("Expected no error for tool %s, got %v", tool, err)
+			}
+			if generator == nil {
+				t.Fatalf("Expected generator for tool %s, got nil", tool)
+			}
+		})
+	}
+
+	// Cursor has no file-based agents
+	if _, err := factory.NewAgentGenerator(Cursor); err == nil {
+		t.Error("Expected error for cursor, got nil")
+	}
+}
+
+func TestHookGeneratorFactory(t *testing.T) {
+	factory := NewHookGeneratorFactory()
+
+	for _, tool := range []Tool{Claude, Kiro} {
+		if _, err := factory.NewHookGenerator(tool); err != nil {
+			t.Errorf("Expected no error for tool %s, got %v", tool, err)
+		}
+	}
+
+	for _, tool := range []Tool{Cursor, Copilot, AmazonQ, Markdown} {
+		if _, err := factory.NewHookGenerator(tool); err == nil {
+			t.Errorf("Expected error for tool %s, got nil", tool)
+		}
+	}
+}
@@ -46,16 +46,20 @@ func GenerateRuleMetadata(namespace string, ruleset *resource.RulesetResource, r
 	if len(rule.Scope) > 0 {
 		parts = append(parts, "  scope:")
 		for _, scope := range rule.Scope {
-			if len(scope.Files) > 0 {
-				parts = append(parts, "    - files: [")
-				for i, file := range scope.Files {
-					if i == 0 {
-						parts[len(parts)-1] += fmt.Sprintf(`%q`, file)
-					} else {
-						parts[len(parts)-1] += fmt.Sprintf(`, %q`, file)
-					}
+			prefix := "    - "
+			for _, field := range []struct {
+				name   string
+				values []string
+			}{
+				{"files", scope.Files},
+				{"languages", scope.Languages},
+				{"directories", scope.Directories},
+			} {
+				if len(field.values) == 0 {
+					continue
 				}
-				parts[len(parts)-1] += "]"
+				parts = append(parts, fmt.Sprintf("%s%s: %s", prefix, field.name, quoteList(field.values)))
+				prefix = "      "
 			}
 		}
 	}
@@ -64,3 +68,56 @@ func GenerateRuleMetadata(namespace string, ruleset *resource.RulesetResource, r
 
 	return strings.Join(parts, "\n")
 }
+
+// SplitRuleset prepares a ruleset for a generator. Generators that cannot express a
+// union of file patterns get one rule per pattern, with IDs suffixed -1, -2, ...
+// Other generators get the ruleset unchanged. The returned map gives the rule each
+// emitted rule ID came from; a split part whose ID the ruleset already uses is an error.
+func SplitRuleset(gen RuleGenerator, ruleset *resource.RulesetResource) (*resource.RulesetResource, map[string]string, error) {
+	origins := make(map[string]string, len(ruleset.Spec.Rules))
+	splitter, ok := gen.(ScopeSplitter)
+	if !ok {
+		for ruleID := range ruleset.Spec.Rules {
+			origins[ruleID] = ruleID
+		}
+		return ruleset, origins, nil
+	}
+
+	ruleIDs := make([]string, 0, len(ruleset.Spec.Rules))
+	for ruleID := range ruleset.Spec.Rules {
+		ruleIDs = append(ruleIDs, ruleID)
+	}
+	sort.Strings(ruleIDs)
+
+	split := *ruleset
+	split.Spec.Rules = make(map[string]resource.Rule, len(ruleset.Spec.Rules))
+	for _, ruleID := range ruleIDs {
+		rule := ruleset.Spec.Rules[ruleID]
+		globs := resource.ScopeGlobs(rule.Scope)
+		if len(globs) <= 1 || !splitter.SplitScopes(&rule) {
+			split.Spec.Rules[ruleID] = rule
+			origins[ruleID] = ruleID
+			continue
+		}
+		for i, glob := range globs {
+			partID := fmt.Sprintf("%s-%d", ruleID, i+1)
+			if _, ok := ruleset.Spec.Rules[partID]; ok {
+				return nil, nil, fmt.Errorf("rule %s: split part %s has the same ID as another rule", ruleID, partID)
+			}
+			part := rule
+			part.Scope = []resource.Scope{{Files: []string{glob}}}
+			split.Spec.Rules[partID] = part
+			origins[partID] = ruleID
+		}
+	}
+	return &split, origins, nil
+}
+
+// quoteList renders values as an inline YAML list of quoted strings
+func quoteList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = fmt.Sprintf("%q", value)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
@@ -0,0 +1,110 @@
+package compiler
+
+import (
+	"fmt"
+
+	"github.com/jomadu/ai-resource-manager/internal/arm/resource"
+)
+
+// claudeEditTools are the Claude Code tools that modify files
+const claudeEditTools = "Edit|MultiEdit|Write"
+
+// claudeHookEvents maps hook events to Claude Code hook events
+var claudeHookEvents = map[string]string{
+	"session-start":      "SessionStart",
+	"user-prompt-submit": "UserPromptSubmit",
+	"pre-tool-use":       "PreToolUse",
+	"post-tool-use":      "PostToolUse",
+	"post-edit":          "PostToolUse",
+	"stop":               "Stop",
+}
+
+// ClaudeHookGenerator merges hooks into Claude Code's settings.json
+type ClaudeHookGenerator struct{}
+
+func (g *ClaudeHookGenerator) HookFilename() string {
+	return "settings.json"
+}
+
+func (g *ClaudeHookGenerator) GenerateHookFilename(hooksetID, hookID string) (string, error) {
+	return "", fmt.Errorf("claude hooks are merged into %s", g.HookFilename())
+}
+
+func (g *ClaudeHookGenerator) GenerateHook(hookID string, hook resource.Hook) (string, map[string]interface{}, error) {
+	event, ok := claudeHookEvents[hook.Event]
+	if !ok {
+		return "", nil, fmt.Errorf("claude does not support hook event %s", hook.Event)
+	}
+
+	command := map[string]interface{}{
+		"type":    "command",
+		"command": hook.Command,
+	}
+	if hook.Timeout > 0 {
+		command["timeout"] = hook.Timeout
+	}
+	entry := map[string]interface{}{
+		"hooks": []interface{}{command},
+	}
+
+	// Claude matches tool names only; edit hooks run for every edited file
+	switch {
+	case hook.Event == "post-edit":
+		entry["matcher"] = claudeEditTools
+	case hook.Matcher != "":
+		entry["matcher"] = hook.Matcher
+	}
+	return event, entry, nil
+}
+
+// kiroHookEvents maps hook events to Kiro hook triggers
+var kiroHookEvents = map[string]string{
+	"user-prompt-submit": "promptSubmit",
+	"post-edit":          "fileEdited",
+	"stop":               "agentStop",
+}
+
+// KiroHookGenerator writes one Kiro .kiro.hook file per hook
+type KiroHookGenerator struct{}
+
+func (g *KiroHookGenerator) HookFilename() string {
+	return ""
+}
+
+func (g *KiroHookGenerator) GenerateHookFilename(hooksetID, hookID string) (string, error) {
+	if hooksetID == "" {
+		return "", fmt.Errorf("hooksetID cannot be empty")
+	}
+	if hookID == "" {
+		return "", fmt.Errorf("hookID cannot be empty")
+	}
+
+	return hooksetID + "_" + hookID + ".kiro.hook", nil
+}
+
+func (g *KiroHookGenerator) GenerateHook(hookID string, hook resource.Hook) (string, map[string]interface{}, error) {
+	trigger, ok := kiroHookEvents[hook.Event]
+	if !ok {
+		return "", nil, fmt.Errorf("kiro does not support hook event %s", hook.Event)
+	}
+
+	when := map[string]interface{}{"type": trigger}
+	if hook.Event == "post-edit" {
+		when["patterns"] = []string{withDefault(hook.Matcher, "**/*")}
+	}
+
+	entry := map[string]interface{}{
+		"enabled": true,
+		"name":    hookID,
+		"version": "1",
+		"when":    when,
+		"then": map[string]interface{}{
+			"type":    "runCommand",
+			"command": hook.Command,
+		},
+	}
+	if hook.Description != "" {
+		entry["description"] = hook.Description
+	}
+	return trigger, entry, nil
+}
@@ -0,0 +1,110 @@
+package compiler
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-manager/internal/arm/resource"
+)
+
+func newTestHookset() *resource.HooksetResource {
+	return &resource.HooksetResource{
+		APIVersion: "v1",
+		Kind:       "Hookset",
+		Metadata:   resource.ResourceMetadata{ID: "quality"},
+		Spec: resource.HooksetSpec{
+			Hooks: map[string]resource.Hook{
+				"format": {
+					Event:   "post-edit",
+					Matcher: "**/*.go",
+					Command: "gofmt -w .",
+					Timeout: 30,
+				},
+				"guard": {
+					Event:   "pre-tool-use",
+					Matcher: "Bash",
+					Command: "./scripts/guard.sh",
+				},
+			},
+		},
+	}
+}
+
+func TestClaudeHookGenerator(t *testing.T) {
+	gen := &ClaudeHookGenerator{}
+	hooks := newTestHookset().Spec.Hooks
+
+	event, entry, err := gen.GenerateHook("format", hooks["format"])
+	if err != nil {
+		t.Fatalf("GenerateHook() error = %v", err)
+	}
+	if event != "PostToolUse" || entry["matcher"] != claudeEditTools {
+		t.Errorf("GenerateHook() = %s %v, want PostToolUse on edit tools", event, entry)
+	}
+	command := entry["hooks"].([]interface{})[0].(map[string]interface{})
+	if command["type"] != "command" || command["command"] != "gofmt -w ." || command["timeout"] != 30 {
+		t.Errorf("GenerateHook() command = %v", command)
+	}
+
+	event, entry, err = gen.GenerateHook("guard", hooks["guard"])
+	if err != nil {
+		t.Fatalf("GenerateHook() error = %v", err)
+	}
+	if event != "PreToolUse" || entry["matcher"] != "Bash" {
+		t.Errorf("GenerateHook() = %s %v, want PreToolUse on Bash", event, entry)
+	}
+}
+
+func TestKiroHookGenerator(t *testing.T) {
+	gen := &KiroHookGenerator{}
+	hooks := newTestHookset().Spec.Hooks
+
+	filename, err := gen.GenerateHookFilename("quality", "format")
+	if err != nil || filename != "quality_format.kiro.hook" {
+		t.Errorf("GenerateHookFilename() = %s, %v", filename, err)
+	}
+
+	_, entry, err := gen.GenerateHook("format", hooks["format"])
+	if err != nil {
+		t.Fatalf("GenerateHook() error = %v", err)
+	}
+	when := entry["when"].(map[string]interface{})
+	if when["type"] != "fileEdited" || when["patterns"].([]string)[0] != "**/*.go" {
+		t.Errorf("GenerateHook() when = %v", when)
+	}
+
+	if _, _, err := gen.GenerateHook("guard", hooks["guard"]); err == nil || !strings.Contains(err.Error(), "pre-tool-use") {
+		t.Errorf("GenerateHook() error = %v, want unsupported event", err)
+	}
+}
+
+func TestCompileHookset(t *testing.T) {
+	files, err := CompileHookset(Claude, newTestHookset())
+	if err != nil {
+		t.Fatalf("CompileHookset() error = %v", err)
+	}
+	if len(files) != 1 || files[0].Path != "settings.json" {
+		t.Fatalf("CompileHookset() = %v, want settings.json", files)
+	}
+
+	var settings struct {
+		Hooks map[string][]interface{} `json:"hooks"`
+	}
+	if err := json.Unmarshal(files[0].Content, &settings); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(settings.Hooks["PostToolUse"]) != 1 || len(settings.Hooks["PreToolUse"]) != 1 {
+		t.Errorf("CompileHookset() hooks = %v", settings.Hooks)
+	}
+
+	hookset := newTestHookset()
+	delete(hookset.Spec.Hooks, "guard")
+	files, err = CompileHookset(Kiro, hookset)
+	if err != nil {
+		t.Fatalf("CompileHookset() error = %v", err)
+	}
+	if len(files) != 1 || files[0].Path != "quality_format.kiro.hook" {
+		t.Errorf("CompileHookset() = %v, want one Kiro hook file", files)
+	}
+}
@@ -0,0 +1,238 @@
+package compiler
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jomadu/ai-resource-manager/internal/arm/core"
+	"github.com/jomadu/ai-resource-manager/internal/arm/parser"
+	"github.com/jomadu/ai-resource-manager/internal/arm/resource"
+	"gopkg.in/yaml.v3"
+)
+
+// importSuffixes are the rule file extensions stripped when deriving a rule ID, longest first
+var importSuffixes = []string{".instructions.md", ".mdc", ".md"}
+
+// invalidIDChars matches characters not allowed in derived rule IDs
+var invalidIDChars = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// ImportPatterns returns the file patterns a tool's rule files match
+func ImportPatterns(tool Tool) []string {
+	switch tool {
+	case Cursor:
+		return []string{"*.mdc", ".cursorrules"}
+	case Copilot:
+		return []string{"*.instructions.md", "copilot-instructions.md"}
+	default:
+		return []string{"*.md"}
+	}
+}
+
+// ImportRuleset reads tool rule files back into a ruleset. Tool frontmatter is
+// mapped onto rule fields; files compiled by ARM keep their original rule IDs,
+// names, enforcement, priority and scope from the embedded ARM metadata.
+func ImportRuleset(tool Tool, rulesetID string, files []*core.File) (*resource.RulesetResource, error) {
+	if !IsBuiltinTool(tool) {
+		return nil, fmt.Errorf("unsupported import tool: %s", tool)
+	}
+
+	ruleset := &resource.RulesetResource{
+		APIVersion: "v1",
+		Kind:       "Ruleset",
+		Metadata:   resource.ResourceMetadata{ID: rulesetID},
+		Spec:       resource.RulesetSpec{Rules: make(map[string]resource.Rule)},
+	}
+
+	sources := make(map[string]string)
+	for _, file := range files {
+		ruleID, rule, meta, err := ImportRule(tool, file)
+		if err != nil {
+			return nil, err
+		}
+		if meta != nil && ruleset.Metadata.ID == "" {
+			ruleset.Metadata.ID = meta.Ruleset.ID
+			ruleset.Metadata.Name = meta.Ruleset.Name
+		}
+		if other, exists := sources[ruleID]; exists {
+			return nil, fmt.Errorf("rule %s is imported from both %s and %s", ruleID, other, file.Path)
+		}
+		sources[ruleID] = file.Path
+		ruleset.Spec.Rules[ruleID] = *rule
+	}
+
+	if ruleset.Metadata.ID == "" {
+		return nil, fmt.Errorf("ruleset ID is required")
+	}
+	if len(ruleset.Spec.Rules) == 0 {
+		return nil, fmt.Errorf("no rules to import")
+	}
+	return ruleset, nil
+}
+
+// ImportRule reads a single tool rule file back into a rule. The returned metadata
+// is the ARM metadata block of files ARM compiled, or nil for hand-written files.
+func ImportRule(tool Tool, file *core.File) (string, *resource.Rule, *ImportedMetadata, error) {
+	frontmatter, body, err := parser.SplitFrontmatter(file.Content)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("failed to read %s: %w", file.Path, err)
+	}
+
+	// Copilot and Kiro omit tool frontmatter for some rules, leaving the ARM metadata first
+	fields := parseFrontmatterFields(frontmatter)
+	meta := parseImportedMetadata(frontmatter)
+	if meta != nil {
+		fields = nil
+	} else {
+		metaFrontmatter, rest, err := parser.SplitFrontmatter(body)
+		if err == nil {
+			if meta = parseImportedMetadata(metaFrontmatter); meta != nil {
+				body = rest
+			}
+		}
+	}
+
+	rule := &resource.Rule{
+		Description: fields["description"],
+		Body:        strings.TrimRight(string(body), "\n") + "\n",
+	}
+	if strings.TrimSpace(rule.Body) == "" {
+		return "", nil, nil, fmt.Errorf("rule in %s has no body", file.Path)
+	}
+
+	switch tool {
+	case Cursor:
+		if fields["alwaysApply"] == "true" || filepath.Base(file.Path) == ".cursorrules" {
+			rule.Enforcement = "must"
+		}
+		rule.Scope = importScope(fields["globs"])
+	case Copilot:
+		applyTo := fields["applyTo"]
+		if applyTo == "**" || filepath.Base(file.Path) == "copilot-instructions.md" {
+			rule.Enforcement = "must"
+		} else {
+			rule.Scope = importScope(applyTo)
+		}
+	case Kiro:
+		switch fields["inclusion"] {
+		case "always":
+			rule.Enforcement = "must"
+		case "fileMatch":
+			rule.Scope = importScope(fields["fileMatchPattern"])
+		}
+	case Claude:
+		if filepath.Base(file.Path) == "CLAUDE.md" {
+			rule.Enforcement = "must"
+		}
+	}
+
+	ruleID := importRuleID(file.Path)
+	if meta != nil {
+		ruleID = withDefault(meta.Rule.ID, ruleID)
+		rule.Name = meta.Rule.Name
+		rule.Enforcement = withDefault(strings.ToLower(meta.Rule.Enforcement), rule.Enforcement)
+		rule.Priority = meta.Rule.Priority
+		if len(meta.Rule.Scope) > 0 {
+			rule.Scope = meta.Rule.Scope
+		}
+	}
+	if ruleID == "" {
+		return "", nil, nil, fmt.Errorf("cannot derive a rule ID from %s", file.Path)
+	}
+
+	return ruleID, rule, meta, nil
+}
+
+// ImportedMetadata is the ARM metadata block GenerateRuleMetadata embeds in compiled rules
+type ImportedMetadata struct {
+	Namespace string `yaml:"namespace"`
+	Ruleset   struct {
+		ID   string `yaml:"id"`
+		Name string `yaml:"name"`
+	} `yaml:"ruleset"`
+	Rule struct {
+		ID          string           `yaml:"id"`
+		Name        string           `yaml:"name"`
+		Enforcement string           `yaml:"enforcement"`
+		Priority    int              `yaml:"priority"`
+		Scope       []resource.Scope `yaml:"scope"`
+	} `yaml:"rule"`
+}
+
+// parseImportedMetadata returns the ARM metadata in frontmatter, or nil when the
+// frontmatter is not an ARM metadata block
+func parseImportedMetadata(frontmatter []byte) *ImportedMetadata {
+	if frontmatter == nil {
+		return nil
+	}
+	var meta ImportedMetadata
+	if err := yaml.Unmarshal(frontmatter, &meta); err != nil {
+		return nil
+	}
+	if meta.Namespace == "" || meta.Rule.ID == "" {
+		return nil
+	}
+	return &meta
+}
+
+// parseFrontmatterFields reads tool frontmatter as flat key: value pairs. Tools
+// write unquoted globs such as **/*.ts that are not valid YAML, so the values are
+// taken verbatim with surrounding quotes removed.
+func parseFrontmatterFields(frontmatter []byte) map[string]string {
+	fields := make(map[string]string)
+	for _, line := range strings.Split(string(frontmatter), "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.HasPrefix(line, " ") || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields[strings.TrimSpace(key)] = unquote(strings.TrimSpace(value))
+	}
+	return fields
+}
+
+// importScope converts a comma-separated or bracketed glob list into a rule scope
+func importScope(value string) []resource.Scope {
+	value = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(value), "["), "]")
+
+	var globs []string
+	for _, glob := range strings.Split(value, ",") {
+		if glob = unquote(strings.TrimSpace(glob)); glob != "" {
+			globs = append(globs, glob)
+		}
+	}
+	if len(globs) == 0 {
+		return nil
+	}
+	sort.Strings(globs)
+	return []resource.Scope{{Files: globs}}
+}
+
+// importRuleID derives a rule ID from a rule file name
+func importRuleID(path string) string {
+	name := strings.ToLower(filepath.Base(path))
+	for _, suffix := range importSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			name = strings.TrimSuffix(name, suffix)
+			break
+		}
+	}
+	name = strings.TrimPrefix(name, ".")
+	return strings.Trim(invalidIDChars.ReplaceAllString(name, "-"), "-")
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 {
+		if value[0] == '"' && value[len(value)-1] == '"' {
+			if unquoted, err := strconv.Unquote(value); err == nil {
+				return unquoted
+			}
+		}
+		if value[0] == '\'' && value[len(value)-1] == '\'' {
+			return strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+		}
+	}
+	return value
+}
@@ -0,0 +1,162 @@
+package compiler
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jomadu/ai-resource-manager/internal/arm/core"
+	"github.com/jomadu/ai-resource-manager/internal/arm/resource"
+)
+
+func TestImportRule(t *testing.T) {
+	tests := []struct {
+		name            string
+		tool            Tool
+		path            string
+		content         string
+		wantID          string
+		wantEnforcement string
+		wantScope       []resource.Scope
+	}{
+		{
+			name:            "cursor always apply",
+			tool:            Cursor,
+			path:            ".cursor/rules/security.mdc",
+			content:         "---\ndescription: Security\nalwaysApply: true\n---\n\nNo secrets.\n",
+			wantID:          "security",
+			wantEnforcement: "must",
+		},
+		{
+			name:      "cursor unquoted globs",
+			tool:      Cursor,
+			path:      ".cursor/rules/Go Style.mdc",
+			content:   "---\nglobs: **/*.go, cmd/**\n---\nUse gofmt.\n",
+			wantID:    "go-style",
+			wantScope: []resource.Scope{{Files: []string{"**/*.go", "cmd/**"}}},
+		},
+		{
+			name:            "cursorrules",
+			tool:            Cursor,
+			path:            ".cursorrules",
+			content:         "Be concise.\n",
+			wantID:          "cursorrules",
+			wantEnforcement: "must",
+		},
+		{
+			name:      "copilot applyTo",
+			tool:      Copilot,
+			path:      ".github/instructions/tests.instructions.md",
+			content:   "---\napplyTo: \"**/*_test.go\"\n---\nUse table tests.\n",
+			wantID:    "tests",
+			wantScope: []resource.Scope{{Files: []string{"**/*_test.go"}}},
+		},
+		{
+			name:      "kiro fileMatch",
+			tool:      Kiro,
+			path:      ".kiro/steering/api.md",
+			content:   "---\ninclusion: fileMatch\nfileMatchPattern: \"api/**\"\n---\nVersion every endpoint.\n",
+			wantID:    "api",
+			wantScope: []resource.Scope{{Files: []string{"api/**"}}},
+		},
+		{
+			name:            "claude memory",
+			tool:            Claude,
+			path:            "CLAUDE.md",
+			content:         "# Project\n\nRun make test.\n",
+			wantID:          "claude",
+			wantEnforcement: "must",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ruleID, rule, meta, err := ImportRule(tt.tool, &core.File{Path: tt.path, Content: []byte(tt.content)})
+			if err != nil {
+				t.Fatalf("ImportRule() error = %v", err)
+			}
+			if meta != nil {
+				t.Errorf("ImportRule() metadata = %+v, want nil for hand-written file", meta)
+			}
+			if ruleID != tt.wantID {
+				t.Errorf("ImportRule() ID = %q, want %q", ruleID, tt.wantID)
+			}
+			if rule.Enforcement != tt.wantEnforcement {
+				t.Errorf("ImportRule() enforcement = %q, want %q", rule.Enforcement, tt.wantEnforcement)
+			}
+			if !reflect.DeepEqual(rule.Scope, tt.wantScope) {
+				t.Errorf("ImportRule() scope = %+v, want %+v", rule.Scope, tt.wantScope)
+			}
+		})
+	}
+}
+
+func TestImportRulesetRoundTrip(t *testing.T) {
+	original := &resource.RulesetResource{
+		APIVersion: "v1",
+		Kind:       "Ruleset",
+		Metadata:   resource.ResourceMetadata{ID: "clean-code", Name: "Clean Code"},
+		Spec: resource.RulesetSpec{
+			Rules: map[string]resource.Rule{
+				"naming": {
+					Name:        "Naming",
+					Description: "Naming conventions",
+					Enforcement: "should",
+					Priority:    50,
+					Scope:       []resource.Scope{{Files: []string{"**/*.go"}}},
+					Body:        "Use descriptive names.\n",
+				},
+				"secrets": {
+					Name:        "Secrets",
+					Enforcement: "must",
+					Body:        "Never commit secrets.\n",
+				},
+			},
+		},
+	}
+
+	for _, tool := range []Tool{Cursor, Copilot, Kiro, Claude} {
+		t.Run(string(tool), func(t *testing.T) {
+			files, err := CompileRuleset(tool, "test", original)
+			if err != nil {
+				t.Fatalf("CompileRuleset() error = %v", err)
+			}
+
+			imported, err := ImportRuleset(tool, "", files)
+			if err != nil {
+				t.Fatalf("ImportRuleset() error = %v", err)
+			}
+			if imported.Metadata != original.Metadata {
+				t.Errorf("ImportRuleset() metadata = %+v, want %+v", imported.Metadata, original.Metadata)
+			}
+
+			for ruleID, want := range original.Spec.Rules {
+				got, ok := imported.Spec.Rules[ruleID]
+				if !ok {
+					t.Fatalf("ImportRuleset() missing rule %s: %+v", ruleID, imported.Spec.Rules)
+				}
+				if got.Name != want.Name || got.Enforcement != want.Enforcement || got.Priority != want.Priority || got.Body != want.Body {
+					t.Errorf("ImportRuleset() rule %s = %+v, want %+v", ruleID, got, want)
+				}
+				if !reflect.DeepEqual(got.Scope, want.Scope) {
+					t.Errorf("ImportRuleset() rule %s scope = %+v, want %+v", ruleID, got.Scope, want.Scope)
+				}
+			}
+		})
+	}
+}
+
+func TestImportRulesetErrors(t *testing.T) {
+	files := []*core.File{
+		{Path: "a/style.md", Content: []byte("One.\n")},
+		{Path: "b/style.md", Content: []byte("Two.\n")},
+	}
+	if _, err := ImportRuleset(Markdown, "team", files); err == nil {
+		t.Error("ImportRuleset() expected duplicate rule error")
+	}
+	if _, err := ImportRuleset(Markdown, "", files[:1]); err == nil {
+		t.Error("ImportRuleset() expected missing ID error")
+	}
+	if _, err := ImportRuleset(Markdown, "team", []*core.File{{Path: "empty.md", Content: []byte("---\ndescription: x\n---\n")}}); err == nil {
+		t.Error("ImportRuleset() expected empty body error")
+	}
+}
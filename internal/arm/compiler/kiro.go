@@ -0,0 +1,52 @@
+package compiler
+
+import (
+	"fmt"
+
+	"github.com/jomadu/ai-resource-manager/internal/arm/resource"
+)
+
+// KiroRuleGenerator generates kiro steering content
+type KiroRuleGenerator struct {
+	// Enforcement maps enforcement levels to kiro inclusion modes
+	Enforcement EnforcementPolicy
+}
+
+func (g *KiroRuleGenerator) GenerateRule(namespace string, ruleset *resource.RulesetResource, ruleID string) (string, error) {
+	rule, exists := ruleset.Spec.Rules[ruleID]
+	if !exists {
+		return "", fmt.Errorf("rule %s not found in ruleset", ruleID)
+	}
+
+	// Kiro format: inclusion frontmatter (when not always included) + metadata + content
+	metadata := GenerateRuleMetadata(namespace, ruleset, ruleID, &rule)
+	switch g.Enforcement.Activation(&rule) {
+	case ActivationAlways:
+		return "---\ninclusion: always\n---\n\n" + metadata + "\n\n" + rule.Body, nil
+	case ActivationAgentRequested, ActivationManual:
+		// Kiro has no agent-requested mode; such rules are referenced by hand
+		return "---\ninclusion: manual\n---\n\n" + metadata + "\n\n" + rule.Body, nil
+	}
+
+	globs := resource.ScopeGlobs(rule.Scope)
+	switch len(globs) {
+	case 0:
+		// Steering files without frontmatter are always included
+		return metadata + "\n\n" + rule.Body, nil
+	case 1:
+		frontmatter := fmt.Sprintf("---\ninclusion: fileMatch\nfileMatchPattern: %q\n---", globs[0])
+		return frontmatter + "\n\n" + metadata + "\n\n" + rule.Body, nil
+	default:
+		return "", fmt.Errorf("rule %s matches %d file patterns but kiro supports one per file (use SplitRuleset)", ruleID, len(globs))
+	}
+}
+
+// SplitScopes reports whether the rule is attached with a fileMatchPattern,
+// which kiro limits to a single pattern per steering file
+func (g *KiroRuleGenerator) SplitScopes(rule *resource.Rule) bool {
+	switch g.Enforcement.Activation(rule) {
+	case "", ActivationAutoAttached:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+package compiler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-manager/internal/arm/resource"
+)
+
+func TestKiroRuleGenerator_GenerateRule(t *testing.T) {
+	generator := &KiroRuleGenerator{}
+
+	ruleset := &resource.RulesetResource{
+		Spec: resource.RulesetSpec{
+			Rules: map[string]resource.Rule{
+				"scoped": {
+					Name:  "Scoped",
+					Scope: []resource.Scope{{Languages: []string{"go"}}},
+					Body:  "Go rule",
+				},
+				"unscoped": {Name: "Unscoped", Body: "Everywhere"},
+				"union": {
+					Name:  "Union",
+					Scope: []resource.Scope{{Files: []string{"**/*.go"}}, {Files: []string{"**/*.md"}}},
+					Body:  "Union rule",
+				},
+			},
+		},
+	}
+
+	scoped, err := generator.GenerateRule("ns", ruleset, "scoped")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.HasPrefix(scoped, "---\ninclusion: fileMatch\nfileMatchPattern: \"**/*.go\"\n---\n\n---\nnamespace: ns") {
+		t.Errorf("Expected fileMatch frontmatter, got:\n%s", scoped)
+	}
+
+	unscoped, err := generator.GenerateRule("ns", ruleset, "unscoped")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if strings.Contains(unscoped, "inclusion:") {
+		t.Errorf("Expected no inclusion frontmatter for unscoped rule, got:\n%s", unscoped)
+	}
+
+	if _, err := generator.GenerateRule("ns", ruleset, "union"); err == nil {
+		t.Error("Expected error for unsplit union scope, got nil")
+	}
+}
+
+func TestSplitRuleset(t *testing.T) {
+	ruleset := &resource.RulesetResource{
+		Metadata: resource.ResourceMetadata{ID: "rs"},
+		Spec: resource.RulesetSpec{
+			Rules: map[string]resource.Rule{
+				"union": {
+					Scope: []resource.Scope{{Files: []string{"**/*.go"}}, {Directories: []string{"docs"}}},
+					Body:  "Union rule",
+				},
+				"single": {Scope: []resource.Scope{{Files: []string{"**/*.md"}}}, Body: "Single"},
+			},
+		},
+	}
+
+	// Generators that express unions keep the ruleset as is
+	if got, _, err := SplitRuleset(&CursorRuleGenerator{}, ruleset); err != nil || got != ruleset {
+		t.Errorf("Expected cursor to keep the ruleset unchanged, err = %v", err)
+	}
+
+	split, origins, err := SplitRuleset(&KiroRuleGenerator{}, ruleset)
+	if err != nil {
+		t.Fatalf("SplitRuleset failed: %v", err)
+	}
+	if len(split.Spec.Rules) != 3 {
+		t.Fatalf("Expected 3 rules after split, got %d", len(split.Spec.Rules))
+	}
+	if got := split.Spec.Rules["union-2"].Scope[0].Files[0]; got != "docs/**" {
+		t.Errorf("Expected union-2 scoped to docs/**, got %s", got)
+	}
+	if _, ok := split.Spec.Rules["single"]; !ok {
+		t.Error("Expected single-pattern rule to keep its ID")
+	}
+	want := map[string]string{"union-1": "union", "union-2": "union", "single": "single"}
+	for ruleID, origin := range want {
+		if origins[ruleID] != origin {
+			t.Errorf("Expected %s to come from %s, got %q", ruleID, origin, origins[ruleID])
+		}
+	}
+	if len(ruleset.Spec.Rules) != 2 {
+		t.Error("Expected original ruleset to be left untouched")
+	}
+
+	files, err := CompileRuleset(Kiro, "ns", ruleset)
+	if err != nil {
+		t.Fatalf("CompileRuleset failed: %v", err)
+	}
+	if len(files) != 3 || files[1].Path != "rs_union-1.md" {
+		t.Errorf("Expected split kiro files, got %d files", len(files))
+	}
+}
+
+func TestSplitRuleset_IDCollision(t *testing.T) {
+	ruleset := &resource.RulesetResource{
+		Metadata: resource.ResourceMetadata{ID: "rs"},
+		Spec: resource.RulesetSpec{
+			Rules: map[string]resource.Rule{
+				"go-style": {
+					Scope: []resource.Scope{{Files: []string{"**/*.go"}}, {Files: []string{"**/*.mod"}}},
+					Body:  "Go style",
+				},
+				"go-style-2": {Body: "Unrelated rule"},
+			},
+		},
+	}
+
+	_, _, err := SplitRuleset(&KiroRuleGenerator{}, ruleset)
+	if err == nil || !strings.Contains(err.Error(), "split part go-style-2") {
+		t.Errorf("Expected collision error for go-style-2, got %v", err)
+	}
+	if _, err := CompileRuleset(Kiro, "ns", ruleset); err == nil {
+		t.Error("Expected CompileRuleset to reject the collision")
+	}
+}
+
+func TestCursorRuleGenerator_MultipleScopes(t *testing.T) {
+	generator := &CursorRuleGenerator{}
+
+	ruleset := &resource.RulesetResource{
+		Spec: resource.RulesetSpec{
+			Rules: map[string]resource.Rule{
+				"rule": {
+					Scope: []resource.Scope{
+						{Files: []string{"**/*.go"}},
+						{Directories: []string{"web"}, Languages: []string{"javascript"}},
+					},
+					Body: "Body",
+				},
+			},
+		},
+	}
+
+	result, err := generator.GenerateRule("ns", ruleset, "rule")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	wantGlobs := "globs: **/*.go, web/**/*.js, web/**/*.jsx, web/**/*.mjs, web/**/*.cjs"
+	if !strings.Contains(result, wantGlobs) {
+		t.Errorf("Expected %q in output, got:\n%s", wantGlobs, result)
+	}
+	wantMetadata := "    - files: [\"**/*.go\"]\n    - languages: [\"javascript\"]\n      directories: [\"web\"]"
+	if !strings.Contains(result, wantMetadata) {
+		t.Errorf("Expected metadata to list every scope entry, got:\n%s", result)
+	}
+}
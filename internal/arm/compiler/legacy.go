@@ -0,0 +1,51 @@
+package compiler
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/jomadu/ai-resource-manager/internal/arm/core"
+	"github.com/jomadu/ai-resource-manager/internal/arm/resource"
+)
+
+// LegacyRuleset wraps a raw tool rule file as a single-rule ruleset so it is
+// compiled like any other rule. The ruleset ID is derived from the file's
+// directory and the rule ID from its name; frontmatter is read with the
+// conventions of the tool the file was written for.
+func LegacyRuleset(file *core.File) (*resource.RulesetResource, error) {
+	ruleID, rule, _, err := ImportRule(legacySourceTool(file.Path), file)
+	if err != nil {
+		return nil, err
+	}
+
+	// Files at the package root are their own ruleset; others are grouped by directory
+	var parts []string
+	for dir := filepath.Dir(file.Path); dir != "." && dir != "/" && dir != ""; dir = filepath.Dir(dir) {
+		if part := strings.Trim(invalidIDChars.ReplaceAllString(strings.ToLower(filepath.Base(dir)), "-"), "-"); part != "" {
+			parts = append([]string{part}, parts...)
+		}
+	}
+	rulesetID := withDefault(strings.Join(parts, "-"), ruleID)
+
+	return &resource.RulesetResource{
+		APIVersion: "v1",
+		Kind:       "Ruleset",
+		Metadata:   resource.ResourceMetadata{ID: rulesetID, Name: file.Path},
+		Spec: resource.RulesetSpec{
+			Rules: map[string]resource.Rule{ruleID: *rule},
+		},
+	}, nil
+}
+
+// legacySourceTool guesses which tool a raw rule file was written for
+func legacySourceTool(path string) Tool {
+	base := strings.ToLower(filepath.Base(path))
+	switch {
+	case base == ".cursorrules", strings.HasSuffix(base, ".mdc"):
+		return Cursor
+	case strings.HasSuffix(base, ".instructions.md"):
+		return Copilot
+	default:
+		return Markdown
+	}
+}
@@ -0,0 +1,40 @@
+package compiler
+
+import (
+	"testing"
+
+	"github.com/jomadu/ai-resource-manager/internal/arm/core"
+)
+
+func TestLegacyRuleset(t *testing.T) {
+	tests := []struct {
+		path            string
+		content         string
+		wantRulesetID   string
+		wantRuleID      string
+		wantEnforcement string
+	}{
+		{path: ".cursorrules", content: "Be concise.", wantRulesetID: "cursorrules", wantRuleID: "cursorrules", wantEnforcement: "must"},
+		{path: "rules-new/python.mdc", content: "---\nalwaysApply: true\n---\nUse black.", wantRulesetID: "rules-new", wantRuleID: "python", wantEnforcement: "must"},
+		{path: "Docs/Go Style.md", content: "# Go\n\nUse gofmt.", wantRulesetID: "docs", wantRuleID: "go-style"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			ruleset, err := LegacyRuleset(&core.File{Path: tt.path, Content: []byte(tt.content)})
+			if err != nil {
+				t.Fatalf("LegacyRuleset() error = %v", err)
+			}
+			if ruleset.Metadata.ID != tt.wantRulesetID {
+				t.Errorf("LegacyRuleset() ID = %q, want %q", ruleset.Metadata.ID, tt.wantRulesetID)
+			}
+			rule, ok := ruleset.Spec.Rules[tt.wantRuleID]
+			if !ok {
+				t.Fatalf("LegacyRuleset() rules = %+v, want %s", ruleset.Spec.Rules, tt.wantRuleID)
+			}
+			if rule.Enforcement != tt.wantEnforcement {
+				t.Errorf("LegacyRuleset() enforcement = %q, want %q", rule.Enforcement, tt.wantEnforcement)
+			}
+		})
+	}
+}
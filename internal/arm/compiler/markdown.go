@@ -2,6 +2,7 @@ package compiler
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/jomadu/ai-resource-manager/internal/arm/resource"
 )
@@ -29,8 +30,37 @@ func (g *MarkdownPromptGenerator) GeneratePrompt(namespace string, promptset *re
 		return "", fmt.Errorf("prompt %s not found in promptset", promptID)
 	}
 
-	// Prompts are just content, no metadata
-	return prompt.Body, nil
+	// Markdown has no argument syntax, so arguments are documented ahead of the body
+	return documentPromptArguments(&prompt), nil
+}
+
+// documentPromptArguments renders the prompt body with placeholders shown as
+// <name>, preceded by a list of the arguments the prompt expects
+func documentPromptArguments(prompt *resource.Prompt) string {
+	if len(prompt.Arguments) == 0 {
+		return prompt.Body
+	}
+
+	var b strings.Builder
+	b.WriteString("## Arguments\n\n")
+	for _, arg := range prompt.Arguments {
+		fmt.Fprintf(&b, "- `%s`", arg.Name)
+		switch {
+		case arg.Required:
+			b.WriteString(" (required)")
+		case arg.Default != "":
+			fmt.Fprintf(&b, " (default: `%s`)", arg.Default)
+		}
+		if arg.Description != "" {
+			b.WriteString(": " + arg.Description)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(prompt.RenderBody(func(arg *resource.PromptArgument) string {
+		return "<" + arg.Name + ">"
+	}))
+	return b.String()
 }
 
 // MarkdownRuleFilenameGenerator generates markdown rule filenames
@@ -95,6 +95,34 @@ func TestMarkdownPromptGenerator_GeneratePrompt(t *testing.T) {
 	}
 }
 
+func TestMarkdownPromptGenerator_GeneratePromptWithArguments(t *testing.T) {
+	generator := &MarkdownPromptGenerator{}
+
+	promptset := &resource.PromptsetResource{
+		Spec: resource.PromptsetSpec{
+			Prompts: map[string]resource.Prompt{
+				"explain": {
+					Arguments: []resource.PromptArgument{
+						{Name: "topic", Description: "What to explain", Required: true},
+						{Name: "format", Default: "markdown"},
+					},
+					Body: "Explain {{args.topic}} as {{args.format}}.",
+				},
+			},
+		},
+	}
+
+	result, err := generator.GeneratePrompt("test-namespace", promptset, "explain")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := "## Arguments\n\n- `topic` (required): What to explain\n- `format` (default: `markdown`)\n\nExplain <topic> as <format>."
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
 func TestMarkdownPromptGenerator_GeneratePrompt_NotFound(t *testing.T) {
 	generator := &MarkdownPromptGenerator{}
 	promptset := &resource.PromptsetResource{
@@ -0,0 +1,67 @@
+package compiler
+
+import (
+	"encoding/json"
+
+	"github.com/jomadu/ai-resource-manager/internal/arm/resource"
+)
+
+// McpJSONGenerator renders MCP servers into a JSON configuration file
+type McpJSONGenerator struct {
+	// Filename is the configuration file name within the sink directory
+	Filename string
+	// ServersKey is the top-level object holding the servers
+	ServersKey string
+	// Typed adds a "type" field (stdio or http) to each server
+	Typed bool
+}
+
+func (g *McpJSONGenerator) McpFilename() string {
+	return g.Filename
+}
+
+func (g *McpJSONGenerator) McpServersKey() string {
+	return g.ServersKey
+}
+
+func (g *McpJSONGenerator) GenerateMcpServer(server resource.McpServer) map[string]interface{} {
+	entry := make(map[string]interface{})
+
+	if server.IsRemote() {
+		if g.Typed {
+			entry["type"] = "http"
+		}
+		entry["url"] = server.URL
+		return entry
+	}
+
+	if g.Typed {
+		entry["type"] = "stdio"
+	}
+	entry["command"] = server.Command
+	if len(server.Args) > 0 {
+		entry["args"] = server.Args
+	}
+	if len(server.Env) > 0 {
+		entry["env"] = server.Env
+	}
+	return entry
+}
+
+// MarshalMcpConfig renders a complete MCP configuration holding the given servers
+func MarshalMcpConfig(gen McpGenerator, servers map[string]resource.McpServer) ([]byte, error) {
+	entries := make(map[string]interface{}, len(servers))
+	for name, server := range servers {
+		entries[name] = gen.GenerateMcpServer(server)
+	}
+	return MarshalJSONConfig(map[string]interface{}{gen.McpServersKey(): entries})
+}
+
+// MarshalJSONConfig formats a JSON tool configuration document the way ARM writes it
+func MarshalJSONConfig(config map[string]interface{}) ([]byte, error) {
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,81 @@
+package compiler
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jomadu/ai-resource-manager/internal/arm/resource"
+)
+
+func TestMcpJSONGenerator_GenerateMcpServer(t *testing.T) {
+	local := resource.McpServer{
+		Command: "npx",
+		Args:    []string{"-y", "server"},
+		Env:     map[string]string{"TOKEN": "${TOKEN}"},
+	}
+	remote := resource.McpServer{URL: "https://example.com/mcp"}
+
+	untyped := &McpJSONGenerator{Filename: "mcp.json", ServersKey: "mcpServers"}
+	entry := untyped.GenerateMcpServer(local)
+	if _, ok := entry["type"]; ok {
+		t.Errorf("untyped generator should not emit type, got %v", entry)
+	}
+	if entry["command"] != "npx" || entry["args"] == nil || entry["env"] == nil {
+		t.Errorf("unexpected local entry %v", entry)
+	}
+
+	typed := &McpJSONGenerator{Filename: "mcp.json", ServersKey: "servers", Typed: true}
+	if got := typed.GenerateMcpServer(local)["type"]; got != "stdio" {
+		t.Errorf("expected type stdio, got %v", got)
+	}
+	entry = typed.GenerateMcpServer(remote)
+	if entry["type"] != "http" || entry["url"] != remote.URL {
+		t.Errorf("unexpected remote entry %v", entry)
+	}
+	if _, ok := entry["command"]; ok {
+		t.Errorf("remote entry should not have a command, got %v", entry)
+	}
+}
+
+func TestCompileMcpset(t *testing.T) {
+	mcpset := &resource.McpsetResource{
+		APIVersion: "v1",
+		Kind:       "Mcpset",
+		Metadata:   resource.ResourceMetadata{ID: "servers"},
+		Spec: resource.McpsetSpec{
+			Servers: map[string]resource.McpServer{
+				"docs": {URL: "https://example.com/mcp"},
+			},
+		},
+	}
+
+	tests := []struct {
+		tool       Tool
+		path       string
+		serversKey string
+	}{
+		{Cursor, "mcp.json", "mcpServers"},
+		{Copilot, "mcp.json", "servers"},
+		{Markdown, ".mcp.json", "mcpServers"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.tool), func(t *testing.T) {
+			files, err := CompileMcpset(tt.tool, mcpset)
+			if err != nil {
+				t.Fatalf("CompileMcpset() error = %v", err)
+			}
+			if len(files) != 1 || files[0].Path != tt.path {
+				t.Fatalf("expected single file %s, got %v", tt.path, files)
+			}
+
+			var config map[string]map[string]interface{}
+			if err := json.Unmarshal(files[0].Content, &config); err != nil {
+				t.Fatalf("invalid JSON: %v", err)
+			}
+			if _, ok := config[tt.serversKey]["docs"]; !ok {
+				t.Errorf("expected docs under %s, got %s", tt.serversKey, files[0].Content)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package compiler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jomadu/ai-resource-manager/internal/arm/resource"
+)
+
+// RuleOverlay locally patches a single rule of an upstream ruleset. Fields left
+// empty keep the upstream value. AppendFile and ReplaceFile name local files
+// whose content is appended to or replaces the rule body.
+type RuleOverlay struct {
+	Disabled    bool             `json:"disabled,omitempty"`
+	Enforcement string           `json:"enforcement,omitempty"`
+	Priority    *int             `json:"priority,omitempty"`
+	Scope       []resource.Scope `json:"scope,omitempty"`
+	AppendFile  string           `json:"appendFile,omitempty"`
+	ReplaceFile string           `json:"replaceFile,omitempty"`
+}
+
+// Validate checks that the overlay's fields are well formed
+func (o *RuleOverlay) Validate() error {
+	if o.Enforcement != "" && !isEnforcementLevel(o.Enforcement) {
+		return fmt.Errorf("invalid enforcement level %q (must be %s)", o.Enforcement, strings.Join(enforcementLevels, ", "))
+	}
+	if o.AppendFile != "" && o.ReplaceFile != "" {
+		return fmt.Errorf("appendFile and replaceFile cannot both be set")
+	}
+	for _, scope := range o.Scope {
+		if err := scope.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplyRuleOverlays returns the ruleset with the overlays for its rules applied.
+// Overlays for rules the ruleset does not define are ignored; readFile loads
+// the files named by AppendFile and ReplaceFile. The original ruleset is
+// returned unchanged when no overlay applies.
+func ApplyRuleOverlays(ruleset *resource.RulesetResource, overlays map[string]RuleOverlay, readFile func(path string) ([]byte, error)) (*resource.RulesetResource, error) {
+	patched := *ruleset
+	patched.Spec.Rules = make(map[string]resource.Rule, len(ruleset.Spec.Rules))
+	applied := false
+
+	for _, ruleID := range sortedRuleIDs(ruleset) {
+		rule := ruleset.Spec.Rules[ruleID]
+		overlay, ok := overlays[ruleID]
+		if !ok {
+			patched.Spec.Rules[ruleID] = rule
+			continue
+		}
+		applied = true
+
+		if err := overlay.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid overlay for rule %s: %w", ruleID, err)
+		}
+		if overlay.Disabled {
+			continue
+		}
+
+		if overlay.Enforcement != "" {
+			rule.Enforcement = overlay.Enforcement
+		}
+		if overlay.Priority != nil {
+			rule.Priority = *overlay.Priority
+		}
+		if len(overlay.Scope) > 0 {
+			rule.Scope = overlay.Scope
+		}
+
+		switch {
+		case overlay.ReplaceFile != "":
+			content, err := readFile(overlay.ReplaceFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read overlay for rule %s: %w", ruleID, err)
+			}
+			rule.Body = string(content)
+		case overlay.AppendFile != "":
+			content, err := readFile(overlay.AppendFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read overlay for rule %s: %w", ruleID, err)
+			}
+			rule.Body = strings.TrimRight(rule.Body, "\n") + "\n\n" + string(content)
+		}
+
+		patched.Spec.Rules[ruleID] = rule
+	}
+
+	if !applied {
+		return ruleset, nil
+	}
+	return &patched, nil
+}
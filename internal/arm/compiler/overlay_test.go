@@ -0,0 +1,111 @@
+package compiler
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-manager/internal/arm/resource"
+)
+
+func TestApplyRuleOverlays(t *testing.T) {
+	ruleset := &resource.RulesetResource{
+		Metadata: resource.ResourceMetadata{ID: "clean-code"},
+		Spec: resource.RulesetSpec{Rules: map[string]resource.Rule{
+			"naming":    {Enforcement: "must", Priority: 100, Body: "Use descriptive names.\n"},
+			"comments":  {Enforcement: "should", Body: "Explain why, not what.\n"},
+			"functions": {Enforcement: "may", Body: "Keep functions short.\n"},
+			"errors":    {Body: "Wrap errors.\n"},
+		}},
+	}
+	files := map[string]string{
+		"overlays/naming.md": "Prefer Go initialisms.\n",
+		"overlays/errors.md": "Return errors with context.\n",
+	}
+	readFile := func(path string) ([]byte, error) {
+		content, ok := files[path]
+		if !ok {
+			return nil, fmt.Errorf("no such file: %s", path)
+		}
+		return []byte(content), nil
+	}
+	priority := 10
+
+	got, err := ApplyRuleOverlays(ruleset, map[string]RuleOverlay{
+		"naming":    {Priority: &priority, AppendFile: "overlays/naming.md"},
+		"comments":  {Disabled: true},
+		"functions": {Enforcement: "must", Scope: []resource.Scope{{Files: []string{"**/*.go"}}}},
+		"errors":    {ReplaceFile: "overlays/errors.md"},
+		"unknown":   {Disabled: true},
+	}, readFile)
+	if err != nil {
+		t.Fatalf("ApplyRuleOverlays() error = %v", err)
+	}
+
+	if _, ok := got.Spec.Rules["comments"]; ok {
+		t.Error("disabled rule should be removed")
+	}
+	naming := got.Spec.Rules["naming"]
+	if naming.Priority != 10 || naming.Enforcement != "must" {
+		t.Errorf("naming = %+v, want priority 10 and upstream enforcement", naming)
+	}
+	if naming.Body != "Use descriptive names.\n\nPrefer Go initialisms.\n" {
+		t.Errorf("naming body = %q", naming.Body)
+	}
+	functions := got.Spec.Rules["functions"]
+	if functions.Enforcement != "must" || len(functions.Scope) != 1 || functions.Body != "Keep functions short.\n" {
+		t.Errorf("functions = %+v", functions)
+	}
+	if body := got.Spec.Rules["errors"].Body; body != "Return errors with context.\n" {
+		t.Errorf("errors body = %q", body)
+	}
+
+	// The upstream ruleset is left untouched
+	if len(ruleset.Spec.Rules) != 4 || ruleset.Spec.Rules["naming"].Priority != 100 {
+		t.Errorf("upstream ruleset was modified: %+v", ruleset.Spec.Rules)
+	}
+}
+
+func TestApplyRuleOverlaysWithoutMatches(t *testing.T) {
+	ruleset := &resource.RulesetResource{Spec: resource.RulesetSpec{Rules: map[string]resource.Rule{
+		"naming": {Body: "Use descriptive names.\n"},
+	}}}
+
+	got, err := ApplyRuleOverlays(ruleset, map[string]RuleOverlay{"other": {Disabled: true}}, nil)
+	if err != nil {
+		t.Fatalf("ApplyRuleOverlays() error = %v", err)
+	}
+	if got != ruleset {
+		t.Error("ruleset without matching overlays should be returned unchanged")
+	}
+}
+
+func TestApplyRuleOverlaysErrors(t *testing.T) {
+	ruleset := &resource.RulesetResource{Spec: resource.RulesetSpec{Rules: map[string]resource.Rule{
+		"naming": {Body: "Use descriptive names.\n"},
+	}}}
+	missing := func(path string) ([]byte, error) { return nil, fmt.Errorf("no such file: %s", path) }
+
+	tests := []struct {
+		name    string
+		overlay RuleOverlay
+		wantErr string
+	}{
+		{name: "unknown enforcement", overlay: RuleOverlay{Enforcement: "always"}, wantErr: "invalid enforcement level"},
+		{name: "append and replace", overlay: RuleOverlay{AppendFile: "a.md", ReplaceFile: "b.md"}, wantErr: "cannot both be set"},
+		{name: "invalid scope", overlay: RuleOverlay{Scope: []resource.Scope{{Languages: []string{"cobol"}}}}, wantErr: "cobol"},
+		{name: "missing file", overlay: RuleOverlay{AppendFile: "missing.md"}, wantErr: "no such file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ApplyRuleOverlays(ruleset, map[string]RuleOverlay{"naming": tt.overlay}, missing)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ApplyRuleOverlays() error = %v, want %q", err, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), "rule naming") {
+				t.Errorf("error should name the rule: %v", err)
+			}
+		})
+	}
+}
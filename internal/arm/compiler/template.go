@@ -0,0 +1,220 @@
+package compiler
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/jomadu/ai-resource-manager/internal/arm/resource"
+)
+
+// Default templates used when a tool definition leaves a field empty
+const (
+	defaultRuleTemplate           = "{{ .Metadata }}\n\n{{ .Rule.Body }}"
+	defaultPromptTemplate         = "{{ .Prompt.Body }}"
+	defaultRuleFilenameTemplate   = "{{ .RulesetID }}_{{ .RuleID }}.md"
+	defaultPromptFilenameTemplate = "{{ .PromptsetID }}_{{ .PromptID }}.md"
+)
+
+// ToolDefinition describes a user-defined tool whose output is rendered with text/template
+type ToolDefinition struct {
+	Name           Tool   `json:"name,omitempty" yaml:"name"`
+	Layout         string `json:"layout,omitempty" yaml:"layout,omitempty"`
+	RuleTemplate   string `json:"ruleTemplate,omitempty" yaml:"ruleTemplate,omitempty"`
+	PromptTemplate string `json:"promptTemplate,omitempty" yaml:"promptTemplate,omitempty"`
+	RuleFilename   string `json:"ruleFilename,omitempty" yaml:"ruleFilename,omitempty"`
+	PromptFilename string `json:"promptFilename,omitempty" yaml:"promptFilename,omitempty"`
+}
+
+// RuleTemplateData is the data available to rule templates
+type RuleTemplateData struct {
+	Namespace string
+	Ruleset   *resource.RulesetResource
+	RuleID    string
+	Rule      resource.Rule
+	Priority  int
+	Metadata  string
+	// Activation is the activation the sink's enforcement policy maps the rule to,
+	// empty when the policy does not configure its enforcement level
+	Activation Activation
+}
+
+// PromptTemplateData is the data available to prompt templates
+type PromptTemplateData struct {
+	Namespace string
+	Promptset *resource.PromptsetResource
+	PromptID  string
+	Prompt    resource.Prompt
+}
+
+// RuleFilenameTemplateData is the data available to rule filename templates
+type RuleFilenameTemplateData struct {
+	RulesetID string
+	RuleID    string
+}
+
+// PromptFilenameTemplateData is the data available to prompt filename templates
+type PromptFilenameTemplateData struct {
+	PromptsetID string
+	PromptID    string
+}
+
+// IsBuiltinTool reports whether the tool is one of the tools shipped with ARM
+func IsBuiltinTool(tool Tool) bool {
+	switch tool {
+	case Cursor, Markdown, AmazonQ, Kiro, Copilot, Claude:
+		return true
+	}
+	return false
+}
+
+// Validate checks that the definition has a usable name, layout and templates
+func (d *ToolDefinition) Validate() error {
+	if d.Name == "" {
+		return fmt.Errorf("tool name is required")
+	}
+	if IsBuiltinTool(d.Name) {
+		return fmt.Errorf("tool %s conflicts with a built-in tool", d.Name)
+	}
+	if d.Layout != "" && d.Layout != "flat" && d.Layout != "hierarchical" {
+		return fmt.Errorf("tool %s has invalid layout %q (must be flat or hierarchical)", d.Name, d.Layout)
+	}
+
+	templates := map[string]string{
+		"ruleTemplate":   d.RuleTemplate,
+		"promptTemplate": d.PromptTemplate,
+		"ruleFilename":   d.RuleFilename,
+		"promptFilename": d.PromptFilename,
+	}
+	for field, text := range templates {
+		if _, err := parseTemplate(field, text); err != nil {
+			return fmt.Errorf("tool %s has invalid %s: %w", d.Name, field, err)
+		}
+	}
+	return nil
+}
+
+// TemplateRuleGenerator generates rule content from a tool definition
+type TemplateRuleGenerator struct {
+	Definition  *ToolDefinition
+	Enforcement EnforcementPolicy
+}
+
+func (g *TemplateRuleGenerator) GenerateRule(namespace string, ruleset *resource.RulesetResource, ruleID string) (string, error) {
+	rule, exists := ruleset.Spec.Rules[ruleID]
+	if !exists {
+		return "", fmt.Errorf("rule %s not found in ruleset", ruleID)
+	}
+
+	data := RuleTemplateData{
+		Namespace:  namespace,
+		Ruleset:    ruleset,
+		RuleID:     ruleID,
+		Rule:       rule,
+		Priority:   rule.Priority,
+		Metadata:   GenerateRuleMetadata(namespace, ruleset, ruleID, &rule),
+		Activation: g.Enforcement.Activation(&rule),
+	}
+	return renderTemplate("ruleTemplate", withDefault(g.Definition.RuleTemplate, defaultRuleTemplate), data)
+}
+
+// TemplatePromptGenerator generates prompt content from a tool definition
+type TemplatePromptGenerator struct {
+	Definition *ToolDefinition
+}
+
+func (g *TemplatePromptGenerator) GeneratePrompt(namespace string, promptset *resource.PromptsetResource, promptID string) (string, error) {
+	prompt, exists := promptset.Spec.Prompts[promptID]
+	if !exists {
+		return "", fmt.Errorf("prompt %s not found in promptset", promptID)
+	}
+
+	data := PromptTemplateData{
+		Namespace: namespace,
+		Promptset: promptset,
+		PromptID:  promptID,
+		Prompt:    prompt,
+	}
+	return renderTemplate("promptTemplate", withDefault(g.Definition.PromptTemplate, defaultPromptTemplate), data)
+}
+
+// TemplateRuleFilenameGenerator generates rule filenames from a tool definition
+type TemplateRuleFilenameGenerator struct {
+	Definition *ToolDefinition
+}
+
+func (g *TemplateRuleFilenameGenerator) GenerateRuleFilename(rulesetID, ruleID string) (string, error) {
+	if rulesetID == "" {
+		return "", fmt.Errorf("rulesetID cannot be empty")
+	}
+	if ruleID == "" {
+		return "", fmt.Errorf("ruleID cannot be empty")
+	}
+
+	data := RuleFilenameTemplateData{RulesetID: rulesetID, RuleID: ruleID}
+	return renderFilename("ruleFilename", withDefault(g.Definition.RuleFilename, defaultRuleFilenameTemplate), data)
+}
+
+// TemplatePromptFilenameGenerator generates prompt filenames from a tool definition
+type TemplatePromptFilenameGenerator struct {
+	Definition *ToolDefinition
+}
+
+func (g *TemplatePromptFilenameGenerator) GeneratePromptFilename(promptsetID, promptID string) (string, error) {
+	if promptsetID == "" {
+		return "", fmt.Errorf("promptsetID cannot be empty")
+	}
+	if promptID == "" {
+		return "", fmt.Errorf("promptID cannot be empty")
+	}
+
+	data := PromptFilenameTemplateData{PromptsetID: promptsetID, PromptID: promptID}
+	return renderFilename("promptFilename", withDefault(g.Definition.PromptFilename, defaultPromptFilenameTemplate), data)
+}
+
+// templateFuncs are the helper functions available to tool templates
+var templateFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"quote": func(s string) string { return fmt.Sprintf("%q", s) },
+	"globs": resource.ScopeGlobs,
+}
+
+func parseTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+}
+
+func renderTemplate(name, text string, data interface{}) (string, error) {
+	tmpl, err := parseTemplate(name, text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+func renderFilename(name, text string, data interface{}) (string, error) {
+	filename, err := renderTemplate(name, text, data)
+	if err != nil {
+		return "", err
+	}
+
+	filename = strings.TrimSpace(filename)
+	if filename == "" || strings.ContainsAny(filename, `/\`) {
+		return "", fmt.Errorf("%s rendered invalid filename %q", name, filename)
+	}
+	return filename, nil
+}
+
+func withDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
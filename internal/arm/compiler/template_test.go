@@ -0,0 +1,181 @@
+package compiler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-manager/internal/arm/resource"
+)
+
+func newTemplateTestRuleset() *resource.RulesetResource {
+	return &resource.RulesetResource{
+		Metadata: resource.ResourceMetadata{ID: "clean-code", Name: "Clean Code"},
+		Spec: resource.RulesetSpec{
+			Rules: map[string]resource.Rule{
+				"meaningfulNames": {
+					Name:        "Meaningful Names",
+					Description: "Use intention-revealing names",
+					Enforcement: "must",
+					Priority:    100,
+					Scope: []resource.Scope{
+						{Files: []string{"**/*.go", "**/*.ts"}},
+					},
+					Body: "Use meaningful names.",
+				},
+			},
+		},
+	}
+}
+
+func TestTemplateRuleGenerator_GenerateRule(t *testing.T) {
+	generator := &TemplateRuleGenerator{Definition: &ToolDefinition{
+		Name:         "windsurf",
+		RuleTemplate: "---\ntrigger: {{ if eq .Rule.Enforcement \"must\" }}always_on{{ else }}glob{{ end }}\nglobs: {{ join (globs .Rule.Scope) \",\" }}\npriority: {{ .Priority }}\nsource: {{ .Namespace }}/{{ .Ruleset.Metadata.ID }}/{{ .RuleID }}\n---\n{{ .Rule.Body }}",
+	}}
+
+	result, err := generator.GenerateRule("reg/pkg@1.0.0", newTemplateTestRuleset(), "meaningfulNames")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := `---
+trigger: always_on
+globs: **/*.go,**/*.ts
+priority: 100
+source: reg/pkg@1.0.0/clean-code/meaningfulNames
+---
+Use meaningful names.`
+
+	if result != expected {
+		t.Errorf("Expected:\n%s\n\nGot:\n%s", expected, result)
+	}
+}
+
+func TestTemplateRuleGenerator_DefaultTemplate(t *testing.T) {
+	generator := &TemplateRuleGenerator{Definition: &ToolDefinition{Name: "plain"}}
+
+	result, err := generator.GenerateRule("ns", newTemplateTestRuleset(), "meaningfulNames")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !strings.HasPrefix(result, "---\nnamespace: ns\n") {
+		t.Errorf("Expected default template to start with metadata, got:\n%s", result)
+	}
+	if !strings.HasSuffix(result, "\n\nUse meaningful names.") {
+		t.Errorf("Expected default template to end with body, got:\n%s", result)
+	}
+}
+
+func TestTemplateRuleGenerator_UnknownField(t *testing.T) {
+	generator := &TemplateRuleGenerator{Definition: &ToolDefinition{
+		Name:         "broken",
+		RuleTemplate: "{{ .Missing }}",
+	}}
+
+	if _, err := generator.GenerateRule("ns", newTemplateTestRuleset(), "meaningfulNames"); err == nil {
+		t.Error("Expected error for unknown template field, got nil")
+	}
+}
+
+func TestTemplatePromptGenerator_GeneratePrompt(t *testing.T) {
+	generator := &TemplatePromptGenerator{Definition: &ToolDefinition{
+		Name:           "windsurf",
+		PromptTemplate: "# {{ .Prompt.Name }}\n\n{{ .Prompt.Body }}",
+	}}
+
+	promptset := &resource.PromptsetResource{
+		Metadata: resource.ResourceMetadata{ID: "review"},
+		Spec: resource.PromptsetSpec{
+			Prompts: map[string]resource.Prompt{
+				"codeReview": {Name: "Code Review", Body: "Review this code."},
+			},
+		},
+	}
+
+	result, err := generator.GeneratePrompt("ns", promptset, "codeReview")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := "# Code Review\n\nReview this code."
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestTemplateFilenameGenerators(t *testing.T) {
+	def := &ToolDefinition{
+		Name:         "windsurf",
+		RuleFilename: "{{ .RulesetID }}-{{ lower .RuleID }}.md",
+	}
+
+	ruleFilename, err := (&TemplateRuleFilenameGenerator{Definition: def}).GenerateRuleFilename("clean-code", "meaningfulNames")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if ruleFilename != "clean-code-meaningfulnames.md" {
+		t.Errorf("Expected clean-code-meaningfulnames.md, got %s", ruleFilename)
+	}
+
+	promptFilename, err := (&TemplatePromptFilenameGenerator{Definition: def}).GeneratePromptFilename("review", "codeReview")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if promptFilename != "review_codeReview.md" {
+		t.Errorf("Expected default prompt filename review_codeReview.md, got %s", promptFilename)
+	}
+
+	badDef := &ToolDefinition{Name: "bad", RuleFilename: "{{ .RulesetID }}/{{ .RuleID }}.md"}
+	if _, err := (&TemplateRuleFilenameGenerator{Definition: badDef}).GenerateRuleFilename("a", "b"); err == nil {
+		t.Error("Expected error for filename containing a path separator, got nil")
+	}
+}
+
+func TestToolDefinition_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		def     ToolDefinition
+		wantErr bool
+	}{
+		{name: "valid", def: ToolDefinition{Name: "windsurf", RuleTemplate: "{{ .Rule.Body }}"}},
+		{name: "missing name", def: ToolDefinition{}, wantErr: true},
+		{name: "built-in name", def: ToolDefinition{Name: Cursor}, wantErr: true},
+		{name: "invalid layout", def: ToolDefinition{Name: "windsurf", Layout: "nested"}, wantErr: true},
+		{name: "invalid template", def: ToolDefinition{Name: "windsurf", RuleTemplate: "{{ .Rule.Body "}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.def.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFactoriesResolveCustomTools(t *testing.T) {
+	tools := map[Tool]*ToolDefinition{"windsurf": {Name: "windsurf"}}
+
+	if _, err := NewRuleGeneratorFactoryWithTools(tools).NewRuleGenerator("windsurf"); err != nil {
+		t.Errorf("Expected rule generator for custom tool, got %v", err)
+	}
+	if _, err := NewPromptGeneratorFactoryWithTools(tools).NewPromptGenerator("windsurf"); err != nil {
+		t.Errorf("Expected prompt generator for custom tool, got %v", err)
+	}
+	if _, err := NewRuleFilenameGeneratorFactoryWithTools(tools).NewRuleFilenameGenerator("windsurf"); err != nil {
+		t.Errorf("Expected rule filename generator for custom tool, got %v", err)
+	}
+	if _, err := NewPromptFilenameGeneratorFactoryWithTools(tools).NewPromptFilenameGenerator("windsurf"); err != nil {
+		t.Errorf("Expected prompt filename generator for custom tool, got %v", err)
+	}
+
+	// Built-in tools still resolve when custom tools are configured
+	if _, err := NewRuleGeneratorFactoryWithTools(tools).NewRuleGenerator(Cursor); err != nil {
+		t.Errorf("Expected built-in generator, got %v", err)
+	}
+	if _, err := NewRuleGeneratorFactoryWithTools(tools).NewRuleGenerator("unknown"); err == nil {
+		t.Error("Expected error for unknown tool, got nil")
+	}
+}
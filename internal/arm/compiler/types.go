@@ -13,6 +13,7 @@ const (
 	AmazonQ  Tool = "amazonq"
 	Kiro     Tool = "kiro"
 	Copilot  Tool = "copilot"
+	Claude   Tool = "claude"
 )
 
 // RuleGenerator interface for generating tool-specific rule files
@@ -20,11 +21,40 @@ type RuleGenerator interface {
 	GenerateRule(namespace string, ruleset *resource.RulesetResource, ruleID string) (string, error)
 }
 
+// ScopeSplitter is implemented by rule generators whose tool can attach a rule to
+// only one file pattern; SplitRuleset emits one rule per pattern for them
+type ScopeSplitter interface {
+	SplitScopes(rule *resource.Rule) bool
+}
+
 // PromptGenerator interface for generating tool-specific prompt files
 type PromptGenerator interface {
 	GeneratePrompt(namespace string, promptset *resource.PromptsetResource, promptID string) (string, error)
 }
 
+// McpGenerator interface for rendering MCP servers into a tool's MCP configuration
+type McpGenerator interface {
+	McpFilename() string
+	McpServersKey() string
+	GenerateMcpServer(server resource.McpServer) map[string]interface{}
+}
+
+// AgentGenerator interface for generating tool-specific agent definitions
+type AgentGenerator interface {
+	GenerateAgent(agentset *resource.AgentsetResource, agentID string) (string, error)
+	GenerateAgentFilename(agentsetID, agentID string) (string, error)
+}
+
+// HookGenerator interface for rendering hooks into a tool's hook configuration.
+// Tools with a shared settings file return its name from HookFilename and have
+// each hook merged into the file's hooks object under its event; tools without one
+// return "" and read each hook from its own file named by GenerateHookFilename.
+type HookGenerator interface {
+	HookFilename() string
+	GenerateHookFilename(hooksetID, hookID string) (string, error)
+	GenerateHook(hookID string, hook resource.Hook) (event string, entry map[string]interface{}, err error)
+}
+
 // RuleFilenameGenerator interface for generating rule filenames
 type RuleFilenameGenerator interface {
 	GenerateRuleFilename(rulesetID, ruleID string) (string, error)
@@ -45,6 +75,21 @@ type PromptGeneratorFactory interface {
 	NewPromptGenerator(tool Tool) (PromptGenerator, error)
 }
 
+// McpGeneratorFactory interface for creating MCP generators
+type McpGeneratorFactory interface {
+	NewMcpGenerator(tool Tool) (McpGenerator, error)
+}
+
+// AgentGeneratorFactory interface for creating agent generators
+type AgentGeneratorFactory interface {
+	NewAgentGenerator(tool Tool) (AgentGenerator, error)
+}
+
+// HookGeneratorFactory interface for creating hook generators
+type HookGeneratorFactory interface {
+	NewHookGenerator(tool Tool) (HookGenerator, error)
+}
+
 // RuleFilenameGeneratorFactory interface for creating rule filename generators
 type RuleFilenameGeneratorFactory interface {
 	NewRuleFilenameGenerator(tool Tool) (RuleFilenameGenerator, error)
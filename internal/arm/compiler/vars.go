@@ -0,0 +1,84 @@
+package compiler
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/jomadu/ai-resource-manager/internal/arm/resource"
+)
+
+// varReference matches {{ .Vars.name }} references in rule and prompt bodies
+var varReference = regexp.MustCompile(`\{\{\s*\.Vars\.([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// InterpolateVars replaces {{ .Vars.name }} references in body with their values.
+// The values used are recorded in used; a reference to an unset variable is an error.
+func InterpolateVars(body string, vars, used map[string]string) (string, error) {
+	var missing []string
+	rendered := varReference.ReplaceAllStringFunc(body, func(reference string) string {
+		name := varReference.FindStringSubmatch(reference)[1]
+		value, ok := vars[name]
+		if !ok {
+			missing = append(missing, name)
+			return reference
+		}
+		used[name] = value
+		return value
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("variable %s is not set", missing[0])
+	}
+	return rendered, nil
+}
+
+// InterpolateRuleset returns the ruleset with vars rendered into its rule bodies
+// and the variables it used. The original ruleset is not modified.
+func InterpolateRuleset(ruleset *resource.RulesetResource, vars map[string]string) (*resource.RulesetResource, map[string]string, error) {
+	used := make(map[string]string)
+	rendered := *ruleset
+	rendered.Spec.Rules = make(map[string]resource.Rule, len(ruleset.Spec.Rules))
+	for _, ruleID := range sortedRuleIDs(ruleset) {
+		rule := ruleset.Spec.Rules[ruleID]
+		body, err := InterpolateVars(rule.Body, vars, used)
+		if err != nil {
+			return nil, nil, fmt.Errorf("rule %s: %w", ruleID, err)
+		}
+		rule.Body = body
+		rendered.Spec.Rules[ruleID] = rule
+	}
+	return &rendered, used, nil
+}
+
+// InterpolatePromptset returns the promptset with vars rendered into its prompt
+// bodies and the variables it used. The original promptset is not modified.
+func InterpolatePromptset(promptset *resource.PromptsetResource, vars map[string]string) (*resource.PromptsetResource, map[string]string, error) {
+	promptIDs := make([]string, 0, len(promptset.Spec.Prompts))
+	for promptID := range promptset.Spec.Prompts {
+		promptIDs = append(promptIDs, promptID)
+	}
+	sort.Strings(promptIDs)
+
+	used := make(map[string]string)
+	rendered := *promptset
+	rendered.Spec.Prompts = make(map[string]resource.Prompt, len(promptset.Spec.Prompts))
+	for _, promptID := range promptIDs {
+		prompt := promptset.Spec.Prompts[promptID]
+		body, err := InterpolateVars(prompt.Body, vars, used)
+		if err != nil {
+			return nil, nil, fmt.Errorf("prompt %s: %w", promptID, err)
+		}
+		prompt.Body = body
+		rendered.Spec.Prompts[promptID] = prompt
+	}
+	return &rendered, used, nil
+}
+
+// sortedRuleIDs returns the ruleset's rule IDs in order so errors are reported deterministically
+func sortedRuleIDs(ruleset *resource.RulesetResource) []string {
+	ruleIDs := make([]string, 0, len(ruleset.Spec.Rules))
+	for ruleID := range ruleset.Spec.Rules {
+		ruleIDs = append(ruleIDs, ruleID)
+	}
+	sort.Strings(ruleIDs)
+	return ruleIDs
+}
@@ -0,0 +1,72 @@
+package compiler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-manager/internal/arm/resource"
+)
+
+func TestInterpolateVars(t *testing.T) {
+	vars := map[string]string{"testCommand": "make test", "projectName": "arm"}
+
+	used := make(map[string]string)
+	got, err := InterpolateVars("Run `{{ .Vars.testCommand }}` in {{.Vars.projectName}}. Keep {{ args.topic }} and {{ .Other }}.", vars, used)
+	if err != nil {
+		t.Fatalf("InterpolateVars() error = %v", err)
+	}
+	if want := "Run `make test` in arm. Keep {{ args.topic }} and {{ .Other }}."; got != want {
+		t.Errorf("InterpolateVars() = %q, want %q", got, want)
+	}
+	if len(used) != 2 || used["testCommand"] != "make test" {
+		t.Errorf("used = %v", used)
+	}
+
+	_, err = InterpolateVars("Run {{ .Vars.lintCommand }}.", vars, used)
+	if err == nil || !strings.Contains(err.Error(), "variable lintCommand is not set") {
+		t.Errorf("expected unset variable error, got %v", err)
+	}
+}
+
+func TestInterpolateRuleset(t *testing.T) {
+	ruleset := &resource.RulesetResource{Spec: resource.RulesetSpec{Rules: map[string]resource.Rule{
+		"testing": {Body: "Run {{ .Vars.testCommand }} before committing."},
+		"style":   {Body: "Format the code."},
+	}}}
+
+	got, used, err := InterpolateRuleset(ruleset, map[string]string{"testCommand": "npm test", "unused": "x"})
+	if err != nil {
+		t.Fatalf("InterpolateRuleset() error = %v", err)
+	}
+	if body := got.Spec.Rules["testing"].Body; body != "Run npm test before committing." {
+		t.Errorf("testing body = %q", body)
+	}
+	if len(used) != 1 || used["testCommand"] != "npm test" {
+		t.Errorf("used = %v, want only testCommand", used)
+	}
+	if ruleset.Spec.Rules["testing"].Body != "Run {{ .Vars.testCommand }} before committing." {
+		t.Error("original ruleset was modified")
+	}
+
+	_, _, err = InterpolateRuleset(ruleset, nil)
+	if err == nil || !strings.Contains(err.Error(), "rule testing") {
+		t.Errorf("expected error naming the rule, got %v", err)
+	}
+}
+
+func TestInterpolatePromptset(t *testing.T) {
+	promptset := &resource.PromptsetResource{Spec: resource.PromptsetSpec{Prompts: map[string]resource.Prompt{
+		"review": {Body: "Review this {{ .Vars.primaryLanguage }} change."},
+	}}}
+
+	got, used, err := InterpolatePromptset(promptset, map[string]string{"primaryLanguage": "Go"})
+	if err != nil {
+		t.Fatalf("InterpolatePromptset() error = %v", err)
+	}
+	if body := got.Spec.Prompts["review"].Body; body != "Review this Go change." {
+		t.Errorf("review body = %q", body)
+	}
+	if used["primaryLanguage"] != "Go" {
+		t.Errorf("used = %v", used)
+	}
+}
@@ -123,6 +123,7 @@ func (e *Extractor) extractTarGz(file *File, subdirName string) ([]*File, error)
 			Path:    filepath.Join(subdirName, cleanName),
 			Content: content,
 			Size:    header.Size,
+			Mode:    os.FileMode(header.Mode).Perm(),
 		})
 	}
 
@@ -185,6 +186,7 @@ func (e *Extractor) extractZip(file *File, subdirName string) ([]*File, error) {
 			Path:    filepath.Join(subdirName, cleanName),
 			Content: content,
 			Size:    int64(zipFile.UncompressedSize64),
+			Mode:    zipFile.Mode().Perm(),
 		})
 	}
 
@@ -0,0 +1,47 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   []DiffLine
+	}{
+		{
+			name:   "identical",
+			before: "a\nb\n",
+			after:  "a\nb",
+			want:   []DiffLine{{DiffEqual, "a"}, {DiffEqual, "b"}},
+		},
+		{
+			name:   "changed line",
+			before: "a\nb\nc",
+			after:  "a\nB\nc",
+			want:   []DiffLine{{DiffEqual, "a"}, {DiffDelete, "b"}, {DiffInsert, "B"}, {DiffEqual, "c"}},
+		},
+		{
+			name:   "insert and delete",
+			before: "a\nb",
+			after:  "b\nc",
+			want:   []DiffLine{{DiffDelete, "a"}, {DiffEqual, "b"}, {DiffInsert, "c"}},
+		},
+		{
+			name:  "from empty",
+			after: "a",
+			want:  []DiffLine{{DiffInsert, "a"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DiffLines(tt.before, tt.after); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffLines() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,5 +1,7 @@
 package core
 
+import "os"
+
 type BuildInfo struct {
 	Arch      string
 	Version   Version
@@ -11,6 +13,18 @@ type File struct {
 	Path    string
 	Content []byte
 	Size    int64
+	// Mode holds the permission bits the file was published with, or zero when
+	// its source does not record them
+	Mode os.FileMode
+}
+
+// Perm returns the permission bits to write the file with, 0o644 unless the
+// file was published with its own
+func (f *File) Perm() os.FileMode {
+	if f.Mode.Perm() == 0 {
+		return 0o644
+	}
+	return f.Mode.Perm()
 }
 
 type Version struct {
@@ -27,6 +41,7 @@ type PackageMetadata struct {
 	RegistryName string
 	Name         string
 	Version      Version
+	Description  string // Set by registries that store package descriptions
 }
 
 type Package struct {
@@ -12,9 +12,24 @@ import (
 
 var validate = validator.New()
 
+// documentationNames are the upper-cased base names, without extension, of the
+// project documentation files packages commonly ship alongside their rules
+var documentationNames = map[string]bool{
+	"AUTHORS":         true,
+	"CHANGELOG":       true,
+	"CHANGES":         true,
+	"CODE_OF_CONDUCT": true,
+	"CONTRIBUTING":    true,
+	"HISTORY":         true,
+	"LICENSE":         true,
+	"NOTICE":          true,
+	"README":          true,
+	"SECURITY":        true,
+}
+
 // IsResourceFile checks if file is any ARM resource type
 func IsResourceFile(file *core.File) bool {
-	return IsRulesetFile(file) || IsPromptsetFile(file)
+	return IsRulesetFile(file) || IsPromptsetFile(file) || IsMcpsetFile(file) || IsAgentsetFile(file) || IsHooksetFile(file)
 }
 
 // IsRulesetFile checks extension and validates file content as RulesetResource
@@ -45,6 +60,85 @@ func IsPromptsetFile(file *core.File) bool {
 	return validate.Struct(&promptset) == nil
 }
 
+// IsMcpsetFile checks extension and validates file content as McpsetResource
+func IsMcpsetFile(file *core.File) bool {
+	if !hasYAMLExtension(file.Path) {
+		return false
+	}
+
+	var mcpset resource.McpsetResource
+	if err := yaml.Unmarshal(file.Content, &mcpset); err != nil {
+		return false
+	}
+
+	return validate.Struct(&mcpset) == nil
+}
+
+// IsAgentsetFile checks extension and validates file content as AgentsetResource
+func IsAgentsetFile(file *core.File) bool {
+	if !hasYAMLExtension(file.Path) {
+		return false
+	}
+
+	var agentset resource.AgentsetResource
+	if err := yaml.Unmarshal(file.Content, &agentset); err != nil {
+		return false
+	}
+
+	return validate.Struct(&agentset) == nil
+}
+
+// IsHooksetFile checks extension and validates file content as HooksetResource
+func IsHooksetFile(file *core.File) bool {
+	if !hasYAMLExtension(file.Path) {
+		return false
+	}
+
+	var hookset resource.HooksetResource
+	if err := yaml.Unmarshal(file.Content, &hookset); err != nil {
+		return false
+	}
+
+	return validate.Struct(&hookset) == nil
+}
+
+// IsSkillFile checks whether the file is the SKILL.md of an Agent Skill directory
+func IsSkillFile(file *core.File) bool {
+	return filepath.Base(file.Path) == resource.SkillFilename
+}
+
+// IsRuleMarkdownFile checks whether a file is a markdown rule source (*.rule.md)
+func IsRuleMarkdownFile(file *core.File) bool {
+	return strings.HasSuffix(strings.ToLower(filepath.Base(file.Path)), resource.RuleMarkdownSuffix)
+}
+
+// IsPromptMarkdownFile checks whether a file is a markdown prompt source (*.prompt.md)
+func IsPromptMarkdownFile(file *core.File) bool {
+	return strings.HasSuffix(strings.ToLower(filepath.Base(file.Path)), resource.PromptMarkdownSuffix)
+}
+
+// IsDocumentationFile checks whether a file is project documentation such as
+// README.md or CHANGELOG.md rather than content for AI tools
+func IsDocumentationFile(file *core.File) bool {
+	base := filepath.Base(file.Path)
+	return documentationNames[strings.ToUpper(strings.TrimSuffix(base, filepath.Ext(base)))]
+}
+
+// IsLegacyRuleFile checks whether a file is a raw tool rule file (.cursorrules,
+// .mdc or markdown) that rulesets adapt instead of copying verbatim. Project
+// documentation is never a rule.
+func IsLegacyRuleFile(file *core.File) bool {
+	if IsRuleMarkdownFile(file) || IsPromptMarkdownFile(file) || IsDocumentationFile(file) {
+		return false
+	}
+	base := filepath.Base(file.Path)
+	if base == ".cursorrules" {
+		return true
+	}
+	ext := strings.ToLower(filepath.Ext(base))
+	return ext == ".mdc" || ext == ".md"
+}
+
 func hasYAMLExtension(path string) bool {
 	ext := strings.ToLower(filepath.Ext(path))
 	return ext == ".yml" || ext == ".yaml"
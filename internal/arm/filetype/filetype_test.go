@@ -253,6 +253,47 @@ spec:
       body: "test prompt"`,
 			want: true,
 		},
+		{
+			name:     "mcpset file",
+			filename: "servers.yml",
+			content: `apiVersion: v1
+kind: Mcpset
+metadata:
+  id: "test"
+spec:
+  servers:
+    docs:
+      url: "https://example.com/mcp"`,
+			want: true,
+		},
+		{
+			name:     "agentset file",
+			filename: "agents.yml",
+			content: `apiVersion: v1
+kind: Agentset
+metadata:
+  id: "test"
+spec:
+  agents:
+    reviewer:
+      description: "Reviews code"
+      body: "You review code."`,
+			want: true,
+		},
+		{
+			name:     "hookset file",
+			filename: "hooks.yml",
+			content: `apiVersion: v1
+kind: Hookset
+metadata:
+  id: "test"
+spec:
+  hooks:
+    format:
+      event: post-edit
+      command: "make fmt"`,
+			want: true,
+		},
 		{
 			name:     "regular yaml file",
 			filename: "config.yml",
@@ -290,3 +331,57 @@ This is a readme file.`,
 		})
 	}
 }
+
+func TestIsLegacyRuleFile(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{".cursorrules", true},
+		{"rules/python.mdc", true},
+		{"rules/Style.MD", true},
+		{"rules/ruleset.yml", false},
+		{"rules/naming.rule.md", false},
+		{"prompts/review.prompt.md", false},
+		{"LICENSE", false},
+		{"scripts/lint.sh", false},
+		{"README.md", false},
+		{"rules/Changelog.md", false},
+		{"CONTRIBUTING.md", false},
+		{"docs/readme.mdc", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := IsLegacyRuleFile(&core.File{Path: tt.path}); got != tt.want {
+				t.Errorf("IsLegacyRuleFile(%s) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsMarkdownSourceFile(t *testing.T) {
+	tests := []struct {
+		path       string
+		wantRule   bool
+		wantPrompt bool
+	}{
+		{"rules/naming.rule.md", true, false},
+		{"rules/Naming.Rule.MD", true, false},
+		{"prompts/review.prompt.md", false, true},
+		{"rules/style.md", false, false},
+		{"rules/ruleset.yml", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			file := &core.File{Path: tt.path}
+			if got := IsRuleMarkdownFile(file); got != tt.wantRule {
+				t.Errorf("IsRuleMarkdownFile(%s) = %v, want %v", tt.path, got, tt.wantRule)
+			}
+			if got := IsPromptMarkdownFile(file); got != tt.wantPrompt {
+				t.Errorf("IsPromptMarkdownFile(%s) = %v, want %v", tt.path, got, tt.wantPrompt)
+			}
+		})
+	}
+}
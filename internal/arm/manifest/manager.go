@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/jomadu/ai-resource-manager/internal/arm/compiler"
+	"gopkg.in/yaml.v3"
 )
 
 type ResourceType string
@@ -15,18 +17,27 @@ type ResourceType string
 const (
 	ResourceTypeRuleset   ResourceType = "ruleset"
 	ResourceTypePromptset ResourceType = "promptset"
+	ResourceTypeMcpset    ResourceType = "mcpset"
+	ResourceTypeAgentset  ResourceType = "agentset"
+	ResourceTypeSkillset  ResourceType = "skillset"
+	ResourceTypeHookset   ResourceType = "hookset"
 )
 
 type Manifest struct {
-	Version      int                               `json:"version"`
-	Registries   map[string]map[string]interface{} `json:"registries,omitempty"`
-	Sinks        map[string]SinkConfig             `json:"sinks,omitempty"`
-	Dependencies map[string]map[string]interface{} `json:"dependencies,omitempty"`
+	Version      int                                `json:"version"`
+	Registries   map[string]map[string]interface{}  `json:"registries,omitempty"`
+	Sinks        map[string]SinkConfig              `json:"sinks,omitempty"`
+	Dependencies map[string]map[string]interface{}  `json:"dependencies,omitempty"`
+	Tools        map[string]compiler.ToolDefinition `json:"tools,omitempty"`
+	Vars         map[string]string                  `json:"vars,omitempty"`
 }
 
 type SinkConfig struct {
-	Directory string        `json:"directory"`
-	Tool      compiler.Tool `json:"tool"`
+	Directory   string                     `json:"directory"`
+	Tool        compiler.Tool              `json:"tool"`
+	Enforcement compiler.EnforcementPolicy `json:"enforcement,omitempty"`
+	// Conflicts is report (default) or dedupe; see sink.ConflictMode
+	Conflicts string `json:"conflicts,omitempty"`
 }
 
 type GitRegistryConfig struct {
@@ -59,6 +70,9 @@ func (c CloudsmithRegistryConfig) GetBaseURL() string {
 
 // Manager interface for service layer
 type Manager interface {
+	// Create writes an empty manifest, replacing any existing one
+	Create(ctx context.Context) error
+
 	// Registry operations
 	GetAllRegistriesConfig(ctx context.Context) (map[string]map[string]interface{}, error)
 	GetRegistryConfig(ctx context.Context, name string) (map[string]interface{}, error)
@@ -79,6 +93,15 @@ type Manager interface {
 	UpdateSinkConfigName(ctx context.Context, name string, newName string) error
 	RemoveSinkConfig(ctx context.Context, name string) error
 
+	// Tool operations
+	GetAllToolsConfig(ctx context.Context) (map[compiler.Tool]*compiler.ToolDefinition, error)
+
+	// ProjectDir is the directory tools/ and overlay files are resolved against
+	ProjectDir() string
+
+	// Variable operations
+	GetVars(ctx context.Context) (map[string]string, error)
+
 	// Dependency operations
 	GetAllDependenciesConfig(ctx context.Context) (map[string]map[string]interface{}, error)
 	GetAllRulesetDependenciesConfig(ctx context.Context) (map[string]*RulesetDependencyConfig, error)
@@ -86,9 +109,21 @@ type Manager interface {
 	GetDependencyConfig(ctx context.Context, registry, packageName string) (map[string]interface{}, error)
 	GetRulesetDependencyConfig(ctx context.Context, registry, packageName string) (*RulesetDependencyConfig, error)
 	GetPromptsetDependencyConfig(ctx context.Context, registry, packageName string) (*PromptsetDependencyConfig, error)
+	GetAllMcpsetDependenciesConfig(ctx context.Context) (map[string]*McpsetDependencyConfig, error)
+	GetMcpsetDependencyConfig(ctx context.Context, registry, packageName string) (*McpsetDependencyConfig, error)
+	GetAllAgentsetDependenciesConfig(ctx context.Context) (map[string]*AgentsetDependencyConfig, error)
+	GetAgentsetDependencyConfig(ctx context.Context, registry, packageName string) (*AgentsetDependencyConfig, error)
+	GetAllSkillsetDependenciesConfig(ctx context.Context) (map[string]*SkillsetDependencyConfig, error)
+	GetSkillsetDependencyConfig(ctx context.Context, registry, packageName string) (*SkillsetDependencyConfig, error)
+	GetAllHooksetDependenciesConfig(ctx context.Context) (map[string]*HooksetDependencyConfig, error)
+	GetHooksetDependencyConfig(ctx context.Context, registry, packageName string) (*HooksetDependencyConfig, error)
 	UpsertDependencyConfig(ctx context.Context, registry, packageName string, config map[string]interface{}) error
 	UpsertRulesetDependencyConfig(ctx context.Context, registry, packageName string, config *RulesetDependencyConfig) error
 	UpsertPromptsetDependencyConfig(ctx context.Context, registry, packageName string, config *PromptsetDependencyConfig) error
+	UpsertMcpsetDependencyConfig(ctx context.Context, registry, packageName string, config *McpsetDependencyConfig) error
+	UpsertAgentsetDependencyConfig(ctx context.Context, registry, packageName string, config *AgentsetDependencyConfig) error
+	UpsertSkillsetDependencyConfig(ctx context.Context, registry, packageName string, config *SkillsetDependencyConfig) error
+	UpsertHooksetDependencyConfig(ctx context.Context, registry, packageName string, config *HooksetDependencyConfig) error
 	UpdateDependencyConfigName(ctx context.Context, registry, packageName, newRegistry, newPackageName string) error
 	RemoveDependencyConfig(ctx context.Context, registry, packageName string) error
 }
@@ -100,21 +135,45 @@ type BaseDependencyConfig struct {
 	Sinks   []string     `json:"sinks"`
 	Include []string     `json:"include,omitempty"`
 	Exclude []string     `json:"exclude,omitempty"`
+	// Vars override the manifest-level vars for this dependency
+	Vars map[string]string `json:"vars,omitempty"`
 }
 
 type RulesetDependencyConfig struct {
 	BaseDependencyConfig
-	Priority int `json:"priority,omitempty"`
+	Priority int                             `json:"priority,omitempty"`
+	Overlays map[string]compiler.RuleOverlay `json:"overlays,omitempty"`
 }
 
 type PromptsetDependencyConfig struct {
 	BaseDependencyConfig
 }
 
+type McpsetDependencyConfig struct {
+	BaseDependencyConfig
+}
+
+type AgentsetDependencyConfig struct {
+	BaseDependencyConfig
+}
+
+type SkillsetDependencyConfig struct {
+	BaseDependencyConfig
+}
+
+type HooksetDependencyConfig struct {
+	BaseDependencyConfig
+}
+
+// ToolsDir is the directory next to the manifest holding user-defined tool definitions
+const ToolsDir = "tools"
+
 // FileManager implements file-based manifest management.
 // It reads from and writes to arm.json in the current directory.
 type FileManager struct {
 	manifestPath string
+	// projectDir holds tools/ and overlay files; it defaults to the manifest's directory
+	projectDir string
 }
 
 // NewFileManager creates a new file-based manifest manager.
@@ -129,6 +188,26 @@ func NewFileManagerWithPath(manifestPath string) *FileManager {
 	return &FileManager{manifestPath: manifestPath}
 }
 
+// NewFileManagerWithProjectDir creates a manifest manager for a manifest stored
+// apart from its project, such as a sandbox copy; tools/ and overlay files are read
+// from projectDir.
+func NewFileManagerWithProjectDir(manifestPath, projectDir string) *FileManager {
+	return &FileManager{manifestPath: manifestPath, projectDir: projectDir}
+}
+
+// ProjectDir returns the directory tools/ and overlay files are resolved against
+func (f *FileManager) ProjectDir() string {
+	if f.projectDir != "" {
+		return f.projectDir
+	}
+	return filepath.Dir(f.manifestPath)
+}
+
+// Create writes an empty manifest, replacing any existing one
+func (f *FileManager) Create(ctx context.Context) error {
+	return f.saveManifest(f.newEmptyManifest())
+}
+
 // Registry operations (generic)
 
 func (f *FileManager) GetAllRegistriesConfig(ctx context.Context) (map[string]map[string]interface{}, error) {
@@ -376,6 +455,75 @@ func (f *FileManager) RemoveSinkConfig(ctx context.Context, name string) error {
 	return f.saveManifest(manifest)
 }
 
+// Variable operations
+
+// GetVars returns the manifest-level variables interpolated into rule and prompt bodies
+func (f *FileManager) GetVars(ctx context.Context) (map[string]string, error) {
+	manifest, err := f.loadManifest()
+	if err != nil {
+		return nil, err
+	}
+	return manifest.Vars, nil
+}
+
+// Tool operations
+
+// GetAllToolsConfig returns user-defined tools from the manifest "tools" section
+// and from YAML or JSON files in the tools/ directory next to the manifest.
+// Each definition is validated; a tool defined in both places is an error.
+func (f *FileManager) GetAllToolsConfig(ctx context.Context) (map[compiler.Tool]*compiler.ToolDefinition, error) {
+	manifest, err := f.loadManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	tools := make(map[compiler.Tool]*compiler.ToolDefinition)
+	for name, def := range manifest.Tools {
+		def := def
+		def.Name = compiler.Tool(name)
+		tools[def.Name] = &def
+	}
+
+	toolsDir := filepath.Join(f.ProjectDir(), ToolsDir)
+	entries, err := os.ReadDir(toolsDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yml" && ext != ".yaml" && ext != ".json") {
+			continue
+		}
+
+		path := filepath.Join(toolsDir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		// YAML is a superset of JSON, so one decoder handles both formats
+		var def compiler.ToolDefinition
+		if err := yaml.Unmarshal(data, &def); err != nil {
+			return nil, fmt.Errorf("failed to parse tool definition %s: %w", path, err)
+		}
+		if def.Name == "" {
+			def.Name = compiler.Tool(strings.TrimSuffix(entry.Name(), ext))
+		}
+		if _, exists := tools[def.Name]; exists {
+			return nil, fmt.Errorf("tool %s is defined more than once (see %s)", def.Name, path)
+		}
+		tools[def.Name] = &def
+	}
+
+	for _, def := range tools {
+		if err := def.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return tools, nil
+}
+
 // Dependencies operations (generic)
 
 func (f *FileManager) GetAllDependenciesConfig(ctx context.Context) (map[string]map[string]interface{}, error) {
@@ -458,6 +606,90 @@ func (f *FileManager) UpsertPromptsetDependencyConfig(ctx context.Context, regis
 	return f.saveManifest(manifest)
 }
 
+func (f *FileManager) UpsertMcpsetDependencyConfig(ctx context.Context, registry, packageName string, config *McpsetDependencyConfig) error {
+	key := DependencyKey(registry, packageName)
+	manifest, err := f.loadManifest()
+	if err != nil {
+		return err
+	}
+
+	if manifest.Dependencies == nil {
+		manifest.Dependencies = make(map[string]map[string]interface{})
+	}
+
+	config.Type = ResourceTypeMcpset
+	configMap, err := convertDependencyToMap(config)
+	if err != nil {
+		return err
+	}
+
+	manifest.Dependencies[key] = configMap
+	return f.saveManifest(manifest)
+}
+
+func (f *FileManager) UpsertAgentsetDependencyConfig(ctx context.Context, registry, packageName string, config *AgentsetDependencyConfig) error {
+	key := DependencyKey(registry, packageName)
+	manifest, err := f.loadManifest()
+	if err != nil {
+		return err
+	}
+
+	if manifest.Dependencies == nil {
+		manifest.Dependencies = make(map[string]map[string]interface{})
+	}
+
+	config.Type = ResourceTypeAgentset
+	configMap, err := convertDependencyToMap(config)
+	if err != nil {
+		return err
+	}
+
+	manifest.Dependencies[key] = configMap
+	return f.saveManifest(manifest)
+}
+
+func (f *FileManager) UpsertSkillsetDependencyConfig(ctx context.Context, registry, packageName string, config *SkillsetDependencyConfig) error {
+	key := DependencyKey(registry, packageName)
+	manifest, err := f.loadManifest()
+	if err != nil {
+		return err
+	}
+
+	if manifest.Dependencies == nil {
+		manifest.Dependencies = make(map[string]map[string]interface{})
+	}
+
+	config.Type = ResourceTypeSkillset
+	configMap, err := convertDependencyToMap(config)
+	if err != nil {
+		return err
+	}
+
+	manifest.Dependencies[key] = configMap
+	return f.saveManifest(manifest)
+}
+
+func (f *FileManager) UpsertHooksetDependencyConfig(ctx context.Context, registry, packageName string, config *HooksetDependencyConfig) error {
+	key := DependencyKey(registry, packageName)
+	manifest, err := f.loadManifest()
+	if err != nil {
+		return err
+	}
+
+	if manifest.Dependencies == nil {
+		manifest.Dependencies = make(map[string]map[string]interface{})
+	}
+
+	config.Type = ResourceTypeHookset
+	configMap, err := convertDependencyToMap(config)
+	if err != nil {
+		return err
+	}
+
+	manifest.Dependencies[key] = configMap
+	return f.saveManifest(manifest)
+}
+
 func (f *FileManager) UpdateDependencyConfigName(ctx context.Context, registry, packageName, newRegistry, newPackageName string) error {
 	key := DependencyKey(registry, packageName)
 	newKey := DependencyKey(newRegistry, newPackageName)
@@ -544,6 +776,98 @@ func (f *FileManager) GetAllPromptsetDependenciesConfig(ctx context.Context) (ma
 	return promptsets, nil
 }
 
+func (f *FileManager) GetAllMcpsetDependenciesConfig(ctx context.Context) (map[string]*McpsetDependencyConfig, error) {
+	manifest, err := f.loadManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	mcpsets := make(map[string]*McpsetDependencyConfig)
+	for key, rawConfig := range manifest.Dependencies {
+		depType, ok := rawConfig["type"].(string)
+		if !ok || depType != "mcpset" {
+			continue
+		}
+
+		config, err := convertMapToMcpsetDependency(rawConfig)
+		if err != nil {
+			return nil, err
+		}
+		mcpsets[key] = config
+	}
+
+	return mcpsets, nil
+}
+
+func (f *FileManager) GetAllAgentsetDependenciesConfig(ctx context.Context) (map[string]*AgentsetDependencyConfig, error) {
+	manifest, err := f.loadManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	agentsets := make(map[string]*AgentsetDependencyConfig)
+	for key, rawConfig := range manifest.Dependencies {
+		depType, ok := rawConfig["type"].(string)
+		if !ok || depType != "agentset" {
+			continue
+		}
+
+		config, err := convertMapToAgentsetDependency(rawConfig)
+		if err != nil {
+			return nil, err
+		}
+		agentsets[key] = config
+	}
+
+	return agentsets, nil
+}
+
+func (f *FileManager) GetAllSkillsetDependenciesConfig(ctx context.Context) (map[string]*SkillsetDependencyConfig, error) {
+	manifest, err := f.loadManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	skillsets := make(map[string]*SkillsetDependencyConfig)
+	for key, rawConfig := range manifest.Dependencies {
+		depType, ok := rawConfig["type"].(string)
+		if !ok || depType != "skillset" {
+			continue
+		}
+
+		config, err := convertMapToSkillsetDependency(rawConfig)
+		if err != nil {
+			return nil, err
+		}
+		skillsets[key] = config
+	}
+
+	return skillsets, nil
+}
+
+func (f *FileManager) GetAllHooksetDependenciesConfig(ctx context.Context) (map[string]*HooksetDependencyConfig, error) {
+	manifest, err := f.loadManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	hooksets := make(map[string]*HooksetDependencyConfig)
+	for key, rawConfig := range manifest.Dependencies {
+		depType, ok := rawConfig["type"].(string)
+		if !ok || depType != "hookset" {
+			continue
+		}
+
+		config, err := convertMapToHooksetDependency(rawConfig)
+		if err != nil {
+			return nil, err
+		}
+		hooksets[key] = config
+	}
+
+	return hooksets, nil
+}
+
 func (f *FileManager) GetRulesetDependencyConfig(ctx context.Context, registry, packageName string) (*RulesetDependencyConfig, error) {
 	rawConfig, err := f.GetDependencyConfig(ctx, registry, packageName)
 	if err != nil {
@@ -576,6 +900,70 @@ func (f *FileManager) GetPromptsetDependencyConfig(ctx context.Context, registry
 	return convertMapToPromptsetDependency(rawConfig)
 }
 
+func (f *FileManager) GetMcpsetDependencyConfig(ctx context.Context, registry, packageName string) (*McpsetDependencyConfig, error) {
+	rawConfig, err := f.GetDependencyConfig(ctx, registry, packageName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check dependency type
+	depType, ok := rawConfig["type"].(string)
+	if !ok || depType != "mcpset" {
+		key := DependencyKey(registry, packageName)
+		return nil, fmt.Errorf("dependency %s is not an mcpset", key)
+	}
+
+	return convertMapToMcpsetDependency(rawConfig)
+}
+
+func (f *FileManager) GetAgentsetDependencyConfig(ctx context.Context, registry, packageName string) (*AgentsetDependencyConfig, error) {
+	rawConfig, err := f.GetDependencyConfig(ctx, registry, packageName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check dependency type
+	depType, ok := rawConfig["type"].(string)
+	if !ok || depType != "agentset" {
+		key := DependencyKey(registry, packageName)
+		return nil, fmt.Errorf("dependency %s is not an agentset", key)
+	}
+
+	return convertMapToAgentsetDependency(rawConfig)
+}
+
+func (f *FileManager) GetSkillsetDependencyConfig(ctx context.Context, registry, packageName string) (*SkillsetDependencyConfig, error) {
+	rawConfig, err := f.GetDependencyConfig(ctx, registry, packageName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check dependency type
+	depType, ok := rawConfig["type"].(string)
+	if !ok || depType != "skillset" {
+		key := DependencyKey(registry, packageName)
+		return nil, fmt.Errorf("dependency %s is not a skillset", key)
+	}
+
+	return convertMapToSkillsetDependency(rawConfig)
+}
+
+func (f *FileManager) GetHooksetDependencyConfig(ctx context.Context, registry, packageName string) (*HooksetDependencyConfig, error) {
+	rawConfig, err := f.GetDependencyConfig(ctx, registry, packageName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check dependency type
+	depType, ok := rawConfig["type"].(string)
+	if !ok || depType != "hookset" {
+		key := DependencyKey(registry, packageName)
+		return nil, fmt.Errorf("dependency %s is not a hookset", key)
+	}
+
+	return convertMapToHooksetDependency(rawConfig)
+}
+
 // Helper functions for FileManager implementation
 
 // loadManifest loads the manifest from arm.json file.
@@ -634,6 +1022,9 @@ func (f *FileManager) saveManifest(manifest *Manifest) error {
 	if len(manifest.Dependencies) == 0 {
 		manifest.Dependencies = nil
 	}
+	if len(manifest.Tools) == 0 {
+		manifest.Tools = nil
+	}
 
 	data, err := json.MarshalIndent(manifest, "", "  ")
 	if err != nil {
@@ -734,6 +1125,66 @@ func convertMapToPromptsetDependency(m map[string]interface{}) (*PromptsetDepend
 	return &config, nil
 }
 
+// convertMapToMcpsetDependency converts map[string]interface{} to McpsetDependencyConfig.
+func convertMapToMcpsetDependency(m map[string]interface{}) (*McpsetDependencyConfig, error) {
+	configBytes, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	var config McpsetDependencyConfig
+	if err := json.Unmarshal(configBytes, &config); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// convertMapToAgentsetDependency converts map[string]interface{} to AgentsetDependencyConfig.
+func convertMapToAgentsetDependency(m map[string]interface{}) (*AgentsetDependencyConfig, error) {
+	configBytes, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	var config AgentsetDependencyConfig
+	if err := json.Unmarshal(configBytes, &config); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// convertMapToSkillsetDependency converts map[string]interface{} to SkillsetDependencyConfig.
+func convertMapToSkillsetDependency(m map[string]interface{}) (*SkillsetDependencyConfig, error) {
+	configBytes, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	var config SkillsetDependencyConfig
+	if err := json.Unmarshal(configBytes, &config); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// convertMapToHooksetDependency converts map[string]interface{} to HooksetDependencyConfig.
+func convertMapToHooksetDependency(m map[string]interface{}) (*HooksetDependencyConfig, error) {
+	configBytes, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	var config HooksetDependencyConfig
+	if err := json.Unmarshal(configBytes, &config); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
 // convertRegistryToMap converts a typed registry config to map[string]interface{}.
 func convertRegistryToMap(config interface{}) (map[string]interface{}, error) {
 	configBytes, err := json.Marshal(config)
@@ -38,6 +38,27 @@ func newTestManifest() *Manifest {
 	}
 }
 
+func TestFileManager_Create(t *testing.T) {
+	ctx := context.Background()
+
+	manifest := newTestManifest()
+	manifest.Sinks["old-sink"] = SinkConfig{Directory: ".cursor/rules", Tool: compiler.Cursor}
+	manifestPath := createTestManifest(t, manifest)
+	fm := NewFileManagerWithPath(manifestPath)
+
+	if err := fm.Create(ctx); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		t.Fatalf("Failed to read manifest: %v", err)
+	}
+	if want := "{\n  \"version\": 1\n}"; string(data) != want {
+		t.Errorf("Expected %q, got %q", want, data)
+	}
+}
+
 // Registry tests
 
 func TestFileManager_GetAllRegistriesConfig(t *testing.T) {
@@ -209,6 +230,35 @@ func TestFileManager_UpsertRulesetDependencyConfig(t *testing.T) {
 	}
 }
 
+func TestFileManager_RulesetDependencyConfigOverlays(t *testing.T) {
+	ctx := context.Background()
+	fm := NewFileManagerWithPath(filepath.Join(t.TempDir(), "arm.json"))
+
+	priority := 150
+	config := &RulesetDependencyConfig{
+		BaseDependencyConfig: BaseDependencyConfig{Version: "1.0.0", Sinks: []string{"cursor-rules"}},
+		Overlays: map[string]compiler.RuleOverlay{
+			"comments": {Disabled: true},
+			"naming":   {Enforcement: "must", Priority: &priority, AppendFile: "rules/naming.md"},
+		},
+	}
+	if err := fm.UpsertRulesetDependencyConfig(ctx, "test-reg", "ruleset1", config); err != nil {
+		t.Fatalf("UpsertRulesetDependencyConfig() error = %v", err)
+	}
+
+	retrieved, err := fm.GetRulesetDependencyConfig(ctx, "test-reg", "ruleset1")
+	if err != nil {
+		t.Fatalf("GetRulesetDependencyConfig() error = %v", err)
+	}
+	if !retrieved.Overlays["comments"].Disabled {
+		t.Errorf("Expected comments overlay to be disabled, got %+v", retrieved.Overlays["comments"])
+	}
+	naming := retrieved.Overlays["naming"]
+	if naming.Enforcement != "must" || naming.Priority == nil || *naming.Priority != 150 || naming.AppendFile != "rules/naming.md" {
+		t.Errorf("Expected naming overlay to round-trip, got %+v", naming)
+	}
+}
+
 func TestFileManager_UpsertPromptsetDependencyConfig(t *testing.T) {
 	ctx := context.Background()
 	manifestPath := filepath.Join(t.TempDir(), "empty.json")
@@ -605,3 +655,92 @@ func TestFileManager_UpsertCloudsmithRegistryConfig(t *testing.T) {
 		t.Errorf("Expected owner %s, got %s", config.Owner, retrieved.Owner)
 	}
 }
+
+func TestFileManager_GetAllToolsConfig(t *testing.T) {
+	manifest := newTestManifest()
+	manifest.Tools = map[string]compiler.ToolDefinition{
+		"windsurf": {RuleTemplate: "{{ .Rule.Body }}"},
+	}
+	manifestPath := createTestManifest(t, manifest)
+
+	toolsDir := filepath.Join(filepath.Dir(manifestPath), ToolsDir)
+	if err := os.MkdirAll(toolsDir, 0o755); err != nil {
+		t.Fatalf("Failed to create tools dir: %v", err)
+	}
+	toolYAML := "layout: flat\nruleFilename: \"{{ .RuleID }}.rules.md\"\n"
+	if err := os.WriteFile(filepath.Join(toolsDir, "zed.yml"), []byte(toolYAML), 0o644); err != nil {
+		t.Fatalf("Failed to write tool definition: %v", err)
+	}
+
+	fm := NewFileManagerWithPath(manifestPath)
+	tools, err := fm.GetAllToolsConfig(context.Background())
+	if err != nil {
+		t.Fatalf("GetAllToolsConfig failed: %v", err)
+	}
+
+	if len(tools) != 2 {
+		t.Fatalf("Expected 2 tools, got %d", len(tools))
+	}
+	if tools["windsurf"].Name != "windsurf" {
+		t.Errorf("Expected manifest tool name from key, got %q", tools["windsurf"].Name)
+	}
+	if tools["zed"].Layout != "flat" {
+		t.Errorf("Expected zed layout flat, got %q", tools["zed"].Layout)
+	}
+}
+
+func TestFileManager_GetAllToolsConfig_Duplicate(t *testing.T) {
+	manifest := newTestManifest()
+	manifest.Tools = map[string]compiler.ToolDefinition{"zed": {}}
+	manifestPath := createTestManifest(t, manifest)
+
+	toolsDir := filepath.Join(filepath.Dir(manifestPath), ToolsDir)
+	if err := os.MkdirAll(toolsDir, 0o755); err != nil {
+		t.Fatalf("Failed to create tools dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(toolsDir, "zed.yml"), []byte("layout: flat\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write tool definition: %v", err)
+	}
+
+	fm := NewFileManagerWithPath(manifestPath)
+	if _, err := fm.GetAllToolsConfig(context.Background()); err == nil {
+		t.Error("Expected error for duplicate tool definition, got nil")
+	}
+}
+
+func TestFileManager_UpsertMcpsetDependencyConfig(t *testing.T) {
+	ctx := context.Background()
+	manifestPath := filepath.Join(t.TempDir(), "empty.json")
+	fm := NewFileManagerWithPath(manifestPath)
+
+	config := &McpsetDependencyConfig{
+		BaseDependencyConfig: BaseDependencyConfig{
+			Version: "1.0.0",
+			Sinks:   []string{"cursor-rules"},
+		},
+	}
+
+	if err := fm.UpsertMcpsetDependencyConfig(ctx, "test-reg", "servers", config); err != nil {
+		t.Fatalf("UpsertMcpsetDependencyConfig() error = %v", err)
+	}
+
+	retrieved, err := fm.GetMcpsetDependencyConfig(ctx, "test-reg", "servers")
+	if err != nil {
+		t.Fatalf("GetMcpsetDependencyConfig() error = %v", err)
+	}
+	if retrieved.Type != ResourceTypeMcpset {
+		t.Errorf("Expected type %s, got %s", ResourceTypeMcpset, retrieved.Type)
+	}
+
+	all, err := fm.GetAllMcpsetDependenciesConfig(ctx)
+	if err != nil {
+		t.Fatalf("GetAllMcpsetDependenciesConfig() error = %v", err)
+	}
+	if _, ok := all["test-reg/servers"]; !ok {
+		t.Errorf("Expected test-reg/servers in %v", all)
+	}
+
+	if _, err := fm.GetPromptsetDependencyConfig(ctx, "test-reg", "servers"); err == nil {
+		t.Errorf("Expected error reading mcpset as promptset")
+	}
+}
@@ -0,0 +1,144 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jomadu/ai-resource-manager/internal/arm/core"
+	"github.com/jomadu/ai-resource-manager/internal/arm/filetype"
+	"github.com/jomadu/ai-resource-manager/internal/arm/resource"
+)
+
+// RulesetRef returns the reference a ruleset is extended by. Rulesets of a
+// package are referenced as REGISTRY/PACKAGE/ID; without a package the ID alone is used.
+func RulesetRef(pkgKey, rulesetID string) string {
+	if pkgKey == "" {
+		return rulesetID
+	}
+	return pkgKey + "/" + rulesetID
+}
+
+// ParsePackageRulesets parses the ruleset files among files and returns them keyed
+// by their RulesetRef within the package. Files that fail to parse are skipped;
+// installing or compiling them reports the error. An ID defined by more than one
+// file maps to nil, since references to it are ambiguous.
+func ParsePackageRulesets(pkgKey string, files []*core.File) map[string]*resource.RulesetResource {
+	rulesets := make(map[string]*resource.RulesetResource)
+	for _, file := range files {
+		if !filetype.IsRulesetFile(file) {
+			continue
+		}
+		ruleset, err := ParseRuleset(file)
+		if err != nil {
+			continue
+		}
+		ref := RulesetRef(pkgKey, ruleset.Metadata.ID)
+		if _, exists := rulesets[ref]; exists {
+			rulesets[ref] = nil
+			continue
+		}
+		rulesets[ref] = ruleset
+	}
+	return rulesets
+}
+
+// ExternalExtends returns the REGISTRY/PACKAGE keys of other packages the rulesets extend
+func ExternalExtends(pkgKey string, rulesets map[string]*resource.RulesetResource) []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, ruleset := range rulesets {
+		if ruleset == nil {
+			continue
+		}
+		for _, ref := range ruleset.Spec.Extends {
+			parts := strings.Split(ref, "/")
+			if len(parts) != 3 {
+				continue
+			}
+			key := parts[0] + "/" + parts[1]
+			if key != pkgKey && !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// ExtendedRulesets returns the references of the rulesets the given rulesets extend
+func ExtendedRulesets(pkgKey string, rulesets map[string]*resource.RulesetResource) map[string]bool {
+	extended := make(map[string]bool)
+	for _, ruleset := range rulesets {
+		if ruleset == nil {
+			continue
+		}
+		for _, ref := range ruleset.Spec.Extends {
+			if !strings.Contains(ref, "/") {
+				ref = RulesetRef(pkgKey, ref)
+			}
+			extended[ref] = true
+		}
+	}
+	return extended
+}
+
+// ResolveExtends returns the ruleset with the rules of the rulesets it extends
+// merged in. Extended rulesets are applied in order, each replacing earlier rules
+// with the same ID, and the ruleset's own rules replace inherited ones. An
+// unqualified reference names a ruleset in the same package (pkgKey); a
+// REGISTRY/PACKAGE/ID reference names one from another package. available maps
+// references to the rulesets that can be extended. The original ruleset is
+// returned unchanged when it extends nothing.
+func ResolveExtends(ruleset *resource.RulesetResource, pkgKey string, available map[string]*resource.RulesetResource) (*resource.RulesetResource, error) {
+	return resolveExtends(ruleset, pkgKey, available, []string{RulesetRef(pkgKey, ruleset.Metadata.ID)})
+}
+
+func resolveExtends(ruleset *resource.RulesetResource, pkgKey string, available map[string]*resource.RulesetResource, chain []string) (*resource.RulesetResource, error) {
+	if len(ruleset.Spec.Extends) == 0 {
+		return ruleset, nil
+	}
+
+	rules := make(map[string]resource.Rule)
+	for _, ref := range ruleset.Spec.Extends {
+		qualified, basePkgKey := ref, pkgKey
+		if strings.Contains(ref, "/") {
+			parts := strings.Split(ref, "/")
+			if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+				return nil, fmt.Errorf("ruleset %s extends %s: references to other packages must be REGISTRY/PACKAGE/RULESET_ID", ruleset.Metadata.ID, ref)
+			}
+			basePkgKey = parts[0] + "/" + parts[1]
+		} else {
+			qualified = RulesetRef(pkgKey, ref)
+		}
+
+		for i, seen := range chain {
+			if seen == qualified {
+				cycle := append(append([]string{}, chain[i:]...), qualified)
+				return nil, fmt.Errorf("ruleset extends cycle: %s", strings.Join(cycle, " -> "))
+			}
+		}
+
+		base, ok := available[qualified]
+		if !ok {
+			return nil, fmt.Errorf("ruleset %s extends %s, which is not available", ruleset.Metadata.ID, ref)
+		}
+		if base == nil {
+			return nil, fmt.Errorf("ruleset %s extends %s, which is defined by more than one file", ruleset.Metadata.ID, ref)
+		}
+		base, err := resolveExtends(base, basePkgKey, available, append(chain, qualified))
+		if err != nil {
+			return nil, err
+		}
+		for ruleID, rule := range base.Spec.Rules {
+			rules[ruleID] = rule
+		}
+	}
+	for ruleID, rule := range ruleset.Spec.Rules {
+		rules[ruleID] = rule
+	}
+
+	resolved := *ruleset
+	resolved.Spec.Rules = rules
+	resolved.Spec.Extends = nil
+	return &resolved, nil
+}
@@ -0,0 +1,120 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-manager/internal/arm/core"
+	"github.com/jomadu/ai-resource-manager/internal/arm/resource"
+)
+
+func testRuleset(id string, extends []string, bodies map[string]string) *resource.RulesetResource {
+	rules := make(map[string]resource.Rule, len(bodies))
+	for ruleID, body := range bodies {
+		rules[ruleID] = resource.Rule{Body: body}
+	}
+	return &resource.RulesetResource{
+		Metadata: resource.ResourceMetadata{ID: id},
+		Spec:     resource.RulesetSpec{Extends: extends, Rules: rules},
+	}
+}
+
+func TestResolveExtends(t *testing.T) {
+	available := map[string]*resource.RulesetResource{
+		"reg/rules/base":   testRuleset("base", nil, map[string]string{"naming": "base naming", "tests": "base tests", "docs": "base docs"}),
+		"reg/rules/strict": testRuleset("strict", []string{"base"}, map[string]string{"tests": "strict tests"}),
+		"reg/shared/go":    testRuleset("go", nil, map[string]string{"docs": "go docs", "errors": "go errors"}),
+	}
+	python := testRuleset("python", []string{"strict", "reg/shared/go"}, map[string]string{"naming": "python naming"})
+
+	got, err := ResolveExtends(python, "reg/rules", available)
+	if err != nil {
+		t.Fatalf("ResolveExtends() error = %v", err)
+	}
+
+	want := map[string]string{
+		"naming": "python naming", // own rules win
+		"tests":  "strict tests",  // a chain overrides its own base
+		"docs":   "go docs",       // later extends override earlier ones
+		"errors": "go errors",
+	}
+	if len(got.Spec.Rules) != len(want) {
+		t.Fatalf("rules = %v, want %v", got.Spec.Rules, want)
+	}
+	for ruleID, body := range want {
+		if got.Spec.Rules[ruleID].Body != body {
+			t.Errorf("rule %s = %q, want %q", ruleID, got.Spec.Rules[ruleID].Body, body)
+		}
+	}
+	if len(got.Spec.Extends) != 0 || len(python.Spec.Rules) != 1 {
+		t.Error("resolved ruleset should drop extends and leave the original untouched")
+	}
+}
+
+func TestResolveExtendsErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		available map[string]*resource.RulesetResource
+		ruleset   *resource.RulesetResource
+		wantErr   string
+	}{
+		{
+			name: "cycle",
+			available: map[string]*resource.RulesetResource{
+				"a": testRuleset("a", []string{"b"}, nil),
+				"b": testRuleset("b", []string{"a"}, nil),
+			},
+			ruleset: testRuleset("a", []string{"b"}, nil),
+			wantErr: "ruleset extends cycle: a -> b -> a",
+		},
+		{
+			name:    "self",
+			ruleset: testRuleset("a", []string{"a"}, nil),
+			wantErr: "ruleset extends cycle: a -> a",
+		},
+		{
+			name:    "missing",
+			ruleset: testRuleset("a", []string{"base"}, nil),
+			wantErr: "extends base, which is not available",
+		},
+		{
+			name:    "malformed reference",
+			ruleset: testRuleset("a", []string{"reg/base"}, nil),
+			wantErr: "REGISTRY/PACKAGE/RULESET_ID",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ResolveExtends(tt.ruleset, "", tt.available)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ResolveExtends() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParsePackageRulesets(t *testing.T) {
+	files := []*core.File{
+		{Path: "base.yml", Content: []byte("apiVersion: v1\nkind: Ruleset\nmetadata:\n  id: base\nspec:\n  rules:\n    naming:\n      body: Name things.\n")},
+		{Path: "python.yml", Content: []byte("apiVersion: v1\nkind: Ruleset\nmetadata:\n  id: python\nspec:\n  extends: [base, other/pkg/go]\n")},
+		{Path: "prompts.yml", Content: []byte("apiVersion: v1\nkind: Promptset\nmetadata:\n  id: prompts\nspec:\n  prompts:\n    p:\n      body: Hi\n")},
+	}
+
+	rulesets := ParsePackageRulesets("reg/rules", files)
+	if len(rulesets) != 2 || rulesets["reg/rules/base"] == nil || rulesets["reg/rules/python"] == nil {
+		t.Fatalf("rulesets = %v", rulesets)
+	}
+	if extended := ExtendedRulesets("reg/rules", rulesets); !extended["reg/rules/base"] || !extended["other/pkg/go"] || extended["reg/rules/python"] {
+		t.Errorf("ExtendedRulesets() = %v", extended)
+	}
+	if external := ExternalExtends("reg/rules", rulesets); len(external) != 1 || external[0] != "other/pkg" {
+		t.Errorf("ExternalExtends() = %v", external)
+	}
+
+	// Extending an ID defined by more than one file is ambiguous
+	duplicate := ParsePackageRulesets("reg/rules", append(files, &core.File{Path: "copy.yml", Content: files[0].Content}))
+	if _, err := ResolveExtends(duplicate["reg/rules/python"], "reg/rules", duplicate); err == nil || !strings.Contains(err.Error(), "defined by more than one file") {
+		t.Errorf("expected ambiguous ruleset error, got %v", err)
+	}
+}
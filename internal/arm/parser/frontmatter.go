@@ -0,0 +1,29 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+)
+
+var frontmatterDelimiter = []byte("---")
+
+// SplitFrontmatter separates YAML frontmatter delimited by --- lines from the
+// markdown body that follows it. Content without frontmatter is returned whole
+// as the body.
+func SplitFrontmatter(content []byte) (frontmatter, body []byte, err error) {
+	content = bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+
+	lines := bytes.SplitAfter(content, []byte("\n"))
+	if len(lines) == 0 || !bytes.Equal(bytes.TrimSpace(lines[0]), frontmatterDelimiter) {
+		return nil, content, nil
+	}
+
+	for i := 1; i < len(lines); i++ {
+		if bytes.Equal(bytes.TrimSpace(lines[i]), frontmatterDelimiter) {
+			frontmatter = bytes.Join(lines[1:i], nil)
+			body = bytes.Join(lines[i+1:], nil)
+			return frontmatter, bytes.TrimLeft(body, "\n"), nil
+		}
+	}
+	return nil, nil, errors.New("frontmatter is not terminated by ---")
+}
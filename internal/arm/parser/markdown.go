@@ -0,0 +1,223 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jomadu/ai-resource-manager/internal/arm/core"
+	"github.com/jomadu/ai-resource-manager/internal/arm/resource"
+	"gopkg.in/yaml.v3"
+)
+
+// invalidIDChars matches characters not allowed in IDs derived from directory names
+var invalidIDChars = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// ParseRuleMarkdown parses a *.rule.md file into its rule ID and rule
+func ParseRuleMarkdown(file *core.File) (string, *resource.Rule, error) {
+	var rule resource.Rule
+	ruleID, err := parseMarkdownSource(file, resource.RuleMarkdownSuffix, &rule, &rule.Body)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid rule in %s: %w", file.Path, err)
+	}
+	if err := validate.Struct(&rule); err != nil {
+		return "", nil, fmt.Errorf("invalid rule in %s: %w", file.Path, err)
+	}
+	for _, scope := range rule.Scope {
+		if err := scope.Validate(); err != nil {
+			return "", nil, fmt.Errorf("invalid rule in %s: rule %s: %w", file.Path, ruleID, err)
+		}
+	}
+	return ruleID, &rule, nil
+}
+
+// ParsePromptMarkdown parses a *.prompt.md file into its prompt ID and prompt
+func ParsePromptMarkdown(file *core.File) (string, *resource.Prompt, error) {
+	var prompt resource.Prompt
+	promptID, err := parseMarkdownSource(file, resource.PromptMarkdownSuffix, &prompt, &prompt.Body)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid prompt in %s: %w", file.Path, err)
+	}
+	if err := validate.Struct(&prompt); err != nil {
+		return "", nil, fmt.Errorf("invalid prompt in %s: %w", file.Path, err)
+	}
+	if err := prompt.ValidateArguments(); err != nil {
+		return "", nil, fmt.Errorf("invalid prompt in %s: %w", file.Path, err)
+	}
+	return promptID, &prompt, nil
+}
+
+// parseMarkdownSource decodes the frontmatter of a markdown source into out and
+// stores the markdown body in body. The ID is the file name without suffix.
+func parseMarkdownSource(file *core.File, suffix string, out interface{}, body *string) (string, error) {
+	frontmatter, content, err := SplitFrontmatter(file.Content)
+	if err != nil {
+		return "", err
+	}
+	if frontmatter != nil {
+		if err := yaml.Unmarshal(frontmatter, out); err != nil {
+			return "", fmt.Errorf("failed to parse frontmatter: %w", err)
+		}
+	}
+	*body = string(content)
+
+	base := filepath.Base(file.Path)
+	id := base[:len(base)-len(suffix)]
+	if id == "" {
+		return "", fmt.Errorf("file name has no ID before %s", suffix)
+	}
+	return id, nil
+}
+
+// AssembleMarkdownResources groups markdown rule and prompt sources into resource
+// files so they install and compile exactly like YAML resources. The sources in a
+// directory are merged into the ruleset.yml or promptset.yml metadata file beside
+// them, which may hold inline rules or prompts of its own; without one, the
+// resource ID is the directory name. Other files are returned unchanged.
+func AssembleMarkdownResources(files []*core.File) ([]*core.File, error) {
+	rules := make(map[string][]*core.File)
+	prompts := make(map[string][]*core.File)
+	var others []*core.File
+	for _, file := range files {
+		dir := path.Dir(filepath.ToSlash(file.Path))
+		base := strings.ToLower(filepath.Base(file.Path))
+		switch {
+		case strings.HasSuffix(base, resource.RuleMarkdownSuffix):
+			rules[dir] = append(rules[dir], file)
+		case strings.HasSuffix(base, resource.PromptMarkdownSuffix):
+			prompts[dir] = append(prompts[dir], file)
+		default:
+			others = append(others, file)
+		}
+	}
+	if len(rules) == 0 && len(prompts) == 0 {
+		return files, nil
+	}
+
+	// Metadata files are consumed by the resources they describe
+	var assembled []*core.File
+	rulesetMetadata := make(map[string]*core.File)
+	promptsetMetadata := make(map[string]*core.File)
+	for _, file := range others {
+		dir := path.Dir(filepath.ToSlash(file.Path))
+		switch {
+		case filepath.Base(file.Path) == resource.RulesetMetadataFilename && rules[dir] != nil:
+			rulesetMetadata[dir] = file
+		case filepath.Base(file.Path) == resource.PromptsetMetadataFilename && prompts[dir] != nil:
+			promptsetMetadata[dir] = file
+		default:
+			assembled = append(assembled, file)
+		}
+	}
+
+	for _, dir := range sortedKeys(rules) {
+		file, err := assembleRuleset(dir, rulesetMetadata[dir], rules[dir])
+		if err != nil {
+			return nil, err
+		}
+		assembled = append(assembled, file)
+	}
+	for _, dir := range sortedKeys(prompts) {
+		file, err := assemblePromptset(dir, promptsetMetadata[dir], prompts[dir])
+		if err != nil {
+			return nil, err
+		}
+		assembled = append(assembled, file)
+	}
+	return assembled, nil
+}
+
+func assembleRuleset(dir string, metadata *core.File, sources []*core.File) (*core.File, error) {
+	filePath := path.Join(dir, resource.RulesetMetadataFilename)
+	var ruleset resource.RulesetResource
+	if metadata != nil {
+		filePath = metadata.Path
+		if err := yaml.Unmarshal(metadata.Content, &ruleset); err != nil {
+			return nil, fmt.Errorf("failed to parse YAML in %s: %w", metadata.Path, err)
+		}
+	}
+	ruleset.APIVersion = withDefault(ruleset.APIVersion, "v1")
+	ruleset.Kind = withDefault(ruleset.Kind, "Ruleset")
+	ruleset.Metadata.ID = withDefault(ruleset.Metadata.ID, directoryID(dir, "ruleset"))
+	if ruleset.Spec.Rules == nil {
+		ruleset.Spec.Rules = make(map[string]resource.Rule)
+	}
+
+	for _, source := range sources {
+		ruleID, rule, err := ParseRuleMarkdown(source)
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := ruleset.Spec.Rules[ruleID]; exists {
+			return nil, fmt.Errorf("rule %s in %s is already defined in %s", ruleID, source.Path, filePath)
+		}
+		ruleset.Spec.Rules[ruleID] = *rule
+	}
+	return encodeResource(filePath, &ruleset)
+}
+
+func assemblePromptset(dir string, metadata *core.File, sources []*core.File) (*core.File, error) {
+	filePath := path.Join(dir, resource.PromptsetMetadataFilename)
+	var promptset resource.PromptsetResource
+	if metadata != nil {
+		filePath = metadata.Path
+		if err := yaml.Unmarshal(metadata.Content, &promptset); err != nil {
+			return nil, fmt.Errorf("failed to parse YAML in %s: %w", metadata.Path, err)
+		}
+	}
+	promptset.APIVersion = withDefault(promptset.APIVersion, "v1")
+	promptset.Kind = withDefault(promptset.Kind, "Promptset")
+	promptset.Metadata.ID = withDefault(promptset.Metadata.ID, directoryID(dir, "promptset"))
+	if promptset.Spec.Prompts == nil {
+		promptset.Spec.Prompts = make(map[string]resource.Prompt)
+	}
+
+	for _, source := range sources {
+		promptID, prompt, err := ParsePromptMarkdown(source)
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := promptset.Spec.Prompts[promptID]; exists {
+			return nil, fmt.Errorf("prompt %s in %s is already defined in %s", promptID, source.Path, filePath)
+		}
+		promptset.Spec.Prompts[promptID] = *prompt
+	}
+	return encodeResource(filePath, &promptset)
+}
+
+// encodeResource writes a resource as a YAML file at filePath
+func encodeResource(filePath string, v interface{}) (*core.File, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(v); err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", filePath, err)
+	}
+	return &core.File{Path: filePath, Content: buf.Bytes(), Size: int64(buf.Len())}, nil
+}
+
+// directoryID derives a resource ID from a directory name
+func directoryID(dir, fallback string) string {
+	id := strings.Trim(invalidIDChars.ReplaceAllString(strings.ToLower(path.Base(dir)), "-"), "-")
+	return withDefault(id, fallback)
+}
+
+func withDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+func sortedKeys(m map[string][]*core.File) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,106 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-manager/internal/arm/core"
+)
+
+func TestParseRuleMarkdown(t *testing.T) {
+	file := &core.File{Path: "rules/naming.rule.md", Content: []byte(`---
+name: Naming
+enforcement: must
+priority: 50
+scope:
+  - files: ["**/*.go"]
+---
+
+# Naming
+
+Use descriptive names.
+`)}
+
+	ruleID, rule, err := ParseRuleMarkdown(file)
+	if err != nil {
+		t.Fatalf("ParseRuleMarkdown() error = %v", err)
+	}
+	if ruleID != "naming" || rule.Name != "Naming" || rule.Enforcement != "must" || rule.Priority != 50 {
+		t.Errorf("ParseRuleMarkdown() = %s, %+v", ruleID, rule)
+	}
+	if rule.Body != "# Naming\n\nUse descriptive names.\n" {
+		t.Errorf("ParseRuleMarkdown() body = %q", rule.Body)
+	}
+
+	invalid := &core.File{Path: "rules/bad.rule.md", Content: []byte("---\nenforcement: always\n---\nBody\n")}
+	if _, _, err := ParseRuleMarkdown(invalid); err == nil || !strings.Contains(err.Error(), "invalid rule") {
+		t.Errorf("ParseRuleMarkdown() error = %v, want invalid rule", err)
+	}
+
+	empty := &core.File{Path: "rules/empty.rule.md", Content: []byte("---\nname: Empty\n---\n")}
+	if _, _, err := ParseRuleMarkdown(empty); err == nil {
+		t.Error("ParseRuleMarkdown() expected error for empty body")
+	}
+}
+
+func TestParsePromptMarkdown(t *testing.T) {
+	file := &core.File{Path: "prompts/review.prompt.md", Content: []byte("---\ndescription: Review code\n---\nReview the diff.\n")}
+
+	promptID, prompt, err := ParsePromptMarkdown(file)
+	if err != nil {
+		t.Fatalf("ParsePromptMarkdown() error = %v", err)
+	}
+	if promptID != "review" || prompt.Description != "Review code" || prompt.Body != "Review the diff.\n" {
+		t.Errorf("ParsePromptMarkdown() = %s, %+v", promptID, prompt)
+	}
+}
+
+func TestAssembleMarkdownResources(t *testing.T) {
+	files := []*core.File{
+		{Path: "rules/ruleset.yml", Content: []byte("apiVersion: v1\nkind: Ruleset\nmetadata:\n  id: clean-code\n  name: Clean Code\nspec:\n  rules:\n    inline:\n      body: Inline rule.\n")},
+		{Path: "rules/naming.rule.md", Content: []byte("---\nenforcement: must\n---\nUse descriptive names.\n")},
+		{Path: "prompts/review.prompt.md", Content: []byte("Review the diff.\n")},
+		{Path: "README.md", Content: []byte("# Readme")},
+	}
+
+	assembled, err := AssembleMarkdownResources(files)
+	if err != nil {
+		t.Fatalf("AssembleMarkdownResources() error = %v", err)
+	}
+	if len(assembled) != 3 {
+		t.Fatalf("AssembleMarkdownResources() returned %d files, want 3", len(assembled))
+	}
+
+	byPath := make(map[string]*core.File)
+	for _, file := range assembled {
+		byPath[file.Path] = file
+	}
+	if byPath["README.md"] == nil {
+		t.Error("AssembleMarkdownResources() should keep unrelated files")
+	}
+
+	ruleset, err := ParseRuleset(byPath["rules/ruleset.yml"])
+	if err != nil {
+		t.Fatalf("assembled ruleset is invalid: %v", err)
+	}
+	if ruleset.Metadata.ID != "clean-code" || len(ruleset.Spec.Rules) != 2 || ruleset.Spec.Rules["naming"].Enforcement != "must" {
+		t.Errorf("assembled ruleset = %+v", ruleset)
+	}
+
+	// Without a metadata file the directory names the resource
+	promptset, err := ParsePromptset(byPath["prompts/promptset.yml"])
+	if err != nil {
+		t.Fatalf("assembled promptset is invalid: %v", err)
+	}
+	if promptset.Metadata.ID != "prompts" || promptset.Spec.Prompts["review"].Body != "Review the diff.\n" {
+		t.Errorf("assembled promptset = %+v", promptset)
+	}
+
+	conflict := []*core.File{
+		{Path: "rules/ruleset.yml", Content: []byte("metadata:\n  id: clean-code\nspec:\n  rules:\n    naming:\n      body: Inline.\n")},
+		{Path: "rules/naming.rule.md", Content: []byte("Markdown.\n")},
+	}
+	if _, err := AssembleMarkdownResources(conflict); err == nil || !strings.Contains(err.Error(), "already defined") {
+		t.Errorf("AssembleMarkdownResources() error = %v, want duplicate rule", err)
+	}
+}
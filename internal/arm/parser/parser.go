@@ -2,6 +2,9 @@ package parser
 
 import (
 	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/jomadu/ai-resource-manager/internal/arm/core"
@@ -20,6 +23,16 @@ func ParseRuleset(file *core.File) (*resource.RulesetResource, error) {
 	if err := validate.Struct(&ruleset); err != nil {
 		return nil, fmt.Errorf("invalid ruleset in %s: %w", file.Path, err)
 	}
+	if len(ruleset.Spec.Rules) == 0 && len(ruleset.Spec.Extends) == 0 {
+		return nil, fmt.Errorf("invalid ruleset in %s: a ruleset must define rules or extend another ruleset", file.Path)
+	}
+	for ruleID, rule := range ruleset.Spec.Rules {
+		for _, scope := range rule.Scope {
+			if err := scope.Validate(); err != nil {
+				return nil, fmt.Errorf("invalid ruleset in %s: rule %s: %w", file.Path, ruleID, err)
+			}
+		}
+	}
 	return &ruleset, nil
 }
 
@@ -32,5 +45,82 @@ func ParsePromptset(file *core.File) (*resource.PromptsetResource, error) {
 	if err := validate.Struct(&promptset); err != nil {
 		return nil, fmt.Errorf("invalid promptset in %s: %w", file.Path, err)
 	}
+	promptIDs := make([]string, 0, len(promptset.Spec.Prompts))
+	for promptID := range promptset.Spec.Prompts {
+		promptIDs = append(promptIDs, promptID)
+	}
+	sort.Strings(promptIDs)
+	for _, promptID := range promptIDs {
+		prompt := promptset.Spec.Prompts[promptID]
+		if err := prompt.ValidateArguments(); err != nil {
+			return nil, fmt.Errorf("invalid promptset in %s: prompt %s: %w", file.Path, promptID, err)
+		}
+	}
 	return &promptset, nil
 }
+
+// ParseMcpset parses a file into an mcpset resource
+func ParseMcpset(file *core.File) (*resource.McpsetResource, error) {
+	var mcpset resource.McpsetResource
+	if err := yaml.Unmarshal(file.Content, &mcpset); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML in %s: %w", file.Path, err)
+	}
+	if err := validate.Struct(&mcpset); err != nil {
+		return nil, fmt.Errorf("invalid mcpset in %s: %w", file.Path, err)
+	}
+	for name, server := range mcpset.Spec.Servers {
+		if err := server.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid mcpset in %s: server %s: %w", file.Path, name, err)
+		}
+	}
+	return &mcpset, nil
+}
+
+// ParseAgentset parses a file into an agentset resource
+func ParseAgentset(file *core.File) (*resource.AgentsetResource, error) {
+	var agentset resource.AgentsetResource
+	if err := yaml.Unmarshal(file.Content, &agentset); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML in %s: %w", file.Path, err)
+	}
+	if err := validate.Struct(&agentset); err != nil {
+		return nil, fmt.Errorf("invalid agentset in %s: %w", file.Path, err)
+	}
+	return &agentset, nil
+}
+
+// ParseHookset parses a file into a hookset resource
+func ParseHookset(file *core.File) (*resource.HooksetResource, error) {
+	var hookset resource.HooksetResource
+	if err := yaml.Unmarshal(file.Content, &hookset); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML in %s: %w", file.Path, err)
+	}
+	if err := validate.Struct(&hookset); err != nil {
+		return nil, fmt.Errorf("invalid hookset in %s: %w", file.Path, err)
+	}
+	return &hookset, nil
+}
+
+// ParseSkill parses the frontmatter of a SKILL.md file. A skill that is not at the
+// root of its package must live in a directory named after it.
+func ParseSkill(file *core.File) (*resource.Skill, error) {
+	frontmatter, _, err := SplitFrontmatter(file.Content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid skill in %s: %w", file.Path, err)
+	}
+	if frontmatter == nil {
+		return nil, fmt.Errorf("invalid skill in %s: missing frontmatter", file.Path)
+	}
+
+	var skill resource.Skill
+	if err := yaml.Unmarshal(frontmatter, &skill); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML in %s: %w", file.Path, err)
+	}
+	if err := skill.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid skill in %s: %w", file.Path, err)
+	}
+
+	if dir := path.Dir(filepath.ToSlash(file.Path)); dir != "." && path.Base(dir) != skill.Name {
+		return nil, fmt.Errorf("invalid skill in %s: name %s must match its directory %s", file.Path, skill.Name, path.Base(dir))
+	}
+	return &skill, nil
+}
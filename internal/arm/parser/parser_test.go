@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/jomadu/ai-resource-manager/internal/arm/core"
@@ -30,6 +31,43 @@ spec:
 			},
 			wantErr: false,
 		},
+		{
+			name: "unknown scope language",
+			file: &core.File{
+				Path: "test.yml",
+				Content: []byte(`apiVersion: v1
+kind: Ruleset
+metadata:
+  id: "test"
+spec:
+  rules:
+    rule1:
+      scope:
+        - languages: ["klingon"]
+      body: "test rule"`),
+			},
+			wantErr: true,
+			errMsg:  "unknown scope language",
+		},
+		{
+			name: "scope files outside its languages",
+			file: &core.File{
+				Path: "test.yml",
+				Content: []byte(`apiVersion: v1
+kind: Ruleset
+metadata:
+  id: "test"
+spec:
+  rules:
+    pythonOnly:
+      scope:
+        - files: ["**/*.py"]
+          languages: ["go"]
+      body: "test rule"`),
+			},
+			wantErr: true,
+			errMsg:  "rule pythonOnly: scope matches no files",
+		},
 		{
 			name: "invalid yaml",
 			file: &core.File{
@@ -234,3 +272,272 @@ func findInString(s, substr string) bool {
 	}
 	return false
 }
+
+func TestParseMcpset(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+		errMsg  string
+	}{
+		{
+			name: "valid mcpset",
+			content: `apiVersion: v1
+kind: Mcpset
+metadata:
+  id: "test"
+spec:
+  servers:
+    github:
+      command: "npx"
+      args: ["-y", "@modelcontextprotocol/server-github"]
+      env:
+        GITHUB_TOKEN: "${GITHUB_TOKEN}"
+    docs:
+      url: "https://example.com/mcp"`,
+			wantErr: false,
+		},
+		{
+			name: "empty servers",
+			content: `apiVersion: v1
+kind: Mcpset
+metadata:
+  id: "test"
+spec:
+  servers: {}`,
+			wantErr: true,
+			errMsg:  "invalid mcpset",
+		},
+		{
+			name: "server without command or url",
+			content: `apiVersion: v1
+kind: Mcpset
+metadata:
+  id: "test"
+spec:
+  servers:
+    broken:
+      args: ["serve"]`,
+			wantErr: true,
+			errMsg:  "server broken",
+		},
+		{
+			name: "server with command and url",
+			content: `apiVersion: v1
+kind: Mcpset
+metadata:
+  id: "test"
+spec:
+  servers:
+    both:
+      command: "serve"
+      url: "https://example.com/mcp"`,
+			wantErr: true,
+			errMsg:  "server both",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseMcpset(&core.File{Path: "test.yml", Content: []byte(tt.content)})
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseMcpset() expected error but got none")
+					return
+				}
+				if tt.errMsg != "" && !contains(err.Error(), tt.errMsg) {
+					t.Errorf("ParseMcpset() error = %v, want error containing %v", err, tt.errMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseMcpset() unexpected error = %v", err)
+			}
+			if len(result.Spec.Servers) != 2 {
+				t.Errorf("ParseMcpset() got %d servers, want 2", len(result.Spec.Servers))
+			}
+		})
+	}
+}
+
+func TestParseAgentset(t *testing.T) {
+	valid := &core.File{Path: "agents.yml", Content: []byte(`apiVersion: v1
+kind: Agentset
+metadata:
+  id: "reviewers"
+spec:
+  agents:
+    code-reviewer:
+      description: "Reviews code"
+      tools: ["Read"]
+      body: "You review code."`)}
+
+	result, err := ParseAgentset(valid)
+	if err != nil {
+		t.Fatalf("ParseAgentset() unexpected error = %v", err)
+	}
+	if result.Spec.Agents["code-reviewer"].Tools[0] != "Read" {
+		t.Errorf("ParseAgentset() did not parse tools: %+v", result.Spec.Agents)
+	}
+
+	missingDescription := &core.File{Path: "agents.yml", Content: []byte(`apiVersion: v1
+kind: Agentset
+metadata:
+  id: "reviewers"
+spec:
+  agents:
+    code-reviewer:
+      body: "You review code."`)}
+
+	if _, err := ParseAgentset(missingDescription); err == nil || !contains(err.Error(), "invalid agentset") {
+		t.Errorf("ParseAgentset() error = %v, want invalid agentset", err)
+	}
+}
+
+func TestParseHookset(t *testing.T) {
+	valid := &core.File{Path: "hooks.yml", Content: []byte(`apiVersion: v1
+kind: Hookset
+metadata:
+  id: "quality"
+spec:
+  hooks:
+    format:
+      event: post-edit
+      matcher: "**/*.go"
+      command: "gofmt -w ."
+      timeout: 30`)}
+
+	result, err := ParseHookset(valid)
+	if err != nil {
+		t.Fatalf("ParseHookset() unexpected error = %v", err)
+	}
+	if hook := result.Spec.Hooks["format"]; hook.Event != "post-edit" || hook.Timeout != 30 {
+		t.Errorf("ParseHookset() did not parse hook: %+v", hook)
+	}
+
+	unknownEvent := &core.File{Path: "hooks.yml", Content: []byte(`apiVersion: v1
+kind: Hookset
+metadata:
+  id: "quality"
+spec:
+  hooks:
+    format:
+      event: on-save
+      command: "gofmt -w ."`)}
+
+	if _, err := ParseHookset(unknownEvent); err == nil || !contains(err.Error(), "invalid hookset") {
+		t.Errorf("ParseHookset() error = %v, want invalid hookset", err)
+	}
+}
+
+func TestSplitFrontmatter(t *testing.T) {
+	frontmatter, body, err := SplitFrontmatter([]byte("---\r\nname: test\r\n---\r\n\r\n# Body\r\n"))
+	if err != nil {
+		t.Fatalf("SplitFrontmatter() error = %v", err)
+	}
+	if string(frontmatter) != "name: test\n" || string(body) != "# Body\n" {
+		t.Errorf("SplitFrontmatter() = %q, %q", frontmatter, body)
+	}
+
+	frontmatter, body, err = SplitFrontmatter([]byte("# Just markdown"))
+	if err != nil || frontmatter != nil || string(body) != "# Just markdown" {
+		t.Errorf("SplitFrontmatter() without frontmatter = %q, %q, %v", frontmatter, body, err)
+	}
+
+	if _, _, err := SplitFrontmatter([]byte("---\nname: test\n")); err == nil {
+		t.Error("SplitFrontmatter() expected error for unterminated frontmatter")
+	}
+}
+
+func TestParseSkill(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		errMsg  string
+	}{
+		{
+			name:    "valid skill",
+			path:    "skills/pdf-processing/SKILL.md",
+			content: "---\nname: pdf-processing\ndescription: Extract text from PDFs.\nlicense: Apache-2.0\n---\n\n# PDF\n",
+		},
+		{
+			name:    "skill at package root",
+			path:    "SKILL.md",
+			content: "---\nname: pdf-processing\ndescription: Extract text from PDFs.\n---\n",
+		},
+		{
+			name:    "missing frontmatter",
+			path:    "pdf-processing/SKILL.md",
+			content: "# PDF\n",
+			errMsg:  "missing frontmatter",
+		},
+		{
+			name:    "invalid name",
+			path:    "PDF/SKILL.md",
+			content: "---\nname: PDF\ndescription: Extract text from PDFs.\n---\n",
+			errMsg:  "name",
+		},
+		{
+			name:    "name does not match directory",
+			path:    "skills/pdf/SKILL.md",
+			content: "---\nname: pdf-processing\ndescription: Extract text from PDFs.\n---\n",
+			errMsg:  "must match its directory",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			skill, err := ParseSkill(&core.File{Path: tt.path, Content: []byte(tt.content)})
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Fatalf("ParseSkill() unexpected error = %v", err)
+				}
+				if skill.Name != "pdf-processing" {
+					t.Errorf("ParseSkill() name = %s", skill.Name)
+				}
+				return
+			}
+			if err == nil || !contains(err.Error(), tt.errMsg) {
+				t.Errorf("ParseSkill() error = %v, want error containing %q", err, tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestParsePromptsetArguments(t *testing.T) {
+	valid := &core.File{Path: "prompts.yml", Content: []byte(`apiVersion: v1
+kind: Promptset
+metadata:
+  id: explain
+spec:
+  prompts:
+    explain:
+      arguments:
+        - name: topic
+          required: true
+      body: Explain {{args.topic}}.
+`)}
+	promptset, err := ParsePromptset(valid)
+	if err != nil {
+		t.Fatalf("ParsePromptset() error = %v", err)
+	}
+	if args := promptset.Spec.Prompts["explain"].Arguments; len(args) != 1 || args[0].Name != "topic" || !args[0].Required {
+		t.Errorf("Arguments = %+v", args)
+	}
+
+	invalid := &core.File{Path: "prompts.yml", Content: []byte(`apiVersion: v1
+kind: Promptset
+metadata:
+  id: explain
+spec:
+  prompts:
+    explain:
+      body: Explain {{args.topic}}.
+`)}
+	_, err = ParsePromptset(invalid)
+	if err == nil || !strings.Contains(err.Error(), "prompt explain") || !strings.Contains(err.Error(), "undeclared argument") {
+		t.Errorf("expected undeclared argument error, got %v", err)
+	}
+}
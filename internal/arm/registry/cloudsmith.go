@@ -62,15 +62,27 @@ func (c *CloudsmithRegistry) ListPackages(ctx context.Context) ([]*core.PackageM
 		return nil, err
 	}
 
-	seen := make(map[string]bool)
+	seen := make(map[string]*core.PackageMetadata)
 	var result []*core.PackageMetadata
 	for _, pkg := range packages {
-		if pkg.Format == "raw" && !seen[pkg.Name] {
-			seen[pkg.Name] = true
-			result = append(result, &core.PackageMetadata{
+		if pkg.Format != "raw" {
+			continue
+		}
+		metadata, ok := seen[pkg.Name]
+		if !ok {
+			metadata = &core.PackageMetadata{
 				RegistryName: c.name,
 				Name:         pkg.Name,
-			})
+			}
+			seen[pkg.Name] = metadata
+			result = append(result, metadata)
+		}
+		// Use the first summary, or failing that description, any version has
+		if metadata.Description == "" {
+			metadata.Description = pkg.Summary
+		}
+		if metadata.Description == "" {
+			metadata.Description = pkg.Description
 		}
 	}
 
@@ -116,6 +128,8 @@ type cloudsmithPackage struct {
 	Filename    string `json:"filename"`
 	DownloadURL string `json:"cdn_url"`
 	Size        int64  `json:"size"`
+	Summary     string `json:"summary"`
+	Description string `json:"description"`
 }
 
 func (c *CloudsmithRegistry) ListPackageVersions(ctx context.Context, packageName string) ([]core.Version, error) {
@@ -335,7 +349,8 @@ func (c *cloudsmithClient) downloadFile(ctx context.Context, url string) ([]byte
 }
 
 func (c *CloudsmithRegistry) matchesPatterns(path string, include, exclude []string) bool {
-	// Default to YAML files if no patterns specified
+	// Default to YAML files if no patterns specified; markdown sources are opt-in
+	// so that packages locked before they were supported keep the same files
 	if len(include) == 0 && len(exclude) == 0 {
 		include = []string{"**/*.yml", "**/*.yaml"}
 	}
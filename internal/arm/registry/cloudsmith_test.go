@@ -191,8 +191,8 @@ func TestCloudsmithRegistry_ListPackages(t *testing.T) {
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte(`[
 			{"name": "package-a", "version": "1.0.0", "format": "raw", "filename": "package-a-1.0.0.tar.gz"},
-			{"name": "package-a", "version": "2.0.0", "format": "raw", "filename": "package-a-2.0.0.tar.gz"},
-			{"name": "package-b", "version": "1.0.0", "format": "raw", "filename": "package-b-1.0.0.tar.gz"},
+			{"name": "package-a", "version": "2.0.0", "format": "raw", "filename": "package-a-2.0.0.tar.gz", "summary": "Clean code rules"},
+			{"name": "package-b", "version": "1.0.0", "format": "raw", "filename": "package-b-1.0.0.tar.gz", "description": "Review prompts"},
 			{"name": "package-c", "version": "1.0.0", "format": "docker", "filename": "package-c-1.0.0"}
 		]`))
 	}))
@@ -230,8 +230,10 @@ func TestCloudsmithRegistry_ListPackages(t *testing.T) {
 	}
 
 	names := make(map[string]bool)
+	descriptions := make(map[string]string)
 	for _, pkg := range packages {
 		names[pkg.Name] = true
+		descriptions[pkg.Name] = pkg.Description
 	}
 
 	if !names["package-a"] || !names["package-b"] {
@@ -240,6 +242,9 @@ func TestCloudsmithRegistry_ListPackages(t *testing.T) {
 	if names["package-c"] {
 		t.Errorf("package-c should be filtered out (not raw format)")
 	}
+	if descriptions["package-a"] != "Clean code rules" || descriptions["package-b"] != "Review prompts" {
+		t.Errorf("expected descriptions from summary and description, got: %v", descriptions)
+	}
 }
 
 func TestCloudsmithRegistry_ListPackages_Pagination(t *testing.T) {
@@ -118,6 +118,15 @@ func (g *GitRegistry) ListPackageVersions(ctx context.Context, packageName strin
 	return versions, nil
 }
 
+// GetTagMessage returns the annotation of the tag for version. Branches and
+// lightweight tags have no annotation.
+func (g *GitRegistry) GetTagMessage(ctx context.Context, version *core.Version) (string, error) {
+	if !version.IsSemver {
+		return "", nil
+	}
+	return g.repo.GetTagMessage(ctx, g.config.URL, version.Version)
+}
+
 // normalizePatterns sorts patterns and normalizes path separators for consistent cache keys
 func normalizePatterns(patterns []string) []string {
 	if len(patterns) == 0 {
@@ -197,7 +206,8 @@ func (g *GitRegistry) GetPackage(ctx context.Context, packageName string, versio
 // matchesPatterns checks if file path matches include/exclude patterns.
 // Uses core.MatchPattern for glob pattern support with ** for recursive matching.
 func (g *GitRegistry) matchesPatterns(filePath string, include, exclude []string) bool {
-	// Default to YAML files if no patterns specified
+	// Default to YAML files if no patterns specified; markdown sources are opt-in
+	// so that packages locked before they were supported keep the same files
 	if len(include) == 0 && len(exclude) == 0 {
 		include = []string{"**/*.yml", "**/*.yaml"}
 	}
@@ -929,6 +929,55 @@ func TestGitRegistry_NoPatterns(t *testing.T) {
 	}
 }
 
+func TestGitRegistry_MarkdownSourcesOptIn(t *testing.T) {
+	// Markdown sources are only fetched when included explicitly
+	tempDir := t.TempDir()
+
+	testRepo := storage.NewTestRepo(t, tempDir)
+	builder := testRepo.Builder().
+		Init().
+		AddFile("rules/ruleset.yml", "yml content").
+		AddFile("rules/naming.rule.md", "rule content").
+		AddFile(".github/prompts/review.prompt.md", "copilot prompt").
+		Commit("Initial commit").
+		Tag("v1.0.0")
+	_ = builder.Build()
+
+	config := GitRegistryConfig{
+		RegistryConfig: RegistryConfig{
+			URL:  "file://" + tempDir,
+			Type: "git",
+		},
+	}
+
+	registry, err := NewGitRegistry("test-registry", config)
+	if err != nil {
+		t.Fatalf("failed to create registry: %v", err)
+	}
+
+	ctx := context.Background()
+	versions, err := registry.ListPackageVersions(ctx, "test-package")
+	if err != nil {
+		t.Fatalf("failed to list versions: %v", err)
+	}
+
+	pkg, err := registry.GetPackage(ctx, "test-package", &versions[0], nil, nil)
+	if err != nil {
+		t.Fatalf("failed to get package: %v", err)
+	}
+	if len(pkg.Files) != 1 || pkg.Files[0].Path != "rules/ruleset.yml" {
+		t.Errorf("expected only rules/ruleset.yml by default, got %d files", len(pkg.Files))
+	}
+
+	pkg, err = registry.GetPackage(ctx, "test-package", &versions[0], []string{"**/*.yml", "rules/*.rule.md"}, nil)
+	if err != nil {
+		t.Fatalf("failed to get package: %v", err)
+	}
+	if len(pkg.Files) != 2 {
+		t.Errorf("expected ruleset.yml and naming.rule.md with explicit include, got %d files", len(pkg.Files))
+	}
+}
+
 // Repository Structure
 func TestGitRegistry_MultipleFileTypes(t *testing.T) {
 	// Test .yml, .md, .json files
@@ -372,7 +372,8 @@ func ensureProtocol(baseURL string) string {
 }
 
 func matchesPatterns(filePath string, include, exclude []string) bool {
-	// Default to YAML files if no patterns specified
+	// Default to YAML files if no patterns specified; markdown sources are opt-in
+	// so that packages locked before they were supported keep the same files
 	if len(include) == 0 && len(exclude) == 0 {
 		include = []string{"**/*.yml", "**/*.yaml"}
 	}
@@ -11,3 +11,9 @@ type Registry interface {
 	ListPackageVersions(ctx context.Context, packageName string) ([]core.Version, error)
 	GetPackage(ctx context.Context, packageName string, version *core.Version, include []string, exclude []string) (*core.Package, error)
 }
+
+// TagMessenger is implemented by registries whose versions are git tags, so
+// release notes can be read from tag annotations
+type TagMessenger interface {
+	GetTagMessage(ctx context.Context, version *core.Version) (string, error)
+}
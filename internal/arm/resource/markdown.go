@@ -0,0 +1,11 @@
+package resource
+
+// Markdown source files carry a single rule or prompt: YAML frontmatter holds the
+// Rule or Prompt fields and the markdown after it becomes Body. The files in a
+// directory are grouped by the resource metadata file next to them.
+const (
+	RuleMarkdownSuffix        = ".rule.md"
+	PromptMarkdownSuffix      = ".prompt.md"
+	RulesetMetadataFilename   = "ruleset.yml"
+	PromptsetMetadataFilename = "promptset.yml"
+)
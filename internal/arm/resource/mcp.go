@@ -0,0 +1,30 @@
+package resource
+
+import (
+	"errors"
+	"net/url"
+)
+
+// Validate checks that the server is either a local command or a remote URL
+func (s McpServer) Validate() error {
+	switch {
+	case s.Command == "" && s.URL == "":
+		return errors.New("server requires a command or a url")
+	case s.Command != "" && s.URL != "":
+		return errors.New("server cannot set both command and url")
+	case s.URL != "":
+		if len(s.Args) > 0 || len(s.Env) > 0 {
+			return errors.New("args and env only apply to command servers")
+		}
+		u, err := url.Parse(s.URL)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return errors.New("url must be absolute")
+		}
+	}
+	return nil
+}
+
+// IsRemote reports whether the server is reached over HTTP rather than started locally
+func (s McpServer) IsRemote() bool {
+	return s.URL != ""
+}
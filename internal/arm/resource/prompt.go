@@ -0,0 +1,71 @@
+package resource
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// promptPlaceholder matches {{args.name}} placeholders in prompt bodies
+var promptPlaceholder = regexp.MustCompile(`\{\{\s*args\.([A-Za-z0-9_-]*)\s*\}\}`)
+
+// argumentName matches valid prompt argument names
+var argumentName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+// Placeholders returns the argument names the body refers to, in order of first use
+func (p *Prompt) Placeholders() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, match := range promptPlaceholder.FindAllStringSubmatch(p.Body, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			names = append(names, match[1])
+		}
+	}
+	return names
+}
+
+// RenderBody replaces each placeholder in the body with the text render returns
+// for the argument it refers to
+func (p *Prompt) RenderBody(render func(arg *PromptArgument) string) string {
+	return promptPlaceholder.ReplaceAllStringFunc(p.Body, func(placeholder string) string {
+		name := promptPlaceholder.FindStringSubmatch(placeholder)[1]
+		for i := range p.Arguments {
+			if p.Arguments[i].Name == name {
+				return render(&p.Arguments[i])
+			}
+		}
+		return placeholder
+	})
+}
+
+// ValidateArguments checks that the declared arguments are well formed and match
+// the placeholders used in the body
+func (p *Prompt) ValidateArguments() error {
+	declared := make(map[string]bool, len(p.Arguments))
+	for _, arg := range p.Arguments {
+		if !argumentName.MatchString(arg.Name) {
+			return fmt.Errorf("invalid argument name %q", arg.Name)
+		}
+		if declared[arg.Name] {
+			return fmt.Errorf("argument %s is declared more than once", arg.Name)
+		}
+		if arg.Required && arg.Default != "" {
+			return fmt.Errorf("argument %s cannot be required and have a default", arg.Name)
+		}
+		declared[arg.Name] = true
+	}
+
+	used := make(map[string]bool)
+	for _, name := range p.Placeholders() {
+		if !declared[name] {
+			return fmt.Errorf("placeholder {{args.%s}} refers to an undeclared argument", name)
+		}
+		used[name] = true
+	}
+	for _, arg := range p.Arguments {
+		if !used[arg.Name] {
+			return fmt.Errorf("argument %s is declared but not used in the body", arg.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package resource
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPromptValidateArguments(t *testing.T) {
+	tests := []struct {
+		name    string
+		prompt  Prompt
+		wantErr string
+	}{
+		{
+			name: "matching arguments",
+			prompt: Prompt{
+				Arguments: []PromptArgument{{Name: "topic", Required: true}, {Name: "format", Default: "markdown"}},
+				Body:      "Explain {{args.topic}} as {{ args.format }}. Keep {{args.topic}} short.",
+			},
+		},
+		{name: "no arguments", prompt: Prompt{Body: "Review the code. Literal {{braces}} are fine."}},
+		{
+			name:    "undeclared placeholder",
+			prompt:  Prompt{Body: "Explain {{args.topic}}."},
+			wantErr: "undeclared argument",
+		},
+		{
+			name:    "unused argument",
+			prompt:  Prompt{Arguments: []PromptArgument{{Name: "topic"}}, Body: "Explain."},
+			wantErr: "not used",
+		},
+		{
+			name:    "duplicate argument",
+			prompt:  Prompt{Arguments: []PromptArgument{{Name: "topic"}, {Name: "topic"}}, Body: "{{args.topic}}"},
+			wantErr: "more than once",
+		},
+		{
+			name:    "invalid name",
+			prompt:  Prompt{Arguments: []PromptArgument{{Name: "1topic"}}, Body: "{{args.1topic}}"},
+			wantErr: "invalid argument name",
+		},
+		{
+			name:    "required with default",
+			prompt:  Prompt{Arguments: []PromptArgument{{Name: "topic", Required: true, Default: "go"}}, Body: "{{args.topic}}"},
+			wantErr: "cannot be required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.prompt.ValidateArguments()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ValidateArguments() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ValidateArguments() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPromptRenderBody(t *testing.T) {
+	prompt := Prompt{
+		Arguments: []PromptArgument{{Name: "topic"}, {Name: "format"}},
+		Body:      "Explain {{args.topic}} as {{ args.format }}, then {{args.topic}} again.",
+	}
+
+	if got := prompt.Placeholders(); strings.Join(got, ",") != "topic,format" {
+		t.Errorf("Placeholders() = %v", got)
+	}
+	got := prompt.RenderBody(func(arg *PromptArgument) string { return "<" + arg.Name + ">" })
+	if want := "Explain <topic> as <format>, then <topic> again."; got != want {
+		t.Errorf("RenderBody() = %q, want %q", got, want)
+	}
+}
@@ -10,7 +10,9 @@ type RulesetResource struct {
 
 // RulesetSpec contains the ruleset specification
 type RulesetSpec struct {
-	Rules map[string]Rule `yaml:"rules" validate:"required,min=1"`
+	// Extends references rulesets whose rules are inherited; see parser.ResolveExtends
+	Extends []string        `yaml:"extends,omitempty"`
+	Rules   map[string]Rule `yaml:"rules,omitempty" validate:"required_without=Extends"`
 }
 
 // Promptset represents a Universal Prompt Format file
@@ -45,12 +47,91 @@ type Rule struct {
 
 // Prompt represents a single prompt within a promptset
 type Prompt struct {
-	Name        string `yaml:"name,omitempty"`
+	Name        string           `yaml:"name,omitempty"`
+	Description string           `yaml:"description,omitempty"`
+	Arguments   []PromptArgument `yaml:"arguments,omitempty"`
+	Body        string           `yaml:"body" validate:"required"`
+}
+
+// PromptArgument declares a value the prompt body refers to with {{args.name}}
+type PromptArgument struct {
+	Name        string `yaml:"name"`
 	Description string `yaml:"description,omitempty"`
-	Body        string `yaml:"body" validate:"required"`
+	Required    bool   `yaml:"required,omitempty"`
+	Default     string `yaml:"default,omitempty"`
 }
 
-// Scope defines where a rule applies
+// Scope defines where a rule applies.
+// A rule applies to a file when any of its scope entries match; see Globs for
+// how the fields of a single entry combine.
 type Scope struct {
-	Files []string `yaml:"files"`
+	Files       []string `yaml:"files,omitempty"`
+	Languages   []string `yaml:"languages,omitempty"`
+	Directories []string `yaml:"directories,omitempty"`
+}
+
+// McpsetResource represents a set of MCP server definitions
+type McpsetResource struct {
+	APIVersion string           `yaml:"apiVersion" validate:"required"`
+	Kind       string           `yaml:"kind" validate:"required,eq=Mcpset"`
+	Metadata   ResourceMetadata `yaml:"metadata" validate:"required"`
+	Spec       McpsetSpec       `yaml:"spec" validate:"required"`
+}
+
+// McpsetSpec contains the mcpset specification
+type McpsetSpec struct {
+	Servers map[string]McpServer `yaml:"servers" validate:"required,min=1"`
+}
+
+// McpServer describes how to reach a single MCP server.
+// Local servers set Command (with optional Args and Env); remote servers set URL.
+type McpServer struct {
+	Command string            `yaml:"command,omitempty"`
+	Args    []string          `yaml:"args,omitempty"`
+	Env     map[string]string `yaml:"env,omitempty"`
+	URL     string            `yaml:"url,omitempty"`
+}
+
+// AgentsetResource represents a set of agent (subagent) definitions
+type AgentsetResource struct {
+	APIVersion string           `yaml:"apiVersion" validate:"required"`
+	Kind       string           `yaml:"kind" validate:"required,eq=Agentset"`
+	Metadata   ResourceMetadata `yaml:"metadata" validate:"required"`
+	Spec       AgentsetSpec     `yaml:"spec" validate:"required"`
+}
+
+// AgentsetSpec contains the agentset specification
+type AgentsetSpec struct {
+	Agents map[string]Agent `yaml:"agents" validate:"required,min=1,dive"`
+}
+
+// Agent represents a single agent persona; Body is its system prompt
+type Agent struct {
+	Description string   `yaml:"description" validate:"required"`
+	Model       string   `yaml:"model,omitempty"`
+	Tools       []string `yaml:"tools,omitempty"`
+	Body        string   `yaml:"body" validate:"required"`
+}
+
+// HooksetResource represents a set of agent lifecycle hooks
+type HooksetResource struct {
+	APIVersion string           `yaml:"apiVersion" validate:"required"`
+	Kind       string           `yaml:"kind" validate:"required,eq=Hookset"`
+	Metadata   ResourceMetadata `yaml:"metadata" validate:"required"`
+	Spec       HooksetSpec      `yaml:"spec" validate:"required"`
+}
+
+// HooksetSpec contains the hookset specification
+type HooksetSpec struct {
+	Hooks map[string]Hook `yaml:"hooks" validate:"required,min=1,dive"`
+}
+
+// Hook runs a command when an agent event occurs. Matcher narrows the event:
+// a tool name pattern for tool events, a file glob for post-edit.
+type Hook struct {
+	Description string `yaml:"description,omitempty"`
+	Event       string `yaml:"event" validate:"required,oneof=session-start user-prompt-submit pre-tool-use post-tool-use post-edit stop"`
+	Matcher     string `yaml:"matcher,omitempty"`
+	Command     string `yaml:"command" validate:"required"`
+	Timeout     int    `yaml:"timeout,omitempty" validate:"gte=0"`
 }
@@ -0,0 +1,146 @@
+package resource
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// languageExtensions maps scope language names to the file extensions they cover
+var languageExtensions = map[string][]string{
+	"c":          {"c", "h"},
+	"cpp":        {"cpp", "cc", "cxx", "hpp", "hh", "hxx"},
+	"csharp":     {"cs"},
+	"css":        {"css", "scss", "sass", "less"},
+	"dart":       {"dart"},
+	"go":         {"go"},
+	"html":       {"html", "htm"},
+	"java":       {"java"},
+	"javascript": {"js", "jsx", "mjs", "cjs"},
+	"json":       {"json"},
+	"kotlin":     {"kt", "kts"},
+	"lua":        {"lua"},
+	"markdown":   {"md", "mdx"},
+	"php":        {"php"},
+	"python":     {"py", "pyi"},
+	"ruby":       {"rb"},
+	"rust":       {"rs"},
+	"scala":      {"scala"},
+	"shell":      {"sh", "bash", "zsh"},
+	"sql":        {"sql"},
+	"swift":      {"swift"},
+	"terraform":  {"tf", "tfvars"},
+	"typescript": {"ts", "tsx", "mts", "cts"},
+	"yaml":       {"yml", "yaml"},
+}
+
+// Languages returns the language names scopes may refer to, sorted
+func Languages() []string {
+	names := make([]string, 0, len(languageExtensions))
+	for name := range languageExtensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate checks that the scope only refers to known languages and relative
+// directories, and that its files and languages have files in common
+func (s Scope) Validate() error {
+	for _, lang := range s.Languages {
+		if _, ok := languageExtensions[strings.ToLower(lang)]; !ok {
+			return fmt.Errorf("unknown scope language %q (known: %s)", lang, strings.Join(Languages(), ", "))
+		}
+	}
+	for _, dir := range s.Directories {
+		if dir == "" || path.IsAbs(dir) || strings.HasPrefix(path.Clean(dir), "..") {
+			return fmt.Errorf("scope directory %q must be a relative path inside the project", dir)
+		}
+	}
+	if len(s.Globs()) == 0 {
+		return fmt.Errorf("scope matches no files: files %s are not written in %s",
+			strings.Join(s.Files, ", "), strings.Join(s.Languages, ", "))
+	}
+	return nil
+}
+
+// Globs expands the scope entry into file globs relative to the project root.
+// The fields of one entry narrow each other: a file matches when it is under one
+// of Directories, matches one of Files and is written in one of Languages. Empty
+// fields do not narrow, so an empty entry matches every file.
+func (s Scope) Globs() []string {
+	patterns := s.Files
+	if len(patterns) == 0 {
+		patterns = []string{"**"}
+	}
+
+	if len(s.Languages) > 0 {
+		var narrowed []string
+		for _, pattern := range patterns {
+			narrowed = append(narrowed, narrowToLanguages(pattern, s.Languages)...)
+		}
+		patterns = narrowed
+	}
+
+	if len(s.Directories) > 0 {
+		var scoped []string
+		for _, dir := range s.Directories {
+			for _, pattern := range patterns {
+				scoped = append(scoped, path.Join(path.Clean(dir), pattern))
+			}
+		}
+		patterns = scoped
+	}
+
+	return patterns
+}
+
+// ScopeGlobs returns the union of globs of all scope entries, without duplicates
+// and in declaration order. It returns nil when the rule is unscoped.
+func ScopeGlobs(scopes []Scope) []string {
+	var globs []string
+	seen := make(map[string]bool)
+	for _, scope := range scopes {
+		for _, glob := range scope.Globs() {
+			if !seen[glob] {
+				seen[glob] = true
+				globs = append(globs, glob)
+			}
+		}
+	}
+	return globs
+}
+
+// narrowToLanguages intersects a glob with the extensions of the given languages.
+// Directory wildcards gain an extension, globs with an extension are kept only if
+// the extension belongs to a language, and anything else is kept unchanged.
+func narrowToLanguages(pattern string, languages []string) []string {
+	var exts []string
+	for _, lang := range languages {
+		exts = append(exts, languageExtensions[strings.ToLower(lang)]...)
+	}
+
+	var result []string
+	switch {
+	case pattern == "**" || strings.HasSuffix(pattern, "/**"):
+		for _, ext := range exts {
+			result = append(result, pattern+"/*."+ext)
+		}
+	case strings.HasSuffix(pattern, "*"):
+		for _, ext := range exts {
+			result = append(result, pattern+"."+ext)
+		}
+	default:
+		ext := strings.TrimPrefix(path.Ext(pattern), ".")
+		if ext == "" {
+			return []string{pattern}
+		}
+		for _, e := range exts {
+			if e == ext {
+				return []string{pattern}
+			}
+		}
+	}
+	return result
+}
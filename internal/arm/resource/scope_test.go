@@ -0,0 +1,96 @@
+package resource
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScope_Globs(t *testing.T) {
+	tests := []struct {
+		name  string
+		scope Scope
+		want  []string
+	}{
+		{
+			name:  "files only",
+			scope: Scope{Files: []string{"**/*.go", "Makefile"}},
+			want:  []string{"**/*.go", "Makefile"},
+		},
+		{
+			name:  "empty scope matches everything",
+			scope: Scope{},
+			want:  []string{"**"},
+		},
+		{
+			name:  "language only",
+			scope: Scope{Languages: []string{"typescript"}},
+			want:  []string{"**/*.ts", "**/*.tsx", "**/*.mts", "**/*.cts"},
+		},
+		{
+			name:  "directory only",
+			scope: Scope{Directories: []string{"src/api/"}},
+			want:  []string{"src/api/**"},
+		},
+		{
+			name:  "directory and language",
+			scope: Scope{Directories: []string{"cmd", "internal"}, Languages: []string{"go"}},
+			want:  []string{"cmd/**/*.go", "internal/**/*.go"},
+		},
+		{
+			name:  "files narrowed by language",
+			scope: Scope{Files: []string{"src/*", "**/*.py", "**/*.go", "Dockerfile"}, Languages: []string{"go"}},
+			want:  []string{"src/*.go", "**/*.go", "Dockerfile"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.scope.Globs()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Globs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScopeGlobs_Union(t *testing.T) {
+	scopes := []Scope{
+		{Files: []string{"**/*.go"}},
+		{Languages: []string{"go"}},
+		{Directories: []string{"docs"}},
+	}
+
+	want := []string{"**/*.go", "docs/**"}
+	if got := ScopeGlobs(scopes); !reflect.DeepEqual(got, want) {
+		t.Errorf("ScopeGlobs() = %v, want %v", got, want)
+	}
+
+	if got := ScopeGlobs(nil); got != nil {
+		t.Errorf("ScopeGlobs(nil) = %v, want nil", got)
+	}
+}
+
+func TestScope_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		scope   Scope
+		wantErr bool
+	}{
+		{"known language", Scope{Languages: []string{"Go"}}, false},
+		{"unknown language", Scope{Languages: []string{"cobol"}}, true},
+		{"relative directory", Scope{Directories: []string{"src"}}, false},
+		{"absolute directory", Scope{Directories: []string{"/etc"}}, true},
+		{"escaping directory", Scope{Directories: []string{"../other"}}, true},
+		{"files in language", Scope{Files: []string{"**/*.py", "Makefile"}, Languages: []string{"go"}}, false},
+		{"files outside language", Scope{Files: []string{"**/*.py"}, Languages: []string{"go"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.scope.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package resource
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// SkillFilename is the file that marks a directory as an Agent Skill
+const SkillFilename = "SKILL.md"
+
+// Skill is the frontmatter of an Agent Skills SKILL.md file
+type Skill struct {
+	Name          string            `yaml:"name"`
+	Description   string            `yaml:"description"`
+	License       string            `yaml:"license,omitempty"`
+	Compatibility string            `yaml:"compatibility,omitempty"`
+	Metadata      map[string]string `yaml:"metadata,omitempty"`
+	AllowedTools  string            `yaml:"allowed-tools,omitempty"`
+}
+
+// skillNamePattern allows lowercase alphanumeric words joined by single hyphens
+var skillNamePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Validate checks the frontmatter against the Agent Skills specification
+func (s Skill) Validate() error {
+	switch {
+	case s.Name == "":
+		return errors.New("name is required")
+	case utf8.RuneCountInString(s.Name) > 64:
+		return errors.New("name must be at most 64 characters")
+	case !skillNamePattern.MatchString(s.Name):
+		return fmt.Errorf("name %q may only contain lowercase letters, numbers and single hyphens, and must not start or end with a hyphen", s.Name)
+	}
+
+	switch {
+	case strings.TrimSpace(s.Description) == "":
+		return errors.New("description is required")
+	case utf8.RuneCountInString(s.Description) > 1024:
+		return errors.New("description must be at most 1024 characters")
+	}
+
+	if utf8.RuneCountInString(s.Compatibility) > 500 {
+		return errors.New("compatibility must be at most 500 characters")
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package resource
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSkill_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		skill   Skill
+		wantErr bool
+	}{
+		{"valid", Skill{Name: "pdf-processing", Description: "Extract text from PDFs."}, false},
+		{"digits", Skill{Name: "v2-tools", Description: "Tools."}, false},
+		{"missing name", Skill{Description: "Tools."}, true},
+		{"uppercase", Skill{Name: "PDF-Processing", Description: "Tools."}, true},
+		{"leading hyphen", Skill{Name: "-pdf", Description: "Tools."}, true},
+		{"trailing hyphen", Skill{Name: "pdf-", Description: "Tools."}, true},
+		{"consecutive hyphens", Skill{Name: "pdf--processing", Description: "Tools."}, true},
+		{"name too long", Skill{Name: strings.Repeat("a", 65), Description: "Tools."}, true},
+		{"missing description", Skill{Name: "pdf", Description: "  "}, true},
+		{"description too long", Skill{Name: "pdf", Description: strings.Repeat("a", 1025)}, true},
+		{"compatibility too long", Skill{Name: "pdf", Description: "Tools.", Compatibility: strings.Repeat("a", 501)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.skill.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
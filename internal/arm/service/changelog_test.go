@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jomadu/ai-resource-manager/internal/arm/core"
+	"github.com/jomadu/ai-resource-manager/internal/arm/manifest"
+	"github.com/jomadu/ai-resource-manager/internal/arm/packagelockfile"
+)
+
+// taggedRegistry is a mock registry whose versions carry tag annotations
+type taggedRegistry struct {
+	*mockRegistry
+	messages map[string]string
+}
+
+func (r *taggedRegistry) GetTagMessage(ctx context.Context, version *core.Version) (string, error) {
+	return r.messages[version.Version], nil
+}
+
+func TestChangelog(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	manifestPath := filepath.Join(dir, "arm.json")
+	manifestJSON := `{"version": 1, "registries": {"test-registry": {"type": "git", "url": "https://github.com/test/repo"}}}`
+	if err := os.WriteFile(manifestPath, []byte(manifestJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	newPackage := func(version string, files ...*core.File) *core.Package {
+		v, _ := core.NewVersion(version)
+		return &core.Package{
+			Metadata: core.PackageMetadata{RegistryName: "test-registry", Name: "rules", Version: v},
+			Files:    files,
+		}
+	}
+	v1 := newPackage("1.0.0")
+	v2 := newPackage("2.0.0",
+		&core.File{Path: "docs/CHANGELOG.md", Content: []byte("# Docs changelog")},
+		&core.File{Path: "CHANGELOG.md", Content: []byte("# Changelog\n\n## 2.0.0\n\n- Require descriptive names\n")},
+	)
+	reg := &taggedRegistry{
+		mockRegistry: &mockRegistry{
+			versions: map[string][]core.PackageMetadata{"rules": {v2.Metadata, v1.Metadata}},
+			packages: map[string]*core.Package{"rules@1.0.0": v1, "rules@2.0.0": v2},
+		},
+		messages: map[string]string{"1.0.0": "First release"},
+	}
+	svc := NewArmService(manifest.NewFileManagerWithPath(manifestPath), packagelockfile.NewFileManagerWithPath(filepath.Join(dir, "arm-lock.json")), &mockRegistryFactory{registry: reg})
+
+	tests := []struct {
+		name    string
+		version string
+		want    string
+	}{
+		{"root changelog", "2.0.0", "# Changelog\n\n## 2.0.0\n\n- Require descriptive names"},
+		{"tag annotation", "1.0.0", "First release"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := svc.Changelog(ctx, "test-registry", "rules", tt.version)
+			if err != nil {
+				t.Fatalf("Changelog() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Changelog() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := svc.Changelog(ctx, "test-registry", "rules", "3.0.0"); err == nil {
+		t.Error("Changelog() of an unavailable version should fail")
+	}
+}
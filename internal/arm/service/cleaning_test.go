@@ -112,6 +112,87 @@ func TestNukeCache(t *testing.T) {
 	}
 }
 
+func TestPlanNukeCache(t *testing.T) {
+	ctx := context.Background()
+	tempDir := t.TempDir()
+	storageDir := filepath.Join(tempDir, ".arm", "storage")
+
+	// A registry with a cached version and one with only its metadata
+	reg, err := storage.NewRegistryWithPath(filepath.Join(tempDir, ".arm"), map[string]interface{}{"url": "https://example.com", "type": "git"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	packageCache := storage.NewPackageCache(reg.GetPackagesDir())
+	version := core.Version{Major: 1, Minor: 0, Patch: 0}
+	if err := packageCache.SetPackageVersion(ctx, map[string]interface{}{"name": "test-package"}, &version, []*core.File{
+		{Path: "test.txt", Content: []byte("test")},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := storage.NewRegistryWithPath(filepath.Join(tempDir, ".arm"), map[string]interface{}{"url": "https://example.org", "type": "git"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(storageDir, "registries", "stale.lock"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := planNukeCacheWithPath(storageDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	planned := make(map[string]bool)
+	for _, file := range plan.Files {
+		if file.Action != PlanDelete {
+			t.Errorf("%s planned as %s, want %s", file.Path, file.Action, PlanDelete)
+		}
+		planned[file.Path] = true
+	}
+
+	// Everything nuking removes is planned, itself or through a planned directory
+	// below the storage directory
+	if !planned[storageDir] {
+		t.Error("plan should list the storage directory")
+	}
+	var removed []string
+	if err := filepath.WalkDir(storageDir, func(path string, d os.DirEntry, err error) error {
+		removed = append(removed, path)
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range removed[1:] {
+		covered := false
+		for dir := path; dir != storageDir; dir = filepath.Dir(dir) {
+			if planned[dir] {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			t.Errorf("%s is removed by nuking but not planned", path)
+		}
+	}
+	if !planned[filepath.Join(storageDir, "registries", "stale.lock")] {
+		t.Error("plan should list lock files")
+	}
+
+	service := &ArmService{}
+	if err := service.nukeCacheWithPath(ctx, storageDir); err != nil {
+		t.Fatal(err)
+	}
+	for path := range planned {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s was planned but not removed", path)
+		}
+	}
+
+	// Nothing to plan once the cache is gone
+	plan, err = planNukeCacheWithPath(storageDir)
+	if err != nil || len(plan.Files) != 0 {
+		t.Errorf("expected an empty plan for a missing cache, got %+v, %v", plan, err)
+	}
+}
+
 // Helper functions
 func mustGenerateKey(obj interface{}) string {
 	key, err := storage.GenerateKey(obj)
@@ -171,7 +252,7 @@ func TestCleanSinks(t *testing.T) {
 			{Path: "test.md", Content: []byte("test content")},
 		},
 	}
-	if err := sinkMgr.InstallRuleset(pkg, 100); err != nil {
+	if err := sinkMgr.InstallRuleset(pkg, 100, nil, nil, nil); err != nil {
 		t.Fatal(err)
 	}
 
@@ -243,12 +324,12 @@ func TestNukeSinks(t *testing.T) {
 			{Path: "test.md", Content: []byte("test content")},
 		},
 	}
-	if err := cursorMgr.InstallRuleset(pkg, 100); err != nil {
+	if err := cursorMgr.InstallRuleset(pkg, 100, nil, nil, nil); err != nil {
 		t.Fatal(err)
 	}
 
 	copilotMgr := sink.NewManager(copilotDir, compiler.Copilot)
-	if err := copilotMgr.InstallRuleset(pkg, 100); err != nil {
+	if err := copilotMgr.InstallRuleset(pkg, 100, nil, nil, nil); err != nil {
 		t.Fatal(err)
 	}
 
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-manager/internal/arm/core"
+	"github.com/jomadu/ai-resource-manager/internal/arm/manifest"
+	"github.com/jomadu/ai-resource-manager/internal/arm/packagelockfile"
+)
+
+func TestDiffPackage(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	manifestPath := filepath.Join(dir, "arm.json")
+	lockfilePath := filepath.Join(dir, "arm-lock.json")
+	manifestJSON := `{
+  "version": 1,
+  "registries": {"test-registry": {"type": "git", "url": "https://github.com/test/repo"}},
+  "dependencies": {"test-registry/rules": {"type": "ruleset", "version": "^1.0.0", "sinks": []}}
+}`
+	if err := os.WriteFile(manifestPath, []byte(manifestJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(lockfilePath, []byte(`{"version": 1, "dependencies": {"test-registry/rules@1.0.0": {"integrity": "hash1"}}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	newPackage := func(version, rules, prompts string) *core.Package {
+		v, _ := core.NewVersion(version)
+		return &core.Package{
+			Metadata: core.PackageMetadata{RegistryName: "test-registry", Name: "rules", Version: v},
+			Files: []*core.File{
+				{Path: "rules.yml", Content: []byte("apiVersion: v1\nkind: Ruleset\nmetadata:\n  id: team\nspec:\n  rules:\n" + rules)},
+				{Path: "prompts.yml", Content: []byte("apiVersion: v1\nkind: Promptset\nmetadata:\n  id: review\nspec:\n  prompts:\n" + prompts)},
+				// Documentation is not a rule, so changelog edits are not reported
+				{Path: "CHANGELOG.md", Content: []byte("# Changelog\n\n## " + version + "\n")},
+			},
+		}
+	}
+	v1 := newPackage("1.0.0", `    naming:
+      enforcement: should
+      body: |
+        Use descriptive names.
+        Avoid abbreviations.
+    legacy:
+      body: Old advice.
+    docs:
+      body: Document exported functions.
+`, `    security:
+      body: Review for security issues.
+`)
+	v2 := newPackage("2.0.0", `    naming:
+      enforcement: must
+      scope:
+        - files: ["**/*.go"]
+      body: |
+        Use descriptive names.
+        Spell words out.
+    docs:
+      body: Document exported functions.
+    tests:
+      priority: 10
+      body: Write tests.
+`, `    security:
+      body: Review for security issues.
+`)
+	mockReg := &mockRegistry{
+		versions: map[string][]core.PackageMetadata{"rules": {v2.Metadata, v1.Metadata}},
+		packages: map[string]*core.Package{"rules@1.0.0": v1, "rules@2.0.0": v2},
+	}
+	svc := NewArmService(manifest.NewFileManagerWithPath(manifestPath), packagelockfile.NewFileManagerWithPath(lockfilePath), &mockRegistryFactory{registry: mockReg})
+
+	diff, err := svc.DiffPackage(ctx, "test-registry", "rules", "", "")
+	if err != nil {
+		t.Fatalf("DiffPackage() error = %v", err)
+	}
+	if diff.Package != "test-registry/rules" || diff.From != "1.0.0" || diff.To != "2.0.0" {
+		t.Errorf("DiffPackage() = %s %s..%s, want test-registry/rules 1.0.0..2.0.0", diff.Package, diff.From, diff.To)
+	}
+	if len(diff.Prompts) != 0 {
+		t.Errorf("Prompts = %+v, want no changes", diff.Prompts)
+	}
+
+	want := []ResourceDiff{
+		{ID: "team/legacy", Action: DiffRemoved, Body: []core.DiffLine{{Op: core.DiffDelete, Text: "Old advice."}}},
+		{
+			ID:     "team/naming",
+			Action: DiffChanged,
+			Fields: []FieldChange{
+				{Field: "enforcement", Old: "should", New: "must"},
+				{Field: "scope", Old: "", New: "**/*.go"},
+			},
+			Body: []core.DiffLine{
+				{Op: core.DiffEqual, Text: "Use descriptive names."},
+				{Op: core.DiffDelete, Text: "Avoid abbreviations."},
+				{Op: core.DiffInsert, Text: "Spell words out."},
+			},
+		},
+		{
+			ID:     "team/tests",
+			Action: DiffAdded,
+			Fields: []FieldChange{{Field: "priority", Old: "", New: "10"}},
+			Body:   []core.DiffLine{{Op: core.DiffInsert, Text: "Write tests."}},
+		},
+	}
+	if !reflect.DeepEqual(diff.Rules, want) {
+		t.Errorf("Rules = %+v\nwant %+v", diff.Rules, want)
+	}
+
+	// Explicit versions are compared in either direction
+	diff, err = svc.DiffPackage(ctx, "test-registry", "rules", "2.0.0", "1.0.0")
+	if err != nil {
+		t.Fatalf("DiffPackage() error = %v", err)
+	}
+	if len(diff.Rules) != 3 || diff.Rules[0].Action != DiffAdded || diff.Rules[2].Action != DiffRemoved {
+		t.Errorf("Rules = %+v, want legacy added and tests removed", diff.Rules)
+	}
+
+	if _, err := svc.DiffPackage(ctx, "test-registry", "other", "", ""); err == nil || !strings.Contains(err.Error(), "is not installed") {
+		t.Errorf("DiffPackage() of an uninstalled package error = %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jomadu/ai-resource-manager/internal/arm/compiler"
+	"github.com/jomadu/ai-resource-manager/internal/arm/core"
+	"github.com/jomadu/ai-resource-manager/internal/arm/manifest"
+	"github.com/jomadu/ai-resource-manager/internal/arm/packagelockfile"
+	"github.com/jomadu/ai-resource-manager/internal/arm/registry"
+)
+
+// countingRegistry records how many packages are fetched at once
+type countingRegistry struct {
+	mockRegistry
+	mu      sync.Mutex
+	active  int
+	maxSeen int
+}
+
+func (r *countingRegistry) GetPackage(ctx context.Context, packageName string, version *core.Version, include, exclude []string) (*core.Package, error) {
+	r.mu.Lock()
+	r.active++
+	r.maxSeen = max(r.maxSeen, r.active)
+	r.mu.Unlock()
+
+	time.Sleep(20 * time.Millisecond)
+
+	r.mu.Lock()
+	r.active--
+	r.mu.Unlock()
+	return r.mockRegistry.GetPackage(ctx, packageName, version, include, exclude)
+}
+
+type countingRegistryFactory struct {
+	registry registry.Registry
+	created  map[string]int
+}
+
+func (f *countingRegistryFactory) CreateRegistry(name string, config map[string]interface{}) (registry.Registry, error) {
+	f.created[name]++
+	return f.registry, nil
+}
+
+func TestInstallAllFetchesConcurrently(t *testing.T) {
+	ctx := context.Background()
+	const packages = 6
+
+	reg := &countingRegistry{mockRegistry: mockRegistry{
+		versions: make(map[string][]core.PackageMetadata),
+		packages: make(map[string]*core.Package),
+	}}
+	dependencies := make(map[string]map[string]interface{})
+	for i := 0; i < packages; i++ {
+		name := fmt.Sprintf("ruleset%d", i)
+		version := core.Version{Major: 1, Version: "1.0.0", IsSemver: true}
+		metadata := core.PackageMetadata{RegistryName: "test-registry", Name: name, Version: version}
+		reg.versions[name] = []core.PackageMetadata{metadata}
+		reg.packages[name+"@1.0.0"] = &core.Package{
+			Metadata: metadata,
+			Files: []*core.File{{Path: "rules.yml", Content: []byte(fmt.Sprintf(`apiVersion: v1
+kind: Ruleset
+metadata:
+  id: %s
+spec:
+  rules:
+    rule:
+      body: Rule %d.
+`, name, i))}},
+			Integrity: "hash",
+		}
+
+		data, _ := json.Marshal(manifest.RulesetDependencyConfig{
+			BaseDependencyConfig: manifest.BaseDependencyConfig{Type: manifest.ResourceTypeRuleset, Version: "^1.0.0", Sinks: []string{"test-sink"}},
+			Priority:             100 + i,
+		})
+		var config map[string]interface{}
+		_ = json.Unmarshal(data, &config)
+		dependencies["test-registry/"+name] = config
+	}
+
+	manifestMgr := &mockManifestManager{manifest: &manifest.Manifest{
+		Registries: map[string]map[string]interface{}{
+			"test-registry": {"type": "git", "url": "https://github.com/test/repo"},
+		},
+		Sinks: map[string]manifest.SinkConfig{
+			"test-sink": {Directory: t.TempDir(), Tool: compiler.Cursor},
+		},
+		Dependencies: dependencies,
+	}}
+	lockfileMgr := &mockLockfileManager{locks: make(map[string]*packagelockfile.DependencyLockConfig)}
+	factory := &countingRegistryFactory{registry: reg, created: make(map[string]int)}
+
+	svc := NewArmService(manifestMgr, lockfileMgr, factory)
+	if err := svc.SetJobs(3); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.InstallAll(ctx); err != nil {
+		t.Fatalf("InstallAll() error = %v", err)
+	}
+
+	if len(lockfileMgr.locks) != packages {
+		t.Errorf("locked %d packages, want %d", len(lockfileMgr.locks), packages)
+	}
+	if reg.maxSeen < 2 || reg.maxSeen > 3 {
+		t.Errorf("fetched up to %d packages at once, want 2 to 3", reg.maxSeen)
+	}
+	if factory.created["test-registry"] != 1 {
+		t.Errorf("created test-registry %d times, want once", factory.created["test-registry"])
+	}
+}
+
+func TestSetJobs(t *testing.T) {
+	svc := NewArmService(nil, nil, nil)
+	if svc.jobs != DefaultJobs {
+		t.Errorf("jobs = %d, want %d", svc.jobs, DefaultJobs)
+	}
+	if err := svc.SetJobs(0); err == nil {
+		t.Error("SetJobs(0) should fail")
+	}
+	if err := svc.SetJobs(8); err != nil || svc.jobs != 8 {
+		t.Errorf("SetJobs(8) = %v, jobs = %d", err, svc.jobs)
+	}
+}
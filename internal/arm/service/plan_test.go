@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jomadu/ai-resource-manager/internal/arm/compiler"
+	"github.com/jomadu/ai-resource-manager/internal/arm/core"
+	"github.com/jomadu/ai-resource-manager/internal/arm/manifest"
+	"github.com/jomadu/ai-resource-manager/internal/arm/packagelockfile"
+	"github.com/jomadu/ai-resource-manager/internal/arm/sink"
+)
+
+func TestPlanChanges(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	sinkDir := filepath.Join(dir, "rules")
+	manifestPath := filepath.Join(dir, "arm.json")
+	lockfilePath := filepath.Join(dir, "arm-lock.json")
+
+	manifestJSON := `{
+  "version": 1,
+  "registries": {"test-registry": {"type": "git", "url": "https://github.com/test/repo"}},
+  "sinks": {"test-sink": {"directory": "` + sinkDir + `", "tool": "cursor"}},
+  "dependencies": {
+    "test-registry/ruleset1": {"type": "ruleset", "version": "^1.0.0", "priority": 100, "sinks": ["test-sink"]}
+  }
+}`
+	lockJSON := `{"version": 1, "dependencies": {"test-registry/ruleset1@1.0.0": {"integrity": "hash1"}}}`
+	if err := os.WriteFile(manifestPath, []byte(manifestJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(lockfilePath, []byte(lockJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	newPackage := func(version string, integrity string) *core.Package {
+		v, _ := core.NewVersion(version)
+		return &core.Package{
+			Metadata: core.PackageMetadata{RegistryName: "test-registry", Name: "ruleset1", Version: v},
+			Files: []*core.File{{Path: "rules.yml", Content: []byte(`apiVersion: v1
+kind: Ruleset
+metadata:
+  id: testing
+spec:
+  rules:
+    run-tests:
+      body: Run the tests.
+`)}},
+			Integrity: integrity,
+		}
+	}
+	v1, v2 := newPackage("1.0.0", "hash1"), newPackage("1.1.0", "hash2")
+	mockReg := &mockRegistry{
+		versions: map[string][]core.PackageMetadata{"ruleset1": {v1.Metadata, v2.Metadata}},
+		packages: map[string]*core.Package{"ruleset1@1.0.0": v1, "ruleset1@1.1.0": v2},
+	}
+	if err := sink.NewManager(sinkDir, compiler.Cursor).InstallRuleset(v1, 100, nil, nil, nil); err != nil {
+		t.Fatalf("InstallRuleset() error = %v", err)
+	}
+	before, err := readDirFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	svc := NewArmService(manifest.NewFileManagerWithPath(manifestPath), packagelockfile.NewFileManagerWithPath(lockfilePath), &mockRegistryFactory{registry: mockReg})
+	plan, err := svc.PlanChanges(ctx, manifestPath, lockfilePath, func(ctx context.Context, sandbox *ArmService) error {
+		return sandbox.UpdateAll(ctx)
+	})
+	if err != nil {
+		t.Fatalf("PlanChanges() error = %v", err)
+	}
+
+	if len(plan.Packages) != 1 || plan.Packages[0] != (PlannedPackage{Package: "test-registry/ruleset1", From: "1.0.0", To: "1.1.0"}) {
+		t.Errorf("Packages = %+v", plan.Packages)
+	}
+
+	actions := make(map[string]string)
+	for _, file := range plan.Files {
+		if file.Sink != "test-sink" {
+			t.Errorf("file %s planned for sink %q", file.Path, file.Sink)
+		}
+		actions[file.Path] = file.Action
+	}
+	oldRule := filepath.Join(sinkDir, "arm", "test-registry", "ruleset1", "1.0.0", "testing_run-tests.mdc")
+	newRule := filepath.Join(sinkDir, "arm", "test-registry", "ruleset1", "1.1.0", "testing_run-tests.mdc")
+	if actions[oldRule] != PlanDelete || actions[newRule] != PlanCreate || actions[filepath.Join(sinkDir, "arm", "arm-index.json")] != PlanModify {
+		t.Errorf("Files = %+v", plan.Files)
+	}
+
+	lockChanges := make(map[string]PlannedChange)
+	for _, change := range plan.Lockfile {
+		lockChanges[change.Path] = change
+	}
+	if lockChanges[`dependencies["test-registry/ruleset1@1.0.0"].integrity`].Action != PlanDelete ||
+		lockChanges[`dependencies["test-registry/ruleset1@1.1.0"].integrity`] != (PlannedChange{Path: `dependencies["test-registry/ruleset1@1.1.0"].integrity`, Action: PlanCreate, New: `"hash2"`}) {
+		t.Errorf("Lockfile = %+v", plan.Lockfile)
+	}
+	if len(plan.Manifest) != 0 {
+		t.Errorf("Manifest = %+v, want no changes", plan.Manifest)
+	}
+
+	// Nothing in the project was written
+	after, err := readDirFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(after) != len(before) {
+		t.Fatalf("project files changed: %d before, %d after", len(before), len(after))
+	}
+	for path, content := range before {
+		if string(after[path]) != string(content) {
+			t.Errorf("%s was modified by the dry run", path)
+		}
+	}
+}
+
+func TestPlanChangesReportsManifestChanges(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	manifestPath := filepath.Join(dir, "arm.json")
+	if err := os.WriteFile(manifestPath, []byte(`{"version": 1, "sinks": {"old": {"directory": "`+filepath.Join(dir, "old")+`", "tool": "cursor"}}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := NewArmService(manifest.NewFileManagerWithPath(manifestPath), nil, nil)
+	plan, err := svc.PlanChanges(ctx, manifestPath, filepath.Join(dir, "arm-lock.json"), func(ctx context.Context, sandbox *ArmService) error {
+		return sandbox.SetSinkTool(ctx, "old", compiler.Copilot)
+	})
+	if err != nil {
+		t.Fatalf("PlanChanges() error = %v", err)
+	}
+
+	want := PlannedChange{Path: "sinks.old.tool", Action: PlanModify, Old: `"cursor"`, New: `"copilot"`}
+	if len(plan.Manifest) != 1 || plan.Manifest[0] != want {
+		t.Errorf("Manifest = %+v, want %+v", plan.Manifest, want)
+	}
+}
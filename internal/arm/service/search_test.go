@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jomadu/ai-resource-manager/internal/arm/core"
+	"github.com/jomadu/ai-resource-manager/internal/arm/manifest"
+	"github.com/jomadu/ai-resource-manager/internal/arm/packagelockfile"
+	"github.com/jomadu/ai-resource-manager/internal/arm/registry"
+)
+
+// listingRegistry is a mock registry that enumerates its packages
+type listingRegistry struct {
+	*mockRegistry
+	listed []*core.PackageMetadata
+	err    error
+}
+
+func (r *listingRegistry) ListPackages(ctx context.Context) ([]*core.PackageMetadata, error) {
+	return r.listed, r.err
+}
+
+// namedRegistryFactory returns a different registry for each registry name
+type namedRegistryFactory map[string]registry.Registry
+
+func (f namedRegistryFactory) CreateRegistry(name string, config map[string]interface{}) (registry.Registry, error) {
+	return f[name], nil
+}
+
+func TestSearch(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	manifestPath := filepath.Join(dir, "arm.json")
+	manifestJSON := `{
+  "version": 1,
+  "registries": {
+    "team": {"type": "cloudsmith", "url": "https://api.cloudsmith.io", "owner": "org", "repository": "team"},
+    "public": {"type": "gitlab", "url": "https://gitlab.com", "projectId": "1"},
+    "rules": {"type": "git", "url": "https://github.com/org/rules"}
+  }
+}`
+	if err := os.WriteFile(manifestPath, []byte(manifestJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	v1, _ := core.NewVersion("1.0.0")
+	v2, _ := core.NewVersion("2.0.0")
+	team := &listingRegistry{
+		mockRegistry: &mockRegistry{versions: map[string][]core.PackageMetadata{
+			"security":   {{Version: v1}, {Version: v2}},
+			"clean-code": {{Version: v1}},
+		}},
+		listed: []*core.PackageMetadata{
+			{Name: "security", Description: "Rules for secure code"},
+			{Name: "clean-code", Description: "Naming and structure"},
+			{Name: "review", Description: "Code review prompts"},
+		},
+	}
+	public := &listingRegistry{
+		mockRegistry: &mockRegistry{versions: map[string][]core.PackageMetadata{"go-style": {{Version: v1}}}},
+		listed:       []*core.PackageMetadata{{Name: "go-style"}},
+	}
+	factory := namedRegistryFactory{"team": team, "public": public, "rules": &mockRegistry{}}
+	svc := NewArmService(manifest.NewFileManagerWithPath(manifestPath), packagelockfile.NewFileManagerWithPath(filepath.Join(dir, "arm-lock.json")), factory)
+
+	tests := []struct {
+		name       string
+		query      string
+		registries []string
+		want       []SearchResult
+	}{
+		{
+			name:  "name and description",
+			query: "CODE",
+			want: []SearchResult{
+				{Registry: "team", Package: "clean-code", Latest: "1.0.0", Description: "Naming and structure"},
+				{Registry: "team", Package: "review", Description: "Code review prompts"},
+				{Registry: "team", Package: "security", Latest: "2.0.0", Description: "Rules for secure code"},
+			},
+		},
+		{
+			name:  "across registries",
+			query: "s",
+			want: []SearchResult{
+				{Registry: "public", Package: "go-style", Latest: "1.0.0"},
+				{Registry: "team", Package: "clean-code", Latest: "1.0.0", Description: "Naming and structure"},
+				{Registry: "team", Package: "review", Description: "Code review prompts"},
+				{Registry: "team", Package: "security", Latest: "2.0.0", Description: "Rules for secure code"},
+			},
+		},
+		{
+			name:       "registry filter",
+			query:      "s",
+			registries: []string{"public"},
+			want:       []SearchResult{{Registry: "public", Package: "go-style", Latest: "1.0.0"}},
+		},
+		{
+			name:  "no match",
+			query: "python",
+			want:  []SearchResult{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := svc.Search(ctx, tt.query, tt.registries)
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			results := []SearchResult{}
+			for _, result := range got {
+				results = append(results, *result)
+			}
+			if !reflect.DeepEqual(results, tt.want) {
+				t.Errorf("Search() = %+v, want %+v", results, tt.want)
+			}
+		})
+	}
+
+	if _, err := svc.Search(ctx, "s", []string{"missing"}); err == nil {
+		t.Error("Search() of an unknown registry should fail")
+	}
+
+	// A registry that cannot be listed only fails the search if no other registry could be
+	team.err = errors.New("unauthorized")
+	got, err := svc.Search(ctx, "s", nil)
+	if err != nil || len(got) != 1 || got[0].Package != "go-style" {
+		t.Errorf("Search() = %+v, %v, want go-style only", got, err)
+	}
+	if _, err := svc.Search(ctx, "s", []string{"team"}); err == nil {
+		t.Error("Search() should fail when every registry fails")
+	}
+}
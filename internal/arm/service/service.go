@@ -1,12 +1,19 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/jomadu/ai-resource-manager/internal/arm/compiler"
@@ -16,8 +23,10 @@ import (
 	"github.com/jomadu/ai-resource-manager/internal/arm/packagelockfile"
 	"github.com/jomadu/ai-resource-manager/internal/arm/parser"
 	"github.com/jomadu/ai-resource-manager/internal/arm/registry"
+	"github.com/jomadu/ai-resource-manager/internal/arm/resource"
 	"github.com/jomadu/ai-resource-manager/internal/arm/sink"
 	"github.com/jomadu/ai-resource-manager/internal/arm/storage"
+	"gopkg.in/yaml.v3"
 )
 
 type DependencyInfo struct {
@@ -39,8 +48,17 @@ type ArmService struct {
 	manifestMgr     manifest.Manager
 	lockfileMgr     packagelockfile.Manager
 	registryFactory registry.Factory
+	// sinkDirs redirects sink directories into a sandbox during dry runs
+	sinkDirs map[string]*stagedSink
+	// jobs is the number of packages bulk operations fetch concurrently
+	jobs int
+	// batch shares registries and fetched packages during a bulk operation
+	batch *fetchBatch
 }
 
+// DefaultJobs is the number of packages bulk operations resolve and fetch concurrently by default
+const DefaultJobs = 4
+
 // NewArmService creates a new ARM service
 func NewArmService(manifestMgr manifest.Manager, lockfileMgr packagelockfile.Manager, registryFactory registry.Factory) *ArmService {
 	if registryFactory == nil {
@@ -50,7 +68,18 @@ func NewArmService(manifestMgr manifest.Manager, lockfileMgr packagelockfile.Man
 		manifestMgr:     manifestMgr,
 		lockfileMgr:     lockfileMgr,
 		registryFactory: registryFactory,
+		jobs:            DefaultJobs,
+	}
+}
+
+// SetJobs sets the number of packages InstallAll, UpdateAll, UpgradeAll and
+// ListOutdated resolve and fetch concurrently
+func (s *ArmService) SetJobs(jobs int) error {
+	if jobs < 1 {
+		return fmt.Errorf("jobs must be at least 1, got %d", jobs)
 	}
+	s.jobs = jobs
+	return nil
 }
 
 // ---------------------------------------------
@@ -220,6 +249,10 @@ func (s *ArmService) GetAllRegistriesConfig(ctx context.Context) (map[string]map
 
 // AddSink adds a sink
 func (s *ArmService) AddSink(ctx context.Context, name, directory string, tool compiler.Tool, force bool) error {
+	if _, err := s.resolveTool(ctx, tool); err != nil {
+		return err
+	}
+
 	sinks, err := s.manifestMgr.GetAllSinksConfig(ctx)
 	if err != nil {
 		return err
@@ -268,6 +301,10 @@ func (s *ArmService) SetSinkDirectory(ctx context.Context, name, directory strin
 
 // SetSinkTool sets sink tool
 func (s *ArmService) SetSinkTool(ctx context.Context, name string, tool compiler.Tool) error {
+	if _, err := s.resolveTool(ctx, tool); err != nil {
+		return err
+	}
+
 	sink, err := s.manifestMgr.GetSinkConfig(ctx, name)
 	if err != nil {
 		return err
@@ -277,6 +314,131 @@ func (s *ArmService) SetSinkTool(ctx context.Context, name string, tool compiler
 	return s.manifestMgr.UpsertSinkConfig(ctx, name, sink)
 }
 
+// SetSinkEnforcement sets the enforcement policy of a sink; an empty policy restores tool defaults
+func (s *ArmService) SetSinkEnforcement(ctx context.Context, name string, policy compiler.EnforcementPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+
+	sink, err := s.manifestMgr.GetSinkConfig(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if len(policy) == 0 {
+		policy = nil
+	}
+	sink.Enforcement = policy
+	return s.manifestMgr.UpsertSinkConfig(ctx, name, sink)
+}
+
+// SetSinkConflicts sets how a sink handles conflicting rules (report or dedupe); an
+// empty mode restores report. Rules already installed are withheld, or regenerated
+// from their packages, to match.
+func (s *ArmService) SetSinkConflicts(ctx context.Context, name string, mode sink.ConflictMode) error {
+	if err := mode.Validate(); err != nil {
+		return err
+	}
+
+	sinkConfig, err := s.manifestMgr.GetSinkConfig(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	sinkConfig.Conflicts = string(mode)
+	if err := s.manifestMgr.UpsertSinkConfig(ctx, name, sinkConfig); err != nil {
+		return err
+	}
+
+	sinkMgr, err := s.newSinkManager(ctx, sinkConfig)
+	if err != nil {
+		return err
+	}
+	if err := sinkMgr.ApplyConflictMode(); err != nil {
+		return err
+	}
+	return s.refreshRulesets(ctx)
+}
+
+// SinkRuleConflicts lists the rule conflicts between the rulesets installed in a sink
+type SinkRuleConflicts struct {
+	Sink      string
+	Conflicts []sink.RuleConflict
+}
+
+// RuleConflicts returns the rule conflicts of every sink that has any, ordered by sink name
+func (s *ArmService) RuleConflicts(ctx context.Context) ([]*SinkRuleConflicts, error) {
+	sinks, err := s.manifestMgr.GetAllSinksConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(sinks))
+	for name := range sinks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var reports []*SinkRuleConflicts
+	for _, name := range names {
+		sinkMgr, err := s.newSinkManager(ctx, sinks[name])
+		if err != nil {
+			return nil, err
+		}
+		conflicts, err := sinkMgr.RuleConflicts()
+		if err != nil {
+			return nil, fmt.Errorf("failed to detect conflicts in sink %s: %w", name, err)
+		}
+		if len(conflicts) > 0 {
+			reports = append(reports, &SinkRuleConflicts{Sink: name, Conflicts: conflicts})
+		}
+	}
+	return reports, nil
+}
+
+// resolveTool checks that a tool is built in or user-defined and returns the
+// user-defined tools available to sinks
+func (s *ArmService) resolveTool(ctx context.Context, tool compiler.Tool) (map[compiler.Tool]*compiler.ToolDefinition, error) {
+	var tools map[compiler.Tool]*compiler.ToolDefinition
+	if s.manifestMgr != nil {
+		var err error
+		tools, err = s.manifestMgr.GetAllToolsConfig(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tools: %w", err)
+		}
+	}
+
+	if _, ok := tools[tool]; !ok && !compiler.IsBuiltinTool(tool) {
+		return nil, fmt.Errorf("invalid tool: %s (must be cursor, copilot, amazonq, kiro, claude, markdown, or a user-defined tool)", tool)
+	}
+	return tools, nil
+}
+
+// newSinkManager creates a sink manager that can compile the sink's tool
+func (s *ArmService) newSinkManager(ctx context.Context, sinkConfig manifest.SinkConfig) (*sink.Manager, error) {
+	tools, err := s.resolveTool(ctx, sinkConfig.Tool)
+	if err != nil {
+		return nil, err
+	}
+	if err := sinkConfig.Enforcement.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid enforcement policy for sink %s: %w", sinkConfig.Directory, err)
+	}
+	conflicts := sink.ConflictMode(sinkConfig.Conflicts)
+	if err := conflicts.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid conflicts setting for sink %s: %w", sinkConfig.Directory, err)
+	}
+	opts := sink.Options{
+		Tools:       tools,
+		Enforcement: sinkConfig.Enforcement,
+		Conflicts:   conflicts,
+		OverlayDir:  s.manifestMgr.ProjectDir(),
+	}
+	if staged, ok := s.sinkDirs[filepath.Clean(sinkConfig.Directory)]; ok {
+		opts.Unmanaged = staged.unmanaged
+	}
+	return sink.NewManagerWithOptions(s.sinkDirectory(sinkConfig.Directory), sinkConfig.Tool, opts), nil
+}
+
 // ---------------------
 // Dependency Management
 // ---------------------
@@ -298,27 +460,95 @@ func (s *ArmService) InstallAll(ctx context.Context) error {
 		return err
 	}
 
-	for key, rulesetCfg := range rulesets {
+	mcpsets, err := s.manifestMgr.GetAllMcpsetDependenciesConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	agentsets, err := s.manifestMgr.GetAllAgentsetDependenciesConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	skillsets, err := s.manifestMgr.GetAllSkillsetDependenciesConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	hooksets, err := s.manifestMgr.GetAllHooksetDependenciesConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Fetch every package concurrently, then install them one at a time in a fixed order
+	defer s.startBatch()()
+	baseDeps, err := s.getAllBaseDependenciesConfig(ctx)
+	if err != nil {
+		return err
+	}
+	var requests []fetchRequest
+	for key, config := range rulesets {
+		requests = append(requests, newFetchRequest(key, &config.BaseDependencyConfig, false))
+	}
+	for key, config := range baseDeps {
+		requests = append(requests, newFetchRequest(key, config, false))
+	}
+	s.prefetch(ctx, requests)
+
+	for _, key := range sortedKeys(rulesets) {
+		rulesetCfg := rulesets[key]
 		registryName, packageName := manifest.ParseDependencyKey(key)
 		if err := s.InstallRuleset(ctx, registryName, packageName, rulesetCfg.Version, rulesetCfg.Priority, rulesetCfg.Include, rulesetCfg.Exclude, rulesetCfg.Sinks); err != nil {
 			return err
 		}
 	}
 
-	for key, promptsetCfg := range promptsets {
+	for _, key := range sortedKeys(promptsets) {
+		promptsetCfg := promptsets[key]
 		registryName, packageName := manifest.ParseDependencyKey(key)
 		if err := s.InstallPromptset(ctx, registryName, packageName, promptsetCfg.Version, promptsetCfg.Include, promptsetCfg.Exclude, promptsetCfg.Sinks); err != nil {
 			return err
 		}
 	}
 
+	for _, key := range sortedKeys(mcpsets) {
+		mcpsetCfg := mcpsets[key]
+		registryName, packageName := manifest.ParseDependencyKey(key)
+		if err := s.InstallMcpset(ctx, registryName, packageName, mcpsetCfg.Version, mcpsetCfg.Include, mcpsetCfg.Exclude, mcpsetCfg.Sinks); err != nil {
+			return err
+		}
+	}
+
+	for _, key := range sortedKeys(agentsets) {
+		agentsetCfg := agentsets[key]
+		registryName, packageName := manifest.ParseDependencyKey(key)
+		if err := s.InstallAgentset(ctx, registryName, packageName, agentsetCfg.Version, agentsetCfg.Include, agentsetCfg.Exclude, agentsetCfg.Sinks); err != nil {
+			return err
+		}
+	}
+
+	for _, key := range sortedKeys(skillsets) {
+		skillsetCfg := skillsets[key]
+		registryName, packageName := manifest.ParseDependencyKey(key)
+		if err := s.InstallSkillset(ctx, registryName, packageName, skillsetCfg.Version, skillsetCfg.Include, skillsetCfg.Exclude, skillsetCfg.Sinks); err != nil {
+			return err
+		}
+	}
+
+	for _, key := range sortedKeys(hooksets) {
+		hooksetCfg := hooksets[key]
+		registryName, packageName := manifest.ParseDependencyKey(key)
+		if err := s.InstallHookset(ctx, registryName, packageName, hooksetCfg.Version, hooksetCfg.Include, hooksetCfg.Exclude, hooksetCfg.Sinks); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
 // resolveAndFetchPackage validates registry/sinks, resolves version, and fetches package
 func (s *ArmService) resolveAndFetchPackage(ctx context.Context, registryName, packageName, version string, include, exclude, sinks []string) (pkg *core.Package, resolvedVersion string, sinkConfigs map[string]manifest.SinkConfig, err error) {
-	regConfig, err := s.manifestMgr.GetRegistryConfig(ctx, registryName)
-	if err != nil {
+	if _, err := s.manifestMgr.GetRegistryConfig(ctx, registryName); err != nil {
 		return nil, "", nil, err
 	}
 
@@ -333,22 +563,13 @@ func (s *ArmService) resolveAndFetchPackage(ctx context.Context, registryName, p
 		}
 	}
 
-	reg, err := s.registryFactory.CreateRegistry(registryName, regConfig)
-	if err != nil {
-		return nil, "", nil, err
-	}
-
-	availableVersions, err := reg.ListPackageVersions(ctx, packageName)
-	if err != nil {
-		return nil, "", nil, err
-	}
-
-	resolvedVer, err := core.ResolveVersion(version, availableVersions)
-	if err != nil {
-		return nil, "", nil, err
-	}
-
-	pkg, err = reg.GetPackage(ctx, packageName, &resolvedVer, include, exclude)
+	resolvedVer, pkg, err := s.fetch(ctx, fetchRequest{
+		registryName: registryName,
+		packageName:  packageName,
+		constraint:   version,
+		include:      include,
+		exclude:      exclude,
+	})
 	if err != nil {
 		return nil, "", nil, err
 	}
@@ -368,6 +589,174 @@ func (s *ArmService) resolveAndFetchPackage(ctx context.Context, registryName, p
 	return pkg, resolvedVer.Version, allSinks, nil
 }
 
+// fetchRequest describes a package to resolve and fetch. With latest set the
+// constraint is ignored and the highest available version is fetched.
+type fetchRequest struct {
+	registryName string
+	packageName  string
+	constraint   string
+	latest       bool
+	include      []string
+	exclude      []string
+}
+
+func newFetchRequest(key string, config *manifest.BaseDependencyConfig, latest bool) fetchRequest {
+	registryName, packageName := manifest.ParseDependencyKey(key)
+	return fetchRequest{
+		registryName: registryName,
+		packageName:  packageName,
+		constraint:   config.Version,
+		latest:       latest,
+		include:      config.Include,
+		exclude:      config.Exclude,
+	}
+}
+
+func (r fetchRequest) key() string {
+	return fmt.Sprintf("%s/%s@%s latest=%t include=%q exclude=%q", r.registryName, r.packageName, r.constraint, r.latest, r.include, r.exclude)
+}
+
+type fetchResult struct {
+	version core.Version
+	pkg     *core.Package
+	err     error
+}
+
+// fetchBatch holds the registries and packages shared by the workers of a bulk operation
+type fetchBatch struct {
+	mu         sync.Mutex
+	registries map[string]registry.Registry
+	results    map[string]*fetchResult
+}
+
+// startBatch shares registries and prefetched packages between calls until the
+// returned function is called. Nested batches join the outermost one.
+func (s *ArmService) startBatch() func() {
+	if s.batch != nil {
+		return func() {}
+	}
+	s.batch = &fetchBatch{
+		registries: make(map[string]registry.Registry),
+		results:    make(map[string]*fetchResult),
+	}
+	return func() { s.batch = nil }
+}
+
+// getRegistry creates the named registry; within a batch each registry is
+// created once, so its storage locks serialize access to the shared cache
+func (s *ArmService) getRegistry(ctx context.Context, registryName string) (registry.Registry, error) {
+	if s.batch != nil {
+		s.batch.mu.Lock()
+		defer s.batch.mu.Unlock()
+		if reg, ok := s.batch.registries[registryName]; ok {
+			return reg, nil
+		}
+	}
+
+	regConfig, err := s.manifestMgr.GetRegistryConfig(ctx, registryName)
+	if err != nil {
+		return nil, err
+	}
+	reg, err := s.registryFactory.CreateRegistry(registryName, regConfig)
+	if err != nil {
+		return nil, err
+	}
+	if s.batch != nil {
+		s.batch.registries[registryName] = reg
+	}
+	return reg, nil
+}
+
+// fetch resolves and fetches a package, using the batch's prefetched result if there is one
+func (s *ArmService) fetch(ctx context.Context, req fetchRequest) (core.Version, *core.Package, error) {
+	if s.batch != nil {
+		s.batch.mu.Lock()
+		result, ok := s.batch.results[req.key()]
+		s.batch.mu.Unlock()
+		if ok {
+			return result.version, result.pkg, result.err
+		}
+	}
+
+	reg, err := s.getRegistry(ctx, req.registryName)
+	if err != nil {
+		return core.Version{}, nil, err
+	}
+
+	availableVersions, err := reg.ListPackageVersions(ctx, req.packageName)
+	if err != nil {
+		return core.Version{}, nil, err
+	}
+
+	var version core.Version
+	if req.latest {
+		if len(availableVersions) == 0 {
+			return core.Version{}, nil, fmt.Errorf("no versions available for %s", req.packageName)
+		}
+		version = availableVersions[0]
+	} else {
+		version, err = core.ResolveVersion(req.constraint, availableVersions)
+		if err != nil {
+			return core.Version{}, nil, err
+		}
+	}
+
+	pkg, err := reg.GetPackage(ctx, req.packageName, &version, req.include, req.exclude)
+	if err != nil {
+		return core.Version{}, nil, err
+	}
+	return version, pkg, nil
+}
+
+// prefetch resolves and fetches packages concurrently into the current batch.
+// Failures are recorded too and reported when the package is fetched again.
+func (s *ArmService) prefetch(ctx context.Context, requests []fetchRequest) {
+	seen := make(map[string]bool, len(requests))
+	var unique []fetchRequest
+	for _, req := range requests {
+		if !seen[req.key()] {
+			seen[req.key()] = true
+			unique = append(unique, req)
+		}
+	}
+
+	runJobs(s.jobs, len(unique), func(i int) {
+		version, pkg, err := s.fetch(ctx, unique[i])
+		s.batch.mu.Lock()
+		s.batch.results[unique[i].key()] = &fetchResult{version: version, pkg: pkg, err: err}
+		s.batch.mu.Unlock()
+	})
+}
+
+// runJobs calls job for every index in [0, n) on at most workers goroutines
+func runJobs(workers, n int, job func(i int)) {
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(workers, n); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				job(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // InstallRuleset installs a ruleset
 func (s *ArmService) InstallRuleset(ctx context.Context, registryName, ruleset, version string, priority int, include, exclude, sinks []string) error {
 	pkg, resolvedVersion, allSinks, err := s.resolveAndFetchPackage(ctx, registryName, ruleset, version, include, exclude, sinks)
@@ -384,6 +773,19 @@ func (s *ArmService) InstallRuleset(ctx context.Context, registryName, ruleset,
 		},
 		Priority: priority,
 	}
+	// Reinstalling keeps the overlays and vars already configured for the dependency
+	if existing, err := s.manifestMgr.GetRulesetDependencyConfig(ctx, registryName, ruleset); err == nil {
+		depConfig.Overlays = existing.Overlays
+		depConfig.Vars = existing.Vars
+	}
+	vars, err := s.resolveVars(ctx, depConfig.Vars)
+	if err != nil {
+		return err
+	}
+	bases, err := s.rulesetBases(ctx, pkg)
+	if err != nil {
+		return err
+	}
 
 	if err := s.manifestMgr.UpsertRulesetDependencyConfig(ctx, registryName, ruleset, &depConfig); err != nil {
 		return err
@@ -397,13 +799,17 @@ func (s *ArmService) InstallRuleset(ctx context.Context, registryName, ruleset,
 
 	for _, sinkName := range sinks {
 		sinkConfig := allSinks[sinkName]
-		sinkMgr := sink.NewManager(sinkConfig.Directory, sinkConfig.Tool)
-		if err := sinkMgr.InstallRuleset(pkg, priority); err != nil {
+		sinkMgr, err := s.newSinkManager(ctx, sinkConfig)
+		if err != nil {
+			return err
+		}
+		if err := sinkMgr.InstallRuleset(pkg, priority, depConfig.Overlays, vars, bases); err != nil {
 			return err
 		}
 	}
 
-	return nil
+	// Rulesets extending this one are rebuilt on top of the installed version
+	return s.refreshRulesets(ctx)
 }
 
 // InstallPromptset installs a promptset
@@ -421,6 +827,14 @@ func (s *ArmService) InstallPromptset(ctx context.Context, registryName, prompts
 			Exclude: exclude,
 		},
 	}
+	// Reinstalling keeps the vars already configured for the dependency
+	if existing, err := s.manifestMgr.GetPromptsetDependencyConfig(ctx, registryName, promptset); err == nil {
+		depConfig.Vars = existing.Vars
+	}
+	vars, err := s.resolveVars(ctx, depConfig.Vars)
+	if err != nil {
+		return err
+	}
 
 	if err := s.manifestMgr.UpsertPromptsetDependencyConfig(ctx, registryName, promptset, &depConfig); err != nil {
 		return err
@@ -434,8 +848,11 @@ func (s *ArmService) InstallPromptset(ctx context.Context, registryName, prompts
 
 	for _, sinkName := range sinks {
 		sinkConfig := allSinks[sinkName]
-		sinkMgr := sink.NewManager(sinkConfig.Directory, sinkConfig.Tool)
-		if err := sinkMgr.InstallPromptset(pkg); err != nil {
+		sinkMgr, err := s.newSinkManager(ctx, sinkConfig)
+		if err != nil {
+			return err
+		}
+		if err := sinkMgr.InstallPromptset(pkg, vars); err != nil {
 			return err
 		}
 	}
@@ -443,57 +860,398 @@ func (s *ArmService) InstallPromptset(ctx context.Context, registryName, prompts
 	return nil
 }
 
-// UninstallAll uninstalls all dependencies
-func (s *ArmService) UninstallAll(ctx context.Context) error {
-	rulesets, err := s.manifestMgr.GetAllRulesetDependenciesConfig(ctx)
-	if err != nil {
-		return err
-	}
+// InstallMcpset installs an mcpset
+func (s *ArmService) InstallMcpset(ctx context.Context, registryName, mcpset, version string, include, exclude, sinks []string) error {
+	return s.installBasePackage(ctx, manifest.ResourceTypeMcpset, registryName, mcpset, version, include, exclude, sinks)
+}
 
-	promptsets, err := s.manifestMgr.GetAllPromptsetDependenciesConfig(ctx)
-	if err != nil {
-		return err
+// InstallAgentset installs an agentset
+func (s *ArmService) InstallAgentset(ctx context.Context, registryName, agentset, version string, include, exclude, sinks []string) error {
+	return s.installBasePackage(ctx, manifest.ResourceTypeAgentset, registryName, agentset, version, include, exclude, sinks)
+}
+
+// InstallSkillset installs a skillset. Skill directories hold scripts, references
+// and assets besides SKILL.md, so every file is fetched unless patterns are given.
+func (s *ArmService) InstallSkillset(ctx context.Context, registryName, skillset, version string, include, exclude, sinks []string) error {
+	if len(include) == 0 && len(exclude) == 0 {
+		include = []string{"**/*"}
 	}
+	return s.installBasePackage(ctx, manifest.ResourceTypeSkillset, registryName, skillset, version, include, exclude, sinks)
+}
 
-	allSinks, err := s.manifestMgr.GetAllSinksConfig(ctx)
+// InstallHookset installs a hookset
+func (s *ArmService) InstallHookset(ctx context.Context, registryName, hookset, version string, include, exclude, sinks []string) error {
+	return s.installBasePackage(ctx, manifest.ResourceTypeHookset, registryName, hookset, version, include, exclude, sinks)
+}
+
+// installBasePackage installs a package whose dependency configuration has no
+// settings beyond BaseDependencyConfig
+func (s *ArmService) installBasePackage(ctx context.Context, depType manifest.ResourceType, registryName, packageName, version string, include, exclude, sinks []string) error {
+	pkg, resolvedVersion, allSinks, err := s.resolveAndFetchPackage(ctx, registryName, packageName, version, include, exclude, sinks)
 	if err != nil {
 		return err
 	}
 
-	for key, rulesetConfig := range rulesets {
-		registryName, packageName := manifest.ParseDependencyKey(key)
+	depConfig := manifest.BaseDependencyConfig{
+		Type:    depType,
+		Version: version,
+		Sinks:   sinks,
+		Include: include,
+		Exclude: exclude,
+	}
 
-		for _, sinkName := range rulesetConfig.Sinks {
-			sinkConfig, exists := allSinks[sinkName]
-			if !exists {
-				continue
-			}
+	if err := s.upsertBaseDependencyConfig(ctx, registryName, packageName, &depConfig); err != nil {
+		return err
+	}
 
-			sinkMgr := sink.NewManager(sinkConfig.Directory, sinkConfig.Tool)
-			if err := sinkMgr.Uninstall(registryName, packageName); err != nil {
-				return err
-			}
-		}
+	if err := s.lockfileMgr.UpsertDependencyLock(ctx, registryName, packageName, resolvedVersion, &packagelockfile.DependencyLockConfig{
+		Integrity: pkg.Integrity,
+	}); err != nil {
+		return err
+	}
 
-		if err := s.lockfileMgr.RemoveDependencyLock(ctx, registryName, packageName); err != nil {
+	for _, sinkName := range sinks {
+		sinkConfig := allSinks[sinkName]
+		sinkMgr, err := s.newSinkManager(ctx, sinkConfig)
+		if err != nil {
 			return err
 		}
-
-		if err := s.manifestMgr.RemoveDependencyConfig(ctx, registryName, packageName); err != nil {
+		if err := installToSink(sinkMgr, string(depType), pkg, 0, nil, nil, nil); err != nil {
 			return err
 		}
 	}
 
-	for key, promptsetConfig := range promptsets {
-		registryName, packageName := manifest.ParseDependencyKey(key)
+	return nil
+}
 
-		for _, sinkName := range promptsetConfig.Sinks {
-			sinkConfig, exists := allSinks[sinkName]
+// installToSink installs a fetched package into a sink according to its dependency type
+func installToSink(sinkMgr *sink.Manager, depType string, pkg *core.Package, priority int, overlays map[string]compiler.RuleOverlay, vars map[string]string, bases map[string]*resource.RulesetResource) error {
+	switch manifest.ResourceType(depType) {
+	case manifest.ResourceTypeRuleset:
+		return sinkMgr.InstallRuleset(pkg, priority, overlays, vars, bases)
+	case manifest.ResourceTypePromptset:
+		return sinkMgr.InstallPromptset(pkg, vars)
+	case manifest.ResourceTypeMcpset:
+		return sinkMgr.InstallMcpset(pkg)
+	case manifest.ResourceTypeAgentset:
+		return sinkMgr.InstallAgentset(pkg)
+	case manifest.ResourceTypeSkillset:
+		return sinkMgr.InstallSkillset(pkg)
+	case manifest.ResourceTypeHookset:
+		return sinkMgr.InstallHookset(pkg)
+	default:
+		return fmt.Errorf("unknown package type: %s", depType)
+	}
+}
+
+// resolveVars merges the manifest-level vars with a dependency's own vars, which take precedence
+func (s *ArmService) resolveVars(ctx context.Context, depVars map[string]string) (map[string]string, error) {
+	vars, err := s.manifestMgr.GetVars(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resolved := make(map[string]string, len(vars)+len(depVars))
+	for name, value := range vars {
+		resolved[name] = value
+	}
+	for name, value := range depVars {
+		resolved[name] = value
+	}
+	return resolved, nil
+}
+
+// varsChanged reports whether any of the sinks holds the package rendered with
+// variable values that differ from vars
+func (s *ArmService) varsChanged(ctx context.Context, sinkNames []string, allSinks map[string]manifest.SinkConfig, registryName, packageName, version string, vars map[string]string) bool {
+	for _, sinkName := range sinkNames {
+		sinkMgr, err := s.newSinkManager(ctx, allSinks[sinkName])
+		if err != nil {
+			continue
+		}
+		if sinkMgr.VarsChanged(registryName, packageName, version, vars) {
+			return true
+		}
+	}
+	return false
+}
+
+// rulesetBases fetches the rulesets of other dependencies that the package's
+// rulesets extend, following their own extends, keyed by parser.RulesetRef.
+// Each extended package must be a ruleset dependency in the manifest and is
+// fetched at the version its constraint resolves to.
+func (s *ArmService) rulesetBases(ctx context.Context, pkg *core.Package) (map[string]*resource.RulesetResource, error) {
+	depKey := manifest.DependencyKey(pkg.Metadata.RegistryName, pkg.Metadata.Name)
+	files, err := parser.AssembleMarkdownResources(pkg.Files)
+	if err != nil {
+		return nil, err
+	}
+	rulesets := parser.ParsePackageRulesets(depKey, files)
+
+	bases := make(map[string]*resource.RulesetResource)
+	fetched := map[string]bool{depKey: true}
+	pending := parser.ExternalExtends(depKey, rulesets)
+	for len(pending) > 0 {
+		key := pending[0]
+		pending = pending[1:]
+		if fetched[key] {
+			continue
+		}
+		fetched[key] = true
+
+		registryName, packageName := manifest.ParseDependencyKey(key)
+		config, err := s.manifestMgr.GetRulesetDependencyConfig(ctx, registryName, packageName)
+		if err != nil {
+			return nil, fmt.Errorf("%s extends rulesets of %s, which is not a ruleset dependency: %w", depKey, key, err)
+		}
+		_, basePkg, err := s.resolveAndFetchUpdate(ctx, registryName, packageName, config.Version, config.Include, config.Exclude)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s extended by %s: %w", key, depKey, err)
+		}
+		baseFiles, err := parser.AssembleMarkdownResources(basePkg.Files)
+		if err != nil {
+			return nil, err
+		}
+		baseRulesets := parser.ParsePackageRulesets(key, baseFiles)
+		for ref, ruleset := range baseRulesets {
+			bases[ref] = ruleset
+		}
+		pending = append(pending, parser.ExternalExtends(key, baseRulesets)...)
+	}
+	return bases, nil
+}
+
+// refreshRulesets reinstalls, at their locked versions, the ruleset dependencies
+// whose installed rules are out of date: rules that inherit from rulesets of other
+// packages that have changed since, so upgrading a base package never leaves stale
+// copies of its rules behind, and rules withheld in dedupe mode that no longer lose
+// a conflict, whose files only the package can regenerate
+func (s *ArmService) refreshRulesets(ctx context.Context) error {
+	rulesets, err := s.manifestMgr.GetAllRulesetDependenciesConfig(ctx)
+	if err != nil {
+		return err
+	}
+	allSinks, err := s.manifestMgr.GetAllSinksConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if len(rulesets) == 0 {
+		return nil
+	}
+	lockFile, err := s.lockfileMgr.GetLockFile(ctx)
+	if errors.Is(err, os.ErrNotExist) {
+		// Nothing is installed without a lockfile
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, key := range sortedKeys(rulesets) {
+		config := rulesets[key]
+		registryName, packageName := manifest.ParseDependencyKey(key)
+		version := s.getOldVersionFromLock(lockFile, key)
+		if version == "" {
+			continue
+		}
+
+		// Sinks holding the package built on other packages, and sinks with rules to restore
+		extenders := make(map[string]*sink.Manager)
+		stale := make(map[string]*sink.Manager)
+		for _, sinkName := range config.Sinks {
+			sinkConfig, exists := allSinks[sinkName]
+			if !exists {
+				continue
+			}
+			sinkMgr, err := s.newSinkManager(ctx, sinkConfig)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if sinkMgr.ExtendsOtherPackages(registryName, packageName, version) {
+				extenders[sinkName] = sinkMgr
+			}
+			if sinkMgr.HasRulesToRestore(registryName, packageName, version) {
+				stale[sinkName] = sinkMgr
+			}
+		}
+		if len(extenders) == 0 && len(stale) == 0 {
+			continue
+		}
+
+		_, pkg, err := s.fetch(ctx, fetchRequest{
+			registryName: registryName,
+			packageName:  packageName,
+			constraint:   version,
+			include:      config.Include,
+			exclude:      config.Exclude,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to fetch %s@%s: %w", key, version, err))
+			continue
+		}
+		bases, err := s.rulesetBases(ctx, pkg)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for sinkName, sinkMgr := range extenders {
+			if sinkMgr.BasesChanged(registryName, packageName, version, bases) {
+				stale[sinkName] = sinkMgr
+			}
+		}
+		if len(stale) == 0 {
+			continue
+		}
+		vars, err := s.resolveVars(ctx, config.Vars)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, sinkName := range sortedKeys(stale) {
+			if err := stale[sinkName].InstallRuleset(pkg, config.Priority, config.Overlays, vars, bases); err != nil {
+				errs = append(errs, fmt.Errorf("failed to reinstall %s to sink %s: %w", key, sinkName, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// getAllBaseDependenciesConfig returns the dependencies other than rulesets, whose
+// configuration has no settings beyond BaseDependencyConfig
+func (s *ArmService) getAllBaseDependenciesConfig(ctx context.Context) (map[string]*manifest.BaseDependencyConfig, error) {
+	promptsets, err := s.manifestMgr.GetAllPromptsetDependenciesConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	mcpsets, err := s.manifestMgr.GetAllMcpsetDependenciesConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	agentsets, err := s.manifestMgr.GetAllAgentsetDependenciesConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	skillsets, err := s.manifestMgr.GetAllSkillsetDependenciesConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	hooksets, err := s.manifestMgr.GetAllHooksetDependenciesConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deps := make(map[string]*manifest.BaseDependencyConfig, len(promptsets)+len(mcpsets)+len(agentsets)+len(skillsets)+len(hooksets))
+	for key, config := range promptsets {
+		deps[key] = &config.BaseDependencyConfig
+	}
+	for key, config := range mcpsets {
+		deps[key] = &config.BaseDependencyConfig
+	}
+	for key, config := range agentsets {
+		deps[key] = &config.BaseDependencyConfig
+	}
+	for key, config := range skillsets {
+		deps[key] = &config.BaseDependencyConfig
+	}
+	for key, config := range hooksets {
+		deps[key] = &config.BaseDependencyConfig
+	}
+	return deps, nil
+}
+
+// getBaseDependencyConfig returns a single dependency of a type handled by getAllBaseDependenciesConfig
+func (s *ArmService) getBaseDependencyConfig(ctx context.Context, depType, registryName, packageName string) (*manifest.BaseDependencyConfig, error) {
+	switch manifest.ResourceType(depType) {
+	case manifest.ResourceTypePromptset:
+		config, err := s.manifestMgr.GetPromptsetDependencyConfig(ctx, registryName, packageName)
+		if err != nil {
+			return nil, err
+		}
+		return &config.BaseDependencyConfig, nil
+	case manifest.ResourceTypeMcpset:
+		config, err := s.manifestMgr.GetMcpsetDependencyConfig(ctx, registryName, packageName)
+		if err != nil {
+			return nil, err
+		}
+		return &config.BaseDependencyConfig, nil
+	case manifest.ResourceTypeAgentset:
+		config, err := s.manifestMgr.GetAgentsetDependencyConfig(ctx, registryName, packageName)
+		if err != nil {
+			return nil, err
+		}
+		return &config.BaseDependencyConfig, nil
+	case manifest.ResourceTypeSkillset:
+		config, err := s.manifestMgr.GetSkillsetDependencyConfig(ctx, registryName, packageName)
+		if err != nil {
+			return nil, err
+		}
+		return &config.BaseDependencyConfig, nil
+	case manifest.ResourceTypeHookset:
+		config, err := s.manifestMgr.GetHooksetDependencyConfig(ctx, registryName, packageName)
+		if err != nil {
+			return nil, err
+		}
+		return &config.BaseDependencyConfig, nil
+	default:
+		return nil, fmt.Errorf("unknown package type: %s", depType)
+	}
+}
+
+// upsertBaseDependencyConfig saves a dependency returned by getAllBaseDependenciesConfig
+func (s *ArmService) upsertBaseDependencyConfig(ctx context.Context, registryName, packageName string, config *manifest.BaseDependencyConfig) error {
+	switch config.Type {
+	case manifest.ResourceTypePromptset:
+		return s.manifestMgr.UpsertPromptsetDependencyConfig(ctx, registryName, packageName, &manifest.PromptsetDependencyConfig{BaseDependencyConfig: *config})
+	case manifest.ResourceTypeMcpset:
+		return s.manifestMgr.UpsertMcpsetDependencyConfig(ctx, registryName, packageName, &manifest.McpsetDependencyConfig{BaseDependencyConfig: *config})
+	case manifest.ResourceTypeAgentset:
+		return s.manifestMgr.UpsertAgentsetDependencyConfig(ctx, registryName, packageName, &manifest.AgentsetDependencyConfig{BaseDependencyConfig: *config})
+	case manifest.ResourceTypeSkillset:
+		return s.manifestMgr.UpsertSkillsetDependencyConfig(ctx, registryName, packageName, &manifest.SkillsetDependencyConfig{BaseDependencyConfig: *config})
+	case manifest.ResourceTypeHookset:
+		return s.manifestMgr.UpsertHooksetDependencyConfig(ctx, registryName, packageName, &manifest.HooksetDependencyConfig{BaseDependencyConfig: *config})
+	default:
+		return fmt.Errorf("unknown package type: %s", config.Type)
+	}
+}
+
+// UninstallAll uninstalls all dependencies
+func (s *ArmService) UninstallAll(ctx context.Context) error {
+	rulesets, err := s.manifestMgr.GetAllRulesetDependenciesConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Promptsets, mcpsets, agentsets, skillsets and hooksets share the same dependency settings
+	baseDeps, err := s.getAllBaseDependenciesConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	allSinks, err := s.manifestMgr.GetAllSinksConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	for key, rulesetConfig := range rulesets {
+		registryName, packageName := manifest.ParseDependencyKey(key)
+
+		for _, sinkName := range rulesetConfig.Sinks {
+			sinkConfig, exists := allSinks[sinkName]
 			if !exists {
 				continue
 			}
 
-			sinkMgr := sink.NewManager(sinkConfig.Directory, sinkConfig.Tool)
+			sinkMgr, err := s.newSinkManager(ctx, sinkConfig)
+			if err != nil {
+				return err
+			}
 			if err := sinkMgr.Uninstall(registryName, packageName); err != nil {
 				return err
 			}
@@ -508,7 +1266,34 @@ func (s *ArmService) UninstallAll(ctx context.Context) error {
 		}
 	}
 
-	return nil
+	for key, depConfig := range baseDeps {
+		registryName, packageName := manifest.ParseDependencyKey(key)
+
+		for _, sinkName := range depConfig.Sinks {
+			sinkConfig, exists := allSinks[sinkName]
+			if !exists {
+				continue
+			}
+
+			sinkMgr, err := s.newSinkManager(ctx, sinkConfig)
+			if err != nil {
+				return err
+			}
+			if err := sinkMgr.Uninstall(registryName, packageName); err != nil {
+				return err
+			}
+		}
+
+		if err := s.lockfileMgr.RemoveDependencyLock(ctx, registryName, packageName); err != nil {
+			return err
+		}
+
+		if err := s.manifestMgr.RemoveDependencyConfig(ctx, registryName, packageName); err != nil {
+			return err
+		}
+	}
+
+	return s.refreshRulesets(ctx)
 }
 
 // UninstallPackages uninstalls specific packages
@@ -560,6 +1345,13 @@ func (s *ArmService) UninstallPackages(ctx context.Context, packages []string) e
 				continue
 			}
 			sinks = promptsetConfig.Sinks
+		case "mcpset", "agentset", "skillset", "hookset":
+			baseConfig, err := s.getBaseDependencyConfig(ctx, depType, registryName, packageName)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			sinks = baseConfig.Sinks
 		default:
 			fmt.Fprintf(os.Stderr, "Warning: unknown package type '%s' for '%s'\n", depType, pkg)
 			lastErr = fmt.Errorf("unknown package type: %s", depType)
@@ -572,7 +1364,11 @@ func (s *ArmService) UninstallPackages(ctx context.Context, packages []string) e
 				continue
 			}
 
-			sinkMgr := sink.NewManager(sinkConfig.Directory, sinkConfig.Tool)
+			sinkMgr, err := s.newSinkManager(ctx, sinkConfig)
+			if err != nil {
+				lastErr = err
+				continue
+			}
 			if err := sinkMgr.Uninstall(registryName, packageName); err != nil {
 				lastErr = err
 				continue
@@ -596,7 +1392,8 @@ func (s *ArmService) UninstallPackages(ctx context.Context, packages []string) e
 		return lastErr
 	}
 
-	return nil
+	// Rules the removed packages withheld in dedupe mode are written again
+	return s.refreshRulesets(ctx)
 }
 
 // UpdatePackages updates specific packages
@@ -641,6 +1438,8 @@ func (s *ArmService) UpdatePackages(ctx context.Context, packages []string) erro
 		var include []string
 		var exclude []string
 		var priority int
+		var overlays map[string]compiler.RuleOverlay
+		var depVars map[string]string
 
 		switch depType {
 		case "ruleset":
@@ -654,6 +1453,8 @@ func (s *ArmService) UpdatePackages(ctx context.Context, packages []string) erro
 			include = rulesetConfig.Include
 			exclude = rulesetConfig.Exclude
 			priority = rulesetConfig.Priority
+			overlays = rulesetConfig.Overlays
+			depVars = rulesetConfig.Vars
 		case "promptset":
 			promptsetConfig, err := s.manifestMgr.GetPromptsetDependencyConfig(ctx, registryName, packageName)
 			if err != nil {
@@ -664,6 +1465,18 @@ func (s *ArmService) UpdatePackages(ctx context.Context, packages []string) erro
 			version = promptsetConfig.Version
 			include = promptsetConfig.Include
 			exclude = promptsetConfig.Exclude
+			depVars = promptsetConfig.Vars
+		case "mcpset", "agentset", "skillset", "hookset":
+			baseConfig, err := s.getBaseDependencyConfig(ctx, depType, registryName, packageName)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			sinks = baseConfig.Sinks
+			version = baseConfig.Version
+			include = baseConfig.Include
+			exclude = baseConfig.Exclude
+			depVars = baseConfig.Vars
 		default:
 			fmt.Fprintf(os.Stderr, "Warning: unknown package type '%s' for '%s'\n", depType, pkg)
 			lastErr = fmt.Errorf("unknown package type: %s", depType)
@@ -677,14 +1490,24 @@ func (s *ArmService) UpdatePackages(ctx context.Context, packages []string) erro
 			lastErr = err
 			continue
 		}
+		vars, err := s.resolveVars(ctx, depVars)
+		if err != nil {
+			lastErr = err
+			continue
+		}
 
-		if oldVersion == newVersion {
+		if oldVersion == newVersion && !s.varsChanged(ctx, sinks, allSinks, registryName, packageName, oldVersion, vars) {
 			successCount++
 			continue
 		}
+		bases, err := s.rulesetBases(ctx, fetchedPkg)
+		if err != nil {
+			lastErr = err
+			continue
+		}
 
 		if oldVersion != "" {
-			if err := s.uninstallFromSinks(sinks, allSinks, registryName, packageName); err != nil {
+			if err := s.uninstallFromSinks(ctx, sinks, allSinks, registryName, packageName); err != nil {
 				lastErr = err
 				continue
 			}
@@ -696,17 +1519,14 @@ func (s *ArmService) UpdatePackages(ctx context.Context, packages []string) erro
 
 		for _, sinkName := range sinks {
 			sinkConfig := allSinks[sinkName]
-			sinkMgr := sink.NewManager(sinkConfig.Directory, sinkConfig.Tool)
-			if depType == "ruleset" {
-				if err := sinkMgr.InstallRuleset(fetchedPkg, priority); err != nil {
-					lastErr = err
-					continue
-				}
-			} else {
-				if err := sinkMgr.InstallPromptset(fetchedPkg); err != nil {
-					lastErr = err
-					continue
-				}
+			sinkMgr, err := s.newSinkManager(ctx, sinkConfig)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			if err := installToSink(sinkMgr, depType, fetchedPkg, priority, overlays, vars, bases); err != nil {
+				lastErr = err
+				continue
 			}
 		}
 
@@ -724,7 +1544,8 @@ func (s *ArmService) UpdatePackages(ctx context.Context, packages []string) erro
 		return lastErr
 	}
 
-	return nil
+	// Rulesets extending the packages that changed are rebuilt on top of them
+	return s.refreshRulesets(ctx)
 }
 
 // UpdateAll updates all dependencies
@@ -734,7 +1555,8 @@ func (s *ArmService) UpdateAll(ctx context.Context) error {
 		return err
 	}
 
-	promptsets, err := s.manifestMgr.GetAllPromptsetDependenciesConfig(ctx)
+	// Promptsets, mcpsets, agentsets, skillsets and hooksets share the same dependency settings
+	baseDeps, err := s.getAllBaseDependenciesConfig(ctx)
 	if err != nil {
 		return err
 	}
@@ -749,10 +1571,22 @@ func (s *ArmService) UpdateAll(ctx context.Context) error {
 		return err
 	}
 
+	// Fetch every package concurrently, then apply them one at a time in a fixed order
+	defer s.startBatch()()
+	var requests []fetchRequest
+	for key, config := range rulesets {
+		requests = append(requests, newFetchRequest(key, &config.BaseDependencyConfig, false))
+	}
+	for key, config := range baseDeps {
+		requests = append(requests, newFetchRequest(key, config, false))
+	}
+	s.prefetch(ctx, requests)
+
 	successCount := 0
 	var lastErr error
 
-	for key, rulesetConfig := range rulesets {
+	for _, key := range sortedKeys(rulesets) {
+		rulesetConfig := rulesets[key]
 		registryName, packageName := manifest.ParseDependencyKey(key)
 
 		oldVersion := s.getOldVersionFromLock(lockFile, key)
@@ -762,14 +1596,24 @@ func (s *ArmService) UpdateAll(ctx context.Context) error {
 			lastErr = err
 			continue
 		}
+		vars, err := s.resolveVars(ctx, rulesetConfig.Vars)
+		if err != nil {
+			lastErr = err
+			continue
+		}
 
-		if oldVersion == newVersion {
+		if oldVersion == newVersion && !s.varsChanged(ctx, rulesetConfig.Sinks, allSinks, registryName, packageName, oldVersion, vars) {
 			successCount++
 			continue
 		}
+		bases, err := s.rulesetBases(ctx, pkg)
+		if err != nil {
+			lastErr = err
+			continue
+		}
 
 		if oldVersion != "" {
-			if err := s.uninstallFromSinks(rulesetConfig.Sinks, allSinks, registryName, packageName); err != nil {
+			if err := s.uninstallFromSinks(ctx, rulesetConfig.Sinks, allSinks, registryName, packageName); err != nil {
 				fmt.Fprintf(os.Stderr, "Warning: failed to uninstall '%s': %v\n", key, err)
 				lastErr = err
 				continue
@@ -783,8 +1627,13 @@ func (s *ArmService) UpdateAll(ctx context.Context) error {
 
 		for _, sinkName := range rulesetConfig.Sinks {
 			sinkConfig := allSinks[sinkName]
-			sinkMgr := sink.NewManager(sinkConfig.Directory, sinkConfig.Tool)
-			if err := sinkMgr.InstallRuleset(pkg, rulesetConfig.Priority); err != nil {
+			sinkMgr, err := s.newSinkManager(ctx, sinkConfig)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to install '%s' to sink '%s': %v\n", key, sinkName, err)
+				lastErr = err
+				continue
+			}
+			if err := sinkMgr.InstallRuleset(pkg, rulesetConfig.Priority, rulesetConfig.Overlays, vars, bases); err != nil {
 				fmt.Fprintf(os.Stderr, "Warning: failed to install '%s' to sink '%s': %v\n", key, sinkName, err)
 				lastErr = err
 				continue
@@ -802,24 +1651,30 @@ func (s *ArmService) UpdateAll(ctx context.Context) error {
 		successCount++
 	}
 
-	for key, promptsetConfig := range promptsets {
+	for _, key := range sortedKeys(baseDeps) {
+		depConfig := baseDeps[key]
 		registryName, packageName := manifest.ParseDependencyKey(key)
 
 		oldVersion := s.getOldVersionFromLock(lockFile, key)
-		newVersion, pkg, err := s.resolveAndFetchUpdate(ctx, registryName, packageName, promptsetConfig.Version, promptsetConfig.Include, promptsetConfig.Exclude)
+		newVersion, pkg, err := s.resolveAndFetchUpdate(ctx, registryName, packageName, depConfig.Version, depConfig.Include, depConfig.Exclude)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: failed to update '%s': %v\n", key, err)
 			lastErr = err
 			continue
 		}
+		vars, err := s.resolveVars(ctx, depConfig.Vars)
+		if err != nil {
+			lastErr = err
+			continue
+		}
 
-		if oldVersion == newVersion {
+		if oldVersion == newVersion && !s.varsChanged(ctx, depConfig.Sinks, allSinks, registryName, packageName, oldVersion, vars) {
 			successCount++
 			continue
 		}
 
 		if oldVersion != "" {
-			if err := s.uninstallFromSinks(promptsetConfig.Sinks, allSinks, registryName, packageName); err != nil {
+			if err := s.uninstallFromSinks(ctx, depConfig.Sinks, allSinks, registryName, packageName); err != nil {
 				fmt.Fprintf(os.Stderr, "Warning: failed to uninstall '%s': %v\n", key, err)
 				lastErr = err
 				continue
@@ -831,10 +1686,15 @@ func (s *ArmService) UpdateAll(ctx context.Context) error {
 			}
 		}
 
-		for _, sinkName := range promptsetConfig.Sinks {
+		for _, sinkName := range depConfig.Sinks {
 			sinkConfig := allSinks[sinkName]
-			sinkMgr := sink.NewManager(sinkConfig.Directory, sinkConfig.Tool)
-			if err := sinkMgr.InstallPromptset(pkg); err != nil {
+			sinkMgr, err := s.newSinkManager(ctx, sinkConfig)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to install '%s' to sink '%s': %v\n", key, sinkName, err)
+				lastErr = err
+				continue
+			}
+			if err := installToSink(sinkMgr, string(depConfig.Type), pkg, 0, nil, vars, nil); err != nil {
 				fmt.Fprintf(os.Stderr, "Warning: failed to install '%s' to sink '%s': %v\n", key, sinkName, err)
 				lastErr = err
 				continue
@@ -856,7 +1716,8 @@ func (s *ArmService) UpdateAll(ctx context.Context) error {
 		return lastErr
 	}
 
-	return nil
+	// Rulesets extending the packages that changed are rebuilt on top of them
+	return s.refreshRulesets(ctx)
 }
 
 func (s *ArmService) getOldVersionFromLock(lockFile *packagelockfile.LockFile, key string) string {
@@ -873,42 +1734,30 @@ func (s *ArmService) getOldVersionFromLock(lockFile *packagelockfile.LockFile, k
 }
 
 func (s *ArmService) resolveAndFetchUpdate(ctx context.Context, registryName, packageName, version string, include, exclude []string) (string, *core.Package, error) {
-	regConfig, err := s.manifestMgr.GetRegistryConfig(ctx, registryName)
+	resolvedVersion, pkg, err := s.fetch(ctx, fetchRequest{
+		registryName: registryName,
+		packageName:  packageName,
+		constraint:   version,
+		include:      include,
+		exclude:      exclude,
+	})
 	if err != nil {
 		return "", nil, err
 	}
+	return resolvedVersion.Version, pkg, nil
+}
 
-	reg, err := s.registryFactory.CreateRegistry(registryName, regConfig)
-	if err != nil {
-		return "", nil, err
-	}
-
-	availableVersions, err := reg.ListPackageVersions(ctx, packageName)
-	if err != nil {
-		return "", nil, err
-	}
-
-	resolvedVersion, err := core.ResolveVersion(version, availableVersions)
-	if err != nil {
-		return "", nil, err
-	}
-
-	pkg, err := reg.GetPackage(ctx, packageName, &resolvedVersion, include, exclude)
-	if err != nil {
-		return "", nil, err
-	}
-
-	return resolvedVersion.Version, pkg, nil
-}
-
-func (s *ArmService) uninstallFromSinks(sinkNames []string, allSinks map[string]manifest.SinkConfig, registryName, packageName string) error {
+func (s *ArmService) uninstallFromSinks(ctx context.Context, sinkNames []string, allSinks map[string]manifest.SinkConfig, registryName, packageName string) error {
 	for _, sinkName := range sinkNames {
 		sinkConfig, exists := allSinks[sinkName]
 		if !exists {
 			continue
 		}
 
-		sinkMgr := sink.NewManager(sinkConfig.Directory, sinkConfig.Tool)
+		sinkMgr, err := s.newSinkManager(ctx, sinkConfig)
+		if err != nil {
+			return err
+		}
 		if err := sinkMgr.Uninstall(registryName, packageName); err != nil {
 			return err
 		}
@@ -923,7 +1772,8 @@ func (s *ArmService) UpgradeAll(ctx context.Context) error {
 		return err
 	}
 
-	promptsets, err := s.manifestMgr.GetAllPromptsetDependenciesConfig(ctx)
+	// Promptsets, mcpsets, agentsets, skillsets and hooksets share the same dependency settings
+	baseDeps, err := s.getAllBaseDependenciesConfig(ctx)
 	if err != nil {
 		return err
 	}
@@ -938,10 +1788,22 @@ func (s *ArmService) UpgradeAll(ctx context.Context) error {
 		return err
 	}
 
+	// Fetch every package concurrently, then apply them one at a time in a fixed order
+	defer s.startBatch()()
+	var requests []fetchRequest
+	for key, config := range rulesets {
+		requests = append(requests, newFetchRequest(key, &config.BaseDependencyConfig, true))
+	}
+	for key, config := range baseDeps {
+		requests = append(requests, newFetchRequest(key, config, true))
+	}
+	s.prefetch(ctx, requests)
+
 	successCount := 0
 	var lastErr error
 
-	for key, rulesetConfig := range rulesets {
+	for _, key := range sortedKeys(rulesets) {
+		rulesetConfig := rulesets[key]
 		registryName, packageName := manifest.ParseDependencyKey(key)
 
 		oldVersion := s.getOldVersionFromLock(lockFile, key)
@@ -951,14 +1813,24 @@ func (s *ArmService) UpgradeAll(ctx context.Context) error {
 			lastErr = err
 			continue
 		}
+		vars, err := s.resolveVars(ctx, rulesetConfig.Vars)
+		if err != nil {
+			lastErr = err
+			continue
+		}
 
-		if oldVersion == latestVersion {
+		if oldVersion == latestVersion && !s.varsChanged(ctx, rulesetConfig.Sinks, allSinks, registryName, packageName, oldVersion, vars) {
 			successCount++
 			continue
 		}
+		bases, err := s.rulesetBases(ctx, pkg)
+		if err != nil {
+			lastErr = err
+			continue
+		}
 
 		if oldVersion != "" {
-			if err := s.uninstallFromSinks(rulesetConfig.Sinks, allSinks, registryName, packageName); err != nil {
+			if err := s.uninstallFromSinks(ctx, rulesetConfig.Sinks, allSinks, registryName, packageName); err != nil {
 				fmt.Fprintf(os.Stderr, "Warning: failed to uninstall '%s': %v\n", key, err)
 				lastErr = err
 				continue
@@ -972,8 +1844,13 @@ func (s *ArmService) UpgradeAll(ctx context.Context) error {
 
 		for _, sinkName := range rulesetConfig.Sinks {
 			sinkConfig := allSinks[sinkName]
-			sinkMgr := sink.NewManager(sinkConfig.Directory, sinkConfig.Tool)
-			if err := sinkMgr.InstallRuleset(pkg, rulesetConfig.Priority); err != nil {
+			sinkMgr, err := s.newSinkManager(ctx, sinkConfig)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to install '%s' to sink '%s': %v\n", key, sinkName, err)
+				lastErr = err
+				continue
+			}
+			if err := sinkMgr.InstallRuleset(pkg, rulesetConfig.Priority, rulesetConfig.Overlays, vars, bases); err != nil {
 				fmt.Fprintf(os.Stderr, "Warning: failed to install '%s' to sink '%s': %v\n", key, sinkName, err)
 				lastErr = err
 				continue
@@ -999,24 +1876,30 @@ func (s *ArmService) UpgradeAll(ctx context.Context) error {
 		successCount++
 	}
 
-	for key, promptsetConfig := range promptsets {
+	for _, key := range sortedKeys(baseDeps) {
+		depConfig := baseDeps[key]
 		registryName, packageName := manifest.ParseDependencyKey(key)
 
 		oldVersion := s.getOldVersionFromLock(lockFile, key)
-		latestVersion, pkg, err := s.fetchLatest(ctx, registryName, packageName, promptsetConfig.Include, promptsetConfig.Exclude)
+		latestVersion, pkg, err := s.fetchLatest(ctx, registryName, packageName, depConfig.Include, depConfig.Exclude)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: failed to upgrade '%s': %v\n", key, err)
 			lastErr = err
 			continue
 		}
+		vars, err := s.resolveVars(ctx, depConfig.Vars)
+		if err != nil {
+			lastErr = err
+			continue
+		}
 
-		if oldVersion == latestVersion {
+		if oldVersion == latestVersion && !s.varsChanged(ctx, depConfig.Sinks, allSinks, registryName, packageName, oldVersion, vars) {
 			successCount++
 			continue
 		}
 
 		if oldVersion != "" {
-			if err := s.uninstallFromSinks(promptsetConfig.Sinks, allSinks, registryName, packageName); err != nil {
+			if err := s.uninstallFromSinks(ctx, depConfig.Sinks, allSinks, registryName, packageName); err != nil {
 				fmt.Fprintf(os.Stderr, "Warning: failed to uninstall '%s': %v\n", key, err)
 				lastErr = err
 				continue
@@ -1028,10 +1911,15 @@ func (s *ArmService) UpgradeAll(ctx context.Context) error {
 			}
 		}
 
-		for _, sinkName := range promptsetConfig.Sinks {
+		for _, sinkName := range depConfig.Sinks {
 			sinkConfig := allSinks[sinkName]
-			sinkMgr := sink.NewManager(sinkConfig.Directory, sinkConfig.Tool)
-			if err := sinkMgr.InstallPromptset(pkg); err != nil {
+			sinkMgr, err := s.newSinkManager(ctx, sinkConfig)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to install '%s' to sink '%s': %v\n", key, sinkName, err)
+				lastErr = err
+				continue
+			}
+			if err := installToSink(sinkMgr, string(depConfig.Type), pkg, 0, nil, vars, nil); err != nil {
 				fmt.Fprintf(os.Stderr, "Warning: failed to install '%s' to sink '%s': %v\n", key, sinkName, err)
 				lastErr = err
 				continue
@@ -1047,8 +1935,8 @@ func (s *ArmService) UpgradeAll(ctx context.Context) error {
 		}
 
 		newConstraint := fmt.Sprintf("^%d.0.0", pkg.Metadata.Version.Major)
-		promptsetConfig.Version = newConstraint
-		if err := s.manifestMgr.UpsertPromptsetDependencyConfig(ctx, registryName, packageName, promptsetConfig); err != nil {
+		depConfig.Version = newConstraint
+		if err := s.upsertBaseDependencyConfig(ctx, registryName, packageName, depConfig); err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: failed to update manifest for '%s': %v\n", key, err)
 			lastErr = err
 			continue
@@ -1061,7 +1949,8 @@ func (s *ArmService) UpgradeAll(ctx context.Context) error {
 		return lastErr
 	}
 
-	return nil
+	// Rulesets extending the packages that changed are rebuilt on top of them
+	return s.refreshRulesets(ctx)
 }
 
 func (s *ArmService) UpgradePackages(ctx context.Context, packages []string) error {
@@ -1104,6 +1993,8 @@ func (s *ArmService) UpgradePackages(ctx context.Context, packages []string) err
 		var include []string
 		var exclude []string
 		var priority int
+		var overlays map[string]compiler.RuleOverlay
+		var depVars map[string]string
 
 		switch depType {
 		case "ruleset":
@@ -1116,6 +2007,8 @@ func (s *ArmService) UpgradePackages(ctx context.Context, packages []string) err
 			include = rulesetConfig.Include
 			exclude = rulesetConfig.Exclude
 			priority = rulesetConfig.Priority
+			overlays = rulesetConfig.Overlays
+			depVars = rulesetConfig.Vars
 		case "promptset":
 			promptsetConfig, err := s.manifestMgr.GetPromptsetDependencyConfig(ctx, registryName, packageName)
 			if err != nil {
@@ -1125,6 +2018,17 @@ func (s *ArmService) UpgradePackages(ctx context.Context, packages []string) err
 			sinks = promptsetConfig.Sinks
 			include = promptsetConfig.Include
 			exclude = promptsetConfig.Exclude
+			depVars = promptsetConfig.Vars
+		case "mcpset", "agentset", "skillset", "hookset":
+			baseConfig, err := s.getBaseDependencyConfig(ctx, depType, registryName, packageName)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			sinks = baseConfig.Sinks
+			include = baseConfig.Include
+			exclude = baseConfig.Exclude
+			depVars = baseConfig.Vars
 		default:
 			fmt.Fprintf(os.Stderr, "Warning: unknown package type '%s' for '%s'\n", depType, pkg)
 			lastErr = fmt.Errorf("unknown package type: %s", depType)
@@ -1138,14 +2042,24 @@ func (s *ArmService) UpgradePackages(ctx context.Context, packages []string) err
 			lastErr = err
 			continue
 		}
+		vars, err := s.resolveVars(ctx, depVars)
+		if err != nil {
+			lastErr = err
+			continue
+		}
 
-		if oldVersion == newVersion {
+		if oldVersion == newVersion && !s.varsChanged(ctx, sinks, allSinks, registryName, packageName, oldVersion, vars) {
 			successCount++
 			continue
 		}
+		bases, err := s.rulesetBases(ctx, fetchedPkg)
+		if err != nil {
+			lastErr = err
+			continue
+		}
 
 		if oldVersion != "" {
-			if err := s.uninstallFromSinks(sinks, allSinks, registryName, packageName); err != nil {
+			if err := s.uninstallFromSinks(ctx, sinks, allSinks, registryName, packageName); err != nil {
 				lastErr = err
 				continue
 			}
@@ -1157,17 +2071,14 @@ func (s *ArmService) UpgradePackages(ctx context.Context, packages []string) err
 
 		for _, sinkName := range sinks {
 			sinkConfig := allSinks[sinkName]
-			sinkMgr := sink.NewManager(sinkConfig.Directory, sinkConfig.Tool)
-			if depType == "ruleset" {
-				if err := sinkMgr.InstallRuleset(fetchedPkg, priority); err != nil {
-					lastErr = err
-					continue
-				}
-			} else {
-				if err := sinkMgr.InstallPromptset(fetchedPkg); err != nil {
-					lastErr = err
-					continue
-				}
+			sinkMgr, err := s.newSinkManager(ctx, sinkConfig)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			if err := installToSink(sinkMgr, depType, fetchedPkg, priority, overlays, vars, bases); err != nil {
+				lastErr = err
+				continue
 			}
 		}
 
@@ -1179,20 +2090,28 @@ func (s *ArmService) UpgradePackages(ctx context.Context, packages []string) err
 		}
 
 		newConstraint := fmt.Sprintf("^%d.0.0", fetchedPkg.Metadata.Version.Major)
-		if depType == "ruleset" {
+		switch depType {
+		case "ruleset":
 			rulesetConfig, _ := s.manifestMgr.GetRulesetDependencyConfig(ctx, registryName, packageName)
 			rulesetConfig.Version = newConstraint
 			if err := s.manifestMgr.UpsertRulesetDependencyConfig(ctx, registryName, packageName, rulesetConfig); err != nil {
 				lastErr = err
 				continue
 			}
-		} else {
+		case "promptset":
 			promptsetConfig, _ := s.manifestMgr.GetPromptsetDependencyConfig(ctx, registryName, packageName)
 			promptsetConfig.Version = newConstraint
 			if err := s.manifestMgr.UpsertPromptsetDependencyConfig(ctx, registryName, packageName, promptsetConfig); err != nil {
 				lastErr = err
 				continue
 			}
+		case "mcpset", "agentset", "skillset", "hookset":
+			baseConfig, _ := s.getBaseDependencyConfig(ctx, depType, registryName, packageName)
+			baseConfig.Version = newConstraint
+			if err := s.upsertBaseDependencyConfig(ctx, registryName, packageName, baseConfig); err != nil {
+				lastErr = err
+				continue
+			}
 		}
 
 		successCount++
@@ -1202,36 +2121,21 @@ func (s *ArmService) UpgradePackages(ctx context.Context, packages []string) err
 		return lastErr
 	}
 
-	return nil
+	// Rulesets extending the packages that changed are rebuilt on top of them
+	return s.refreshRulesets(ctx)
 }
 
 func (s *ArmService) fetchLatest(ctx context.Context, registryName, packageName string, include, exclude []string) (string, *core.Package, error) {
-	regConfig, err := s.manifestMgr.GetRegistryConfig(ctx, registryName)
-	if err != nil {
-		return "", nil, err
-	}
-
-	reg, err := s.registryFactory.CreateRegistry(registryName, regConfig)
-	if err != nil {
-		return "", nil, err
-	}
-
-	availableVersions, err := reg.ListPackageVersions(ctx, packageName)
-	if err != nil {
-		return "", nil, err
-	}
-
-	if len(availableVersions) == 0 {
-		return "", nil, fmt.Errorf("no versions available for %s", packageName)
-	}
-
-	latestVersion := availableVersions[0]
-
-	pkg, err := reg.GetPackage(ctx, packageName, &latestVersion, include, exclude)
+	latestVersion, pkg, err := s.fetch(ctx, fetchRequest{
+		registryName: registryName,
+		packageName:  packageName,
+		latest:       true,
+		include:      include,
+		exclude:      exclude,
+	})
 	if err != nil {
 		return "", nil, err
 	}
-
 	return latestVersion.Version, pkg, nil
 }
 
@@ -1330,181 +2234,751 @@ func (s *ArmService) ListOutdated(ctx context.Context) ([]*OutdatedDependency, e
 		return nil, err
 	}
 
-	var result []*OutdatedDependency
-	for key, config := range allDeps {
-		registryName, packageName := manifest.ParseDependencyKey(key)
-
-		versionConstraint, ok := config["version"].(string)
-		if !ok {
-			continue
-		}
-
-		var currentVersion string
-		if lockFile != nil && lockFile.Dependencies != nil {
-			for lockKey := range lockFile.Dependencies {
-				if strings.HasPrefix(lockKey, key+"@") {
-					currentVersion = lockKey[len(key)+1:]
-					break
-				}
-			}
-		}
+	// Check every dependency concurrently, sharing registries between them
+	defer s.startBatch()()
+	keys := sortedKeys(allDeps)
+	outdated := make([]*OutdatedDependency, len(keys))
+	runJobs(s.jobs, len(keys), func(i int) {
+		outdated[i] = s.checkOutdated(ctx, lockFile, keys[i], allDeps[keys[i]])
+	})
 
-		if currentVersion == "" {
-			continue
+	var result []*OutdatedDependency
+	for _, dep := range outdated {
+		if dep != nil {
+			result = append(result, dep)
 		}
+	}
+	return result, nil
+}
 
-		regConfig, err := s.manifestMgr.GetRegistryConfig(ctx, registryName)
-		if err != nil {
-			continue
-		}
+// checkOutdated returns the dependency's wanted and latest versions, or nil if
+// it is up to date, not locked or its versions cannot be listed
+func (s *ArmService) checkOutdated(ctx context.Context, lockFile *packagelockfile.LockFile, key string, config map[string]interface{}) *OutdatedDependency {
+	registryName, packageName := manifest.ParseDependencyKey(key)
 
-		reg, err := s.registryFactory.CreateRegistry(registryName, regConfig)
-		if err != nil {
-			continue
-		}
+	versionConstraint, ok := config["version"].(string)
+	if !ok {
+		return nil
+	}
 
-		availableVersions, err := reg.ListPackageVersions(ctx, packageName)
-		if err != nil {
-			continue
-		}
+	currentVersion := s.getOldVersionFromLock(lockFile, key)
+	if currentVersion == "" {
+		return nil
+	}
 
-		if len(availableVersions) == 0 {
-			continue
-		}
+	reg, err := s.getRegistry(ctx, registryName)
+	if err != nil {
+		return nil
+	}
 
-		wantedVersion, err := core.ResolveVersion(versionConstraint, availableVersions)
-		if err != nil {
-			continue
-		}
+	availableVersions, err := reg.ListPackageVersions(ctx, packageName)
+	if err != nil {
+		return nil
+	}
 
-		latestVersion := availableVersions[0]
+	if len(availableVersions) == 0 {
+		return nil
+	}
 
-		if wantedVersion.Version != currentVersion || latestVersion.Version != currentVersion {
-			result = append(result, &OutdatedDependency{
-				Current: core.PackageMetadata{
-					Name:         packageName,
-					RegistryName: registryName,
-					Version:      core.Version{Version: currentVersion},
-				},
-				Constraint: versionConstraint,
-				Wanted: core.PackageMetadata{
-					Name:         packageName,
-					RegistryName: registryName,
-					Version:      wantedVersion,
-				},
-				Latest: core.PackageMetadata{
-					Name:         packageName,
-					RegistryName: registryName,
-					Version:      latestVersion,
-				},
-			})
-		}
+	wantedVersion, err := core.ResolveVersion(versionConstraint, availableVersions)
+	if err != nil {
+		return nil
 	}
 
-	return result, nil
-}
+	latestVersion := availableVersions[0]
 
-// SetRulesetName sets ruleset name
-func (s *ArmService) SetRulesetName(ctx context.Context, registry, ruleset, newName string) error {
-	return s.manifestMgr.UpdateDependencyConfigName(ctx, registry, ruleset, registry, newName)
+	if wantedVersion.Version == currentVersion && latestVersion.Version == currentVersion {
+		return nil
+	}
+	return &OutdatedDependency{
+		Current: core.PackageMetadata{
+			Name:         packageName,
+			RegistryName: registryName,
+			Version:      core.Version{Version: currentVersion},
+		},
+		Constraint: versionConstraint,
+		Wanted: core.PackageMetadata{
+			Name:         packageName,
+			RegistryName: registryName,
+			Version:      wantedVersion,
+		},
+		Latest: core.PackageMetadata{
+			Name:         packageName,
+			RegistryName: registryName,
+			Version:      latestVersion,
+		},
+	}
 }
 
-// SetRulesetVersion sets ruleset version
-func (s *ArmService) SetRulesetVersion(ctx context.Context, registry, ruleset, version string) error {
-	config, err := s.manifestMgr.GetRulesetDependencyConfig(ctx, registry, ruleset)
+// Changelog returns the release notes for a version of a package: the
+// CHANGELOG.md it ships, or otherwise the annotation of its git tag. It returns
+// an empty string if the version has neither.
+func (s *ArmService) Changelog(ctx context.Context, registryName, packageName, version string) (string, error) {
+	_, pkg, err := s.fetch(ctx, fetchRequest{
+		registryName: registryName,
+		packageName:  packageName,
+		constraint:   version,
+		include:      []string{"CHANGELOG.md", "**/CHANGELOG.md"},
+	})
 	if err != nil {
-		return err
+		return "", fmt.Errorf("failed to fetch %s/%s@%s: %w", registryName, packageName, version, err)
 	}
-	config.Version = version
-	return s.manifestMgr.UpsertRulesetDependencyConfig(ctx, registry, ruleset, config)
-}
 
-// SetRulesetPriority sets ruleset priority
-func (s *ArmService) SetRulesetPriority(ctx context.Context, registry, ruleset string, priority int) error {
-	config, err := s.manifestMgr.GetRulesetDependencyConfig(ctx, registry, ruleset)
-	if err != nil {
-		return err
+	// Prefer the changelog closest to the package root
+	var changelog *core.File
+	for _, file := range pkg.Files {
+		if filepath.Base(file.Path) != "CHANGELOG.md" {
+			continue
+		}
+		if changelog == nil || strings.Count(file.Path, "/") < strings.Count(changelog.Path, "/") {
+			changelog = file
+		}
+	}
+	if changelog != nil {
+		return strings.TrimSpace(string(changelog.Content)), nil
 	}
-	config.Priority = priority
-	return s.manifestMgr.UpsertRulesetDependencyConfig(ctx, registry, ruleset, config)
-}
 
-// SetRulesetInclude sets ruleset include patterns
-func (s *ArmService) SetRulesetInclude(ctx context.Context, registry, ruleset string, include []string) error {
-	config, err := s.manifestMgr.GetRulesetDependencyConfig(ctx, registry, ruleset)
+	reg, err := s.getRegistry(ctx, registryName)
 	if err != nil {
-		return err
+		return "", err
 	}
-	config.Include = include
-	return s.manifestMgr.UpsertRulesetDependencyConfig(ctx, registry, ruleset, config)
+	if tagged, ok := reg.(registry.TagMessenger); ok {
+		message, err := tagged.GetTagMessage(ctx, &pkg.Metadata.Version)
+		if err != nil {
+			return "", fmt.Errorf("failed to read tag %s: %w", pkg.Metadata.Version.Version, err)
+		}
+		return message, nil
+	}
+	return "", nil
 }
 
-// SetRulesetExclude sets ruleset exclude patterns
-func (s *ArmService) SetRulesetExclude(ctx context.Context, registry, ruleset string, exclude []string) error {
-	config, err := s.manifestMgr.GetRulesetDependencyConfig(ctx, registry, ruleset)
-	if err != nil {
-		return err
-	}
-	config.Exclude = exclude
-	return s.manifestMgr.UpsertRulesetDependencyConfig(ctx, registry, ruleset, config)
+// SearchResult is a registry package whose name or description matched a search
+type SearchResult struct {
+	Registry    string `json:"registry"`
+	Package     string `json:"package"`
+	Latest      string `json:"latest,omitempty"`
+	Description string `json:"description,omitempty"`
 }
 
-// SetRulesetSinks sets ruleset sinks
-func (s *ArmService) SetRulesetSinks(ctx context.Context, registry, ruleset string, sinks []string) error {
-	allSinks, err := s.manifestMgr.GetAllSinksConfig(ctx)
+// Search lists the packages whose name or description contains query, ignoring
+// case, in the named registries or in every configured registry if none are
+// named. Registries are searched concurrently, up to the configured number of
+// jobs at a time. Git registries define no packages of their own, so they
+// never match. A registry that cannot be listed is reported as a warning, and
+// fails the search only if every registry does.
+func (s *ArmService) Search(ctx context.Context, query string, registryNames []string) ([]*SearchResult, error) {
+	allRegistries, err := s.manifestMgr.GetAllRegistriesConfig(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	for _, sinkName := range sinks {
-		if _, exists := allSinks[sinkName]; !exists {
-			return fmt.Errorf("sink %s does not exist", sinkName)
+	if len(registryNames) == 0 {
+		registryNames = sortedKeys(allRegistries)
+	}
+	for _, name := range registryNames {
+		if _, ok := allRegistries[name]; !ok {
+			return nil, fmt.Errorf("registry %s not found", name)
 		}
 	}
-	config, err := s.manifestMgr.GetRulesetDependencyConfig(ctx, registry, ruleset)
-	if err != nil {
-		return err
+
+	defer s.startBatch()()
+	query = strings.ToLower(query)
+	matches := make([][]*SearchResult, len(registryNames))
+	errs := make([]error, len(registryNames))
+	runJobs(s.jobs, len(registryNames), func(i int) {
+		matches[i], errs[i] = s.searchRegistry(ctx, registryNames[i], query)
+	})
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
-	config.Sinks = sinks
-	return s.manifestMgr.UpsertRulesetDependencyConfig(ctx, registry, ruleset, config)
-}
 
-// SetPromptsetName sets promptset name
-func (s *ArmService) SetPromptsetName(ctx context.Context, registry, ruleset, newName string) error {
-	return s.manifestMgr.UpdateDependencyConfigName(ctx, registry, ruleset, registry, newName)
+	results := []*SearchResult{}
+	failed := 0
+	var lastErr error
+	for i, name := range registryNames {
+		if errs[i] != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to search registry '%s': %v\n", name, errs[i])
+			failed++
+			lastErr = errs[i]
+			continue
+		}
+		results = append(results, matches[i]...)
+	}
+	if failed > 0 && failed == len(registryNames) {
+		return nil, lastErr
+	}
+	return results, nil
 }
 
-// SetPromptsetVersion sets promptset version
-func (s *ArmService) SetPromptsetVersion(ctx context.Context, registry, promptset, version string) error {
-	config, err := s.manifestMgr.GetPromptsetDependencyConfig(ctx, registry, promptset)
+// searchRegistry returns the registry's packages that match the lowercased
+// query, ordered by name, with their latest versions
+func (s *ArmService) searchRegistry(ctx context.Context, registryName, query string) ([]*SearchResult, error) {
+	reg, err := s.getRegistry(ctx, registryName)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	config.Version = version
-	return s.manifestMgr.UpsertPromptsetDependencyConfig(ctx, registry, promptset, config)
-}
-
-// SetPromptsetInclude sets promptset include patterns
-func (s *ArmService) SetPromptsetInclude(ctx context.Context, registry, promptset string, include []string) error {
-	config, err := s.manifestMgr.GetPromptsetDependencyConfig(ctx, registry, promptset)
+	packages, err := reg.ListPackages(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	config.Include = include
-	return s.manifestMgr.UpsertPromptsetDependencyConfig(ctx, registry, promptset, config)
-}
 
-// SetPromptsetExclude sets promptset exclude patterns
-func (s *ArmService) SetPromptsetExclude(ctx context.Context, registry, promptset string, exclude []string) error {
-	config, err := s.manifestMgr.GetPromptsetDependencyConfig(ctx, registry, promptset)
+	var results []*SearchResult
+	for _, pkg := range packages {
+		if !strings.Contains(strings.ToLower(pkg.Name), query) && !strings.Contains(strings.ToLower(pkg.Description), query) {
+			continue
+		}
+		versions, err := reg.ListPackageVersions(ctx, pkg.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list versions of %s: %w", pkg.Name, err)
+		}
+		result := &SearchResult{Registry: registryName, Package: pkg.Name, Description: pkg.Description}
+		if len(versions) > 0 {
+			result.Latest = versions[0].Version
+		}
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Package < results[j].Package
+	})
+	return results, nil
+}
+
+// RegistryTree groups the configured packages of a registry
+type RegistryTree struct {
+	Name     string
+	Packages []*PackageTree
+}
+
+// PackageTree is a configured dependency and the files it installed in each of its sinks
+type PackageTree struct {
+	Name     string
+	Type     string
+	Version  string // Locked version; empty if the package is not installed
+	Priority int    // Rulesets only
+	Sinks    []*SinkTree
+}
+
+// SinkTree lists the files a package installed in one sink
+type SinkTree struct {
+	Name      string
+	Directory string
+	Files     []sink.InstalledFile
+}
+
+// Tree returns every configured dependency grouped by registry, with the files
+// it installed in each sink according to the lockfile and the sink indexes.
+// Registries and packages are ordered by name.
+func (s *ArmService) Tree(ctx context.Context) ([]*RegistryTree, error) {
+	rulesets, err := s.manifestMgr.GetAllRulesetDependenciesConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	deps, err := s.getAllBaseDependenciesConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for key, config := range rulesets {
+		deps[key] = &config.BaseDependencyConfig
+	}
+
+	allSinks, err := s.manifestMgr.GetAllSinksConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	lockFile, err := s.lockfileMgr.GetLockFile(ctx)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	// Each sink index is read once, however many packages install into it
+	sinkFiles := make(map[string][]sink.InstalledFile)
+	filesOf := func(name string) ([]sink.InstalledFile, error) {
+		if files, ok := sinkFiles[name]; ok {
+			return files, nil
+		}
+		sinkMgr, err := s.newSinkManager(ctx, allSinks[name])
+		if err != nil {
+			return nil, err
+		}
+		files, err := sinkMgr.Files()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read index of sink %s: %w", name, err)
+		}
+		sinkFiles[name] = files
+		return files, nil
+	}
+
+	var trees []*RegistryTree
+	for _, key := range sortedKeys(deps) {
+		config := deps[key]
+		registryName, packageName := manifest.ParseDependencyKey(key)
+		pkg := &PackageTree{
+			Name:     packageName,
+			Type:     string(config.Type),
+			Version:  s.getOldVersionFromLock(lockFile, key),
+			Priority: rulesets[key].Priority,
+		}
+
+		for _, sinkName := range config.Sinks {
+			sinkConfig, ok := allSinks[sinkName]
+			if !ok {
+				return nil, fmt.Errorf("sink %s not found", sinkName)
+			}
+			sinkTree := &SinkTree{Name: sinkName, Directory: sinkConfig.Directory}
+			if pkg.Version != "" {
+				files, err := filesOf(sinkName)
+				if err != nil {
+					return nil, err
+				}
+				for _, file := range files {
+					if file.Package == key+"@"+pkg.Version {
+						sinkTree.Files = append(sinkTree.Files, file)
+					}
+				}
+			}
+			pkg.Sinks = append(pkg.Sinks, sinkTree)
+		}
+
+		if len(trees) == 0 || trees[len(trees)-1].Name != registryName {
+			trees = append(trees, &RegistryTree{Name: registryName})
+		}
+		registryTree := trees[len(trees)-1]
+		registryTree.Packages = append(registryTree.Packages, pkg)
+	}
+	return trees, nil
+}
+
+// FileOrigin traces a file in a sink back to the package that installed it
+type FileOrigin struct {
+	Sink string
+	sink.InstalledFile
+}
+
+// Why reports which package installed the file at path. A shared configuration
+// file, such as an MCP configuration, may have several origins.
+func (s *ArmService) Why(ctx context.Context, path string) ([]*FileOrigin, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	allSinks, err := s.manifestMgr.GetAllSinksConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var origins []*FileOrigin
+	inSink := false
+	for _, name := range sortedKeys(allSinks) {
+		sinkDir, err := filepath.Abs(s.sinkDirectory(allSinks[name].Directory))
+		if err != nil {
+			return nil, err
+		}
+		relPath, err := filepath.Rel(sinkDir, absPath)
+		if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+			continue
+		}
+		inSink = true
+
+		sinkMgr, err := s.newSinkManager(ctx, allSinks[name])
+		if err != nil {
+			return nil, err
+		}
+		if sinkMgr.IsIndexFile(relPath) {
+			return nil, fmt.Errorf("%s is the index ARM maintains for sink %s", path, name)
+		}
+		files, err := sinkMgr.FindFile(relPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read index of sink %s: %w", name, err)
+		}
+		for _, file := range files {
+			origins = append(origins, &FileOrigin{Sink: name, InstalledFile: file})
+		}
+	}
+
+	if !inSink {
+		return nil, fmt.Errorf("%s is not in any sink directory", path)
+	}
+	if len(origins) == 0 {
+		return nil, fmt.Errorf("%s was not installed by ARM", path)
+	}
+	return origins, nil
+}
+
+// VerifyReport lists where the project differs from what ARM installed
+type VerifyReport struct {
+	Files    []FileDrift    `json:"files"`
+	Packages []PackageDrift `json:"packages"`
+}
+
+// FileDrift is a sink file that was edited, deleted or added outside ARM
+type FileDrift struct {
+	Sink    string `json:"sink"`
+	Path    string `json:"path"`
+	Kind    string `json:"kind"`
+	Package string `json:"package,omitempty"`
+}
+
+// PackageDrift is a locked package whose contents no longer match the lockfile
+type PackageDrift struct {
+	Package string `json:"package"`
+	Locked  string `json:"locked"`
+	Actual  string `json:"actual,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Clean reports whether no drift was found
+func (r *VerifyReport) Clean() bool {
+	return len(r.Files) == 0 && len(r.Packages) == 0
+}
+
+// Verify checks every sink against its index and every locked package against
+// its lockfile integrity. Packages are read from the cache, or fetched if they
+// are not cached, up to the configured number of jobs at a time.
+func (s *ArmService) Verify(ctx context.Context) (*VerifyReport, error) {
+	report := &VerifyReport{Files: []FileDrift{}, Packages: []PackageDrift{}}
+
+	allSinks, err := s.manifestMgr.GetAllSinksConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range sortedKeys(allSinks) {
+		sinkMgr, err := s.newSinkManager(ctx, allSinks[name])
+		if err != nil {
+			return nil, err
+		}
+		drifts, err := sinkMgr.Verify()
+		if err != nil {
+			return nil, fmt.Errorf("failed to verify sink %s: %w", name, err)
+		}
+		for _, drift := range drifts {
+			report.Files = append(report.Files, FileDrift{
+				Sink:    name,
+				Path:    filepath.Join(allSinks[name].Directory, drift.Path),
+				Kind:    string(drift.Kind),
+				Package: drift.Package,
+			})
+		}
+	}
+
+	rulesets, err := s.manifestMgr.GetAllRulesetDependenciesConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	deps, err := s.getAllBaseDependenciesConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for key, config := range rulesets {
+		deps[key] = &config.BaseDependencyConfig
+	}
+
+	lockFile, err := s.lockfileMgr.GetLockFile(ctx)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	// Only locked dependencies with a recorded integrity can be checked
+	var keys []string
+	var requests []fetchRequest
+	for _, key := range sortedKeys(deps) {
+		version := s.getOldVersionFromLock(lockFile, key)
+		if version == "" || lockFile.Dependencies[key+"@"+version].Integrity == "" {
+			continue
+		}
+		req := newFetchRequest(key, deps[key], false)
+		req.constraint = version
+		keys = append(keys, key+"@"+version)
+		requests = append(requests, req)
+	}
+
+	defer s.startBatch()()
+	drifts := make([]*PackageDrift, len(requests))
+	runJobs(s.jobs, len(requests), func(i int) {
+		locked := lockFile.Dependencies[keys[i]].Integrity
+		_, pkg, err := s.fetch(ctx, requests[i])
+		switch {
+		case err != nil:
+			drifts[i] = &PackageDrift{Package: keys[i], Locked: locked, Error: err.Error()}
+		case pkg.Integrity != locked:
+			drifts[i] = &PackageDrift{Package: keys[i], Locked: locked, Actual: pkg.Integrity}
+		}
+	})
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	for _, drift := range drifts {
+		if drift != nil {
+			report.Packages = append(report.Packages, *drift)
+		}
+	}
+	return report, nil
+}
+
+// PackageDiff lists the rules and prompts that differ between two versions of a package
+type PackageDiff struct {
+	Package string         `json:"package"`
+	From    string         `json:"from"`
+	To      string         `json:"to"`
+	Rules   []ResourceDiff `json:"rules"`
+	Prompts []ResourceDiff `json:"prompts"`
+}
+
+// ResourceDiff is a rule or prompt, identified as RESOURCE_ID/ITEM_ID, that was
+// added, removed or changed
+type ResourceDiff struct {
+	ID     string          `json:"id"`
+	Action string          `json:"action"`
+	Fields []FieldChange   `json:"fields,omitempty"`
+	Body   []core.DiffLine `json:"body,omitempty"`
+}
+
+// FieldChange is a rule or prompt field whose value changed; empty means unset
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// Diff actions
+const (
+	DiffAdded   = "added"
+	DiffRemoved = "removed"
+	DiffChanged = "changed"
+)
+
+// DiffPackage fetches two versions of a package and compares their rules and
+// prompts by ID. from and to are versions or constraints; from defaults to the
+// locked version and to to the latest available version. Include and exclude
+// patterns come from the manifest when the package is a dependency.
+func (s *ArmService) DiffPackage(ctx context.Context, registryName, packageName, from, to string) (*PackageDiff, error) {
+	key := registryName + "/" + packageName
+	req := fetchRequest{registryName: registryName, packageName: packageName}
+	deps, err := s.getAllBaseDependenciesConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rulesets, err := s.manifestMgr.GetAllRulesetDependenciesConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if config, ok := rulesets[key]; ok {
+		deps[key] = &config.BaseDependencyConfig
+	}
+	if config, ok := deps[key]; ok {
+		req.include, req.exclude = config.Include, config.Exclude
+	}
+
+	if from == "" {
+		lockFile, err := s.lockfileMgr.GetLockFile(ctx)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		from = s.getOldVersionFromLock(lockFile, key)
+		if from == "" {
+			return nil, fmt.Errorf("%s is not installed; specify the version to compare from", key)
+		}
+	}
+
+	fromReq, toReq := req, req
+	fromReq.constraint = from
+	toReq.constraint = to
+	if to == "" {
+		toReq.latest = true
+		to = "latest"
+	}
+
+	fromVersion, fromPkg, err := s.fetch(ctx, fromReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s@%s: %w", key, from, err)
+	}
+	toVersion, toPkg, err := s.fetch(ctx, toReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s@%s: %w", key, to, err)
+	}
+
+	fromRules, fromPrompts, err := packageResources(fromPkg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s@%s: %w", key, fromVersion.Version, err)
+	}
+	toRules, toPrompts, err := packageResources(toPkg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s@%s: %w", key, toVersion.Version, err)
+	}
+
+	return &PackageDiff{
+		Package: key,
+		From:    fromVersion.Version,
+		To:      toVersion.Version,
+		Rules:   diffResources(fromRules, toRules, ruleFields),
+		Prompts: diffResources(fromPrompts, toPrompts, promptFields),
+	}, nil
+}
+
+// packageResources returns the rules and prompts a package defines, keyed by
+// RESOURCE_ID/ITEM_ID. Rules are read as declared, without rules inherited
+// through extends.
+func packageResources(pkg *core.Package) (map[string]resource.Rule, map[string]resource.Prompt, error) {
+	files, err := parser.AssembleMarkdownResources(pkg.Files)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rules := make(map[string]resource.Rule)
+	prompts := make(map[string]resource.Prompt)
+	for _, file := range files {
+		var ruleset *resource.RulesetResource
+		switch {
+		case filetype.IsRulesetFile(file):
+			ruleset, err = parser.ParseRuleset(file)
+		case filetype.IsPromptsetFile(file):
+			promptset, err := parser.ParsePromptset(file)
+			if err != nil {
+				return nil, nil, err
+			}
+			for id, prompt := range promptset.Spec.Prompts {
+				prompts[promptset.Metadata.ID+"/"+id] = prompt
+			}
+			continue
+		case filetype.IsResourceFile(file):
+			continue
+		case filetype.IsLegacyRuleFile(file):
+			ruleset, err = compiler.LegacyRuleset(file)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		for id, rule := range ruleset.Spec.Rules {
+			rules[ruleset.Metadata.ID+"/"+id] = rule
+		}
+	}
+	return rules, prompts, nil
+}
+
+// diffResources compares rules or prompts by ID; fields returns the compared
+// field values and the body of an item
+func diffResources[T any](before, after map[string]T, fields func(item T) ([][2]string, string)) []ResourceDiff {
+	ids := make(map[string]bool)
+	for id := range before {
+		ids[id] = true
+	}
+	for id := range after {
+		ids[id] = true
+	}
+
+	diffs := []ResourceDiff{}
+	for _, id := range sortedKeys(ids) {
+		oldItem, inBefore := before[id]
+		newItem, inAfter := after[id]
+		oldFields, oldBody := fields(oldItem)
+		newFields, newBody := fields(newItem)
+
+		diff := ResourceDiff{ID: id, Action: DiffChanged}
+		switch {
+		case !inBefore:
+			diff.Action = DiffAdded
+		case !inAfter:
+			diff.Action = DiffRemoved
+		}
+		// A missing item is the zero value, so all of its fields are unset
+		for i := range oldFields {
+			if oldFields[i][1] != newFields[i][1] {
+				diff.Fields = append(diff.Fields, FieldChange{Field: oldFields[i][0], Old: oldFields[i][1], New: newFields[i][1]})
+			}
+		}
+		if oldBody != newBody {
+			diff.Body = core.DiffLines(oldBody, newBody)
+		}
+
+		if diff.Action != DiffChanged || len(diff.Fields) > 0 || len(diff.Body) > 0 {
+			diffs = append(diffs, diff)
+		}
+	}
+	return diffs
+}
+
+func ruleFields(rule resource.Rule) ([][2]string, string) {
+	priority := ""
+	if rule.Priority != 0 {
+		priority = strconv.Itoa(rule.Priority)
+	}
+	return [][2]string{
+		{"name", rule.Name},
+		{"description", rule.Description},
+		{"enforcement", rule.Enforcement},
+		{"scope", strings.Join(resource.ScopeGlobs(rule.Scope), ", ")},
+		{"priority", priority},
+	}, rule.Body
+}
+
+func promptFields(prompt resource.Prompt) ([][2]string, string) {
+	var args []string
+	for _, arg := range prompt.Arguments {
+		switch {
+		case arg.Required:
+			args = append(args, arg.Name+" (required)")
+		case arg.Default != "":
+			args = append(args, arg.Name+"="+arg.Default)
+		default:
+			args = append(args, arg.Name)
+		}
+	}
+	return [][2]string{
+		{"name", prompt.Name},
+		{"description", prompt.Description},
+		{"arguments", strings.Join(args, ", ")},
+	}, prompt.Body
+}
+
+// SetRulesetName sets ruleset name
+func (s *ArmService) SetRulesetName(ctx context.Context, registry, ruleset, newName string) error {
+	return s.manifestMgr.UpdateDependencyConfigName(ctx, registry, ruleset, registry, newName)
+}
+
+// SetRulesetVersion sets ruleset version
+func (s *ArmService) SetRulesetVersion(ctx context.Context, registry, ruleset, version string) error {
+	config, err := s.manifestMgr.GetRulesetDependencyConfig(ctx, registry, ruleset)
+	if err != nil {
+		return err
+	}
+	config.Version = version
+	return s.manifestMgr.UpsertRulesetDependencyConfig(ctx, registry, ruleset, config)
+}
+
+// SetRulesetPriority sets ruleset priority
+func (s *ArmService) SetRulesetPriority(ctx context.Context, registry, ruleset string, priority int) error {
+	config, err := s.manifestMgr.GetRulesetDependencyConfig(ctx, registry, ruleset)
+	if err != nil {
+		return err
+	}
+	config.Priority = priority
+	return s.manifestMgr.UpsertRulesetDependencyConfig(ctx, registry, ruleset, config)
+}
+
+// SetRulesetInclude sets ruleset include patterns
+func (s *ArmService) SetRulesetInclude(ctx context.Context, registry, ruleset string, include []string) error {
+	config, err := s.manifestMgr.GetRulesetDependencyConfig(ctx, registry, ruleset)
+	if err != nil {
+		return err
+	}
+	config.Include = include
+	return s.manifestMgr.UpsertRulesetDependencyConfig(ctx, registry, ruleset, config)
+}
+
+// SetRulesetExclude sets ruleset exclude patterns
+func (s *ArmService) SetRulesetExclude(ctx context.Context, registry, ruleset string, exclude []string) error {
+	config, err := s.manifestMgr.GetRulesetDependencyConfig(ctx, registry, ruleset)
 	if err != nil {
 		return err
 	}
 	config.Exclude = exclude
-	return s.manifestMgr.UpsertPromptsetDependencyConfig(ctx, registry, promptset, config)
+	return s.manifestMgr.UpsertRulesetDependencyConfig(ctx, registry, ruleset, config)
 }
 
-// SetPromptsetSinks sets promptset sinks
-func (s *ArmService) SetPromptsetSinks(ctx context.Context, registry, promptset string, sinks []string) error {
+// SetRulesetSinks sets ruleset sinks
+func (s *ArmService) SetRulesetSinks(ctx context.Context, registry, ruleset string, sinks []string) error {
 	allSinks, err := s.manifestMgr.GetAllSinksConfig(ctx)
 	if err != nil {
 		return err
@@ -1514,478 +2988,1635 @@ func (s *ArmService) SetPromptsetSinks(ctx context.Context, registry, promptset
 			return fmt.Errorf("sink %s does not exist", sinkName)
 		}
 	}
-	config, err := s.manifestMgr.GetPromptsetDependencyConfig(ctx, registry, promptset)
-	if err != nil {
-		return err
-	}
-	config.Sinks = sinks
-	return s.manifestMgr.UpsertPromptsetDependencyConfig(ctx, registry, promptset, config)
+	config, err := s.manifestMgr.GetRulesetDependencyConfig(ctx, registry, ruleset)
+	if err != nil {
+		return err
+	}
+	config.Sinks = sinks
+	return s.manifestMgr.UpsertRulesetDependencyConfig(ctx, registry, ruleset, config)
+}
+
+// SetPromptsetName sets promptset name
+func (s *ArmService) SetPromptsetName(ctx context.Context, registry, ruleset, newName string) error {
+	return s.manifestMgr.UpdateDependencyConfigName(ctx, registry, ruleset, registry, newName)
+}
+
+// SetPromptsetVersion sets promptset version
+func (s *ArmService) SetPromptsetVersion(ctx context.Context, registry, promptset, version string) error {
+	config, err := s.manifestMgr.GetPromptsetDependencyConfig(ctx, registry, promptset)
+	if err != nil {
+		return err
+	}
+	config.Version = version
+	return s.manifestMgr.UpsertPromptsetDependencyConfig(ctx, registry, promptset, config)
+}
+
+// SetPromptsetInclude sets promptset include patterns
+func (s *ArmService) SetPromptsetInclude(ctx context.Context, registry, promptset string, include []string) error {
+	config, err := s.manifestMgr.GetPromptsetDependencyConfig(ctx, registry, promptset)
+	if err != nil {
+		return err
+	}
+	config.Include = include
+	return s.manifestMgr.UpsertPromptsetDependencyConfig(ctx, registry, promptset, config)
+}
+
+// SetPromptsetExclude sets promptset exclude patterns
+func (s *ArmService) SetPromptsetExclude(ctx context.Context, registry, promptset string, exclude []string) error {
+	config, err := s.manifestMgr.GetPromptsetDependencyConfig(ctx, registry, promptset)
+	if err != nil {
+		return err
+	}
+	config.Exclude = exclude
+	return s.manifestMgr.UpsertPromptsetDependencyConfig(ctx, registry, promptset, config)
+}
+
+// SetPromptsetSinks sets promptset sinks
+func (s *ArmService) SetPromptsetSinks(ctx context.Context, registry, promptset string, sinks []string) error {
+	allSinks, err := s.manifestMgr.GetAllSinksConfig(ctx)
+	if err != nil {
+		return err
+	}
+	for _, sinkName := range sinks {
+		if _, exists := allSinks[sinkName]; !exists {
+			return fmt.Errorf("sink %s does not exist", sinkName)
+		}
+	}
+	config, err := s.manifestMgr.GetPromptsetDependencyConfig(ctx, registry, promptset)
+	if err != nil {
+		return err
+	}
+	config.Sinks = sinks
+	return s.manifestMgr.UpsertPromptsetDependencyConfig(ctx, registry, promptset, config)
+}
+
+// --------
+// Cleaning
+// --------
+
+// CleanCacheByAge cleans cache by age
+func (s *ArmService) CleanCacheByAge(ctx context.Context, maxAge time.Duration) error {
+	return s.CleanCacheByAgeWithHomeDir(ctx, maxAge, "")
+}
+
+// CleanCacheByAgeWithHomeDir cleans cache by age with custom home directory
+func (s *ArmService) CleanCacheByAgeWithHomeDir(ctx context.Context, maxAge time.Duration, homeDir string) error {
+	storageDir, err := storageDirectory(homeDir)
+	if err != nil {
+		return err
+	}
+	return s.cleanCacheByAgeWithPath(ctx, maxAge, storageDir)
+}
+
+func (s *ArmService) cleanCacheByAgeWithPath(ctx context.Context, maxAge time.Duration, storageDir string) error {
+	registriesDir := filepath.Join(storageDir, "registries")
+	if _, err := os.Stat(registriesDir); os.IsNotExist(err) {
+		return nil
+	}
+
+	entries, err := os.ReadDir(registriesDir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			packagesDir := filepath.Join(registriesDir, entry.Name(), "packages")
+			if _, err := os.Stat(packagesDir); os.IsNotExist(err) {
+				continue
+			}
+			packageCache := storage.NewPackageCache(packagesDir)
+			if err := packageCache.RemoveOldVersions(ctx, maxAge); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// CleanCacheByTimeSinceLastAccess cleans cache by time since last access
+func (s *ArmService) CleanCacheByTimeSinceLastAccess(ctx context.Context, maxTimeSinceLastAccess time.Duration) error {
+	return s.CleanCacheByTimeSinceLastAccessWithHomeDir(ctx, maxTimeSinceLastAccess, "")
+}
+
+// CleanCacheByTimeSinceLastAccessWithHomeDir cleans cache by time since last access with custom home directory
+func (s *ArmService) CleanCacheByTimeSinceLastAccessWithHomeDir(ctx context.Context, maxTimeSinceLastAccess time.Duration, homeDir string) error {
+	storageDir, err := storageDirectory(homeDir)
+	if err != nil {
+		return err
+	}
+	return s.cleanCacheByTimeSinceLastAccessWithPath(ctx, maxTimeSinceLastAccess, storageDir)
+}
+
+func (s *ArmService) cleanCacheByTimeSinceLastAccessWithPath(ctx context.Context, maxTimeSinceLastAccess time.Duration, storageDir string) error {
+	registriesDir := filepath.Join(storageDir, "registries")
+	if _, err := os.Stat(registriesDir); os.IsNotExist(err) {
+		return nil
+	}
+
+	entries, err := os.ReadDir(registriesDir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			packagesDir := filepath.Join(registriesDir, entry.Name(), "packages")
+			if _, err := os.Stat(packagesDir); os.IsNotExist(err) {
+				continue
+			}
+			packageCache := storage.NewPackageCache(packagesDir)
+			if err := packageCache.RemoveUnusedVersions(ctx, maxTimeSinceLastAccess); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// storageDirectory returns the package cache directory under homeDir, which
+// defaults to ARM_HOME or the user's home directory
+func storageDirectory(homeDir string) (string, error) {
+	if homeDir == "" {
+		homeDir = os.Getenv("ARM_HOME")
+		if homeDir == "" {
+			var err error
+			homeDir, err = os.UserHomeDir()
+			if err != nil {
+				return "", err
+			}
+		}
+	}
+	return filepath.Join(homeDir, ".arm", "storage"), nil
+}
+
+// NukeCache nukes the cache
+func (s *ArmService) NukeCache(ctx context.Context) error {
+	return s.NukeCacheWithHomeDir(ctx, "")
+}
+
+// NukeCacheWithHomeDir nukes the cache with custom home directory
+func (s *ArmService) NukeCacheWithHomeDir(ctx context.Context, homeDir string) error {
+	storageDir, err := storageDirectory(homeDir)
+	if err != nil {
+		return err
+	}
+	return s.nukeCacheWithPath(ctx, storageDir)
+}
+
+func (s *ArmService) nukeCacheWithPath(_ context.Context, storageDir string) error {
+	return os.RemoveAll(storageDir)
+}
+
+// PlanNukeCache returns what NukeCache would remove: the storage directory and,
+// down to each registry's cache, everything in it
+func (s *ArmService) PlanNukeCache(ctx context.Context) (*Plan, error) {
+	storageDir, err := storageDirectory("")
+	if err != nil {
+		return nil, err
+	}
+	return planNukeCacheWithPath(storageDir)
+}
+
+// nukePlanDepth is how far below the storage directory a nuke plan lists entries:
+// registries/<registry>/<packages, repo, metadata.json, ...>
+const nukePlanDepth = 3
+
+func planNukeCacheWithPath(storageDir string) (*Plan, error) {
+	if _, err := os.Stat(storageDir); err != nil {
+		if os.IsNotExist(err) {
+			return &Plan{}, nil
+		}
+		return nil, err
+	}
+
+	plan := &Plan{}
+	err := filepath.WalkDir(storageDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		plan.Files = append(plan.Files, PlannedFile{Sink: "cache", Path: path, Action: PlanDelete})
+		rel, err := filepath.Rel(storageDir, path)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && rel != "." && len(strings.Split(rel, string(filepath.Separator))) >= nukePlanDepth {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// CleanSinks cleans sinks
+func (s *ArmService) CleanSinks(ctx context.Context) error {
+	sinks, err := s.manifestMgr.GetAllSinksConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, sinkConfig := range sinks {
+		sinkMgr, err := s.newSinkManager(ctx, sinkConfig)
+		if err != nil {
+			return err
+		}
+		if err := sinkMgr.Clean(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NukeSinks nukes sinks
+func (s *ArmService) NukeSinks(ctx context.Context) error {
+	sinks, err := s.manifestMgr.GetAllSinksConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, sinkConfig := range sinks {
+		sinkMgr, err := s.newSinkManager(ctx, sinkConfig)
+		if err != nil {
+			return err
+		}
+
+		// MCP servers, agents, skills and hooks live outside ARM's own files, so remove them first
+		if err := sinkMgr.UninstallShared(); err != nil {
+			return err
+		}
+
+		if sinkMgr.Layout() == sink.LayoutFlat {
+			// Flat layout: remove arm_* files and arm-index.json
+			entries, err := os.ReadDir(s.sinkDirectory(sinkConfig.Directory))
+			if err != nil {
+				if os.IsNotExist(err) {
+					continue
+				}
+				return err
+			}
+			for _, entry := range entries {
+				name := entry.Name()
+				if len(name) >= 4 && name[:4] == "arm_" || name == "arm-index.json" {
+					_ = os.Remove(filepath.Join(s.sinkDirectory(sinkConfig.Directory), name))
+				}
+			}
+		} else {
+			// Hierarchical layout: remove arm/ directory
+			armDir := filepath.Join(s.sinkDirectory(sinkConfig.Directory), "arm")
+			_ = os.RemoveAll(armDir)
+		}
+	}
+	return nil
+}
+
+// CompileRequest groups compile parameters following ARM patterns
+type CompileRequest struct {
+	Paths        []string
+	Tool         string
+	OutputDir    string
+	Namespace    string
+	Force        bool
+	Recursive    bool
+	Verbose      bool
+	ValidateOnly bool
+	Include      []string
+	Exclude      []string
+	FailFast     bool
+}
+
+// CompileFiles compiles files
+func (s *ArmService) CompileFiles(ctx context.Context, req *CompileRequest) error {
+	// Discover files from input paths
+	files, err := s.discoverFiles(req.Paths, req.Recursive, req.Include, req.Exclude)
+	if err != nil {
+		return fmt.Errorf("failed to discover files: %w", err)
+	}
+
+	if len(files) == 0 {
+		return fmt.Errorf("no files found matching criteria")
+	}
+
+	// Markdown sources compile exactly like the resources they assemble into
+	files, err = parser.AssembleMarkdownResources(files)
+	if err != nil {
+		return err
+	}
+
+	// Rulesets may extend any other ruleset among the compiled files
+	rulesets := parser.ParsePackageRulesets("", files)
+
+	// Process each file
+	var errors []error
+	for _, file := range files {
+		if err := s.compileFile(ctx, file, req, rulesets); err != nil {
+			errors = append(errors, err)
+			if req.FailFast {
+				return err
+			}
+		}
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("compilation failed with %d error(s): %v", len(errors), errors[0])
+	}
+
+	return nil
+}
+
+func (s *ArmService) discoverFiles(paths []string, recursive bool, include, exclude []string) ([]*core.File, error) {
+	// Default include patterns if none specified
+	if len(include) == 0 {
+		include = []string{"*.yml", "*.yaml", "*" + resource.RuleMarkdownSuffix, "*" + resource.PromptMarkdownSuffix}
+	}
+
+	var files []*core.File
+	seen := make(map[string]bool)
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat %s: %w", path, err)
+		}
+
+		if info.IsDir() {
+			// Discover files in directory
+			dirFiles, err := s.discoverInDirectory(path, recursive, include, exclude)
+			if err != nil {
+				return nil, err
+			}
+			for _, f := range dirFiles {
+				if !seen[f.Path] {
+					files = append(files, f)
+					seen[f.Path] = true
+				}
+			}
+		} else if !seen[path] {
+			// Single file
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", path, err)
+			}
+			files = append(files, &core.File{
+				Path:    path,
+				Content: content,
+				Size:    info.Size(),
+			})
+			seen[path] = true
+		}
+	}
+
+	return files, nil
+}
+
+func (s *ArmService) discoverInDirectory(dir string, recursive bool, include, exclude []string) ([]*core.File, error) {
+	var files []*core.File
+
+	walkFn := func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil // Continue on errors
+		}
+
+		if info.IsDir() {
+			if !recursive && path != dir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		// Get relative path for pattern matching
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return nil
+		}
+
+		// Check if file matches patterns
+		if !s.matchesPatterns(relPath, include, exclude) {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil // Continue on errors
+		}
+
+		files = append(files, &core.File{
+			Path:    path,
+			Content: content,
+			Size:    info.Size(),
+		})
+
+		return nil
+	}
+
+	if err := filepath.Walk(dir, walkFn); err != nil {
+		return nil, fmt.Errorf("failed to walk directory %s: %w", dir, err)
+	}
+
+	return files, nil
+}
+
+func (s *ArmService) matchesPatterns(filePath string, include, exclude []string) bool {
+	// Check exclude patterns first
+	for _, pattern := range exclude {
+		if core.MatchPattern(pattern, filePath) {
+			return false
+		}
+	}
+
+	// If no include patterns, accept all
+	if len(include) == 0 {
+		return true
+	}
+
+	// Check include patterns
+	for _, pattern := range include {
+		if core.MatchPattern(pattern, filePath) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *ArmService) parseTool(ctx context.Context, toolStr string) (compiler.Tool, map[compiler.Tool]*compiler.ToolDefinition, error) {
+	tool := compiler.Tool(toolStr)
+	tools, err := s.resolveTool(ctx, tool)
+	if err != nil {
+		return "", nil, err
+	}
+	return tool, tools, nil
+}
+
+func (s *ArmService) compileFile(ctx context.Context, file *core.File, req *CompileRequest, rulesets map[string]*resource.RulesetResource) error {
+	// Detect file type
+	isRuleset := filetype.IsRulesetFile(file)
+	isPromptset := filetype.IsPromptsetFile(file)
+	isMcpset := filetype.IsMcpsetFile(file)
+	isAgentset := filetype.IsAgentsetFile(file)
+	isHookset := filetype.IsHooksetFile(file)
+
+	if !isRuleset && !isPromptset && !isMcpset && !isAgentset && !isHookset {
+		return fmt.Errorf("file %s is not a valid ruleset, promptset, mcpset, agentset or hookset", file.Path)
+	}
+
+	// Parse and validate
+	if isRuleset {
+		ruleset, err := parser.ParseRuleset(file)
+		if err != nil {
+			return fmt.Errorf("failed to parse ruleset %s: %w", file.Path, err)
+		}
+		isExtended := parser.ExtendedRulesets("", rulesets)[ruleset.Metadata.ID]
+		ruleset, err = parser.ResolveExtends(ruleset, "", rulesets)
+		if err != nil {
+			return fmt.Errorf("failed to resolve extends of ruleset %s: %w", file.Path, err)
+		}
+
+		// If validate-only, we're done
+		if req.ValidateOnly {
+			if req.Verbose {
+				fmt.Printf("✓ Validated ruleset: %s\n", file.Path)
+			}
+			return nil
+		}
+
+		// Rulesets extended by another compiled ruleset are compiled through it
+		if isExtended {
+			if req.Verbose {
+				fmt.Printf("✓ Skipped ruleset: %s (extended by another ruleset)\n", file.Path)
+			}
+			return nil
+		}
+
+		// Determine namespace
+		namespace := req.Namespace
+		if namespace == "" {
+			namespace = ruleset.Metadata.ID
+		}
+
+		// Compile
+		tool, tools, err := s.parseTool(ctx, req.Tool)
+		if err != nil {
+			return err
+		}
+
+		compiledFiles, err := compiler.CompileRulesetWithTools(tool, tools, namespace, ruleset)
+		if err != nil {
+			return fmt.Errorf("failed to compile ruleset %s: %w", file.Path, err)
+		}
+
+		// Write output files
+		if err := s.writeCompiledFiles(compiledFiles, req.OutputDir, req.Force); err != nil {
+			return fmt.Errorf("failed to write compiled files for %s: %w", file.Path, err)
+		}
+
+		if req.Verbose {
+			fmt.Printf("✓ Compiled ruleset: %s -> %d file(s)\n", file.Path, len(compiledFiles))
+		}
+
+	} else if isPromptset {
+		promptset, err := parser.ParsePromptset(file)
+		if err != nil {
+			return fmt.Errorf("failed to parse promptset %s: %w", file.Path, err)
+		}
+
+		// If validate-only, we're done
+		if req.ValidateOnly {
+			if req.Verbose {
+				fmt.Printf("✓ Validated promptset: %s\n", file.Path)
+			}
+			return nil
+		}
+
+		// Determine namespace
+		namespace := req.Namespace
+		if namespace == "" {
+			namespace = promptset.Metadata.ID
+		}
+
+		// Compile
+		tool, tools, err := s.parseTool(ctx, req.Tool)
+		if err != nil {
+			return err
+		}
+
+		compiledFiles, err := compiler.CompilePromptsetWithTools(tool, tools, namespace, promptset)
+		if err != nil {
+			return fmt.Errorf("failed to compile promptset %s: %w", file.Path, err)
+		}
+
+		// Write output files
+		if err := s.writeCompiledFiles(compiledFiles, req.OutputDir, req.Force); err != nil {
+			return fmt.Errorf("failed to write compiled files for %s: %w", file.Path, err)
+		}
+
+		if req.Verbose {
+			fmt.Printf("✓ Compiled promptset: %s -> %d file(s)\n", file.Path, len(compiledFiles))
+		}
+	} else if isMcpset {
+		mcpset, err := parser.ParseMcpset(file)
+		if err != nil {
+			return fmt.Errorf("failed to parse mcpset %s: %w", file.Path, err)
+		}
+
+		// If validate-only, we're done
+		if req.ValidateOnly {
+			if req.Verbose {
+				fmt.Printf("✓ Validated mcpset: %s\n", file.Path)
+			}
+			return nil
+		}
+
+		// Compile
+		tool, tools, err := s.parseTool(ctx, req.Tool)
+		if err != nil {
+			return err
+		}
+
+		compiledFiles, err := compiler.CompileMcpsetWithTools(tool, tools, mcpset)
+		if err != nil {
+			return fmt.Errorf("failed to compile mcpset %s: %w", file.Path, err)
+		}
+
+		// Write output files
+		if err := s.writeCompiledFiles(compiledFiles, req.OutputDir, req.Force); err != nil {
+			return fmt.Errorf("failed to write compiled files for %s: %w", file.Path, err)
+		}
+
+		if req.Verbose {
+			fmt.Printf("✓ Compiled mcpset: %s -> %d file(s)\n", file.Path, len(compiledFiles))
+		}
+	} else if isAgentset {
+		agentset, err := parser.ParseAgentset(file)
+		if err != nil {
+			return fmt.Errorf("failed to parse agentset %s: %w", file.Path, err)
+		}
+
+		// If validate-only, we're done
+		if req.ValidateOnly {
+			if req.Verbose {
+				fmt.Printf("✓ Validated agentset: %s\n", file.Path)
+			}
+			return nil
+		}
+
+		// Compile
+		tool, tools, err := s.parseTool(ctx, req.Tool)
+		if err != nil {
+			return err
+		}
+
+		compiledFiles, err := compiler.CompileAgentsetWithTools(tool, tools, agentset)
+		if err != nil {
+			return fmt.Errorf("failed to compile agentset %s: %w", file.Path, err)
+		}
+
+		// Write output files
+		if err := s.writeCompiledFiles(compiledFiles, req.OutputDir, req.Force); err != nil {
+			return fmt.Errorf("failed to write compiled files for %s: %w", file.Path, err)
+		}
+
+		if req.Verbose {
+			fmt.Printf("✓ Compiled agentset: %s -> %d file(s)\n", file.Path, len(compiledFiles))
+		}
+	} else if isHookset {
+		hookset, err := parser.ParseHookset(file)
+		if err != nil {
+			return fmt.Errorf("failed to parse hookset %s: %w", file.Path, err)
+		}
+
+		// If validate-only, we're done
+		if req.ValidateOnly {
+			if req.Verbose {
+				fmt.Printf("✓ Validated hookset: %s\n", file.Path)
+			}
+			return nil
+		}
+
+		// Compile
+		tool, _, err := s.parseTool(ctx, req.Tool)
+		if err != nil {
+			return err
+		}
+
+		compiledFiles, err := compiler.CompileHookset(tool, hookset)
+		if err != nil {
+			return fmt.Errorf("failed to compile hookset %s: %w", file.Path, err)
+		}
+
+		// Write output files
+		if err := s.writeCompiledFiles(compiledFiles, req.OutputDir, req.Force); err != nil {
+			return fmt.Errorf("failed to write compiled files for %s: %w", file.Path, err)
+		}
+
+		if req.Verbose {
+			fmt.Printf("✓ Compiled hookset: %s -> %d file(s)\n", file.Path, len(compiledFiles))
+		}
+	}
+
+	return nil
+}
+
+// ImportRequest groups import parameters following ARM patterns
+type ImportRequest struct {
+	Paths     []string
+	Tool      string
+	ID        string
+	Name      string
+	Output    string
+	Force     bool
+	Recursive bool
+}
+
+// ImportRuleset converts tool rule files into a ruleset resource. The ruleset YAML
+// is written to req.Output when set and returned either way.
+func (s *ArmService) ImportRuleset(ctx context.Context, req *ImportRequest) ([]byte, error) {
+	tool := compiler.Tool(req.Tool)
+	files, err := s.discoverFiles(req.Paths, req.Recursive, compiler.ImportPatterns(tool), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover files: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files found matching criteria")
+	}
+
+	ruleset, err := compiler.ImportRuleset(tool, req.ID, files)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name != "" {
+		ruleset.Metadata.Name = req.Name
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(ruleset); err != nil {
+		return nil, fmt.Errorf("failed to encode ruleset: %w", err)
+	}
+	content := buf.Bytes()
+
+	// Round-trip through the parser so only valid rulesets are written
+	if _, err := parser.ParseRuleset(&core.File{Path: ruleset.Metadata.ID + ".yml", Content: content}); err != nil {
+		return nil, err
+	}
+
+	if req.Output != "" {
+		if err := s.writeCompiledFiles([]*core.File{{
+			Path:    filepath.Base(req.Output),
+			Content: content,
+			Size:    int64(len(content)),
+		}}, filepath.Dir(req.Output), req.Force); err != nil {
+			return nil, err
+		}
+	}
+	return content, nil
+}
+
+func (s *ArmService) writeCompiledFiles(files []*core.File, outputDir string, force bool) error {
+	for _, file := range files {
+		outputPath := filepath.Join(outputDir, file.Path)
+
+		// Check if file exists
+		if !force {
+			if _, err := os.Stat(outputPath); err == nil {
+				return fmt.Errorf("file %s already exists (use --force to overwrite)", outputPath)
+			}
+		}
+
+		// Create directory if needed
+		dir := filepath.Dir(outputPath)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create directory %s: %w", dir, err)
+		}
+
+		// Write file
+		if err := os.WriteFile(outputPath, file.Content, 0o644); err != nil {
+			return fmt.Errorf("failed to write file %s: %w", outputPath, err)
+		}
+	}
+
+	return nil
 }
 
-// --------
-// Cleaning
-// --------
+// -------------
+// Project Setup
+// -------------
 
-// CleanCacheByAge cleans cache by age
-func (s *ArmService) CleanCacheByAge(ctx context.Context, maxAge time.Duration) error {
-	return s.CleanCacheByAgeWithHomeDir(ctx, maxAge, "")
+// ToolSink is a sink arm init creates for a tool
+type ToolSink struct {
+	Name      string
+	Directory string
+	Tool      compiler.Tool
 }
 
-// CleanCacheByAgeWithHomeDir cleans cache by age with custom home directory
-func (s *ArmService) CleanCacheByAgeWithHomeDir(ctx context.Context, maxAge time.Duration, homeDir string) error {
-	if homeDir == "" {
-		homeDir = os.Getenv("ARM_HOME")
-		if homeDir == "" {
-			var err error
-			homeDir, err = os.UserHomeDir()
-			if err != nil {
-				return err
+// toolMarkers are the files and directories that show a tool is used in a
+// project, in the order tools are detected
+var toolMarkers = []struct {
+	tool  compiler.Tool
+	paths []string
+}{
+	{compiler.Cursor, []string{".cursor", ".cursorrules"}},
+	{compiler.Copilot, []string{".github/copilot-instructions.md", ".github/copilot"}},
+	{compiler.AmazonQ, []string{".amazonq"}},
+	{compiler.Kiro, []string{".kiro"}},
+	{compiler.Claude, []string{".claude", "CLAUDE.md"}},
+}
+
+// DetectTools returns the AI tools whose configuration already exists in dir
+func DetectTools(dir string) []compiler.Tool {
+	var tools []compiler.Tool
+	for _, marker := range toolMarkers {
+		for _, path := range marker.paths {
+			if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(path))); err == nil {
+				tools = append(tools, marker.tool)
+				break
 			}
 		}
 	}
-	storageDir := filepath.Join(homeDir, ".arm", "storage")
-	return s.cleanCacheByAgeWithPath(ctx, maxAge, storageDir)
+	return tools
 }
 
-func (s *ArmService) cleanCacheByAgeWithPath(ctx context.Context, maxAge time.Duration, storageDir string) error {
-	registriesDir := filepath.Join(storageDir, "registries")
-	if _, err := os.Stat(registriesDir); os.IsNotExist(err) {
+// DefaultSinks returns the sinks arm init creates for a tool: one for rules
+// and, if the tool supports them, one for prompts. Tools without a standard
+// location, such as markdown, have none.
+func DefaultSinks(tool compiler.Tool) []ToolSink {
+	switch tool {
+	case compiler.Cursor:
+		return []ToolSink{{"cursor-rules", ".cursor/rules", tool}, {"cursor-commands", ".cursor/commands", tool}}
+	case compiler.Copilot:
+		return []ToolSink{{"copilot-rules", ".github/copilot", tool}}
+	case compiler.AmazonQ:
+		return []ToolSink{{"q-rules", ".amazonq/rules", tool}, {"q-prompts", ".amazonq/prompts", tool}}
+	case compiler.Kiro:
+		return []ToolSink{{"kiro-steering", ".kiro/steering", tool}, {"kiro-prompts", ".kiro/prompts", tool}}
+	case compiler.Claude:
+		return []ToolSink{{"claude-rules", ".claude/rules", tool}, {"claude-commands", ".claude/commands", tool}}
+	default:
 		return nil
 	}
+}
 
-	entries, err := os.ReadDir(registriesDir)
-	if err != nil {
-		return err
+// InitRequest groups init parameters following ARM patterns
+type InitRequest struct {
+	Tools         []compiler.Tool
+	GitRegistries map[string]string // Registry URLs by name
+}
+
+// Init writes a new manifest, replacing any existing one, with the default
+// sinks of each tool and the given git registries
+func (s *ArmService) Init(ctx context.Context, req *InitRequest) error {
+	for _, tool := range req.Tools {
+		if len(DefaultSinks(tool)) == 0 {
+			return fmt.Errorf("tool %s has no default sink directories; add its sinks with arm add sink", tool)
+		}
 	}
 
-	for _, entry := range entries {
-		if entry.IsDir() {
-			packagesDir := filepath.Join(registriesDir, entry.Name(), "packages")
-			if _, err := os.Stat(packagesDir); os.IsNotExist(err) {
-				continue
-			}
-			packageCache := storage.NewPackageCache(packagesDir)
-			if err := packageCache.RemoveOldVersions(ctx, maxAge); err != nil {
-				return err
+	if err := s.manifestMgr.Create(ctx); err != nil {
+		return fmt.Errorf("failed to create manifest: %w", err)
+	}
+	for _, tool := range req.Tools {
+		for _, sink := range DefaultSinks(tool) {
+			if err := s.AddSink(ctx, sink.Name, sink.Directory, sink.Tool, false); err != nil {
+				return fmt.Errorf("failed to add sink %s: %w", sink.Name, err)
 			}
 		}
 	}
+	for _, name := range sortedKeys(req.GitRegistries) {
+		if err := s.AddGitRegistry(ctx, name, req.GitRegistries[name], nil, false); err != nil {
+			return fmt.Errorf("failed to add registry %s: %w", name, err)
+		}
+	}
 	return nil
 }
 
-// CleanCacheByTimeSinceLastAccess cleans cache by time since last access
-func (s *ArmService) CleanCacheByTimeSinceLastAccess(ctx context.Context, maxTimeSinceLastAccess time.Duration) error {
-	return s.CleanCacheByTimeSinceLastAccessWithHomeDir(ctx, maxTimeSinceLastAccess, "")
+// NewPackageRequest groups scaffolding parameters following ARM patterns
+type NewPackageRequest struct {
+	Kind  string // ruleset or promptset
+	ID    string
+	Name  string
+	Dir   string
+	Force bool
 }
 
-// CleanCacheByTimeSinceLastAccessWithHomeDir cleans cache by time since last access with custom home directory
-func (s *ArmService) CleanCacheByTimeSinceLastAccessWithHomeDir(ctx context.Context, maxTimeSinceLastAccess time.Duration, homeDir string) error {
-	if homeDir == "" {
-		homeDir = os.Getenv("ARM_HOME")
-		if homeDir == "" {
-			var err error
-			homeDir, err = os.UserHomeDir()
-			if err != nil {
-				return err
-			}
-		}
+// validResourceID matches IDs that are safe to use in generated file names
+var validResourceID = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+// NewPackage scaffolds a package in req.Dir: a ruleset or promptset file with
+// one example item, plus a README.md and a CHANGELOG.md unless they already
+// exist. The resource file is only overwritten with req.Force. It returns the
+// paths of the files written.
+func (s *ArmService) NewPackage(ctx context.Context, req *NewPackageRequest) ([]string, error) {
+	if !validResourceID.MatchString(req.ID) {
+		return nil, fmt.Errorf("invalid ID %q: use letters, digits, '-' and '_', starting with a letter", req.ID)
+	}
+	name := req.Name
+	if name == "" {
+		name = titleFromID(req.ID)
 	}
-	storageDir := filepath.Join(homeDir, ".arm", "storage")
-	return s.cleanCacheByTimeSinceLastAccessWithPath(ctx, maxTimeSinceLastAccess, storageDir)
-}
 
-func (s *ArmService) cleanCacheByTimeSinceLastAccessWithPath(ctx context.Context, maxTimeSinceLastAccess time.Duration, storageDir string) error {
-	registriesDir := filepath.Join(storageDir, "registries")
-	if _, err := os.Stat(registriesDir); os.IsNotExist(err) {
-		return nil
+	var content string
+	switch req.Kind {
+	case "ruleset":
+		content = fmt.Sprintf(rulesetTemplate, req.ID, name)
+	case "promptset":
+		content = fmt.Sprintf(promptsetTemplate, req.ID, name)
+	default:
+		return nil, fmt.Errorf("unknown kind %q (must be ruleset or promptset)", req.Kind)
 	}
+	resourceFile := &core.File{Path: kebabFromID(req.ID) + "-" + req.Kind + ".yml", Content: []byte(content)}
 
-	entries, err := os.ReadDir(registriesDir)
+	// Round-trip through the parser so the scaffold is known to be valid
+	var err error
+	if req.Kind == "ruleset" {
+		_, err = parser.ParseRuleset(resourceFile)
+	} else {
+		_, err = parser.ParsePromptset(resourceFile)
+	}
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to generate %s: %w", req.Kind, err)
 	}
 
-	for _, entry := range entries {
-		if entry.IsDir() {
-			packagesDir := filepath.Join(registriesDir, entry.Name(), "packages")
-			if _, err := os.Stat(packagesDir); os.IsNotExist(err) {
-				continue
-			}
-			packageCache := storage.NewPackageCache(packagesDir)
-			if err := packageCache.RemoveUnusedVersions(ctx, maxTimeSinceLastAccess); err != nil {
-				return err
-			}
+	if err := s.writeCompiledFiles([]*core.File{resourceFile}, req.Dir, req.Force); err != nil {
+		return nil, err
+	}
+	written := []string{filepath.Join(req.Dir, resourceFile.Path)}
+
+	for _, file := range []*core.File{
+		{Path: "README.md", Content: []byte(fmt.Sprintf(readmeTemplate, name, req.Kind))},
+		{Path: "CHANGELOG.md", Content: []byte(fmt.Sprintf(changelogTemplate, req.ID, req.Kind))},
+	} {
+		path := filepath.Join(req.Dir, file.Path)
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+		if err := s.writeCompiledFiles([]*core.File{file}, req.Dir, false); err != nil {
+			return nil, err
 		}
+		written = append(written, path)
 	}
-	return nil
+	return written, nil
 }
 
-// NukeCache nukes the cache
-func (s *ArmService) NukeCache(ctx context.Context) error {
-	return s.NukeCacheWithHomeDir(ctx, "")
+// titleFromID turns an ID such as cleanCode or clean-code into "Clean Code"
+func titleFromID(id string) string {
+	words := strings.FieldsFunc(kebabFromID(id), func(r rune) bool { return r == '-' })
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(words, " ")
 }
 
-// NukeCacheWithHomeDir nukes the cache with custom home directory
-func (s *ArmService) NukeCacheWithHomeDir(ctx context.Context, homeDir string) error {
-	if homeDir == "" {
-		homeDir = os.Getenv("ARM_HOME")
-		if homeDir == "" {
-			var err error
-			homeDir, err = os.UserHomeDir()
-			if err != nil {
-				return err
+// kebabFromID turns an ID such as cleanCode or clean_code into clean-code
+func kebabFromID(id string) string {
+	var b strings.Builder
+	for i, r := range id {
+		switch {
+		case r == '_':
+			b.WriteRune('-')
+		case r >= 'A' && r <= 'Z':
+			if i > 0 && id[i-1] != '-' && id[i-1] != '_' {
+				b.WriteRune('-')
 			}
+			b.WriteRune(r + ('a' - 'A'))
+		default:
+			b.WriteRune(r)
 		}
 	}
-	storageDir := filepath.Join(homeDir, ".arm", "storage")
-	return s.nukeCacheWithPath(ctx, storageDir)
+	return b.String()
 }
 
-func (s *ArmService) nukeCacheWithPath(_ context.Context, storageDir string) error {
-	return os.RemoveAll(storageDir)
+const rulesetTemplate = `apiVersion: v1
+kind: Ruleset
+metadata:
+  id: %q
+  name: %q
+  description: "Describe what these rules are for"
+spec:
+  rules:
+    example:
+      name: "Example Rule"
+      description: "Replace this rule with your own"
+      # must, should or may
+      enforcement: should
+      # Higher priority rules win when rules conflict
+      priority: 100
+      # Remove scope to apply the rule to every file
+      scope:
+        - files: ["**/*"]
+      body: |
+        Describe what the assistant should do, and why.
+`
+
+const promptsetTemplate = `apiVersion: v1
+kind: Promptset
+metadata:
+  id: %q
+  name: %q
+  description: "Describe what these prompts are for"
+spec:
+  prompts:
+    example:
+      name: "Example Prompt"
+      description: "Replace this prompt with your own"
+      body: |
+        Describe the task the assistant should carry out.
+`
+
+const readmeTemplate = "# %[1]s\n\n" +
+	"An ARM %[2]s.\n\n" +
+	"## Install\n\n" +
+	"```bash\n" +
+	"arm add registry git --url REPOSITORY_URL REGISTRY_NAME\n" +
+	"arm install %[2]s REGISTRY_NAME/PACKAGE_NAME SINK_NAME\n" +
+	"```\n\n" +
+	"Tag each release with a semantic version, such as v1.0.0.\n"
+
+const changelogTemplate = "# Changelog\n\n" +
+	"## Unreleased\n\n" +
+	"- Add the %s %s\n"
+
+// ------------------------
+// Dry Runs and Transactions
+// ------------------------
+
+// Plan lists the changes a command would make to the project
+type Plan struct {
+	Packages []PlannedPackage `json:"packages,omitempty"`
+	Files    []PlannedFile    `json:"files,omitempty"`
+	Manifest []PlannedChange  `json:"manifest,omitempty"`
+	Lockfile []PlannedChange  `json:"lockfile,omitempty"`
 }
 
-// CleanSinks cleans sinks
-func (s *ArmService) CleanSinks(ctx context.Context) error {
-	sinks, err := s.manifestMgr.GetAllSinksConfig(ctx)
+// PlannedPackage is a locked package version before and after the command;
+// From is empty for new packages and To for removed ones
+type PlannedPackage struct {
+	Package string `json:"package"`
+	From    string `json:"from,omitempty"`
+	To      string `json:"to,omitempty"`
+}
+
+// PlannedFile is a file the command would create, modify or delete in a sink
+type PlannedFile struct {
+	Sink   string `json:"sink"`
+	Path   string `json:"path"`
+	Action string `json:"action"`
+}
+
+// PlannedChange is a value the command would add, change or remove in a JSON
+// file, addressed by its path in the document
+type PlannedChange struct {
+	Path   string `json:"path"`
+	Action string `json:"action"`
+	Old    string `json:"old,omitempty"`
+	New    string `json:"new,omitempty"`
+}
+
+// Change actions used in plans
+const (
+	PlanCreate = "create"
+	PlanModify = "modify"
+	PlanDelete = "delete"
+)
+
+// PlanChanges runs apply against a sandbox copy of the manifest, the lockfile
+// and every sink directory, and returns the changes it made there. Nothing in
+// the project is written, although packages may still be fetched into the cache.
+func (s *ArmService) PlanChanges(ctx context.Context, manifestPath, lockfilePath string, apply func(ctx context.Context, sandbox *ArmService) error) (*Plan, error) {
+	box, err := s.newSandbox(ctx, manifestPath, lockfilePath)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	defer box.remove()
 
-	for _, sinkConfig := range sinks {
-		sinkMgr := sink.NewManager(sinkConfig.Directory, sinkConfig.Tool)
-		if err := sinkMgr.Clean(); err != nil {
-			return err
-		}
+	if err := apply(ctx, box.service); err != nil {
+		return nil, err
 	}
-	return nil
+
+	changes, err := box.sinkChanges()
+	if err != nil {
+		return nil, err
+	}
+	plan := &Plan{}
+	for _, change := range changes {
+		plan.Files = append(plan.Files, PlannedFile{Sink: change.sink, Path: change.path, Action: change.action})
+	}
+	if plan.Manifest, err = diffJSONFiles(manifestPath, box.manifestPath); err != nil {
+		return nil, err
+	}
+	if plan.Lockfile, err = diffJSONFiles(lockfilePath, box.lockfilePath); err != nil {
+		return nil, err
+	}
+	if plan.Packages, err = diffLockedPackages(ctx, lockfilePath, box.lockfilePath); err != nil {
+		return nil, err
+	}
+	return plan, nil
 }
 
-// NukeSinks nukes sinks
-func (s *ArmService) NukeSinks(ctx context.Context) error {
-	sinks, err := s.manifestMgr.GetAllSinksConfig(ctx)
+// Transact runs apply against a sandbox copy of the manifest, the lockfile and
+// the files ARM owns in every sink directory, and then commits the changed
+// files to the project: sink files first, then the lockfile, then the manifest.
+// If apply fails or ctx is cancelled before the commit, the project is left
+// untouched; if the commit fails or is cancelled part way, every file already
+// written is restored.
+func (s *ArmService) Transact(ctx context.Context, manifestPath, lockfilePath string, apply func(ctx context.Context, sandbox *ArmService) error) error {
+	box, err := s.newSandbox(ctx, manifestPath, lockfilePath)
 	if err != nil {
 		return err
 	}
+	defer box.remove()
 
-	for _, sinkConfig := range sinks {
-		if sinkConfig.Tool == compiler.Copilot {
-			// Flat layout: remove arm_* files and arm-index.json
-			entries, err := os.ReadDir(sinkConfig.Directory)
-			if err != nil {
-				if os.IsNotExist(err) {
-					continue
-				}
-				return err
-			}
-			for _, entry := range entries {
-				name := entry.Name()
-				if len(name) >= 4 && name[:4] == "arm_" || name == "arm-index.json" {
-					_ = os.Remove(filepath.Join(sinkConfig.Directory, name))
-				}
-			}
-		} else {
-			// Hierarchical layout: remove arm/ directory
-			armDir := filepath.Join(sinkConfig.Directory, "arm")
-			_ = os.RemoveAll(armDir)
+	if err := apply(ctx, box.service); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	changes, err := box.sinkChanges()
+	if err != nil {
+		return err
+	}
+	for _, file := range []struct{ path, staged string }{
+		{lockfilePath, box.lockfilePath},
+		{manifestPath, box.manifestPath},
+	} {
+		change, err := diffFile(file.path, file.staged)
+		if err != nil {
+			return err
+		}
+		if change != nil {
+			changes = append(changes, *change)
 		}
 	}
-	return nil
+	return commitChanges(ctx, changes)
 }
 
-// CompileRequest groups compile parameters following ARM patterns
-type CompileRequest struct {
-	Paths        []string
-	Tool         string
-	OutputDir    string
-	Namespace    string
-	Force        bool
-	Recursive    bool
-	Verbose      bool
-	ValidateOnly bool
-	Include      []string
-	Exclude      []string
-	FailFast     bool
+// sandbox holds copies of the manifest, the lockfile and the files ARM owns in
+// the sink directories that a command can change without touching the project
+type sandbox struct {
+	dir          string
+	service      *ArmService
+	manifestPath string
+	lockfilePath string
+	sinkNames    []string
+	sinks        map[string]manifest.SinkConfig
 }
 
-// CompileFiles compiles files
-func (s *ArmService) CompileFiles(ctx context.Context, req *CompileRequest) error {
-	// Discover files from input paths
-	files, err := s.discoverFiles(req.Paths, req.Recursive, req.Include, req.Exclude)
+func (s *ArmService) newSandbox(ctx context.Context, manifestPath, lockfilePath string) (*sandbox, error) {
+	sinks, err := s.manifestMgr.GetAllSinksConfig(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to discover files: %w", err)
+		return nil, err
 	}
 
-	if len(files) == 0 {
-		return fmt.Errorf("no files found matching criteria")
+	dir, err := os.MkdirTemp("", "arm-sandbox-")
+	if err != nil {
+		return nil, err
 	}
-
-	// Process each file
-	var errors []error
-	for _, file := range files {
-		if err := s.compileFile(ctx, file, req); err != nil {
-			errors = append(errors, err)
-			if req.FailFast {
-				return err
-			}
-		}
+	box := &sandbox{
+		dir:          dir,
+		manifestPath: filepath.Join(dir, "arm.json"),
+		lockfilePath: filepath.Join(dir, "arm-lock.json"),
+		sinks:        sinks,
 	}
-
-	if len(errors) > 0 {
-		return fmt.Errorf("compilation failed with %d error(s): %v", len(errors), errors[0])
+	if err := box.populate(ctx, manifestPath, lockfilePath, s.registryFactory); err != nil {
+		box.remove()
+		return nil, err
 	}
-
-	return nil
+	box.service.jobs = s.jobs
+	return box, nil
 }
 
-func (s *ArmService) discoverFiles(paths []string, recursive bool, include, exclude []string) ([]*core.File, error) {
-	// Default include patterns if none specified
-	if len(include) == 0 {
-		include = []string{"*.yml", "*.yaml"}
+func (b *sandbox) populate(ctx context.Context, manifestPath, lockfilePath string, registryFactory registry.Factory) error {
+	if err := copyIfExists(manifestPath, b.manifestPath); err != nil {
+		return err
+	}
+	if err := copyIfExists(lockfilePath, b.lockfilePath); err != nil {
+		return err
 	}
 
-	var files []*core.File
-	seen := make(map[string]bool)
+	for name := range b.sinks {
+		b.sinkNames = append(b.sinkNames, name)
+	}
+	sort.Strings(b.sinkNames)
 
-	for _, path := range paths {
-		info, err := os.Stat(path)
-		if err != nil {
-			return nil, fmt.Errorf("failed to stat %s: %w", path, err)
+	// tools/ stays in the project; only the files a command writes are copied
+	manifestMgr := manifest.NewFileManagerWithProjectDir(b.manifestPath, filepath.Dir(manifestPath))
+	b.service = NewArmService(manifestMgr, packagelockfile.NewFileManagerWithPath(b.lockfilePath), registryFactory)
+	staged := make(map[string]*stagedSink)
+	for i, name := range b.sinkNames {
+		sinkConfig := b.sinks[name]
+		staged[filepath.Clean(sinkConfig.Directory)] = &stagedSink{
+			dir:    filepath.Join(b.dir, "sinks", strconv.Itoa(i)),
+			origin: sinkConfig.Directory,
+			owned:  make(map[string]bool),
 		}
-
-		if info.IsDir() {
-			// Discover files in directory
-			dirFiles, err := s.discoverInDirectory(path, recursive, include, exclude)
-			if err != nil {
-				return nil, err
-			}
-			for _, f := range dirFiles {
-				if !seen[f.Path] {
-					files = append(files, f)
-					seen[f.Path] = true
-				}
-			}
-		} else if !seen[path] {
-			// Single file
-			content, err := os.ReadFile(path)
-			if err != nil {
-				return nil, fmt.Errorf("failed to read %s: %w", path, err)
-			}
-			files = append(files, &core.File{
-				Path:    path,
-				Content: content,
-				Size:    info.Size(),
-			})
-			seen[path] = true
+	}
+	// Sink managers see the project until every sink is staged
+	for _, name := range b.sinkNames {
+		sinkConfig := b.sinks[name]
+		if err := staged[filepath.Clean(sinkConfig.Directory)].stage(ctx, b.service, sinkConfig); err != nil {
+			return fmt.Errorf("failed to copy sink %s: %w", name, err)
 		}
 	}
-
-	return files, nil
+	b.service.sinkDirs = staged
+	return nil
 }
 
-func (s *ArmService) discoverInDirectory(dir string, recursive bool, include, exclude []string) ([]*core.File, error) {
-	var files []*core.File
+// stagedSink is a sink directory redirected into a sandbox. Only the files ARM
+// owns are copied, so the user's own files, a .git or node_modules directory
+// in a "." sink among them, stay out of the sandbox.
+type stagedSink struct {
+	dir    string
+	origin string
+	// owned holds the files staged from the project, relative to the sink
+	// directory; files missing from the project are included
+	owned map[string]bool
+}
 
-	walkFn := func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil // Continue on errors
+// stage copies the files ARM owns in the sink from the project
+func (st *stagedSink) stage(ctx context.Context, service *ArmService, sinkConfig manifest.SinkConfig) error {
+	if err := os.MkdirAll(st.dir, 0o755); err != nil {
+		return err
+	}
+	if _, err := os.Stat(st.origin); os.IsNotExist(err) {
+		return nil
+	}
+	// Only the tool decides which files ARM owns; a sink whose tool cannot be
+	// resolved has nothing a command could change
+	sinkMgr, err := service.newSinkManager(ctx, manifest.SinkConfig{Directory: sinkConfig.Directory, Tool: sinkConfig.Tool})
+	if err != nil {
+		return nil
+	}
+	owned, err := sinkMgr.OwnedFiles()
+	if err != nil {
+		return err
+	}
+	for _, path := range owned {
+		target := filepath.Join(st.dir, path)
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
 		}
-
-		if info.IsDir() {
-			if !recursive && path != dir {
-				return filepath.SkipDir
-			}
-			return nil
+		if err := copyIfExists(filepath.Join(st.origin, path), target); err != nil {
+			return err
 		}
+		st.owned[path] = true
+	}
+	return nil
+}
 
-		// Get relative path for pattern matching
-		relPath, err := filepath.Rel(dir, path)
-		if err != nil {
-			return nil
+// unmanaged reports whether the project has a file or directory at path,
+// relative to the sink directory, that was left out of the sandbox
+func (st *stagedSink) unmanaged(path string) bool {
+	path = filepath.Clean(path)
+	if _, err := os.Lstat(filepath.Join(st.origin, path)); err != nil {
+		return false
+	}
+	prefix := path + string(filepath.Separator)
+	for owned := range st.owned {
+		if owned == path || strings.HasPrefix(owned, prefix) {
+			return false
 		}
+	}
+	return true
+}
 
-		// Check if file matches patterns
-		if !s.matchesPatterns(relPath, include, exclude) {
-			return nil
+// changedFiles lists the files a sandboxed command created, modified or
+// deleted: the staged files and whatever the command wrote to the sandbox
+func (st *stagedSink) changedFiles() ([]PlannedFile, error) {
+	paths := make(map[string]bool, len(st.owned))
+	for path := range st.owned {
+		paths[path] = true
+	}
+	err := filepath.WalkDir(st.dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
 		}
+		rel, err := filepath.Rel(st.dir, path)
+		if err != nil {
+			return err
+		}
+		paths[rel] = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		content, err := os.ReadFile(path)
+	var changes []PlannedFile
+	for path := range paths {
+		change, err := diffFile(filepath.Join(st.origin, path), filepath.Join(st.dir, path))
 		if err != nil {
-			return nil // Continue on errors
+			return nil, err
 		}
+		if change != nil {
+			changes = append(changes, PlannedFile{Path: path, Action: change.action})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
 
-		files = append(files, &core.File{
-			Path:    path,
-			Content: content,
-			Size:    info.Size(),
-		})
+func (b *sandbox) remove() {
+	_ = os.RemoveAll(b.dir)
+}
 
-		return nil
+// fileChange is a project file a sandboxed command created, modified or deleted
+type fileChange struct {
+	sink   string
+	root   string // sink directory the file belongs to, if any
+	path   string // path in the project
+	staged string // path of the new content in the sandbox
+	action string
+}
+
+// sinkChanges lists the files the sandboxed command changed in each sink
+func (b *sandbox) sinkChanges() ([]fileChange, error) {
+	var changes []fileChange
+	for _, name := range b.sinkNames {
+		directory := b.sinks[name].Directory
+		stagedDir := b.service.sinkDirectory(directory)
+		files, err := b.service.sinkDirs[filepath.Clean(directory)].changedFiles()
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			changes = append(changes, fileChange{
+				sink:   name,
+				root:   directory,
+				path:   filepath.Join(directory, file.Path),
+				staged: filepath.Join(stagedDir, file.Path),
+				action: file.Action,
+			})
+		}
 	}
+	return changes, nil
+}
 
-	if err := filepath.Walk(dir, walkFn); err != nil {
-		return nil, fmt.Errorf("failed to walk directory %s: %w", dir, err)
+// diffFile compares a project file with its sandbox copy; it returns nil when they match
+func diffFile(path, staged string) (*fileChange, error) {
+	old, oldErr := os.ReadFile(path)
+	if oldErr != nil && !os.IsNotExist(oldErr) {
+		return nil, oldErr
+	}
+	content, newErr := os.ReadFile(staged)
+	if newErr != nil && !os.IsNotExist(newErr) {
+		return nil, newErr
+	}
+
+	change := &fileChange{path: path, staged: staged}
+	switch {
+	case oldErr != nil && newErr != nil:
+		return nil, nil
+	case oldErr != nil:
+		change.action = PlanCreate
+	case newErr != nil:
+		change.action = PlanDelete
+	case bytes.Equal(old, content):
+		return nil, nil
+	default:
+		change.action = PlanModify
 	}
+	return change, nil
+}
 
-	return files, nil
+// backup is the state of a project file before a commit replaced it
+type backup struct {
+	change  fileChange
+	existed bool
+	content []byte
+	mode    os.FileMode
 }
 
-func (s *ArmService) matchesPatterns(filePath string, include, exclude []string) bool {
-	// Check exclude patterns first
-	for _, pattern := range exclude {
-		if core.MatchPattern(pattern, filePath) {
-			return false
+// commitChanges applies changes to the project in order, restoring every file
+// already changed if one fails or ctx is cancelled
+func commitChanges(ctx context.Context, changes []fileChange) error {
+	var backups []backup
+	for _, change := range changes {
+		err := ctx.Err()
+		if err == nil {
+			var saved backup
+			saved, err = backupFile(change)
+			if err == nil {
+				backups = append(backups, saved)
+				err = applyChange(change)
+			}
+		}
+		if err != nil {
+			if rollbackErr := rollback(backups); rollbackErr != nil {
+				return fmt.Errorf("failed to commit %s: %w (rollback failed: %v)", change.path, err, rollbackErr)
+			}
+			return fmt.Errorf("failed to commit %s, changes rolled back: %w", change.path, err)
 		}
 	}
+	return nil
+}
 
-	// If no include patterns, accept all
-	if len(include) == 0 {
-		return true
+func backupFile(change fileChange) (backup, error) {
+	saved := backup{change: change}
+	info, err := os.Stat(change.path)
+	if os.IsNotExist(err) {
+		return saved, nil
+	}
+	if err != nil {
+		return saved, err
 	}
+	if saved.content, err = os.ReadFile(change.path); err != nil {
+		return saved, err
+	}
+	saved.existed = true
+	saved.mode = info.Mode().Perm()
+	return saved, nil
+}
 
-	// Check include patterns
-	for _, pattern := range include {
-		if core.MatchPattern(pattern, filePath) {
-			return true
+func applyChange(change fileChange) error {
+	if change.action == PlanDelete {
+		if err := os.Remove(change.path); err != nil && !os.IsNotExist(err) {
+			return err
 		}
+		removeEmptyParents(change.path, change.root)
+		return nil
 	}
 
-	return false
+	content, err := os.ReadFile(change.staged)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(change.staged)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(change.path, content, info.Mode().Perm())
 }
 
-func (s *ArmService) parseTool(toolStr string) (compiler.Tool, error) {
-	switch toolStr {
-	case "cursor":
-		return compiler.Cursor, nil
-	case "copilot":
-		return compiler.Copilot, nil
-	case "amazonq":
-		return compiler.AmazonQ, nil
-	case "kiro":
-		return compiler.Kiro, nil
-	case "markdown":
-		return compiler.Markdown, nil
-	default:
-		return "", fmt.Errorf("invalid tool: %s (must be cursor, copilot, amazonq, kiro, or markdown)", toolStr)
+// rollback restores backed up files, most recent first
+func rollback(backups []backup) error {
+	var errs []error
+	for i := len(backups) - 1; i >= 0; i-- {
+		saved := backups[i]
+		if saved.existed {
+			if err := writeFileAtomic(saved.change.path, saved.content, saved.mode); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if err := os.Remove(saved.change.path); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+		}
+		removeEmptyParents(saved.change.path, saved.change.root)
 	}
+	return errors.Join(errs...)
 }
 
-func (s *ArmService) compileFile(_ context.Context, file *core.File, req *CompileRequest) error {
-	// Detect file type
-	isRuleset := filetype.IsRulesetFile(file)
-	isPromptset := filetype.IsPromptsetFile(file)
+// writeFileAtomic replaces path with content through a rename, so readers never
+// see a partially written file
+func writeFileAtomic(path string, content []byte, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".arm-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
 
-	if !isRuleset && !isPromptset {
-		return fmt.Errorf("file %s is not a valid ruleset or promptset", file.Path)
+	if _, err := tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
 	}
+	return os.Rename(tmp.Name(), path)
+}
 
-	// Parse and validate
-	if isRuleset {
-		ruleset, err := parser.ParseRuleset(file)
-		if err != nil {
-			return fmt.Errorf("failed to parse ruleset %s: %w", file.Path, err)
+// removeEmptyParents removes the empty directories above path, up to but not including root
+func removeEmptyParents(path, root string) {
+	if root == "" {
+		return
+	}
+	root = filepath.Clean(root)
+	for dir := filepath.Dir(path); dir != root && strings.HasPrefix(dir, root+string(filepath.Separator)); dir = filepath.Dir(dir) {
+		if err := os.Remove(dir); err != nil {
+			return
 		}
+	}
+}
 
-		// If validate-only, we're done
-		if req.ValidateOnly {
-			if req.Verbose {
-				fmt.Printf("✓ Validated ruleset: %s\n", file.Path)
-			}
-			return nil
-		}
+// PlanCleanCacheByAge returns the cached package versions CleanCacheByAge would remove
+func (s *ArmService) PlanCleanCacheByAge(ctx context.Context, maxAge time.Duration) (*Plan, error) {
+	storageDir, err := storageDirectory("")
+	if err != nil {
+		return nil, err
+	}
 
-		// Determine namespace
-		namespace := req.Namespace
-		if namespace == "" {
-			namespace = ruleset.Metadata.ID
+	registriesDir := filepath.Join(storageDir, "registries")
+	entries, err := os.ReadDir(registriesDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Plan{}, nil
 		}
+		return nil, err
+	}
 
-		// Compile
-		tool, err := s.parseTool(req.Tool)
-		if err != nil {
-			return err
+	plan := &Plan{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
 		}
-
-		compiledFiles, err := compiler.CompileRuleset(tool, namespace, ruleset)
+		packageCache := storage.NewPackageCache(filepath.Join(registriesDir, entry.Name(), "packages"))
+		dirs, err := packageCache.OldVersionDirs(ctx, maxAge)
 		if err != nil {
-			return fmt.Errorf("failed to compile ruleset %s: %w", file.Path, err)
+			return nil, err
 		}
-
-		// Write output files
-		if err := s.writeCompiledFiles(compiledFiles, req.OutputDir, req.Force); err != nil {
-			return fmt.Errorf("failed to write compiled files for %s: %w", file.Path, err)
-		}
-
-		if req.Verbose {
-			fmt.Printf("✓ Compiled ruleset: %s -> %d file(s)\n", file.Path, len(compiledFiles))
+		for _, dir := range dirs {
+			plan.Files = append(plan.Files, PlannedFile{Sink: "cache", Path: dir, Action: PlanDelete})
 		}
+	}
+	return plan, nil
+}
 
-	} else if isPromptset {
-		promptset, err := parser.ParsePromptset(file)
-		if err != nil {
-			return fmt.Errorf("failed to parse promptset %s: %w", file.Path, err)
-		}
+// sinkDirectory returns the directory a sink's files are written to; dry runs
+// redirect sinks into a sandbox
+func (s *ArmService) sinkDirectory(directory string) string {
+	if staged, ok := s.sinkDirs[filepath.Clean(directory)]; ok {
+		return staged.dir
+	}
+	return directory
+}
 
-		// If validate-only, we're done
-		if req.ValidateOnly {
-			if req.Verbose {
-				fmt.Printf("✓ Validated promptset: %s\n", file.Path)
-			}
+// copyIfExists copies src to dst with its permissions; a missing src is skipped
+func copyIfExists(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		if os.IsNotExist(err) {
 			return nil
 		}
+		return err
+	}
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(dst, data, info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Chmod(dst, info.Mode().Perm())
+}
 
-		// Determine namespace
-		namespace := req.Namespace
-		if namespace == "" {
-			namespace = promptset.Metadata.ID
+// readJSONFile decodes a JSON file, treating a missing file as an empty document
+func readJSONFile(path string) (interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]interface{}{}, nil
 		}
+		return nil, err
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return value, nil
+}
 
-		// Compile
-		tool, err := s.parseTool(req.Tool)
-		if err != nil {
-			return err
-		}
+// diffJSONFiles lists the values that differ between two JSON files
+func diffJSONFiles(before, after string) ([]PlannedChange, error) {
+	oldValue, err := readJSONFile(before)
+	if err != nil {
+		return nil, err
+	}
+	newValue, err := readJSONFile(after)
+	if err != nil {
+		return nil, err
+	}
 
-		compiledFiles, err := compiler.CompilePromptset(tool, namespace, promptset)
-		if err != nil {
-			return fmt.Errorf("failed to compile promptset %s: %w", file.Path, err)
-		}
+	oldLeaves := make(map[string]string)
+	newLeaves := make(map[string]string)
+	flattenJSON("", oldValue, oldLeaves)
+	flattenJSON("", newValue, newLeaves)
 
-		// Write output files
-		if err := s.writeCompiledFiles(compiledFiles, req.OutputDir, req.Force); err != nil {
-			return fmt.Errorf("failed to write compiled files for %s: %w", file.Path, err)
+	var changes []PlannedChange
+	for path, value := range newLeaves {
+		old, exists := oldLeaves[path]
+		switch {
+		case !exists:
+			changes = append(changes, PlannedChange{Path: path, Action: PlanCreate, New: value})
+		case old != value:
+			changes = append(changes, PlannedChange{Path: path, Action: PlanModify, Old: old, New: value})
 		}
-
-		if req.Verbose {
-			fmt.Printf("✓ Compiled promptset: %s -> %d file(s)\n", file.Path, len(compiledFiles))
+	}
+	for path, value := range oldLeaves {
+		if _, exists := newLeaves[path]; !exists {
+			changes = append(changes, PlannedChange{Path: path, Action: PlanDelete, Old: value})
 		}
 	}
-
-	return nil
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
 }
 
-func (s *ArmService) writeCompiledFiles(files []*core.File, outputDir string, force bool) error {
-	for _, file := range files {
-		outputPath := filepath.Join(outputDir, file.Path)
-
-		// Check if file exists
-		if !force {
-			if _, err := os.Stat(outputPath); err == nil {
-				return fmt.Errorf("file %s already exists (use --force to overwrite)", outputPath)
+// flattenJSON records the JSON-encoded leaves of value by their path; objects
+// are descended into, anything else (including arrays) is a leaf
+func flattenJSON(path string, value interface{}, leaves map[string]string) {
+	if object, ok := value.(map[string]interface{}); ok {
+		for key, child := range object {
+			segment := "." + key
+			if strings.ContainsAny(key, ".[]") {
+				segment = fmt.Sprintf("[%q]", key)
 			}
+			flattenJSON(path+segment, child, leaves)
 		}
+		return
+	}
+	encoded, _ := json.Marshal(value)
+	leaves[strings.TrimPrefix(path, ".")] = string(encoded)
+}
 
-		// Create directory if needed
-		dir := filepath.Dir(outputPath)
-		if err := os.MkdirAll(dir, 0o755); err != nil {
-			return fmt.Errorf("failed to create directory %s: %w", dir, err)
-		}
+// diffLockedPackages lists the packages whose locked version differs between two lockfiles
+func diffLockedPackages(ctx context.Context, before, after string) ([]PlannedPackage, error) {
+	oldVersions, err := lockedVersions(ctx, before)
+	if err != nil {
+		return nil, err
+	}
+	newVersions, err := lockedVersions(ctx, after)
+	if err != nil {
+		return nil, err
+	}
 
-		// Write file
-		if err := os.WriteFile(outputPath, file.Content, 0o644); err != nil {
-			return fmt.Errorf("failed to write file %s: %w", outputPath, err)
+	var packages []PlannedPackage
+	for pkg, version := range newVersions {
+		if oldVersions[pkg] != version {
+			packages = append(packages, PlannedPackage{Package: pkg, From: oldVersions[pkg], To: version})
+		}
+	}
+	for pkg, version := range oldVersions {
+		if _, exists := newVersions[pkg]; !exists {
+			packages = append(packages, PlannedPackage{Package: pkg, From: version})
 		}
 	}
+	sort.Slice(packages, func(i, j int) bool { return packages[i].Package < packages[j].Package })
+	return packages, nil
+}
 
-	return nil
+// lockedVersions maps REGISTRY/PACKAGE to the version locked in a lockfile
+func lockedVersions(ctx context.Context, lockfilePath string) (map[string]string, error) {
+	versions := make(map[string]string)
+	lockfile, err := packagelockfile.NewFileManagerWithPath(lockfilePath).GetLockFile(ctx)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return versions, nil
+		}
+		return nil, err
+	}
+	for key := range lockfile.Dependencies {
+		if at := strings.LastIndex(key, "@"); at > 0 {
+			versions[key[:at]] = key[at+1:]
+		}
+	}
+	return versions, nil
 }
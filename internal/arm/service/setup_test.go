@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-manager/internal/arm/compiler"
+	"github.com/jomadu/ai-resource-manager/internal/arm/manifest"
+)
+
+func TestDetectTools(t *testing.T) {
+	dir := t.TempDir()
+	for _, path := range []string{".cursor/rules", ".kiro", ".github/workflows"} {
+		if err := os.MkdirAll(filepath.Join(dir, path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".github", "copilot-instructions.md"), []byte("Be brief."), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []compiler.Tool{compiler.Cursor, compiler.Copilot, compiler.Kiro}
+	if got := DetectTools(dir); !reflect.DeepEqual(got, want) {
+		t.Errorf("DetectTools() = %v, want %v", got, want)
+	}
+	if got := DetectTools(t.TempDir()); len(got) != 0 {
+		t.Errorf("DetectTools() of an empty directory = %v, want none", got)
+	}
+}
+
+func TestInit(t *testing.T) {
+	ctx := context.Background()
+	manifestPath := filepath.Join(t.TempDir(), "arm.json")
+	if err := os.WriteFile(manifestPath, []byte(`{"version": 1, "sinks": {"old": {"directory": "old", "tool": "cursor"}}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	svc := NewArmService(manifest.NewFileManagerWithPath(manifestPath), nil, nil)
+
+	err := svc.Init(ctx, &InitRequest{
+		Tools:         []compiler.Tool{compiler.Cursor, compiler.Copilot},
+		GitRegistries: map[string]string{"ai-rules": "https://github.com/org/ai-rules"},
+	})
+	if err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	sinks, err := svc.GetAllSinkConfigs(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]manifest.SinkConfig{
+		"cursor-rules":    {Directory: ".cursor/rules", Tool: compiler.Cursor},
+		"cursor-commands": {Directory: ".cursor/commands", Tool: compiler.Cursor},
+		"copilot-rules":   {Directory: ".github/copilot", Tool: compiler.Copilot},
+	}
+	if !reflect.DeepEqual(sinks, want) {
+		t.Errorf("sinks = %v, want %v", sinks, want)
+	}
+	registries, err := svc.GetAllRegistriesConfig(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(registries) != 1 || registries["ai-rules"]["url"] != "https://github.com/org/ai-rules" {
+		t.Errorf("registries = %v, want ai-rules", registries)
+	}
+
+	if err := svc.Init(ctx, &InitRequest{Tools: []compiler.Tool{compiler.Markdown}}); err == nil || !strings.Contains(err.Error(), "no default sink") {
+		t.Errorf("Init() with markdown error = %v", err)
+	}
+}
+
+func TestNewPackage(t *testing.T) {
+	ctx := context.Background()
+	svc := NewArmService(nil, nil, nil)
+
+	for _, kind := range []string{"ruleset", "promptset"} {
+		t.Run(kind, func(t *testing.T) {
+			dir := t.TempDir()
+			written, err := svc.NewPackage(ctx, &NewPackageRequest{Kind: kind, ID: "cleanCode", Dir: dir})
+			if err != nil {
+				t.Fatalf("NewPackage() error = %v", err)
+			}
+			resourcePath := filepath.Join(dir, "clean-code-"+kind+".yml")
+			want := []string{resourcePath, filepath.Join(dir, "README.md"), filepath.Join(dir, "CHANGELOG.md")}
+			if !reflect.DeepEqual(written, want) {
+				t.Errorf("NewPackage() wrote %v, want %v", written, want)
+			}
+			content, err := os.ReadFile(resourcePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(content), `name: "Clean Code"`) {
+				t.Errorf("expected a name derived from the ID:\n%s", content)
+			}
+
+			if err := svc.CompileFiles(ctx, &CompileRequest{Paths: []string{dir}, ValidateOnly: true}); err != nil {
+				t.Errorf("scaffold does not validate: %v", err)
+			}
+
+			// The resource file is kept unless forced; README and CHANGELOG are always kept
+			if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Mine"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := svc.NewPackage(ctx, &NewPackageRequest{Kind: kind, ID: "cleanCode", Dir: dir}); err == nil {
+				t.Error("NewPackage() should not overwrite the resource file without force")
+			}
+			written, err = svc.NewPackage(ctx, &NewPackageRequest{Kind: kind, ID: "cleanCode", Name: "Tidy", Dir: dir, Force: true})
+			if err != nil {
+				t.Fatalf("NewPackage() with force error = %v", err)
+			}
+			if len(written) != 1 {
+				t.Errorf("NewPackage() with force wrote %v, want only the resource file", written)
+			}
+			if readme, _ := os.ReadFile(filepath.Join(dir, "README.md")); string(readme) != "# Mine" {
+				t.Errorf("README.md was overwritten: %s", readme)
+			}
+		})
+	}
+
+	if _, err := svc.NewPackage(ctx, &NewPackageRequest{Kind: "ruleset", ID: "../escape", Dir: t.TempDir()}); err == nil {
+		t.Error("NewPackage() should reject an ID that is not a valid file name")
+	}
+	if _, err := svc.NewPackage(ctx, &NewPackageRequest{Kind: "mcpset", ID: "tools", Dir: t.TempDir()}); err == nil {
+		t.Error("NewPackage() should reject kinds other than ruleset and promptset")
+	}
+}
@@ -4,10 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/jomadu/ai-resource-manager/internal/arm/compiler"
+	"github.com/jomadu/ai-resource-manager/internal/arm/core"
 	"github.com/jomadu/ai-resource-manager/internal/arm/manifest"
+	"github.com/jomadu/ai-resource-manager/internal/arm/packagelockfile"
+	"github.com/jomadu/ai-resource-manager/internal/arm/sink"
 )
 
 type mockManifestManager struct {
@@ -16,6 +22,19 @@ type mockManifestManager struct {
 	saveErr  error
 }
 
+func (m *mockManifestManager) Create(ctx context.Context) error {
+	if m.saveErr != nil {
+		return m.saveErr
+	}
+	m.manifest = &manifest.Manifest{
+		Version:      1,
+		Registries:   make(map[string]map[string]interface{}),
+		Sinks:        make(map[string]manifest.SinkConfig),
+		Dependencies: make(map[string]map[string]interface{}),
+	}
+	return nil
+}
+
 func (m *mockManifestManager) GetAllRegistriesConfig(ctx context.Context) (map[string]map[string]interface{}, error) {
 	if m.loadErr != nil {
 		return nil, m.loadErr
@@ -224,6 +243,30 @@ func (m *mockManifestManager) RemoveSinkConfig(ctx context.Context, name string)
 	return nil
 }
 
+func (m *mockManifestManager) GetVars(ctx context.Context) (map[string]string, error) {
+	if m.loadErr != nil {
+		return nil, m.loadErr
+	}
+	return m.manifest.Vars, nil
+}
+
+func (m *mockManifestManager) ProjectDir() string {
+	return ""
+}
+
+func (m *mockManifestManager) GetAllToolsConfig(ctx context.Context) (map[compiler.Tool]*compiler.ToolDefinition, error) {
+	if m.loadErr != nil {
+		return nil, m.loadErr
+	}
+	tools := make(map[compiler.Tool]*compiler.ToolDefinition)
+	for name, def := range m.manifest.Tools {
+		def := def
+		def.Name = compiler.Tool(name)
+		tools[def.Name] = &def
+	}
+	return tools, nil
+}
+
 func (m *mockManifestManager) GetAllDependenciesConfig(ctx context.Context) (map[string]map[string]interface{}, error) {
 	if m.loadErr != nil {
 		return nil, m.loadErr
@@ -287,6 +330,70 @@ func (m *mockManifestManager) UpsertPromptsetDependencyConfig(ctx context.Contex
 	return nil
 }
 
+func (m *mockManifestManager) UpsertMcpsetDependencyConfig(ctx context.Context, registry, packageName string, config *manifest.McpsetDependencyConfig) error {
+	key := registry + "/" + packageName
+	if m.saveErr != nil {
+		return m.saveErr
+	}
+	if m.manifest.Dependencies == nil {
+		m.manifest.Dependencies = make(map[string]map[string]interface{})
+	}
+	config.Type = manifest.ResourceTypeMcpset
+	configMap, _ := json.Marshal(config)
+	var result map[string]interface{}
+	_ = json.Unmarshal(configMap, &result)
+	m.manifest.Dependencies[key] = result
+	return nil
+}
+
+func (m *mockManifestManager) UpsertAgentsetDependencyConfig(ctx context.Context, registry, packageName string, config *manifest.AgentsetDependencyConfig) error {
+	key := registry + "/" + packageName
+	if m.saveErr != nil {
+		return m.saveErr
+	}
+	if m.manifest.Dependencies == nil {
+		m.manifest.Dependencies = make(map[string]map[string]interface{})
+	}
+	config.Type = manifest.ResourceTypeAgentset
+	configMap, _ := json.Marshal(config)
+	var result map[string]interface{}
+	_ = json.Unmarshal(configMap, &result)
+	m.manifest.Dependencies[key] = result
+	return nil
+}
+
+func (m *mockManifestManager) UpsertSkillsetDependencyConfig(ctx context.Context, registry, packageName string, config *manifest.SkillsetDependencyConfig) error {
+	key := registry + "/" + packageName
+	if m.saveErr != nil {
+		return m.saveErr
+	}
+	if m.manifest.Dependencies == nil {
+		m.manifest.Dependencies = make(map[string]map[string]interface{})
+	}
+	config.Type = manifest.ResourceTypeSkillset
+	configMap, _ := json.Marshal(config)
+	var result map[string]interface{}
+	_ = json.Unmarshal(configMap, &result)
+	m.manifest.Dependencies[key] = result
+	return nil
+}
+
+func (m *mockManifestManager) UpsertHooksetDependencyConfig(ctx context.Context, registry, packageName string, config *manifest.HooksetDependencyConfig) error {
+	key := registry + "/" + packageName
+	if m.saveErr != nil {
+		return m.saveErr
+	}
+	if m.manifest.Dependencies == nil {
+		m.manifest.Dependencies = make(map[string]map[string]interface{})
+	}
+	config.Type = manifest.ResourceTypeHookset
+	configMap, _ := json.Marshal(config)
+	var result map[string]interface{}
+	_ = json.Unmarshal(configMap, &result)
+	m.manifest.Dependencies[key] = result
+	return nil
+}
+
 func (m *mockManifestManager) UpdateDependencyConfigName(ctx context.Context, registry, packageName, newRegistry, newPackageName string) error {
 	key := registry + "/" + packageName
 	newKey := newRegistry + "/" + newPackageName
@@ -350,6 +457,66 @@ func (m *mockManifestManager) GetPromptsetDependencyConfig(ctx context.Context,
 	return &result, nil
 }
 
+func (m *mockManifestManager) GetMcpsetDependencyConfig(ctx context.Context, registry, packageName string) (*manifest.McpsetDependencyConfig, error) {
+	key := registry + "/" + packageName
+	if m.loadErr != nil {
+		return nil, m.loadErr
+	}
+	cfg, exists := m.manifest.Dependencies[key]
+	if !exists {
+		return nil, errors.New("dependency does not exist")
+	}
+	configMap, _ := json.Marshal(cfg)
+	var result manifest.McpsetDependencyConfig
+	_ = json.Unmarshal(configMap, &result)
+	return &result, nil
+}
+
+func (m *mockManifestManager) GetAgentsetDependencyConfig(ctx context.Context, registry, packageName string) (*manifest.AgentsetDependencyConfig, error) {
+	key := registry + "/" + packageName
+	if m.loadErr != nil {
+		return nil, m.loadErr
+	}
+	cfg, exists := m.manifest.Dependencies[key]
+	if !exists {
+		return nil, errors.New("dependency does not exist")
+	}
+	configMap, _ := json.Marshal(cfg)
+	var result manifest.AgentsetDependencyConfig
+	_ = json.Unmarshal(configMap, &result)
+	return &result, nil
+}
+
+func (m *mockManifestManager) GetSkillsetDependencyConfig(ctx context.Context, registry, packageName string) (*manifest.SkillsetDependencyConfig, error) {
+	key := registry + "/" + packageName
+	if m.loadErr != nil {
+		return nil, m.loadErr
+	}
+	cfg, exists := m.manifest.Dependencies[key]
+	if !exists {
+		return nil, errors.New("dependency does not exist")
+	}
+	configMap, _ := json.Marshal(cfg)
+	var result manifest.SkillsetDependencyConfig
+	_ = json.Unmarshal(configMap, &result)
+	return &result, nil
+}
+
+func (m *mockManifestManager) GetHooksetDependencyConfig(ctx context.Context, registry, packageName string) (*manifest.HooksetDependencyConfig, error) {
+	key := registry + "/" + packageName
+	if m.loadErr != nil {
+		return nil, m.loadErr
+	}
+	cfg, exists := m.manifest.Dependencies[key]
+	if !exists {
+		return nil, errors.New("dependency does not exist")
+	}
+	configMap, _ := json.Marshal(cfg)
+	var result manifest.HooksetDependencyConfig
+	_ = json.Unmarshal(configMap, &result)
+	return &result, nil
+}
+
 func (m *mockManifestManager) GetAllRulesetDependenciesConfig(ctx context.Context) (map[string]*manifest.RulesetDependencyConfig, error) {
 	if m.loadErr != nil {
 		return nil, m.loadErr
@@ -386,6 +553,78 @@ func (m *mockManifestManager) GetAllPromptsetDependenciesConfig(ctx context.Cont
 	return promptsets, nil
 }
 
+func (m *mockManifestManager) GetAllMcpsetDependenciesConfig(ctx context.Context) (map[string]*manifest.McpsetDependencyConfig, error) {
+	if m.loadErr != nil {
+		return nil, m.loadErr
+	}
+	mcpsets := make(map[string]*manifest.McpsetDependencyConfig)
+	for key, rawConfig := range m.manifest.Dependencies {
+		depType, ok := rawConfig["type"].(string)
+		if !ok || depType != "mcpset" {
+			continue
+		}
+		configMap, _ := json.Marshal(rawConfig)
+		var result manifest.McpsetDependencyConfig
+		_ = json.Unmarshal(configMap, &result)
+		mcpsets[key] = &result
+	}
+	return mcpsets, nil
+}
+
+func (m *mockManifestManager) GetAllAgentsetDependenciesConfig(ctx context.Context) (map[string]*manifest.AgentsetDependencyConfig, error) {
+	if m.loadErr != nil {
+		return nil, m.loadErr
+	}
+	agentsets := make(map[string]*manifest.AgentsetDependencyConfig)
+	for key, rawConfig := range m.manifest.Dependencies {
+		depType, ok := rawConfig["type"].(string)
+		if !ok || depType != "agentset" {
+			continue
+		}
+		configMap, _ := json.Marshal(rawConfig)
+		var result manifest.AgentsetDependencyConfig
+		_ = json.Unmarshal(configMap, &result)
+		agentsets[key] = &result
+	}
+	return agentsets, nil
+}
+
+func (m *mockManifestManager) GetAllSkillsetDependenciesConfig(ctx context.Context) (map[string]*manifest.SkillsetDependencyConfig, error) {
+	if m.loadErr != nil {
+		return nil, m.loadErr
+	}
+	skillsets := make(map[string]*manifest.SkillsetDependencyConfig)
+	for key, rawConfig := range m.manifest.Dependencies {
+		depType, ok := rawConfig["type"].(string)
+		if !ok || depType != "skillset" {
+			continue
+		}
+		configMap, _ := json.Marshal(rawConfig)
+		var result manifest.SkillsetDependencyConfig
+		_ = json.Unmarshal(configMap, &result)
+		skillsets[key] = &result
+	}
+	return skillsets, nil
+}
+
+func (m *mockManifestManager) GetAllHooksetDependenciesConfig(ctx context.Context) (map[string]*manifest.HooksetDependencyConfig, error) {
+	if m.loadErr != nil {
+		return nil, m.loadErr
+	}
+	hooksets := make(map[string]*manifest.HooksetDependencyConfig)
+	for key, rawConfig := range m.manifest.Dependencies {
+		depType, ok := rawConfig["type"].(string)
+		if !ok || depType != "hookset" {
+			continue
+		}
+		configMap, _ := json.Marshal(rawConfig)
+		var result manifest.HooksetDependencyConfig
+		_ = json.Unmarshal(configMap, &result)
+		hooksets[key] = &result
+	}
+	return hooksets, nil
+}
+
 func TestAddSink(t *testing.T) {
 	t.Run("add new sink", func(t *testing.T) {
 		mgr := &mockManifestManager{
@@ -868,3 +1107,85 @@ func TestSetSinkTool(t *testing.T) {
 		}
 	})
 }
+
+func TestDedupeRegeneratesWithheldRules(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	sinkDir := filepath.Join(dir, "rules")
+	manifestPath := filepath.Join(dir, "arm.json")
+	manifestJSON := `{
+  "version": 1,
+  "registries": {"test-registry": {"type": "git", "url": "https://github.com/test/repo"}},
+  "sinks": {"test-sink": {"directory": "` + sinkDir + `", "tool": "cursor", "conflicts": "dedupe"}}
+}`
+	if err := os.WriteFile(manifestPath, []byte(manifestJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	newPackage := func(name, enforcement, body string) *core.Package {
+		v, _ := core.NewVersion("1.0.0")
+		return &core.Package{
+			Metadata: core.PackageMetadata{RegistryName: "test-registry", Name: name, Version: v},
+			Files: []*core.File{{Path: name + ".yml", Content: []byte("apiVersion: v1\nkind: Ruleset\nmetadata:\n  id: " + name +
+				"\nspec:\n  rules:\n    naming:\n      enforcement: " + enforcement + "\n      body: " + body + "\n")}},
+		}
+	}
+	team, base := newPackage("team", "must", "Use descriptive names."), newPackage("base", "should", "Prefer short names.")
+	mockReg := &mockRegistry{
+		versions: map[string][]core.PackageMetadata{"team": {team.Metadata}, "base": {base.Metadata}},
+		packages: map[string]*core.Package{"team@1.0.0": team, "base@1.0.0": base},
+	}
+	svc := NewArmService(manifest.NewFileManagerWithPath(manifestPath), packagelockfile.NewFileManagerWithPath(filepath.Join(dir, "arm-lock.json")), &mockRegistryFactory{registry: mockReg})
+
+	sinks := []string{"test-sink"}
+	if err := svc.InstallRuleset(ctx, "test-registry", "base", "1.0.0", 100, nil, nil, sinks); err != nil {
+		t.Fatalf("InstallRuleset(base) error = %v", err)
+	}
+	if err := svc.InstallRuleset(ctx, "test-registry", "team", "1.0.0", 200, nil, nil, sinks); err != nil {
+		t.Fatalf("InstallRuleset(team) error = %v", err)
+	}
+	baseNaming := filepath.Join(sinkDir, "arm", "test-registry", "base", "1.0.0", "base_naming.mdc")
+	if _, err := os.Stat(baseNaming); !os.IsNotExist(err) {
+		t.Fatalf("base naming rule should be withheld, stat err = %v", err)
+	}
+
+	// Switching to report regenerates the rule from base
+	if err := svc.SetSinkConflicts(ctx, "test-sink", sink.ConflictsReport); err != nil {
+		t.Fatalf("SetSinkConflicts(report) error = %v", err)
+	}
+	if content, err := os.ReadFile(baseNaming); err != nil || !strings.Contains(string(content), "Prefer short names.") {
+		t.Fatalf("expected base naming rule restored in report mode, got %s, %v", content, err)
+	}
+	if err := svc.SetSinkConflicts(ctx, "test-sink", sink.ConflictsDedupe); err != nil {
+		t.Fatalf("SetSinkConflicts(dedupe) error = %v", err)
+	}
+	if _, err := os.Stat(baseNaming); !os.IsNotExist(err) {
+		t.Fatalf("base naming rule should be withheld again, stat err = %v", err)
+	}
+
+	// An overlay added while the rule is withheld applies when it comes back
+	if err := os.WriteFile(filepath.Join(dir, "naming.md"), []byte("Spell out acronyms."), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := svc.manifestMgr.GetRulesetDependencyConfig(ctx, "test-registry", "base")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Overlays = map[string]compiler.RuleOverlay{"naming": {AppendFile: "naming.md"}}
+	if err := svc.manifestMgr.UpsertRulesetDependencyConfig(ctx, "test-registry", "base", config); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := svc.UninstallPackages(ctx, []string{"test-registry/team"}); err != nil {
+		t.Fatalf("UninstallPackages() error = %v", err)
+	}
+	content, err := os.ReadFile(baseNaming)
+	if err != nil {
+		t.Fatalf("base naming rule should be restored once team is uninstalled: %v", err)
+	}
+	for _, want := range []string{"Prefer short names.", "Spell out acronyms."} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("restored rule missing %q:\n%s", want, content)
+		}
+	}
+}
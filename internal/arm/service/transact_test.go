@@ -0,0 +1,333 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-manager/internal/arm/compiler"
+	"github.com/jomadu/ai-resource-manager/internal/arm/core"
+	"github.com/jomadu/ai-resource-manager/internal/arm/manifest"
+	"github.com/jomadu/ai-resource-manager/internal/arm/packagelockfile"
+	"github.com/jomadu/ai-resource-manager/internal/arm/sink"
+)
+
+// newTransactProject sets up a project with ruleset1@1.0.0 installed and 1.1.0 available
+func newTransactProject(t *testing.T) (svc *ArmService, dir, manifestPath, lockfilePath string) {
+	t.Helper()
+	dir = t.TempDir()
+	sinkDir := filepath.Join(dir, "rules")
+	manifestPath = filepath.Join(dir, "arm.json")
+	lockfilePath = filepath.Join(dir, "arm-lock.json")
+
+	manifestJSON := `{
+  "version": 1,
+  "registries": {"test-registry": {"type": "git", "url": "https://github.com/test/repo"}},
+  "sinks": {"test-sink": {"directory": "` + sinkDir + `", "tool": "cursor"}},
+  "dependencies": {
+    "test-registry/ruleset1": {"type": "ruleset", "version": "^1.0.0", "priority": 100, "sinks": ["test-sink"]}
+  }
+}`
+	lockJSON := `{"version": 1, "dependencies": {"test-registry/ruleset1@1.0.0": {"integrity": "hash1"}}}`
+	if err := os.WriteFile(manifestPath, []byte(manifestJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(lockfilePath, []byte(lockJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	newPackage := func(version string, integrity string) *core.Package {
+		v, _ := core.NewVersion(version)
+		return &core.Package{
+			Metadata: core.PackageMetadata{RegistryName: "test-registry", Name: "ruleset1", Version: v},
+			Files: []*core.File{{Path: "rules.yml", Content: []byte(`apiVersion: v1
+kind: Ruleset
+metadata:
+  id: testing
+spec:
+  rules:
+    run-tests:
+      body: Run the tests.
+`)}},
+			Integrity: integrity,
+		}
+	}
+	v1, v2 := newPackage("1.0.0", "hash1"), newPackage("1.1.0", "hash2")
+	mockReg := &mockRegistry{
+		versions: map[string][]core.PackageMetadata{"ruleset1": {v1.Metadata, v2.Metadata}},
+		packages: map[string]*core.Package{"ruleset1@1.0.0": v1, "ruleset1@1.1.0": v2},
+	}
+	if err := sink.NewManager(sinkDir, compiler.Cursor).InstallRuleset(v1, 100, nil, nil, nil); err != nil {
+		t.Fatalf("InstallRuleset() error = %v", err)
+	}
+
+	svc = NewArmService(manifest.NewFileManagerWithPath(manifestPath), packagelockfile.NewFileManagerWithPath(lockfilePath), &mockRegistryFactory{registry: mockReg})
+	return svc, dir, manifestPath, lockfilePath
+}
+
+func assertUnchanged(t *testing.T, dir string, before map[string][]byte) {
+	t.Helper()
+	after, err := readDirFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(after) != len(before) {
+		t.Errorf("project files changed: %d before, %d after", len(before), len(after))
+	}
+	for path, content := range before {
+		if string(after[path]) != string(content) {
+			t.Errorf("%s was modified", path)
+		}
+	}
+}
+
+// readDirFiles returns the contents of the regular files under dir keyed by relative path
+func readDirFiles(dir string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return files, nil
+	}
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		files[rel] = content
+		return nil
+	})
+	return files, err
+}
+
+func TestTransactCommitsChanges(t *testing.T) {
+	ctx := context.Background()
+	svc, dir, manifestPath, lockfilePath := newTransactProject(t)
+
+	err := svc.Transact(ctx, manifestPath, lockfilePath, func(ctx context.Context, sandbox *ArmService) error {
+		return sandbox.UpdateAll(ctx)
+	})
+	if err != nil {
+		t.Fatalf("Transact() error = %v", err)
+	}
+
+	versionsDir := filepath.Join(dir, "rules", "arm", "test-registry", "ruleset1")
+	if _, err := os.Stat(filepath.Join(versionsDir, "1.1.0", "testing_run-tests.mdc")); err != nil {
+		t.Errorf("new rule not installed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(versionsDir, "1.0.0")); !os.IsNotExist(err) {
+		t.Errorf("old version directory still exists: %v", err)
+	}
+
+	versions, err := lockedVersions(ctx, lockfilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if versions["test-registry/ruleset1"] != "1.1.0" {
+		t.Errorf("locked versions = %v, want ruleset1 at 1.1.0", versions)
+	}
+}
+
+func TestTransactStagesOnlyOwnedFiles(t *testing.T) {
+	ctx := context.Background()
+	svc, dir, manifestPath, lockfilePath := newTransactProject(t)
+
+	// A sink at the project root shares its directory with everything else
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = []byte(strings.Replace(string(data), filepath.Join(dir, "rules"), dir, 1))
+	if err := os.WriteFile(manifestPath, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(filepath.Join(dir, "rules", "arm"), filepath.Join(dir, "arm")); err != nil {
+		t.Fatal(err)
+	}
+	userFiles := map[string]string{
+		filepath.Join(".git", "HEAD"):                         "ref: refs/heads/main\n",
+		filepath.Join("node_modules", "left-pad", "index.js"): "module.exports = {}\n",
+	}
+	for path, content := range userFiles {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, path)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, path), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err = svc.Transact(ctx, manifestPath, lockfilePath, func(ctx context.Context, sandbox *ArmService) error {
+		staged := sandbox.sinkDirs[dir]
+		for path := range userFiles {
+			if _, err := os.Stat(filepath.Join(staged.dir, path)); !os.IsNotExist(err) {
+				t.Errorf("%s was copied into the sandbox", path)
+			}
+		}
+		if !staged.owned[filepath.Join("arm", "arm-index.json")] {
+			t.Errorf("index was not staged, staged files = %v", staged.owned)
+		}
+		if !staged.unmanaged("node_modules") || staged.unmanaged("arm") {
+			t.Errorf("unmanaged paths are not reported from the project")
+		}
+		return sandbox.UpdateAll(ctx)
+	})
+	if err != nil {
+		t.Fatalf("Transact() error = %v", err)
+	}
+
+	versionsDir := filepath.Join(dir, "arm", "test-registry", "ruleset1")
+	if _, err := os.Stat(filepath.Join(versionsDir, "1.1.0", "testing_run-tests.mdc")); err != nil {
+		t.Errorf("new rule not installed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(versionsDir, "1.0.0")); !os.IsNotExist(err) {
+		t.Errorf("old version directory still exists: %v", err)
+	}
+	for path, content := range userFiles {
+		got, err := os.ReadFile(filepath.Join(dir, path))
+		if err != nil || string(got) != content {
+			t.Errorf("%s changed: %q, %v", path, got, err)
+		}
+	}
+}
+
+func TestTransactWithToolFromToolsDir(t *testing.T) {
+	ctx := context.Background()
+	svc, dir, manifestPath, lockfilePath := newTransactProject(t)
+
+	toolsDir := filepath.Join(dir, manifest.ToolsDir)
+	if err := os.MkdirAll(toolsDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	toolYAML := "layout: hierarchical\nruleTemplate: \"{{ .Rule.Body }}\"\nruleFilename: \"{{ .RulesetID }}_{{ .RuleID }}.md\"\n"
+	if err := os.WriteFile(filepath.Join(toolsDir, "windsurf.yml"), []byte(toolYAML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	windsurfDir := filepath.Join(dir, "windsurf")
+	if err := svc.AddSink(ctx, "windsurf-sink", windsurfDir, compiler.Tool("windsurf"), false); err != nil {
+		t.Fatalf("AddSink() error = %v", err)
+	}
+
+	err := svc.Transact(ctx, manifestPath, lockfilePath, func(ctx context.Context, sandbox *ArmService) error {
+		return sandbox.InstallRuleset(ctx, "test-registry", "ruleset1", "1.1.0", 100, nil, nil, []string{"windsurf-sink"})
+	})
+	if err != nil {
+		t.Fatalf("Transact() error = %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(windsurfDir, "arm", "test-registry", "ruleset1", "1.1.0", "testing_run-tests.md"))
+	if err != nil {
+		t.Fatalf("rule not installed through the tools/ definition: %v", err)
+	}
+	if string(content) != "Run the tests." {
+		t.Errorf("rule content = %q, want the tool template output", content)
+	}
+}
+
+func TestTransactResolvesOverlaysAgainstManifestDir(t *testing.T) {
+	ctx := context.Background()
+	svc, dir, manifestPath, lockfilePath := newTransactProject(t)
+
+	if err := os.MkdirAll(filepath.Join(dir, "overlays"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "overlays", "run-tests.md"), []byte("Run them with -race."), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := svc.manifestMgr.GetRulesetDependencyConfig(ctx, "test-registry", "ruleset1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Overlays = map[string]compiler.RuleOverlay{"run-tests": {AppendFile: "overlays/run-tests.md"}}
+	if err := svc.manifestMgr.UpsertRulesetDependencyConfig(ctx, "test-registry", "ruleset1", config); err != nil {
+		t.Fatal(err)
+	}
+
+	// Run from somewhere other than the project root
+	t.Chdir(t.TempDir())
+	err = svc.Transact(ctx, manifestPath, lockfilePath, func(ctx context.Context, sandbox *ArmService) error {
+		return sandbox.UpdateAll(ctx)
+	})
+	if err != nil {
+		t.Fatalf("Transact() error = %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "rules", "arm", "test-registry", "ruleset1", "1.1.0", "testing_run-tests.mdc"))
+	if err != nil {
+		t.Fatalf("rule not installed: %v", err)
+	}
+	if !strings.Contains(string(content), "Run them with -race.") {
+		t.Errorf("overlay file was not appended:\n%s", content)
+	}
+}
+
+func TestTransactLeavesProjectUntouchedOnError(t *testing.T) {
+	ctx := context.Background()
+	svc, dir, manifestPath, lockfilePath := newTransactProject(t)
+	before, err := readDirFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantErr := errors.New("install failed")
+	err = svc.Transact(ctx, manifestPath, lockfilePath, func(ctx context.Context, sandbox *ArmService) error {
+		if err := sandbox.UpdateAll(ctx); err != nil {
+			return err
+		}
+		return wantErr
+	})
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("Transact() error = %v, want %v", err, wantErr)
+	}
+	assertUnchanged(t, dir, before)
+}
+
+// cancelAfter is a context that reports cancellation once Err has been called n times
+type cancelAfter struct {
+	context.Context
+	n int
+}
+
+func (c *cancelAfter) Err() error {
+	if c.n <= 0 {
+		return context.Canceled
+	}
+	c.n--
+	return nil
+}
+
+func TestTransactRollsBackWhenCancelled(t *testing.T) {
+	tests := []struct {
+		name string
+		n    int
+	}{
+		{"before commit", 0},
+		{"during commit", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, dir, manifestPath, lockfilePath := newTransactProject(t)
+			before, err := readDirFiles(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := &cancelAfter{Context: context.Background(), n: 1 << 30}
+			err = svc.Transact(ctx, manifestPath, lockfilePath, func(_ context.Context, sandbox *ArmService) error {
+				err := sandbox.UpdateAll(context.Background())
+				ctx.n = tt.n
+				return err
+			})
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("Transact() error = %v, want context.Canceled", err)
+			}
+			assertUnchanged(t, dir, before)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTree(t *testing.T) {
+	ctx := context.Background()
+	svc, dir, _, _ := newTransactProject(t)
+
+	trees, err := svc.Tree(ctx)
+	if err != nil {
+		t.Fatalf("Tree() error = %v", err)
+	}
+	if len(trees) != 1 || trees[0].Name != "test-registry" || len(trees[0].Packages) != 1 {
+		t.Fatalf("Tree() = %+v, want one package in test-registry", trees)
+	}
+
+	pkg := trees[0].Packages[0]
+	if pkg.Name != "ruleset1" || pkg.Type != "ruleset" || pkg.Version != "1.0.0" || pkg.Priority != 100 {
+		t.Errorf("package = %+v", pkg)
+	}
+	if len(pkg.Sinks) != 1 || pkg.Sinks[0].Name != "test-sink" || pkg.Sinks[0].Directory != filepath.Join(dir, "rules") {
+		t.Fatalf("sinks = %+v", pkg.Sinks)
+	}
+	files := pkg.Sinks[0].Files
+	if len(files) != 1 || files[0].Rule != "run-tests" || files[0].Source != "rules.yml" {
+		t.Errorf("files = %+v", files)
+	}
+}
+
+func TestWhy(t *testing.T) {
+	ctx := context.Background()
+	svc, dir, _, _ := newTransactProject(t)
+
+	rulePath := filepath.Join(dir, "rules", "arm", "test-registry", "ruleset1", "1.0.0", "testing_run-tests.mdc")
+	origins, err := svc.Why(ctx, rulePath)
+	if err != nil {
+		t.Fatalf("Why() error = %v", err)
+	}
+	if len(origins) != 1 {
+		t.Fatalf("Why() = %+v, want one origin", origins)
+	}
+	origin := origins[0]
+	if origin.Sink != "test-sink" || origin.Package != "test-registry/ruleset1@1.0.0" || origin.Source != "rules.yml" ||
+		origin.Ruleset != "testing" || origin.Rule != "run-tests" || origin.Priority != 100 {
+		t.Errorf("origin = %+v", origin)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{filepath.Join(dir, "rules", "mine.mdc"), "was not installed by ARM"},
+		{filepath.Join(dir, "README.md"), "is not in any sink directory"},
+		{filepath.Join(dir, "rules", "arm", "arm-index.json"), "is the index ARM maintains for sink test-sink"},
+	}
+	for _, tt := range tests {
+		if _, err := svc.Why(ctx, tt.path); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Why(%s) error = %v, want %q", tt.path, err, tt.want)
+		}
+	}
+}
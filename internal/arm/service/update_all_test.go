@@ -3,12 +3,16 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/jomadu/ai-resource-manager/internal/arm/compiler"
 	"github.com/jomadu/ai-resource-manager/internal/arm/core"
 	"github.com/jomadu/ai-resource-manager/internal/arm/manifest"
 	"github.com/jomadu/ai-resource-manager/internal/arm/packagelockfile"
+	"github.com/jomadu/ai-resource-manager/internal/arm/sink"
 )
 
 // Normal: update multiple dependencies with newer versions available
@@ -172,6 +176,152 @@ func TestUpdateAll_AlreadyUpToDate(t *testing.T) {
 	}
 }
 
+// Normal: same version is reinstalled when the vars it was rendered with change
+func TestUpdateAll_ReinstallsWhenVarsChange(t *testing.T) {
+	ctx := context.Background()
+	sinkDir := t.TempDir()
+
+	rulesetDep := manifest.RulesetDependencyConfig{
+		BaseDependencyConfig: manifest.BaseDependencyConfig{
+			Type:    manifest.ResourceTypeRuleset,
+			Version: "^1.0.0",
+			Sinks:   []string{"test-sink"},
+		},
+		Priority: 100,
+	}
+	rulesetDepMap, _ := json.Marshal(rulesetDep)
+	var rulesetDepInterface map[string]interface{}
+	_ = json.Unmarshal(rulesetDepMap, &rulesetDepInterface)
+
+	manifestMgr := &mockManifestManager{
+		manifest: &manifest.Manifest{
+			Registries: map[string]map[string]interface{}{
+				"test-registry": {"type": "git", "url": "https://github.com/test/repo"},
+			},
+			Sinks: map[string]manifest.SinkConfig{
+				"test-sink": {Directory: sinkDir, Tool: compiler.Cursor},
+			},
+			Dependencies: map[string]map[string]interface{}{
+				"test-registry/ruleset1": rulesetDepInterface,
+			},
+			Vars: map[string]string{"testCommand": "npm test"},
+		},
+	}
+
+	lockfileMgr := &mockLockfileManager{
+		locks: map[string]*packagelockfile.DependencyLockConfig{
+			"test-registry/ruleset1@1.0.0": {Integrity: "hash1"},
+		},
+	}
+
+	version := core.Version{Major: 1, Minor: 0, Patch: 0, Version: "1.0.0", IsSemver: true}
+	pkg := &core.Package{
+		Metadata: core.PackageMetadata{RegistryName: "test-registry", Name: "ruleset1", Version: version},
+		Files: []*core.File{{Path: "rules.yml", Content: []byte(`apiVersion: v1
+kind: Ruleset
+metadata:
+  id: testing
+spec:
+  rules:
+    run-tests:
+      body: Run {{ .Vars.testCommand }} before committing.
+`)}},
+		Integrity: "hash1",
+	}
+	mockReg := &mockRegistry{
+		versions: map[string][]core.PackageMetadata{"ruleset1": {pkg.Metadata}},
+		packages: map[string]*core.Package{"ruleset1@1.0.0": pkg},
+	}
+
+	// Installed earlier while the manifest still said "make test"
+	if err := sink.NewManager(sinkDir, compiler.Cursor).InstallRuleset(pkg, 100, nil, map[string]string{"testCommand": "make test"}, nil); err != nil {
+		t.Fatalf("InstallRuleset() error = %v", err)
+	}
+
+	service := NewArmService(manifestMgr, lockfileMgr, &mockRegistryFactory{registry: mockReg})
+	if err := service.UpdateAll(ctx); err != nil {
+		t.Fatalf("UpdateAll() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(sinkDir, "arm", "test-registry", "ruleset1", "1.0.0", "testing_run-tests.mdc"))
+	if err != nil || !strings.Contains(string(content), "Run npm test before committing.") {
+		t.Errorf("expected rule re-rendered with the new vars, got %s, %v", content, err)
+	}
+}
+
+// Normal: updating a package rebuilds the rulesets of other packages extending it
+func TestUpdatePackages_RebuildsExtendingRulesets(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	sinkDir := filepath.Join(dir, "rules")
+	manifestPath := filepath.Join(dir, "arm.json")
+	manifestJSON := `{
+  "version": 1,
+  "registries": {"test-registry": {"type": "git", "url": "https://github.com/test/repo"}},
+  "sinks": {"test-sink": {"directory": "` + sinkDir + `", "tool": "cursor"}}
+}`
+	if err := os.WriteFile(manifestPath, []byte(manifestJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	newPackage := func(name, version, content string) *core.Package {
+		v, _ := core.NewVersion(version)
+		return &core.Package{
+			Metadata: core.PackageMetadata{RegistryName: "test-registry", Name: name, Version: v},
+			Files:    []*core.File{{Path: name + ".yml", Content: []byte(content)}},
+		}
+	}
+	shared := func(version, body string) *core.Package {
+		return newPackage("shared", version, "apiVersion: v1\nkind: Ruleset\nmetadata:\n  id: base\nspec:\n  rules:\n    naming:\n      body: "+body+"\n")
+	}
+	app := newPackage("app", "1.0.0", `apiVersion: v1
+kind: Ruleset
+metadata:
+  id: python
+spec:
+  extends: ["test-registry/shared/base"]
+  rules:
+    tests:
+      body: Write pytest tests.
+`)
+	shared1, shared2 := shared("1.0.0", "Use short names."), shared("1.1.0", "Use descriptive names.")
+	mockReg := &mockRegistry{
+		versions: map[string][]core.PackageMetadata{"app": {app.Metadata}, "shared": {shared1.Metadata}},
+		packages: map[string]*core.Package{"app@1.0.0": app, "shared@1.0.0": shared1, "shared@1.1.0": shared2},
+	}
+	svc := NewArmService(manifest.NewFileManagerWithPath(manifestPath), packagelockfile.NewFileManagerWithPath(filepath.Join(dir, "arm-lock.json")), &mockRegistryFactory{registry: mockReg})
+
+	sinks := []string{"test-sink"}
+	if err := svc.InstallRuleset(ctx, "test-registry", "shared", "^1.0.0", 100, nil, nil, sinks); err != nil {
+		t.Fatalf("InstallRuleset(shared) error = %v", err)
+	}
+	if err := svc.InstallRuleset(ctx, "test-registry", "app", "^1.0.0", 200, nil, nil, sinks); err != nil {
+		t.Fatalf("InstallRuleset(app) error = %v", err)
+	}
+	inherited := filepath.Join(sinkDir, "arm", "test-registry", "app", "1.0.0", "python_naming.mdc")
+	if content, err := os.ReadFile(inherited); err != nil || !strings.Contains(string(content), "Use short names.") {
+		t.Fatalf("expected the inherited rule from shared 1.0.0, got %s, %v", content, err)
+	}
+
+	// shared 1.1.0 is released; app itself has no new version
+	mockReg.versions["shared"] = append(mockReg.versions["shared"], shared2.Metadata)
+	if err := svc.UpdatePackages(ctx, []string{"test-registry/shared"}); err != nil {
+		t.Fatalf("UpdatePackages() error = %v", err)
+	}
+
+	content, err := os.ReadFile(inherited)
+	if err != nil || !strings.Contains(string(content), "Use descriptive names.") {
+		t.Errorf("expected app rebuilt on shared 1.1.0, got %s, %v", content, err)
+	}
+	lockFile, err := packagelockfile.NewFileManagerWithPath(filepath.Join(dir, "arm-lock.json")).GetLockFile(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := lockFile.Dependencies["test-registry/app@1.0.0"]; !ok {
+		t.Errorf("app should stay locked at 1.0.0: %v", lockFile.Dependencies)
+	}
+}
+
 // Edge: empty dependencies
 func TestUpdateAll_EmptyDependencies(t *testing.T) {
 	ctx := context.Background()
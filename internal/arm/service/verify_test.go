@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	ctx := context.Background()
+	svc, dir, _, lockfilePath := newTransactProject(t)
+
+	report, err := svc.Verify(ctx)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if !report.Clean() {
+		t.Fatalf("Verify() = %+v, want no drift", report)
+	}
+
+	rulePath := filepath.Join(dir, "rules", "arm", "test-registry", "ruleset1", "1.0.0", "testing_run-tests.mdc")
+	if err := os.WriteFile(rulePath, []byte("edited"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	lockJSON := `{"version": 1, "dependencies": {"test-registry/ruleset1@1.0.0": {"integrity": "hash0"}}}`
+	if err := os.WriteFile(lockfilePath, []byte(lockJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err = svc.Verify(ctx)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	wantFile := FileDrift{Sink: "test-sink", Path: rulePath, Kind: "modified", Package: "test-registry/ruleset1@1.0.0"}
+	if len(report.Files) != 1 || report.Files[0] != wantFile {
+		t.Errorf("Files = %+v, want %+v", report.Files, wantFile)
+	}
+	wantPackage := PackageDrift{Package: "test-registry/ruleset1@1.0.0", Locked: "hash0", Actual: "hash1"}
+	if len(report.Packages) != 1 || report.Packages[0] != wantPackage {
+		t.Errorf("Packages = %+v, want %+v", report.Packages, wantPackage)
+	}
+
+	// A locked version the registry no longer has cannot be verified
+	lockJSON = `{"version": 1, "dependencies": {"test-registry/ruleset1@0.9.0": {"integrity": "hash0"}}}`
+	if err := os.WriteFile(lockfilePath, []byte(lockJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	report, err = svc.Verify(ctx)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if len(report.Packages) != 1 || !strings.Contains(report.Packages[0].Error, "no version satisfies constraint") {
+		t.Errorf("Packages = %+v, want a fetch error", report.Packages)
+	}
+}
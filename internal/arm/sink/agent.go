@@ -0,0 +1,117 @@
+package sink
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jomadu/ai-resource-manager/internal/arm/core"
+	"github.com/jomadu/ai-resource-manager/internal/arm/filetype"
+	"github.com/jomadu/ai-resource-manager/internal/arm/parser"
+)
+
+// AgentsetIndexEntry records the agent files a package owns in the sink directory
+type AgentsetIndexEntry struct {
+	Files []string `json:"files"`
+	// Sources maps each agent file to the package file that defines the agent
+	Sources map[string]string `json:"sources,omitempty"`
+}
+
+// InstallAgentset installs an agentset package. Tools only discover agents at the
+// top level of their agents directory, so agent files are written directly into the
+// sink directory regardless of layout and never replace files ARM did not install.
+func (m *Manager) InstallAgentset(pkg *core.Package) error {
+	if m.agentGenerator == nil {
+		return fmt.Errorf("sink tool does not support agents")
+	}
+
+	// Uninstall all existing versions of this package
+	if err := m.Uninstall(pkg.Metadata.RegistryName, pkg.Metadata.Name); err != nil {
+		return err
+	}
+
+	key := pkgKey(pkg.Metadata.RegistryName, pkg.Metadata.Name, pkg.Metadata.Version.Version)
+
+	contents := make(map[string]string)
+	sources := make(map[string]string)
+	for _, file := range pkg.Files {
+		if !filetype.IsAgentsetFile(file) {
+			continue
+		}
+		agentset, err := parser.ParseAgentset(file)
+		if err != nil {
+			return err
+		}
+		for agentID := range agentset.Spec.Agents {
+			filename, err := m.agentGenerator.GenerateAgentFilename(agentset.Metadata.ID, agentID)
+			if err != nil {
+				return err
+			}
+			if _, exists := contents[filename]; exists {
+				return fmt.Errorf("agent file %s is generated more than once in %s", filename, key)
+			}
+			content, err := m.agentGenerator.GenerateAgent(agentset, agentID)
+			if err != nil {
+				return err
+			}
+			contents[filename] = content
+			sources[filename] = file.Path
+		}
+	}
+
+	index, err := m.loadIndex()
+	if err != nil {
+		return err
+	}
+
+	owners := make(map[string]string)
+	for ownerKey, entry := range index.Agentsets {
+		for _, filename := range entry.Files {
+			owners[filename] = ownerKey
+		}
+	}
+
+	filenames := make([]string, 0, len(contents))
+	for filename := range contents {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	for _, filename := range filenames {
+		if owner, ok := owners[filename]; ok {
+			return fmt.Errorf("agent file %s is already installed by %s", filename, owner)
+		}
+		if m.taken(filename) {
+			return fmt.Errorf("agent file %s already exists in %s and is not managed by ARM", filename, m.directory)
+		}
+	}
+
+	for _, filename := range filenames {
+		if err := os.WriteFile(filepath.Join(m.directory, filename), []byte(contents[filename]), 0o644); err != nil {
+			return err
+		}
+	}
+
+	index.Agentsets[key] = AgentsetIndexEntry{
+		Files:   filenames,
+		Sources: nilIfEmpty(sources),
+	}
+
+	return m.saveIndex(index)
+}
+
+// removeAgents deletes the agent files owned by packages whose key starts with prefix
+// and drops their index entries
+func (m *Manager) removeAgents(index *Index, prefix string) {
+	for key, entry := range index.Agentsets {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		for _, filename := range entry.Files {
+			_ = os.Remove(filepath.Join(m.directory, filename)) // Ignore errors
+		}
+		delete(index.Agentsets, key)
+	}
+}
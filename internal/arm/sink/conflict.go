@@ -0,0 +1,312 @@
+package sink
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jomadu/ai-resource-manager/internal/arm/resource"
+)
+
+// ConflictMode controls what a sink does with rules that conflict across installed rulesets
+type ConflictMode string
+
+const (
+	// ConflictsReport installs every rule and only reports conflicts
+	ConflictsReport ConflictMode = "report"
+	// ConflictsDedupe does not emit rules that conflict with a rule of a higher-priority ruleset
+	ConflictsDedupe ConflictMode = "dedupe"
+)
+
+// Validate checks that the mode is known; an empty mode means ConflictsReport
+func (c ConflictMode) Validate() error {
+	switch c {
+	case "", ConflictsReport, ConflictsDedupe:
+		return nil
+	default:
+		return fmt.Errorf("invalid conflict mode %q (must be %s or %s)", c, ConflictsReport, ConflictsDedupe)
+	}
+}
+
+// ConflictKind describes why two installed rules conflict
+type ConflictKind string
+
+const (
+	// ConflictDuplicateID means both rulesets define a rule with the same ID
+	ConflictDuplicateID ConflictKind = "duplicate-id"
+	// ConflictEnforcement means both rulesets define the same rule for the same scope with different enforcement
+	ConflictEnforcement ConflictKind = "enforcement"
+	// ConflictDuplicateBody means the rules have different IDs but identical bodies
+	ConflictDuplicateBody ConflictKind = "duplicate-body"
+)
+
+// RuleIndexEntry records an installed rule so conflicts can be detected
+// without re-reading the generated files
+type RuleIndexEntry struct {
+	Ruleset     string   `json:"ruleset"`
+	ID          string   `json:"id"`
+	Enforcement string   `json:"enforcement,omitempty"`
+	Scope       string   `json:"scope,omitempty"`
+	BodyHash    string   `json:"bodyHash"`
+	Files       []string `json:"files"`
+	// Withheld reports that the rule's files are not written because the rule
+	// loses a conflict in dedupe mode; reinstalling the package regenerates them
+	Withheld bool `json:"withheld,omitempty"`
+}
+
+// RuleRef identifies a rule of an installed ruleset package
+type RuleRef struct {
+	Package  string // REGISTRY/PACKAGE@VERSION
+	Ruleset  string
+	Rule     string
+	Priority int
+}
+
+func (r RuleRef) String() string {
+	return fmt.Sprintf("%s %s/%s (priority %d)", r.Package, r.Ruleset, r.Rule, r.Priority)
+}
+
+// RuleConflict is a pair of conflicting rules from different packages. Winner
+// belongs to the higher-priority ruleset; equal priorities are ordered by package.
+type RuleConflict struct {
+	Kind   ConflictKind
+	Winner RuleRef
+	Loser  RuleRef
+	// Suppressed reports that the losing rule is not emitted (dedupe mode)
+	Suppressed bool
+}
+
+// RuleConflicts returns the conflicts between the rulesets installed in the sink
+func (m *Manager) RuleConflicts() ([]RuleConflict, error) {
+	index, err := m.loadIndex()
+	if err != nil {
+		return nil, err
+	}
+	return m.detectConflicts(index), nil
+}
+
+// newRuleIndexEntry describes a rule as installed to files
+func newRuleIndexEntry(rulesetID, ruleID string, rule *resource.Rule, files []string) RuleIndexEntry {
+	sort.Strings(files)
+	return RuleIndexEntry{
+		Ruleset:     rulesetID,
+		ID:          ruleID,
+		Enforcement: rule.Enforcement,
+		Scope:       canonicalScope(rule.Scope),
+		BodyHash:    bodyHash(rule.Body),
+		Files:       files,
+	}
+}
+
+// canonicalScope renders a scope so that equal scopes compare equal regardless of ordering
+func canonicalScope(scopes []resource.Scope) string {
+	entries := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		entries = append(entries, fmt.Sprintf("files=%s;languages=%s;directories=%s",
+			sortedJoin(scope.Files), sortedJoin(scope.Languages), sortedJoin(scope.Directories)))
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, "|")
+}
+
+func sortedJoin(values []string) string {
+	sorted := append([]string(nil), values...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
+
+// bodyHash hashes a rule body with whitespace normalized
+func bodyHash(body string) string {
+	hash := sha256.Sum256([]byte(strings.Join(strings.Fields(body), " ")))
+	return hex.EncodeToString(hash[:])[:16]
+}
+
+type installedRule struct {
+	ref   RuleRef
+	entry *RuleIndexEntry
+}
+
+// installedRules returns the rules of every installed ruleset, highest priority first
+func installedRules(index *Index) []installedRule {
+	var rules []installedRule
+	for key, entry := range index.Rulesets {
+		for i := range entry.Rules {
+			rule := &entry.Rules[i]
+			rules = append(rules, installedRule{
+				ref:   RuleRef{Package: key, Ruleset: rule.Ruleset, Rule: rule.ID, Priority: entry.Priority},
+				entry: rule,
+			})
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		a, b := rules[i].ref, rules[j].ref
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+		if a.Ruleset != b.Ruleset {
+			return a.Ruleset < b.Ruleset
+		}
+		return a.Rule < b.Rule
+	})
+	return rules
+}
+
+type conflictingRules struct {
+	kind          ConflictKind
+	winner, loser installedRule
+}
+
+// conflictingPairs compares the rules of different packages pairwise, in priority order
+func conflictingPairs(index *Index) []conflictingRules {
+	rules := installedRules(index)
+	var pairs []conflictingRules
+	for i, winner := range rules {
+		for _, loser := range rules[i+1:] {
+			if winner.ref.Package == loser.ref.Package {
+				continue
+			}
+			if kind, ok := conflictKind(winner.entry, loser.entry); ok {
+				pairs = append(pairs, conflictingRules{kind: kind, winner: winner, loser: loser})
+			}
+		}
+	}
+	return pairs
+}
+
+// dedupePairs returns the conflicts that withhold a rule in dedupe mode. A rule
+// that is withheld itself wins against nothing, so a lower-priority rule that only
+// conflicts with it is kept. Pairs come in priority order, so every rule's own
+// conflicts with higher-priority rules are settled before it is seen as a winner.
+func dedupePairs(pairs []conflictingRules) []conflictingRules {
+	withheld := make(map[*RuleIndexEntry]bool)
+	var kept []conflictingRules
+	for _, pair := range pairs {
+		if withheld[pair.winner.entry] {
+			continue
+		}
+		withheld[pair.loser.entry] = true
+		kept = append(kept, pair)
+	}
+	return kept
+}
+
+func (m *Manager) detectConflicts(index *Index) []RuleConflict {
+	pairs := conflictingPairs(index)
+	if m.conflicts == ConflictsDedupe {
+		pairs = dedupePairs(pairs)
+	}
+
+	var conflicts []RuleConflict
+	for _, pair := range pairs {
+		conflicts = append(conflicts, RuleConflict{
+			Kind:       pair.kind,
+			Winner:     pair.winner.ref,
+			Loser:      pair.loser.ref,
+			Suppressed: m.conflicts == ConflictsDedupe,
+		})
+	}
+	return conflicts
+}
+
+func conflictKind(a, b *RuleIndexEntry) (ConflictKind, bool) {
+	switch {
+	case a.ID == b.ID && a.Scope == b.Scope && a.Enforcement != b.Enforcement:
+		return ConflictEnforcement, true
+	case a.ID == b.ID:
+		return ConflictDuplicateID, true
+	case a.BodyHash == b.BodyHash:
+		return ConflictDuplicateBody, true
+	default:
+		return "", false
+	}
+}
+
+// withheldRules returns the rules that lose a conflict under the sink's conflict mode
+func (m *Manager) withheldRules(index *Index) map[*RuleIndexEntry]bool {
+	withheld := make(map[*RuleIndexEntry]bool)
+	if m.conflicts == ConflictsDedupe {
+		for _, pair := range dedupePairs(conflictingPairs(index)) {
+			withheld[pair.loser.entry] = true
+		}
+	}
+	return withheld
+}
+
+// applyConflictMode removes the files of rules that lose a conflict in dedupe
+// mode. Rules that no longer lose stay withheld until their package is
+// reinstalled, since only the package can regenerate their files.
+func (m *Manager) applyConflictMode(index *Index) error {
+	for entry := range m.withheldRules(index) {
+		if entry.Withheld {
+			continue
+		}
+		for _, file := range entry.Files {
+			if err := os.Remove(filepath.Join(m.directory, file)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		entry.Withheld = true
+	}
+	return nil
+}
+
+// HasRulesToRestore reports whether the installed package has withheld rules
+// that no longer lose a conflict, so it needs reinstalling to write them again
+func (m *Manager) HasRulesToRestore(registryName, packageName, version string) bool {
+	index, err := m.loadIndex()
+	if err != nil {
+		return false
+	}
+	entry, ok := index.Rulesets[pkgKey(registryName, packageName, version)]
+	if !ok {
+		return false
+	}
+	withheld := m.withheldRules(index)
+	for i := range entry.Rules {
+		if entry.Rules[i].Withheld && !withheld[&entry.Rules[i]] {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyConflictMode withholds the installed rules that lose a conflict under the
+// sink's conflict mode, for when the mode changes after rulesets were installed.
+// Packages whose rules should be written again are reported by HasRulesToRestore.
+func (m *Manager) ApplyConflictMode() error {
+	index, err := m.loadIndex()
+	if err != nil {
+		return err
+	}
+	if len(index.Rulesets) == 0 {
+		return nil
+	}
+	if err := m.applyConflictMode(index); err != nil {
+		return err
+	}
+	if err := m.saveIndex(index); err != nil {
+		return err
+	}
+	return m.generateRulesetIndexRuleFile()
+}
+
+// suppressedFiles returns the files of an installed ruleset that are withheld in dedupe mode
+func suppressedFiles(entry RulesetIndexEntry) map[string]bool {
+	files := make(map[string]bool)
+	for _, rule := range entry.Rules {
+		if !rule.Withheld {
+			continue
+		}
+		for _, file := range rule.Files {
+			files[file] = true
+		}
+	}
+	return files
+}
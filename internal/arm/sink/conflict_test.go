@@ -0,0 +1,251 @@
+package sink
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-manager/internal/arm/compiler"
+	"github.com/jomadu/ai-resource-manager/internal/arm/core"
+)
+
+func conflictTestPackage(name, rules string) *core.Package {
+	return &core.Package{
+		Metadata: core.PackageMetadata{RegistryName: "test-reg", Name: name, Version: mustVersion("1.0.0")},
+		Files: []*core.File{{Path: name + ".yml", Content: []byte(`apiVersion: v1
+kind: Ruleset
+metadata:
+  id: ` + name + `
+spec:
+  rules:
+` + rules)}},
+	}
+}
+
+// installConflictingRulesets installs base at priority 100 and team at 200, and returns base
+func installConflictingRulesets(t *testing.T, m *Manager) *core.Package {
+	t.Helper()
+	team := conflictTestPackage("team", `    naming:
+      enforcement: must
+      body: Use descriptive names.
+    tests:
+      body: Write table-driven tests.
+`)
+	base := conflictTestPackage("base", `    naming:
+      enforcement: should
+      body: Prefer short names.
+    testing:
+      body: "Write  table-driven tests."
+    docs:
+      body: Document exported functions.
+`)
+	if err := m.InstallRuleset(base, 100, nil, nil, nil); err != nil {
+		t.Fatalf("InstallRuleset(base) failed: %v", err)
+	}
+	if err := m.InstallRuleset(team, 200, nil, nil, nil); err != nil {
+		t.Fatalf("InstallRuleset(team) failed: %v", err)
+	}
+	return base
+}
+
+func TestRuleConflicts(t *testing.T) {
+	tmpDir := t.TempDir()
+	m := NewManager(tmpDir, compiler.Cursor)
+	installConflictingRulesets(t, m)
+
+	conflicts, err := m.RuleConflicts()
+	if err != nil {
+		t.Fatalf("RuleConflicts() error = %v", err)
+	}
+
+	want := []string{
+		"enforcement: test-reg/team@1.0.0 team/naming (priority 200) over test-reg/base@1.0.0 base/naming (priority 100)",
+		"duplicate-body: test-reg/team@1.0.0 team/tests (priority 200) over test-reg/base@1.0.0 base/testing (priority 100)",
+	}
+	if len(conflicts) != len(want) {
+		t.Fatalf("RuleConflicts() = %v, want %d conflicts", conflicts, len(want))
+	}
+	for i, conflict := range conflicts {
+		if got := string(conflict.Kind) + ": " + conflict.Winner.String() + " over " + conflict.Loser.String(); got != want[i] {
+			t.Errorf("conflict %d = %q, want %q", i, got, want[i])
+		}
+		if conflict.Suppressed {
+			t.Errorf("conflict %d should not be suppressed in report mode", i)
+		}
+	}
+
+	// Report mode installs every rule
+	if _, err := os.Stat(filepath.Join(tmpDir, "arm", "test-reg", "base", "1.0.0", "base_naming.mdc")); err != nil {
+		t.Errorf("lower-priority rule should be installed in report mode: %v", err)
+	}
+}
+
+func TestRuleConflictsSameIDDifferentScope(t *testing.T) {
+	m := NewManager(t.TempDir(), compiler.Cursor)
+	a := conflictTestPackage("a", `    naming:
+      enforcement: must
+      scope:
+        - files: ["**/*.go"]
+      body: Go names.
+`)
+	b := conflictTestPackage("b", `    naming:
+      enforcement: may
+      scope:
+        - files: ["**/*.py"]
+      body: Python names.
+`)
+	for _, pkg := range []*core.Package{a, b} {
+		if err := m.InstallRuleset(pkg, 100, nil, nil, nil); err != nil {
+			t.Fatalf("InstallRuleset failed: %v", err)
+		}
+	}
+
+	conflicts, err := m.RuleConflicts()
+	if err != nil {
+		t.Fatalf("RuleConflicts() error = %v", err)
+	}
+	// Equal priorities are ordered by package, so a wins
+	if len(conflicts) != 1 || conflicts[0].Kind != ConflictDuplicateID || conflicts[0].Winner.Package != "test-reg/a@1.0.0" {
+		t.Errorf("RuleConflicts() = %+v, want one duplicate-id conflict won by a", conflicts)
+	}
+}
+
+func TestRuleConflictsDedupe(t *testing.T) {
+	tmpDir := t.TempDir()
+	m := NewManagerWithOptions(tmpDir, compiler.Cursor, Options{Conflicts: ConflictsDedupe})
+	base := installConflictingRulesets(t, m)
+
+	baseDir := filepath.Join(tmpDir, "arm", "test-reg", "base", "1.0.0")
+	for _, name := range []string{"base_naming.mdc", "base_testing.mdc"} {
+		if _, err := os.Stat(filepath.Join(baseDir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s should not be emitted in dedupe mode, stat err = %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(baseDir, "base_docs.mdc")); err != nil {
+		t.Errorf("non-conflicting rule should be installed: %v", err)
+	}
+
+	conflicts, err := m.RuleConflicts()
+	if err != nil {
+		t.Fatalf("RuleConflicts() error = %v", err)
+	}
+	if len(conflicts) != 2 || !conflicts[0].Suppressed || !conflicts[1].Suppressed {
+		t.Errorf("RuleConflicts() = %+v, want two suppressed conflicts", conflicts)
+	}
+
+	indexRule, err := os.ReadFile(m.rulesetIndexRulePath)
+	if err != nil {
+		t.Fatalf("failed to read ruleset index rule: %v", err)
+	}
+	if strings.Contains(string(indexRule), "base_naming.mdc") {
+		t.Errorf("ruleset index should not list withheld rules:\n%s", indexRule)
+	}
+
+	// The index records which rules are withheld, not their content
+	index, err := os.ReadFile(m.indexPath)
+	if err != nil {
+		t.Fatalf("failed to read index: %v", err)
+	}
+	if strings.Contains(string(index), "Prefer short names.") {
+		t.Errorf("index should not hold withheld rule content:\n%s", index)
+	}
+
+	// Withheld rules are regenerated by reinstalling once the higher-priority package goes away
+	if m.HasRulesToRestore("test-reg", "base", "1.0.0") {
+		t.Error("base should have nothing to restore while team is installed")
+	}
+	if err := m.Uninstall("test-reg", "team"); err != nil {
+		t.Fatalf("Uninstall failed: %v", err)
+	}
+	if !m.HasRulesToRestore("test-reg", "base", "1.0.0") {
+		t.Fatal("base should have rules to restore once team is uninstalled")
+	}
+	if err := m.InstallRuleset(base, 100, nil, nil, nil); err != nil {
+		t.Fatalf("InstallRuleset(base) failed: %v", err)
+	}
+	if m.HasRulesToRestore("test-reg", "base", "1.0.0") {
+		t.Error("reinstalling base should restore its rules")
+	}
+	content, err := os.ReadFile(filepath.Join(baseDir, "base_naming.mdc"))
+	if err != nil {
+		t.Fatalf("withheld rule should be restored: %v", err)
+	}
+	if !strings.Contains(string(content), "Prefer short names.") {
+		t.Errorf("restored rule has unexpected content:\n%s", content)
+	}
+}
+
+func TestRuleConflictsDedupeSkipsWithheldWinners(t *testing.T) {
+	tmpDir := t.TempDir()
+	m := NewManagerWithOptions(tmpDir, compiler.Cursor, Options{Conflicts: ConflictsDedupe})
+	high := conflictTestPackage("high", `    style:
+      body: Keep functions small.
+`)
+	mid := conflictTestPackage("mid", `    naming:
+      body: Keep functions small.
+`)
+	low := conflictTestPackage("low", `    naming:
+      body: Prefer short names.
+`)
+	for i, pkg := range []*core.Package{high, mid, low} {
+		if err := m.InstallRuleset(pkg, 300-100*i, nil, nil, nil); err != nil {
+			t.Fatalf("InstallRuleset(%s) failed: %v", pkg.Metadata.Name, err)
+		}
+	}
+
+	// mid/naming is withheld as a copy of high/style, so it cannot withhold low/naming
+	if _, err := os.Stat(filepath.Join(tmpDir, "arm", "test-reg", "mid", "1.0.0", "mid_naming.mdc")); !os.IsNotExist(err) {
+		t.Errorf("mid_naming.mdc should not be emitted, stat err = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "arm", "test-reg", "low", "1.0.0", "low_naming.mdc")); err != nil {
+		t.Errorf("rule that only conflicts with a withheld rule should be installed: %v", err)
+	}
+
+	conflicts, err := m.RuleConflicts()
+	if err != nil {
+		t.Fatalf("RuleConflicts() error = %v", err)
+	}
+	if len(conflicts) != 1 || conflicts[0].Kind != ConflictDuplicateBody || conflicts[0].Loser.Package != "test-reg/mid@1.0.0" {
+		t.Errorf("RuleConflicts() = %+v, want one duplicate-body conflict lost by mid", conflicts)
+	}
+}
+
+func TestApplyConflictMode(t *testing.T) {
+	tmpDir := t.TempDir()
+	base := installConflictingRulesets(t, NewManager(tmpDir, compiler.Cursor))
+	baseNaming := filepath.Join(tmpDir, "arm", "test-reg", "base", "1.0.0", "base_naming.mdc")
+
+	if err := NewManagerWithOptions(tmpDir, compiler.Cursor, Options{Conflicts: ConflictsDedupe}).ApplyConflictMode(); err != nil {
+		t.Fatalf("ApplyConflictMode(dedupe) error = %v", err)
+	}
+	if _, err := os.Stat(baseNaming); !os.IsNotExist(err) {
+		t.Errorf("switching to dedupe should withhold installed losers, stat err = %v", err)
+	}
+
+	// Switching back to report leaves the rules for the package to regenerate
+	report := NewManager(tmpDir, compiler.Cursor)
+	if err := report.ApplyConflictMode(); err != nil {
+		t.Fatalf("ApplyConflictMode(report) error = %v", err)
+	}
+	if !report.HasRulesToRestore("test-reg", "base", "1.0.0") {
+		t.Fatal("switching back to report should leave base with rules to restore")
+	}
+	if err := report.InstallRuleset(base, 100, nil, nil, nil); err != nil {
+		t.Fatalf("InstallRuleset(base) failed: %v", err)
+	}
+	if _, err := os.Stat(baseNaming); err != nil {
+		t.Errorf("reinstalling should restore withheld rules: %v", err)
+	}
+}
+
+func TestConflictModeValidate(t *testing.T) {
+	for _, mode := range []ConflictMode{"", ConflictsReport, ConflictsDedupe} {
+		if err := mode.Validate(); err != nil {
+			t.Errorf("Validate(%q) error = %v", mode, err)
+		}
+	}
+	if err := ConflictMode("drop").Validate(); err == nil {
+		t.Error("expected error for unknown conflict mode")
+	}
+}
@@ -0,0 +1,267 @@
+package sink
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jomadu/ai-resource-manager/internal/arm/compiler"
+	"github.com/jomadu/ai-resource-manager/internal/arm/core"
+	"github.com/jomadu/ai-resource-manager/internal/arm/filetype"
+	"github.com/jomadu/ai-resource-manager/internal/arm/parser"
+)
+
+// hooksKey is the settings key tool hook configurations are stored under
+const hooksKey = "hooks"
+
+// HooksetIndexEntry records the hooks a package owns, either as entries merged into
+// the tool's settings file or as hook files in the sink directory
+type HooksetIndexEntry struct {
+	File    string           `json:"file,omitempty"`
+	Entries []HookIndexEntry `json:"entries,omitempty"`
+	Files   []string         `json:"files,omitempty"`
+}
+
+// HookIndexEntry records one hook entry merged into a settings file under its event
+type HookIndexEntry struct {
+	Event string                 `json:"event"`
+	Entry map[string]interface{} `json:"entry"`
+}
+
+// generatedHook is a hook rendered for the sink tool
+type generatedHook struct {
+	id    string
+	event string
+	entry map[string]interface{}
+	file  string
+}
+
+// InstallHookset installs a hookset package. Hooks are merged into the tool's
+// settings file next to the user's own hooks, or written as one file per hook for
+// tools without a shared settings file. Entries ARM did not install are never touched.
+func (m *Manager) InstallHookset(pkg *core.Package) error {
+	if m.hookGenerator == nil {
+		return fmt.Errorf("sink tool does not support hooks")
+	}
+
+	// Uninstall all existing versions of this package
+	if err := m.Uninstall(pkg.Metadata.RegistryName, pkg.Metadata.Name); err != nil {
+		return err
+	}
+
+	key := pkgKey(pkg.Metadata.RegistryName, pkg.Metadata.Name, pkg.Metadata.Version.Version)
+
+	var hooks []generatedHook
+	for _, file := range pkg.Files {
+		if !filetype.IsHooksetFile(file) {
+			continue
+		}
+		hookset, err := parser.ParseHookset(file)
+		if err != nil {
+			return err
+		}
+
+		hookIDs := make([]string, 0, len(hookset.Spec.Hooks))
+		for hookID := range hookset.Spec.Hooks {
+			hookIDs = append(hookIDs, hookID)
+		}
+		sort.Strings(hookIDs)
+
+		for _, hookID := range hookIDs {
+			event, entry, err := m.hookGenerator.GenerateHook(hookID, hookset.Spec.Hooks[hookID])
+			if err != nil {
+				return fmt.Errorf("invalid hook %s in %s: %w", hookID, file.Path, err)
+			}
+			hook := generatedHook{id: hookID, event: event, entry: entry}
+			if m.hookGenerator.HookFilename() == "" {
+				hook.file, err = m.hookGenerator.GenerateHookFilename(hookset.Metadata.ID, hookID)
+				if err != nil {
+					return err
+				}
+			}
+			hooks = append(hooks, hook)
+		}
+	}
+
+	index, err := m.loadIndex()
+	if err != nil {
+		return err
+	}
+
+	var entry HooksetIndexEntry
+	if m.hookGenerator.HookFilename() != "" {
+		entry, err = m.mergeHooks(index, key, hooks)
+	} else {
+		entry, err = m.writeHookFiles(index, key, hooks)
+	}
+	if err != nil {
+		return err
+	}
+	index.Hooksets[key] = entry
+
+	return m.saveIndex(index)
+}
+
+// mergeHooks appends hook entries to the events of the tool's settings file
+func (m *Manager) mergeHooks(index *Index, key string, hooks []generatedHook) (HooksetIndexEntry, error) {
+	filename := m.hookGenerator.HookFilename()
+	entry := HooksetIndexEntry{File: filename}
+	if len(hooks) == 0 {
+		return entry, nil
+	}
+
+	config, err := m.loadJSONConfig(filename)
+	if err != nil {
+		return entry, err
+	}
+	events := jsonObject(config, hooksKey)
+
+	for _, hook := range hooks {
+		entries, _ := events[hook.event].([]interface{})
+		if indexOfHookEntry(entries, hook.entry) >= 0 {
+			if owner := hookEntryOwner(index, filename, hook.event, hook.entry); owner != "" {
+				return entry, fmt.Errorf("hook %s is already installed by %s", hook.id, owner)
+			}
+			return entry, fmt.Errorf("hook %s already exists in %s and is not managed by ARM", hook.id, filename)
+		}
+		events[hook.event] = append(entries, hook.entry)
+		entry.Entries = append(entry.Entries, HookIndexEntry{Event: hook.event, Entry: hook.entry})
+	}
+	config[hooksKey] = events
+
+	if err := m.saveJSONConfig(filename, config); err != nil {
+		return entry, err
+	}
+	return entry, nil
+}
+
+// writeHookFiles writes one hook file per hook into the sink directory
+func (m *Manager) writeHookFiles(index *Index, key string, hooks []generatedHook) (HooksetIndexEntry, error) {
+	var entry HooksetIndexEntry
+
+	owners := make(map[string]string)
+	for ownerKey, owned := range index.Hooksets {
+		for _, filename := range owned.Files {
+			owners[filename] = ownerKey
+		}
+	}
+
+	contents := make(map[string][]byte)
+	for _, hook := range hooks {
+		if _, exists := contents[hook.file]; exists {
+			return entry, fmt.Errorf("hook file %s is generated more than once in %s", hook.file, key)
+		}
+		if owner, ok := owners[hook.file]; ok {
+			return entry, fmt.Errorf("hook file %s is already installed by %s", hook.file, owner)
+		}
+		if m.taken(hook.file) {
+			return entry, fmt.Errorf("hook file %s already exists in %s and is not managed by ARM", hook.file, m.directory)
+		}
+		content, err := compiler.MarshalJSONConfig(hook.entry)
+		if err != nil {
+			return entry, err
+		}
+		contents[hook.file] = content
+		entry.Files = append(entry.Files, hook.file)
+	}
+	sort.Strings(entry.Files)
+
+	for _, filename := range entry.Files {
+		if err := os.WriteFile(filepath.Join(m.directory, filename), contents[filename], 0o644); err != nil {
+			return entry, err
+		}
+	}
+	return entry, nil
+}
+
+// removeHooks removes the hook entries and files owned by packages whose key starts
+// with prefix and drops their index entries
+func (m *Manager) removeHooks(index *Index, prefix string) error {
+	for key, entry := range index.Hooksets {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+
+		for _, filename := range entry.Files {
+			_ = os.Remove(filepath.Join(m.directory, filename)) // Ignore errors
+		}
+
+		if len(entry.Entries) > 0 {
+			config, err := m.loadJSONConfig(entry.File)
+			if err != nil {
+				return err
+			}
+
+			events := jsonObject(config, hooksKey)
+			for _, owned := range entry.Entries {
+				entries, _ := events[owned.Event].([]interface{})
+				if i := indexOfHookEntry(entries, owned.Entry); i >= 0 {
+					entries = append(entries[:i], entries[i+1:]...)
+				}
+				if len(entries) == 0 {
+					delete(events, owned.Event)
+				} else {
+					events[owned.Event] = entries
+				}
+			}
+
+			if len(events) == 0 {
+				delete(config, hooksKey)
+			} else {
+				config[hooksKey] = events
+			}
+
+			if len(config) == 0 {
+				_ = os.Remove(filepath.Join(m.directory, entry.File))
+			} else if err := m.saveJSONConfig(entry.File, config); err != nil {
+				return err
+			}
+		}
+
+		delete(index.Hooksets, key)
+	}
+	return nil
+}
+
+// hookEntryOwner returns the package that installed an identical hook entry, if any
+func hookEntryOwner(index *Index, filename, event string, hookEntry map[string]interface{}) string {
+	for ownerKey, owned := range index.Hooksets {
+		if owned.File != filename {
+			continue
+		}
+		for _, e := range owned.Entries {
+			if e.Event == event && sameJSON(e.Entry, hookEntry) {
+				return ownerKey
+			}
+		}
+	}
+	return ""
+}
+
+// indexOfHookEntry returns the position of the first entry equal to hookEntry, or -1
+func indexOfHookEntry(entries []interface{}, hookEntry map[string]interface{}) int {
+	for i, e := range entries {
+		if sameJSON(e, hookEntry) {
+			return i
+		}
+	}
+	return -1
+}
+
+// sameJSON reports whether two values encode to the same JSON document
+func sameJSON(a, b interface{}) bool {
+	aData, aErr := json.Marshal(a)
+	bData, bErr := json.Marshal(b)
+	return aErr == nil && bErr == nil && string(aData) == string(bData)
+}
+
+// jsonObject returns the object stored under key in a JSON configuration, creating it when missing
+func jsonObject(config map[string]interface{}, key string) map[string]interface{} {
+	if object, ok := config[key].(map[string]interface{}); ok {
+		return object
+	}
+	return make(map[string]interface{})
+}
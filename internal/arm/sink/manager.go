@@ -8,12 +8,14 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/jomadu/ai-resource-manager/internal/arm/compiler"
 	"github.com/jomadu/ai-resource-manager/internal/arm/core"
 	"github.com/jomadu/ai-resource-manager/internal/arm/filetype"
 	"github.com/jomadu/ai-resource-manager/internal/arm/parser"
+	"github.com/jomadu/ai-resource-manager/internal/arm/resource"
 )
 
 type Layout string
@@ -34,25 +36,51 @@ type Manager struct {
 	armDir                  string
 	indexPath               string
 	rulesetIndexRulePath    string
+	enforcement             compiler.EnforcementPolicy
+	conflicts               ConflictMode
+	overlayDir              string
+	unmanaged               func(path string) bool
 	ruleGenerator           compiler.RuleGenerator
 	promptGenerator         compiler.PromptGenerator
 	ruleFilenameGenerator   compiler.RuleFilenameGenerator
 	promptFilenameGenerator compiler.PromptFilenameGenerator
+	mcpGenerator            compiler.McpGenerator
+	agentGenerator          compiler.AgentGenerator
+	hookGenerator           compiler.HookGenerator
 }
 
 type Index struct {
 	Version    int                            `json:"version"`
 	Rulesets   map[string]RulesetIndexEntry   `json:"rulesets,omitempty"`
 	Promptsets map[string]PromptsetIndexEntry `json:"promptsets,omitempty"`
+	Mcpsets    map[string]McpsetIndexEntry    `json:"mcpsets,omitempty"`
+	Agentsets  map[string]AgentsetIndexEntry  `json:"agentsets,omitempty"`
+	Skillsets  map[string]SkillsetIndexEntry  `json:"skillsets,omitempty"`
+	Hooksets   map[string]HooksetIndexEntry   `json:"hooksets,omitempty"`
+	// Hashes holds the content hash of each installed file as ARM wrote it,
+	// keyed by path, so local edits can be detected
+	Hashes map[string]string `json:"hashes,omitempty"`
 }
 
 type RulesetIndexEntry struct {
 	Priority int      `json:"priority"`
 	Files    []string `json:"files"`
+	// Vars are the variable values interpolated into the installed rules
+	Vars map[string]string `json:"vars,omitempty"`
+	// BasesHash identifies the rulesets of other packages the installed rules inherit from
+	BasesHash string `json:"basesHash,omitempty"`
+	// Rules describe the installed rules for conflict detection
+	Rules []RuleIndexEntry `json:"rules,omitempty"`
+	// Sources maps each installed file to the package file it was generated from
+	Sources map[string]string `json:"sources,omitempty"`
 }
 
 type PromptsetIndexEntry struct {
 	Files []string `json:"files"`
+	// Vars are the variable values interpolated into the installed prompts
+	Vars map[string]string `json:"vars,omitempty"`
+	// Sources maps each installed file to the package file it was generated from
+	Sources map[string]string `json:"sources,omitempty"`
 }
 
 type InstalledRuleset struct {
@@ -66,15 +94,39 @@ type InstalledPromptset struct {
 	Files    []string
 }
 
+// Options holds optional sink manager settings
+type Options struct {
+	// Tools holds user-defined tool definitions the sink tool may refer to
+	Tools map[compiler.Tool]*compiler.ToolDefinition
+	// Enforcement maps rule enforcement levels to tool activations
+	Enforcement compiler.EnforcementPolicy
+	// Conflicts controls what happens to rules that conflict with higher-priority rulesets
+	Conflicts ConflictMode
+	// OverlayDir is the directory relative overlay file paths are resolved against
+	OverlayDir string
+	// Unmanaged reports files, relative to the sink directory, that exist
+	// elsewhere and must not be overwritten, for sinks staged in a sandbox
+	// without the user's own files
+	Unmanaged func(path string) bool
+}
+
 // NewManager creates a new sink manager
 func NewManager(directory string, tool compiler.Tool) *Manager {
+	return NewManagerWithOptions(directory, tool, Options{})
+}
+
+// NewManagerWithOptions creates a new sink manager with optional settings
+func NewManagerWithOptions(directory string, tool compiler.Tool, opts Options) *Manager {
 	// Create directory if it doesn't exist
 	_ = os.MkdirAll(directory, 0o755)
 
 	// Determine layout: copilot is flat, others are hierarchical
+	// unless a user-defined tool asks otherwise
 	layout := LayoutHierarchical
 	if tool == compiler.Copilot {
 		layout = LayoutFlat
+	} else if def, ok := opts.Tools[tool]; ok && def.Layout == string(LayoutFlat) {
+		layout = LayoutFlat
 	}
 
 	// Set up paths based on layout
@@ -88,15 +140,21 @@ func NewManager(directory string, tool compiler.Tool) *Manager {
 	}
 
 	// Initialize generators
-	ruleGenFactory := compiler.NewRuleGeneratorFactory()
-	promptGenFactory := compiler.NewPromptGeneratorFactory()
-	ruleFilenameGenFactory := compiler.NewRuleFilenameGeneratorFactory()
-	promptFilenameGenFactory := compiler.NewPromptFilenameGeneratorFactory()
+	ruleGenFactory := &compiler.DefaultRuleGeneratorFactory{Tools: opts.Tools, Enforcement: opts.Enforcement}
+	promptGenFactory := compiler.NewPromptGeneratorFactoryWithTools(opts.Tools)
+	ruleFilenameGenFactory := compiler.NewRuleFilenameGeneratorFactoryWithTools(opts.Tools)
+	promptFilenameGenFactory := compiler.NewPromptFilenameGeneratorFactoryWithTools(opts.Tools)
+	mcpGenFactory := compiler.NewMcpGeneratorFactoryWithTools(opts.Tools)
+	agentGenFactory := compiler.NewAgentGeneratorFactoryWithTools(opts.Tools)
+	hookGenFactory := compiler.NewHookGeneratorFactory()
 
 	ruleGen, _ := ruleGenFactory.NewRuleGenerator(tool)
 	promptGen, _ := promptGenFactory.NewPromptGenerator(tool)
 	ruleFilenameGen, _ := ruleFilenameGenFactory.NewRuleFilenameGenerator(tool)
 	promptFilenameGen, _ := promptFilenameGenFactory.NewPromptFilenameGenerator(tool)
+	mcpGen, _ := mcpGenFactory.NewMcpGenerator(tool)
+	agentGen, _ := agentGenFactory.NewAgentGenerator(tool)
+	hookGen, _ := hookGenFactory.NewHookGenerator(tool)
 
 	// Generate ruleset index rule filename
 	rulesetIndexFilename, _ := ruleFilenameGen.GenerateRuleFilename("arm", "index")
@@ -108,15 +166,25 @@ func NewManager(directory string, tool compiler.Tool) *Manager {
 		armDir:                  armDir,
 		indexPath:               indexPath,
 		rulesetIndexRulePath:    rulesetIndexRulePath,
+		enforcement:             opts.Enforcement,
+		conflicts:               opts.Conflicts,
+		overlayDir:              opts.OverlayDir,
+		unmanaged:               opts.Unmanaged,
 		ruleGenerator:           ruleGen,
 		promptGenerator:         promptGen,
 		ruleFilenameGenerator:   ruleFilenameGen,
 		promptFilenameGenerator: promptFilenameGen,
+		mcpGenerator:            mcpGen,
+		agentGenerator:          agentGen,
+		hookGenerator:           hookGen,
 	}
 }
 
-// InstallRuleset installs a ruleset package with priority
-func (m *Manager) InstallRuleset(pkg *core.Package, priority int) error {
+// InstallRuleset installs a ruleset package with priority. Overlays keyed by
+// rule ID patch or disable upstream rules before they are generated, and vars
+// are interpolated into the rule bodies. Rulesets extending rulesets of other
+// packages find them in bases, keyed by parser.RulesetRef.
+func (m *Manager) InstallRuleset(pkg *core.Package, priority int, overlays map[string]compiler.RuleOverlay, vars map[string]string, bases map[string]*resource.RulesetResource) error {
 	// Uninstall all existing versions of this package
 	if err := m.Uninstall(pkg.Metadata.RegistryName, pkg.Metadata.Name); err != nil {
 		return err
@@ -125,51 +193,123 @@ func (m *Manager) InstallRuleset(pkg *core.Package, priority int) error {
 	var installedFiles []string
 	namespace := fmt.Sprintf("%s/%s@%s", pkg.Metadata.RegistryName, pkg.Metadata.Name, pkg.Metadata.Version.Version)
 
-	for _, file := range pkg.Files {
-		if filetype.IsResourceFile(file) {
-			if filetype.IsRulesetFile(file) {
-				rulesetResource, err := parser.ParseRuleset(file)
-				if err != nil {
-					return err
-				}
+	// Markdown rule and prompt sources are grouped into resource files first
+	files, err := parser.AssembleMarkdownResources(pkg.Files)
+	if err != nil {
+		return err
+	}
 
-				for ruleID := range rulesetResource.Spec.Rules {
-					content, err := m.ruleGenerator.GenerateRule(namespace, rulesetResource, ruleID)
-					if err != nil {
-						return err
-					}
+	// Rulesets extended by another ruleset of the package are installed through it
+	depKey := pkg.Metadata.RegistryName + "/" + pkg.Metadata.Name
+	available := parser.ParsePackageRulesets(depKey, files)
+	extended := parser.ExtendedRulesets(depKey, available)
+	for ref, base := range bases {
+		available[ref] = base
+	}
 
-					filename, err := m.ruleFilenameGenerator.GenerateRuleFilename(rulesetResource.Metadata.ID, ruleID)
-					if err != nil {
-						return err
-					}
+	definedRules := make(map[string]bool)
+	usedVars := make(map[string]string)
+	sources := make(map[string]string)
+	var ruleEntries []RuleIndexEntry
+	for _, file := range files {
+		var rulesetResource *resource.RulesetResource
+		var err error
+		switch {
+		case filetype.IsRulesetFile(file):
+			rulesetResource, err = parser.ParseRuleset(file)
+			if err != nil {
+				break
+			}
+			if extended[parser.RulesetRef(depKey, rulesetResource.Metadata.ID)] {
+				continue
+			}
+			rulesetResource, err = parser.ResolveExtends(rulesetResource, depKey, available)
+		case filetype.IsResourceFile(file):
+			continue
+		case filetype.IsLegacyRuleFile(file):
+			// Raw .cursorrules, .mdc and markdown files are compiled like rulesets
+			rulesetResource, err = compiler.LegacyRuleset(file)
+		default:
+			fullPath := m.getFilePath(pkg.Metadata.RegistryName, pkg.Metadata.Name, pkg.Metadata.Version.Version, file.Path)
 
-					combinedPath := filepath.Join(filepath.Dir(file.Path), filename)
-					fullPath := m.getFilePath(pkg.Metadata.RegistryName, pkg.Metadata.Name, pkg.Metadata.Version.Version, combinedPath)
+			if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(fullPath, file.Content, 0o644); err != nil {
+				return err
+			}
 
-					if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
-						return err
-					}
-					if err := os.WriteFile(fullPath, []byte(content), 0o644); err != nil {
-						return err
-					}
+			relPath, _ := filepath.Rel(m.directory, fullPath)
+			installedFiles = append(installedFiles, relPath)
+			sources[relPath] = file.Path
+			continue
+		}
+		if err != nil {
+			return err
+		}
 
-					relPath, _ := filepath.Rel(m.directory, fullPath)
-					installedFiles = append(installedFiles, relPath)
-				}
+		for ruleID := range rulesetResource.Spec.Rules {
+			definedRules[ruleID] = true
+		}
+		rulesetResource, err = compiler.ApplyRuleOverlays(rulesetResource, overlays, m.readOverlayFile)
+		if err != nil {
+			return fmt.Errorf("failed to apply overlays to %s: %w", file.Path, err)
+		}
+		rulesetResource, used, err := compiler.InterpolateRuleset(rulesetResource, vars)
+		if err != nil {
+			return fmt.Errorf("failed to interpolate vars into %s: %w", file.Path, err)
+		}
+		for name, value := range used {
+			usedVars[name] = value
+		}
+
+		rulesetResource = compiler.FilterRuleset(m.enforcement, rulesetResource)
+		filtered := rulesetResource
+		rulesetResource, origins, err := compiler.SplitRuleset(m.ruleGenerator, rulesetResource)
+		if err != nil {
+			return fmt.Errorf("failed to split rules in %s: %w", file.Path, err)
+		}
+
+		// Split rules are recorded under the rule they were split from
+		ruleFiles := make(map[string][]string)
+		for ruleID := range rulesetResource.Spec.Rules {
+			content, err := m.ruleGenerator.GenerateRule(namespace, rulesetResource, ruleID)
+			if err != nil {
+				return err
 			}
-		} else {
-			fullPath := m.getFilePath(pkg.Metadata.RegistryName, pkg.Metadata.Name, pkg.Metadata.Version.Version, file.Path)
+
+			filename, err := m.ruleFilenameGenerator.GenerateRuleFilename(rulesetResource.Metadata.ID, ruleID)
+			if err != nil {
+				return err
+			}
+
+			combinedPath := filepath.Join(filepath.Dir(file.Path), filename)
+			fullPath := m.getFilePath(pkg.Metadata.RegistryName, pkg.Metadata.Name, pkg.Metadata.Version.Version, combinedPath)
 
 			if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
 				return err
 			}
-			if err := os.WriteFile(fullPath, file.Content, 0o644); err != nil {
+			if err := os.WriteFile(fullPath, []byte(content), 0o644); err != nil {
 				return err
 			}
 
 			relPath, _ := filepath.Rel(m.directory, fullPath)
 			installedFiles = append(installedFiles, relPath)
+			sources[relPath] = file.Path
+
+			originID := origins[ruleID]
+			ruleFiles[originID] = append(ruleFiles[originID], relPath)
+		}
+		for ruleID, files := range ruleFiles {
+			rule := filtered.Spec.Rules[ruleID]
+			ruleEntries = append(ruleEntries, newRuleIndexEntry(filtered.Metadata.ID, ruleID, &rule, files))
+		}
+	}
+
+	// An overlay for a rule the package no longer defines is most likely stale
+	for ruleID := range overlays {
+		if !definedRules[ruleID] {
+			return fmt.Errorf("overlay references rule %s, which %s does not define", ruleID, pkg.Metadata.Name)
 		}
 	}
 
@@ -178,10 +318,23 @@ func (m *Manager) InstallRuleset(pkg *core.Package, priority int) error {
 		return err
 	}
 
+	sort.Slice(ruleEntries, func(i, j int) bool {
+		if ruleEntries[i].Ruleset != ruleEntries[j].Ruleset {
+			return ruleEntries[i].Ruleset < ruleEntries[j].Ruleset
+		}
+		return ruleEntries[i].ID < ruleEntries[j].ID
+	})
 	key := pkgKey(pkg.Metadata.RegistryName, pkg.Metadata.Name, pkg.Metadata.Version.Version)
 	index.Rulesets[key] = RulesetIndexEntry{
-		Priority: priority,
-		Files:    installedFiles,
+		Priority:  priority,
+		Files:     installedFiles,
+		Vars:      nilIfEmpty(usedVars),
+		BasesHash: basesHash(bases),
+		Rules:     ruleEntries,
+		Sources:   nilIfEmpty(sources),
+	}
+	if err := m.applyConflictMode(index); err != nil {
+		return err
 	}
 
 	if err := m.saveIndex(index); err != nil {
@@ -191,8 +344,17 @@ func (m *Manager) InstallRuleset(pkg *core.Package, priority int) error {
 	return m.generateRulesetIndexRuleFile()
 }
 
-// InstallPromptset installs a promptset package
-func (m *Manager) InstallPromptset(pkg *core.Package) error {
+// readOverlayFile reads a file named by an overlay, resolving relative paths
+// against the overlay directory rather than the working directory
+func (m *Manager) readOverlayFile(path string) ([]byte, error) {
+	if !filepath.IsAbs(path) && m.overlayDir != "" {
+		path = filepath.Join(m.overlayDir, path)
+	}
+	return os.ReadFile(path)
+}
+
+// InstallPromptset installs a promptset package, interpolating vars into the prompt bodies
+func (m *Manager) InstallPromptset(pkg *core.Package, vars map[string]string) error {
 	// Uninstall all existing versions of this package
 	if err := m.Uninstall(pkg.Metadata.RegistryName, pkg.Metadata.Name); err != nil {
 		return err
@@ -201,13 +363,28 @@ func (m *Manager) InstallPromptset(pkg *core.Package) error {
 	var installedFiles []string
 	namespace := fmt.Sprintf("%s/%s@%s", pkg.Metadata.RegistryName, pkg.Metadata.Name, pkg.Metadata.Version.Version)
 
-	for _, file := range pkg.Files {
+	// Markdown rule and prompt sources are grouped into resource files first
+	files, err := parser.AssembleMarkdownResources(pkg.Files)
+	if err != nil {
+		return err
+	}
+
+	usedVars := make(map[string]string)
+	sources := make(map[string]string)
+	for _, file := range files {
 		if filetype.IsResourceFile(file) {
 			if filetype.IsPromptsetFile(file) {
 				promptsetResource, err := parser.ParsePromptset(file)
 				if err != nil {
 					return err
 				}
+				promptsetResource, used, err := compiler.InterpolatePromptset(promptsetResource, vars)
+				if err != nil {
+					return fmt.Errorf("failed to interpolate vars into %s: %w", file.Path, err)
+				}
+				for name, value := range used {
+					usedVars[name] = value
+				}
 
 				for promptID := range promptsetResource.Spec.Prompts {
 					content, err := m.promptGenerator.GeneratePrompt(namespace, promptsetResource, promptID)
@@ -232,6 +409,7 @@ func (m *Manager) InstallPromptset(pkg *core.Package) error {
 
 					relPath, _ := filepath.Rel(m.directory, fullPath)
 					installedFiles = append(installedFiles, relPath)
+					sources[relPath] = file.Path
 				}
 			}
 		} else {
@@ -246,6 +424,7 @@ func (m *Manager) InstallPromptset(pkg *core.Package) error {
 
 			relPath, _ := filepath.Rel(m.directory, fullPath)
 			installedFiles = append(installedFiles, relPath)
+			sources[relPath] = file.Path
 		}
 	}
 
@@ -256,7 +435,9 @@ func (m *Manager) InstallPromptset(pkg *core.Package) error {
 
 	key := pkgKey(pkg.Metadata.RegistryName, pkg.Metadata.Name, pkg.Metadata.Version.Version)
 	index.Promptsets[key] = PromptsetIndexEntry{
-		Files: installedFiles,
+		Files:   installedFiles,
+		Vars:    nilIfEmpty(usedVars),
+		Sources: nilIfEmpty(sources),
 	}
 
 	return m.saveIndex(index)
@@ -293,14 +474,34 @@ func (m *Manager) Uninstall(registryName, packageName string) error {
 		}
 	}
 
+	// Remove servers from the MCP configuration for mcpsets
+	if err := m.removeMcpServers(index, prefix); err != nil {
+		return err
+	}
+
+	// Remove agent files for agentsets
+	m.removeAgents(index, prefix)
+
+	// Remove skill directories for skillsets
+	m.removeSkills(index, prefix)
+
+	// Remove hook entries and files for hooksets
+	if err := m.removeHooks(index, prefix); err != nil {
+		return err
+	}
+
 	// Clean up index files if all packages uninstalled
-	if len(index.Rulesets) == 0 && len(index.Promptsets) == 0 {
+	if len(index.Rulesets) == 0 && len(index.Promptsets) == 0 && len(index.Mcpsets) == 0 && len(index.Agentsets) == 0 && len(index.Skillsets) == 0 && len(index.Hooksets) == 0 {
 		_ = os.Remove(m.indexPath) // Ignore error if file doesn't exist
 		// Also remove priority index file
 		if _, err := os.Stat(m.rulesetIndexRulePath); err == nil {
 			_ = os.Remove(m.rulesetIndexRulePath)
 		}
 	} else {
+		// Rules withheld because of the removed package may be emitted again
+		if err := m.applyConflictMode(index); err != nil {
+			return err
+		}
 		// Save updated index
 		if err := m.saveIndex(index); err != nil {
 			return err
@@ -371,6 +572,62 @@ func (m *Manager) CleanupEmptyDirectories() error {
 	return nil
 }
 
+// VarsChanged reports whether an installed ruleset or promptset was rendered with
+// variable values that differ from vars, so it needs to be reinstalled
+func (m *Manager) VarsChanged(registryName, packageName, version string, vars map[string]string) bool {
+	index, err := m.loadIndex()
+	if err != nil {
+		return false
+	}
+
+	key := pkgKey(registryName, packageName, version)
+	used := index.Rulesets[key].Vars
+	if entry, ok := index.Promptsets[key]; ok {
+		used = entry.Vars
+	}
+	for name, value := range used {
+		if current, ok := vars[name]; !ok || current != value {
+			return true
+		}
+	}
+	return false
+}
+
+// ExtendsOtherPackages reports whether an installed ruleset inherits rules from
+// rulesets of other packages
+func (m *Manager) ExtendsOtherPackages(registryName, packageName, version string) bool {
+	index, err := m.loadIndex()
+	if err != nil {
+		return false
+	}
+	return index.Rulesets[pkgKey(registryName, packageName, version)].BasesHash != ""
+}
+
+// BasesChanged reports whether an installed ruleset inherited its rules from
+// rulesets of other packages that differ from bases, so it needs to be reinstalled
+func (m *Manager) BasesChanged(registryName, packageName, version string, bases map[string]*resource.RulesetResource) bool {
+	index, err := m.loadIndex()
+	if err != nil {
+		return false
+	}
+	entry, ok := index.Rulesets[pkgKey(registryName, packageName, version)]
+	return ok && entry.BasesHash != basesHash(bases)
+}
+
+// basesHash returns a hash of the extended rulesets of other packages, or an
+// empty string when there are none
+func basesHash(bases map[string]*resource.RulesetResource) string {
+	if len(bases) == 0 {
+		return ""
+	}
+	// Map keys are sorted when encoded, so equal bases hash equally
+	data, err := json.Marshal(bases)
+	if err != nil {
+		return ""
+	}
+	return hashContent(data)
+}
+
 // IsInstalled checks if a package is installed
 func (m *Manager) IsInstalled(metadata *core.PackageMetadata) bool {
 	index, err := m.loadIndex()
@@ -381,7 +638,11 @@ func (m *Manager) IsInstalled(metadata *core.PackageMetadata) bool {
 	key := pkgKey(metadata.RegistryName, metadata.Name, metadata.Version.Version)
 	_, rulesetExists := index.Rulesets[key]
 	_, promptsetExists := index.Promptsets[key]
-	return rulesetExists || promptsetExists
+	_, mcpsetExists := index.Mcpsets[key]
+	_, agentsetExists := index.Agentsets[key]
+	_, skillsetExists := index.Skillsets[key]
+	_, hooksetExists := index.Hooksets[key]
+	return rulesetExists || promptsetExists || mcpsetExists || agentsetExists || skillsetExists || hooksetExists
 }
 
 // ListRulesets returns all installed rulesets
@@ -468,38 +729,115 @@ func (m *Manager) Clean() error {
 			trackedFiles[filePath] = true
 		}
 	}
+	for _, entry := range index.Agentsets {
+		for _, filePath := range entry.Files {
+			trackedFiles[filePath] = true
+		}
+	}
+	for _, entry := range index.Skillsets {
+		for _, filePath := range entry.Files {
+			trackedFiles[filePath] = true
+		}
+	}
+	for _, entry := range index.Hooksets {
+		for _, filePath := range entry.Files {
+			trackedFiles[filePath] = true
+		}
+	}
 
-	// Walk arm directory and remove untracked files
-	if _, err := os.Stat(m.armDir); !os.IsNotExist(err) {
-		return filepath.Walk(m.armDir, func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return nil // Continue on errors
-			}
-			if info.IsDir() {
-				return nil // Skip directories
-			}
+	// Remove ARM's own files that are no longer tracked
+	files, err := m.armFiles()
+	if err != nil {
+		return err
+	}
+	for _, relPath := range files {
+		path := filepath.Join(m.directory, relPath)
+		// Skip ARM system files
+		if path == m.indexPath || path == m.rulesetIndexRulePath || trackedFiles[relPath] {
+			continue
+		}
+		_ = os.Remove(path)
+	}
 
-			// Skip ARM system files
-			if path == m.indexPath || path == m.rulesetIndexRulePath {
-				return nil
-			}
+	return nil
+}
 
-			// Get relative path from directory
-			relPath, err := filepath.Rel(m.directory, path)
-			if err != nil {
-				return nil
+// armFiles returns the files ARM generates for itself, relative to the sink
+// directory: everything under arm/, or the top-level arm_* files and the index
+// in a flat layout, where the directory is shared with the user's own files
+func (m *Manager) armFiles() ([]string, error) {
+	var files []string
+	if m.layout == LayoutFlat {
+		entries, err := os.ReadDir(m.directory)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, nil
 			}
-
-			// Remove if not tracked
-			if !trackedFiles[relPath] {
-				_ = os.Remove(path)
+			return nil, err
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if !entry.IsDir() && (strings.HasPrefix(name, "arm_") || name == filepath.Base(m.indexPath)) {
+				files = append(files, name)
 			}
+		}
+		return files, nil
+	}
 
-			return nil
-		})
+	if _, err := os.Stat(m.armDir); os.IsNotExist(err) {
+		return nil, nil
+	}
+	err := filepath.Walk(m.armDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil // Continue on errors and skip directories
+		}
+		if relPath, err := filepath.Rel(m.directory, path); err == nil {
+			files = append(files, relPath)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// OwnedFiles returns the files in the sink ARM may write to, relative to the
+// sink directory: its own files, the files of installed packages and the
+// shared configuration files the tool merges MCP servers and hooks into. The
+// files need not exist.
+func (m *Manager) OwnedFiles() ([]string, error) {
+	index, err := m.loadIndex()
+	if err != nil {
+		return nil, err
+	}
+	files, err := m.armFiles()
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	owned := make(map[string]bool)
+	for _, path := range files {
+		owned[path] = true
+	}
+	for _, file := range installedFiles(index) {
+		owned[filepath.Clean(file.Path)] = true
+	}
+	for _, path := range []string{m.indexPath, m.rulesetIndexRulePath} {
+		if relPath, err := filepath.Rel(m.directory, path); err == nil {
+			owned[relPath] = true
+		}
+	}
+	if m.mcpGenerator != nil {
+		owned[m.mcpGenerator.McpFilename()] = true
+	}
+	if m.hookGenerator != nil && m.hookGenerator.HookFilename() != "" {
+		owned[m.hookGenerator.HookFilename()] = true
+	}
+
+	paths := make([]string, 0, len(owned))
+	for path := range owned {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths, nil
 }
 
 // Index management
@@ -510,6 +848,10 @@ func (m *Manager) loadIndex() (*Index, error) {
 			Version:    1,
 			Rulesets:   make(map[string]RulesetIndexEntry),
 			Promptsets: make(map[string]PromptsetIndexEntry),
+			Mcpsets:    make(map[string]McpsetIndexEntry),
+			Agentsets:  make(map[string]AgentsetIndexEntry),
+			Skillsets:  make(map[string]SkillsetIndexEntry),
+			Hooksets:   make(map[string]HooksetIndexEntry),
 		}, nil
 	}
 
@@ -530,6 +872,18 @@ func (m *Manager) loadIndex() (*Index, error) {
 	if index.Promptsets == nil {
 		index.Promptsets = make(map[string]PromptsetIndexEntry)
 	}
+	if index.Mcpsets == nil {
+		index.Mcpsets = make(map[string]McpsetIndexEntry)
+	}
+	if index.Agentsets == nil {
+		index.Agentsets = make(map[string]AgentsetIndexEntry)
+	}
+	if index.Skillsets == nil {
+		index.Skillsets = make(map[string]SkillsetIndexEntry)
+	}
+	if index.Hooksets == nil {
+		index.Hooksets = make(map[string]HooksetIndexEntry)
+	}
 
 	return &index, nil
 }
@@ -540,6 +894,8 @@ func (m *Manager) saveIndex(index *Index) error {
 		return err
 	}
 
+	m.updateHashes(index)
+
 	data, err := json.MarshalIndent(index, "", "  ")
 	if err != nil {
 		return err
@@ -579,21 +935,28 @@ func (m *Manager) generateRulesetIndexRuleFile() error {
 
 	var entries []entry
 	for key, info := range index.Rulesets {
+		// Rules withheld in dedupe mode and documentation copied along are not listed
+		suppressed := suppressedFiles(info)
+		var files []string
+		for _, file := range info.Files {
+			if !suppressed[file] && !filetype.IsDocumentationFile(&core.File{Path: file}) {
+				files = append(files, file)
+			}
+		}
 		entries = append(entries, entry{
 			key:      key,
 			priority: info.Priority,
-			files:    info.Files,
+			files:    files,
 		})
 	}
 
-	// Sort by priority (high to low)
-	for i := 0; i < len(entries); i++ {
-		for j := i + 1; j < len(entries); j++ {
-			if entries[j].priority > entries[i].priority {
-				entries[i], entries[j] = entries[j], entries[i]
-			}
+	// Sort by priority (high to low), then by package for a stable order
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].priority != entries[j].priority {
+			return entries[i].priority > entries[j].priority
 		}
-	}
+		return entries[i].key < entries[j].key
+	})
 
 	for _, e := range entries {
 		body += fmt.Sprintf("### %s\n", e.key)
@@ -611,6 +974,15 @@ func (m *Manager) generateRulesetIndexRuleFile() error {
 	return os.WriteFile(m.rulesetIndexRulePath, []byte(body), 0o644)
 }
 
+// taken reports whether path, relative to the sink directory, is already
+// occupied, including by files a sandbox did not stage
+func (m *Manager) taken(path string) bool {
+	if _, err := os.Stat(filepath.Join(m.directory, path)); err == nil {
+		return true
+	}
+	return m.unmanaged != nil && m.unmanaged(path)
+}
+
 // Helper functions for path computation
 
 // getFilePath returns the appropriate path for a file based on layout
@@ -659,3 +1031,15 @@ func (m *Manager) hashPath(filePath string) string {
 	hash := sha256.Sum256([]byte(filePath))
 	return hex.EncodeToString(hash[:])[:4]
 }
+
+// Layout returns the sink's file layout
+func (m *Manager) Layout() Layout {
+	return m.layout
+}
+
+func nilIfEmpty(m map[string]string) map[string]string {
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/jomadu/ai-resource-manager/internal/arm/compiler"
 	"github.com/jomadu/ai-resource-manager/internal/arm/core"
+	"github.com/jomadu/ai-resource-manager/internal/arm/resource"
 )
 
 //nolint:unparam // Test helper function
@@ -526,6 +527,32 @@ func TestClean(t *testing.T) {
 		t.Errorf("tracked file should remain")
 	}
 }
+func TestCleanFlatKeepsUserFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	m := NewManager(tmpDir, compiler.Copilot)
+
+	orphanFile := filepath.Join(tmpDir, "arm_0000_0000_orphan.instructions.md")
+	userFile := filepath.Join(tmpDir, "copilot-instructions.md")
+	workflow := filepath.Join(tmpDir, "workflows", "ci.yml")
+	_ = os.MkdirAll(filepath.Dir(workflow), 0o755)
+	for _, path := range []string{orphanFile, userFile, workflow} {
+		_ = os.WriteFile(path, []byte("content"), 0o644)
+	}
+
+	if err := m.Clean(); err != nil {
+		t.Fatalf("Clean failed: %v", err)
+	}
+
+	if _, err := os.Stat(orphanFile); !os.IsNotExist(err) {
+		t.Errorf("orphaned file should be removed")
+	}
+	for _, path := range []string{userFile, workflow} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("user file %s should remain: %v", path, err)
+		}
+	}
+}
+
 func TestFilenameTruncation(t *testing.T) {
 	tmpDir := t.TempDir()
 	m := NewManager(tmpDir, compiler.Copilot) // Use Copilot for flat layout
@@ -706,7 +733,7 @@ func TestInstallRuleset(t *testing.T) {
 		},
 	}
 
-	err := m.InstallRuleset(pkg, 100)
+	err := m.InstallRuleset(pkg, 100, nil, nil, nil)
 	if err != nil {
 		t.Errorf("InstallRuleset failed: %v", err)
 	}
@@ -740,6 +767,12 @@ func TestInstallRuleset(t *testing.T) {
 	if _, err := os.Stat(m.rulesetIndexRulePath); os.IsNotExist(err) {
 		t.Errorf("index rule file should exist")
 	}
+
+	// README.md is documentation, so it is copied rather than compiled as a rule
+	indexRule, _ := os.ReadFile(m.rulesetIndexRulePath)
+	if strings.Contains(string(indexRule), "README") {
+		t.Errorf("priority index should not list README.md:\n%s", indexRule)
+	}
 }
 
 func TestInstallRulesetReplacesOldVersion(t *testing.T) {
@@ -760,7 +793,7 @@ func TestInstallRulesetReplacesOldVersion(t *testing.T) {
 			},
 		},
 	}
-	_ = m.InstallRuleset(pkg1, 100)
+	_ = m.InstallRuleset(pkg1, 100, nil, nil, nil)
 
 	// Install v1.0.0 again with different file
 	pkg2 := &core.Package{
@@ -776,18 +809,18 @@ func TestInstallRulesetReplacesOldVersion(t *testing.T) {
 			},
 		},
 	}
-	err := m.InstallRuleset(pkg2, 200)
+	err := m.InstallRuleset(pkg2, 200, nil, nil, nil)
 	if err != nil {
 		t.Errorf("InstallRuleset failed: %v", err)
 	}
 
-	// Check only new file exists
-	oldPath := filepath.Join(tmpDir, "arm", "test-reg", "test-pkg", "1.0.0", "old.md")
+	// Check only new file exists; markdown files are compiled as rules
+	oldPath := filepath.Join(tmpDir, "arm", "test-reg", "test-pkg", "1.0.0", "old_old.mdc")
 	if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
 		t.Errorf("old file should be removed")
 	}
 
-	newPath := filepath.Join(tmpDir, "arm", "test-reg", "test-pkg", "1.0.0", "new.md")
+	newPath := filepath.Join(tmpDir, "arm", "test-reg", "test-pkg", "1.0.0", "new_new.mdc")
 	if _, err := os.Stat(newPath); os.IsNotExist(err) {
 		t.Errorf("new file should exist")
 	}
@@ -814,7 +847,7 @@ func TestInstallRulesetEmptyPackage(t *testing.T) {
 		Files: []*core.File{},
 	}
 
-	err := m.InstallRuleset(pkg, 100)
+	err := m.InstallRuleset(pkg, 100, nil, nil, nil)
 	if err != nil {
 		t.Errorf("InstallRuleset should handle empty package: %v", err)
 	}
@@ -850,7 +883,7 @@ func TestInstallPromptset(t *testing.T) {
 		},
 	}
 
-	err := m.InstallPromptset(pkg)
+	err := m.InstallPromptset(pkg, nil)
 	if err != nil {
 		t.Errorf("InstallPromptset failed: %v", err)
 	}
@@ -896,7 +929,7 @@ func TestInstallPromptsetReplacesOldVersion(t *testing.T) {
 			},
 		},
 	}
-	_ = m.InstallPromptset(pkg1)
+	_ = m.InstallPromptset(pkg1, nil)
 
 	// Install v1.0.0 again with different file
 	pkg2 := &core.Package{
@@ -912,7 +945,7 @@ func TestInstallPromptsetReplacesOldVersion(t *testing.T) {
 			},
 		},
 	}
-	err := m.InstallPromptset(pkg2)
+	err := m.InstallPromptset(pkg2, nil)
 	if err != nil {
 		t.Errorf("InstallPromptset failed: %v", err)
 	}
@@ -943,7 +976,7 @@ func TestInstallPromptsetEmptyPackage(t *testing.T) {
 		Files: []*core.File{},
 	}
 
-	err := m.InstallPromptset(pkg)
+	err := m.InstallPromptset(pkg, nil)
 	if err != nil {
 		t.Errorf("InstallPromptset should handle empty package: %v", err)
 	}
@@ -992,7 +1025,7 @@ spec:
 		},
 	}
 
-	err := m.InstallRuleset(pkg, 100)
+	err := m.InstallRuleset(pkg, 100, nil, nil, nil)
 	if err != nil {
 		t.Fatalf("InstallRuleset failed: %v", err)
 	}
@@ -1072,7 +1105,7 @@ spec:
 		},
 	}
 
-	err := m.InstallPromptset(pkg)
+	err := m.InstallPromptset(pkg, nil)
 	if err != nil {
 		t.Fatalf("InstallPromptset failed: %v", err)
 	}
@@ -1118,3 +1151,867 @@ spec:
 		t.Errorf("expected 2 compiled prompt files in index, got %d", len(entry.Files))
 	}
 }
+
+func TestInstallRulesetWithCustomTool(t *testing.T) {
+	tmpDir := t.TempDir()
+	tools := map[compiler.Tool]*compiler.ToolDefinition{
+		"windsurf": {
+			Name:         "windsurf",
+			Layout:       "flat",
+			RuleTemplate: "trigger: always_on\n{{ .Rule.Body }}",
+			RuleFilename: "{{ .RulesetID }}-{{ .RuleID }}.windsurf.md",
+		},
+	}
+	m := NewManagerWithOptions(tmpDir, "windsurf", Options{Tools: tools})
+
+	if m.Layout() != LayoutFlat {
+		t.Fatalf("expected flat layout from tool definition, got %v", m.Layout())
+	}
+
+	rulesetYAML := `apiVersion: v1
+kind: Ruleset
+metadata:
+  id: "testRuleset"
+spec:
+  rules:
+    rule1:
+      name: "Rule 1"
+      body: "This is rule 1"`
+
+	pkg := &core.Package{
+		Metadata: core.PackageMetadata{
+			RegistryName: "test-reg",
+			Name:         "test-pkg",
+			Version:      mustVersion("1.0.0"),
+		},
+		Files: []*core.File{
+			{Path: "test.yml", Content: []byte(rulesetYAML)},
+		},
+	}
+
+	if err := m.InstallRuleset(pkg, 100, nil, nil, nil); err != nil {
+		t.Fatalf("InstallRuleset failed: %v", err)
+	}
+
+	index, _ := m.loadIndex()
+	entry := index.Rulesets[pkgKey("test-reg", "test-pkg", "1.0.0")]
+	if len(entry.Files) != 1 || !strings.HasSuffix(entry.Files[0], "testRuleset-rule1.windsurf.md") {
+		t.Fatalf("expected one templated rule file, got %v", entry.Files)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, entry.Files[0]))
+	if err != nil {
+		t.Fatalf("failed to read rule file: %v", err)
+	}
+	if string(content) != "trigger: always_on\nThis is rule 1" {
+		t.Errorf("unexpected rule content: %q", content)
+	}
+
+	if _, err := os.Stat(filepath.Join(tmpDir, "arm-index.windsurf.md")); err != nil {
+		t.Errorf("expected priority index rule rendered with custom filename: %v", err)
+	}
+}
+
+func TestInstallRulesetWithEnforcementPolicy(t *testing.T) {
+	tmpDir := t.TempDir()
+	m := NewManagerWithOptions(tmpDir, compiler.Cursor, Options{
+		Enforcement: compiler.EnforcementPolicy{
+			"should": compiler.ActivationAgentRequested,
+			"may":    compiler.ActivationOmit,
+		},
+	})
+
+	rulesetYAML := `apiVersion: v1
+kind: Ruleset
+metadata:
+  id: "testRuleset"
+spec:
+  rules:
+    shouldRule:
+      name: "Should Rule"
+      enforcement: should
+      scope:
+        - files: ["**/*.go"]
+      body: "This is a should rule"
+    mayRule:
+      name: "May Rule"
+      enforcement: may
+      body: "This is a may rule"`
+
+	pkg := &core.Package{
+		Metadata: core.PackageMetadata{
+			RegistryName: "test-reg",
+			Name:         "test-pkg",
+			Version:      mustVersion("1.0.0"),
+		},
+		Files: []*core.File{
+			{Path: "test.yml", Content: []byte(rulesetYAML)},
+		},
+	}
+
+	if err := m.InstallRuleset(pkg, 100, nil, nil, nil); err != nil {
+		t.Fatalf("InstallRuleset failed: %v", err)
+	}
+
+	index, _ := m.loadIndex()
+	entry := index.Rulesets[pkgKey("test-reg", "test-pkg", "1.0.0")]
+	if len(entry.Files) != 1 || !strings.HasSuffix(entry.Files[0], "testRuleset_shouldRule.mdc") {
+		t.Fatalf("expected only the should rule to be installed, got %v", entry.Files)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, entry.Files[0]))
+	if err != nil {
+		t.Fatalf("failed to read rule file: %v", err)
+	}
+	if !strings.HasPrefix(string(content), "---\ndescription: \"Should Rule\"\n---") {
+		t.Errorf("expected agent-requested frontmatter without globs, got:\n%s", content)
+	}
+}
+
+func newMcpsetPackage(name, version, content string) *core.Package {
+	return &core.Package{
+		Metadata: core.PackageMetadata{
+			RegistryName: "test-reg",
+			Name:         name,
+			Version:      mustVersion(version),
+		},
+		Files: []*core.File{
+			{Path: "servers.yml", Content: []byte(content)},
+		},
+	}
+}
+
+func TestInstallMcpset(t *testing.T) {
+	tmpDir := t.TempDir()
+	m := NewManager(tmpDir, compiler.Cursor)
+
+	// A server the user configured by hand must survive install and uninstall
+	mcpPath := filepath.Join(tmpDir, "mcp.json")
+	userConfig := `{"mcpServers": {"local": {"command": "my-server"}}}`
+	if err := os.WriteFile(mcpPath, []byte(userConfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	pkg := newMcpsetPackage("servers", "1.0.0", `apiVersion: v1
+kind: Mcpset
+metadata:
+  id: servers
+spec:
+  servers:
+    docs:
+      url: "https://example.com/mcp"`)
+
+	if err := m.InstallMcpset(pkg); err != nil {
+		t.Fatalf("InstallMcpset failed: %v", err)
+	}
+	if !m.IsInstalled(&pkg.Metadata) {
+		t.Errorf("package should be installed")
+	}
+
+	content, _ := os.ReadFile(mcpPath)
+	if !strings.Contains(string(content), `"docs"`) || !strings.Contains(string(content), `"local"`) {
+		t.Errorf("expected merged servers, got %s", content)
+	}
+
+	if err := m.Uninstall("test-reg", "servers"); err != nil {
+		t.Fatalf("Uninstall failed: %v", err)
+	}
+	content, _ = os.ReadFile(mcpPath)
+	if strings.Contains(string(content), `"docs"`) || !strings.Contains(string(content), `"local"`) {
+		t.Errorf("expected only user server to remain, got %s", content)
+	}
+}
+
+func TestInstallMcpsetConflicts(t *testing.T) {
+	tmpDir := t.TempDir()
+	m := NewManager(tmpDir, compiler.Cursor)
+
+	if err := os.WriteFile(filepath.Join(tmpDir, "mcp.json"), []byte(`{"mcpServers": {"docs": {"command": "mine"}}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	content := `apiVersion: v1
+kind: Mcpset
+metadata:
+  id: servers
+spec:
+  servers:
+    docs:
+      url: "https://example.com/mcp"`
+
+	err := m.InstallMcpset(newMcpsetPackage("servers", "1.0.0", content))
+	if err == nil || !strings.Contains(err.Error(), "not managed by ARM") {
+		t.Fatalf("expected unmanaged conflict error, got %v", err)
+	}
+
+	// Once the user entry is gone, a second package may not claim the same server
+	if err := os.Remove(filepath.Join(tmpDir, "mcp.json")); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.InstallMcpset(newMcpsetPackage("servers", "1.0.0", content)); err != nil {
+		t.Fatalf("InstallMcpset failed: %v", err)
+	}
+	err = m.InstallMcpset(newMcpsetPackage("other", "1.0.0", content))
+	if err == nil || !strings.Contains(err.Error(), "already installed by") {
+		t.Fatalf("expected ownership conflict error, got %v", err)
+	}
+}
+
+func TestUninstallMcpsetRemovesEmptyConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	m := NewManager(tmpDir, compiler.Copilot)
+
+	pkg := newMcpsetPackage("servers", "1.0.0", `apiVersion: v1
+kind: Mcpset
+metadata:
+  id: servers
+spec:
+  servers:
+    github:
+      command: npx
+      args: ["-y", "server-github"]`)
+
+	if err := m.InstallMcpset(pkg); err != nil {
+		t.Fatalf("InstallMcpset failed: %v", err)
+	}
+	mcpPath := filepath.Join(tmpDir, "mcp.json")
+	content, _ := os.ReadFile(mcpPath)
+	if !strings.Contains(string(content), `"servers"`) || !strings.Contains(string(content), `"stdio"`) {
+		t.Errorf("expected copilot server format, got %s", content)
+	}
+
+	if err := m.UninstallShared(); err != nil {
+		t.Fatalf("UninstallShared failed: %v", err)
+	}
+	if _, err := os.Stat(mcpPath); !os.IsNotExist(err) {
+		t.Errorf("mcp.json should be removed when no servers remain")
+	}
+}
+
+func newAgentsetPackage(name string) *core.Package {
+	return &core.Package{
+		Metadata: core.PackageMetadata{
+			RegistryName: "test-reg",
+			Name:         name,
+			Version:      mustVersion("1.0.0"),
+		},
+		Files: []*core.File{
+			{Path: "agents/reviewers.yml", Content: []byte(`apiVersion: v1
+kind: Agentset
+metadata:
+  id: reviewers
+spec:
+  agents:
+    code-reviewer:
+      description: "Reviews code"
+      body: "You review code."`)},
+		},
+	}
+}
+
+func TestInstallAgentset(t *testing.T) {
+	tmpDir := t.TempDir()
+	m := NewManager(tmpDir, compiler.Claude)
+
+	pkg := newAgentsetPackage("reviewers")
+	if err := m.InstallAgentset(pkg); err != nil {
+		t.Fatalf("InstallAgentset failed: %v", err)
+	}
+	if !m.IsInstalled(&pkg.Metadata) {
+		t.Errorf("package should be installed")
+	}
+
+	// Agents are written at the top level of the sink, not under arm/
+	agentPath := filepath.Join(tmpDir, "reviewers_code-reviewer.md")
+	content, err := os.ReadFile(agentPath)
+	if err != nil {
+		t.Fatalf("agent file should exist: %v", err)
+	}
+	if !strings.Contains(string(content), "name: code-reviewer") {
+		t.Errorf("unexpected agent content:\n%s", content)
+	}
+
+	// Another package generating the same file is rejected
+	err = m.InstallAgentset(newAgentsetPackage("other"))
+	if err == nil || !strings.Contains(err.Error(), "already installed by") {
+		t.Fatalf("expected ownership conflict error, got %v", err)
+	}
+
+	if err := m.Uninstall("test-reg", "reviewers"); err != nil {
+		t.Fatalf("Uninstall failed: %v", err)
+	}
+	if _, err := os.Stat(agentPath); !os.IsNotExist(err) {
+		t.Errorf("agent file should be removed")
+	}
+}
+
+func TestInstallAgentsetUnmanagedConflict(t *testing.T) {
+	tmpDir := t.TempDir()
+	m := NewManager(tmpDir, compiler.Kiro)
+
+	agentPath := filepath.Join(tmpDir, "reviewers_code-reviewer.json")
+	if err := os.WriteFile(agentPath, []byte(`{"name": "mine"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := m.InstallAgentset(newAgentsetPackage("reviewers"))
+	if err == nil || !strings.Contains(err.Error(), "not managed by ARM") {
+		t.Fatalf("expected unmanaged conflict error, got %v", err)
+	}
+	content, _ := os.ReadFile(agentPath)
+	if string(content) != `{"name": "mine"}` {
+		t.Errorf("user agent file should be untouched, got %s", content)
+	}
+}
+
+func TestInstallAgentsetUnmanagedElsewhere(t *testing.T) {
+	tmpDir := t.TempDir()
+	// A sandbox stages only ARM's files and reports the rest of the project
+	m := NewManagerWithOptions(tmpDir, compiler.Kiro, Options{
+		Unmanaged: func(path string) bool { return path == "reviewers_code-reviewer.json" },
+	})
+
+	err := m.InstallAgentset(newAgentsetPackage("reviewers"))
+	if err == nil || !strings.Contains(err.Error(), "not managed by ARM") {
+		t.Fatalf("expected unmanaged conflict error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "reviewers_code-reviewer.json")); !os.IsNotExist(err) {
+		t.Errorf("agent file should not be written")
+	}
+}
+
+func TestInstallRulesetKiroSplit(t *testing.T) {
+	tmpDir := t.TempDir()
+	m := NewManager(tmpDir, compiler.Kiro)
+	pkg := conflictTestPackage("style", `    go-style:
+      scope:
+        - files: ["**/*.go", "**/*.mod"]
+      body: Go style.
+    md-style:
+      scope:
+        - files: ["**/*.md"]
+      body: Markdown style.
+`)
+	if err := m.InstallRuleset(pkg, 100, nil, nil, nil); err != nil {
+		t.Fatalf("InstallRuleset failed: %v", err)
+	}
+
+	index, err := m.loadIndex()
+	if err != nil {
+		t.Fatalf("loadIndex failed: %v", err)
+	}
+	rules := index.Rulesets[pkgKey("test-reg", "style", "1.0.0")].Rules
+	if len(rules) != 2 || rules[0].ID != "go-style" || len(rules[0].Files) != 2 || rules[1].ID != "md-style" || len(rules[1].Files) != 1 {
+		t.Errorf("expected split parts recorded under go-style and md-style kept whole, got %+v", rules)
+	}
+
+	clash := conflictTestPackage("clash", `    go-style:
+      scope:
+        - files: ["**/*.go", "**/*.mod"]
+      body: Go style.
+    go-style-1:
+      body: Unrelated.
+`)
+	if err := m.InstallRuleset(clash, 100, nil, nil, nil); err == nil || !strings.Contains(err.Error(), "split part go-style-1") {
+		t.Errorf("expected split ID collision error, got %v", err)
+	}
+}
+
+func TestInstallAgentsetUnsupportedTool(t *testing.T) {
+	m := NewManager(t.TempDir(), compiler.Cursor)
+	if err := m.InstallAgentset(newAgentsetPackage("reviewers")); err == nil {
+		t.Error("expected error installing agents to a cursor sink")
+	}
+}
+
+func newSkillsetPackage(name string) *core.Package {
+	return &core.Package{
+		Metadata: core.PackageMetadata{
+			RegistryName: "test-reg",
+			Name:         name,
+			Version:      mustVersion("1.0.0"),
+		},
+		Files: []*core.File{
+			{Path: "README.md", Content: []byte("# Skills")},
+			{Path: "skills/pdf-processing/SKILL.md", Content: []byte("---\nname: pdf-processing\ndescription: Extract text from PDFs.\n---\n\n# PDF\n")},
+			{Path: "skills/pdf-processing/scripts/extract.py", Content: []byte("print('extract')"), Mode: 0o755},
+			{Path: "skills/pdf-processing/references/REFERENCE.md", Content: []byte("# Reference")},
+		},
+	}
+}
+
+func TestInstallSkillset(t *testing.T) {
+	tmpDir := t.TempDir()
+	m := NewManager(tmpDir, compiler.Claude)
+
+	pkg := newSkillsetPackage("pdf")
+	if err := m.InstallSkillset(pkg); err != nil {
+		t.Fatalf("InstallSkillset failed: %v", err)
+	}
+	if !m.IsInstalled(&pkg.Metadata) {
+		t.Errorf("package should be installed")
+	}
+
+	// The skill directory is copied intact to <sink>/<name>/
+	for _, rel := range []string{"SKILL.md", "scripts/extract.py", "references/REFERENCE.md"} {
+		if _, err := os.Stat(filepath.Join(tmpDir, "pdf-processing", rel)); err != nil {
+			t.Errorf("expected %s to be installed: %v", rel, err)
+		}
+	}
+	// Files keep the permissions they were published with
+	for rel, want := range map[string]os.FileMode{"scripts/extract.py": 0o755, "SKILL.md": 0o644} {
+		info, err := os.Stat(filepath.Join(tmpDir, "pdf-processing", rel))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := info.Mode().Perm(); got != want {
+			t.Errorf("%s mode = %v, want %v", rel, got, want)
+		}
+	}
+	// Files outside skill directories are not installed
+	if _, err := os.Stat(filepath.Join(tmpDir, "README.md")); !os.IsNotExist(err) {
+		t.Errorf("README.md outside the skill should not be installed")
+	}
+
+	err := m.InstallSkillset(newSkillsetPackage("other"))
+	if err == nil || !strings.Contains(err.Error(), "already installed by") {
+		t.Fatalf("expected ownership conflict error, got %v", err)
+	}
+
+	if err := m.Uninstall("test-reg", "pdf"); err != nil {
+		t.Fatalf("Uninstall failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "pdf-processing")); !os.IsNotExist(err) {
+		t.Errorf("skill directory should be removed")
+	}
+}
+
+func TestInstallSkillsetUnmanagedConflict(t *testing.T) {
+	tmpDir := t.TempDir()
+	m := NewManager(tmpDir, compiler.Claude)
+
+	userSkill := filepath.Join(tmpDir, "pdf-processing", "SKILL.md")
+	if err := os.MkdirAll(filepath.Dir(userSkill), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(userSkill, []byte("mine"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := m.InstallSkillset(newSkillsetPackage("pdf"))
+	if err == nil || !strings.Contains(err.Error(), "not managed by ARM") {
+		t.Fatalf("expected unmanaged conflict error, got %v", err)
+	}
+}
+
+func TestInstallSkillsetInvalidSkill(t *testing.T) {
+	m := NewManager(t.TempDir(), compiler.Claude)
+	pkg := newSkillsetPackage("pdf")
+	pkg.Files[1].Content = []byte("---\nname: pdf-processing\n---\n")
+
+	if err := m.InstallSkillset(pkg); err == nil || !strings.Contains(err.Error(), "description is required") {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
+
+func newHooksetPackage(name, version, content string) *core.Package {
+	return &core.Package{
+		Metadata: core.PackageMetadata{
+			RegistryName: "test-reg",
+			Name:         name,
+			Version:      mustVersion(version),
+		},
+		Files: []*core.File{
+			{Path: "hooks.yml", Content: []byte(content)},
+		},
+	}
+}
+
+const testHookset = `apiVersion: v1
+kind: Hookset
+metadata:
+  id: quality
+spec:
+  hooks:
+    format:
+      event: post-edit
+      command: "make fmt"
+      timeout: 30`
+
+func TestInstallHooksetMergesClaudeSettings(t *testing.T) {
+	tmpDir := t.TempDir()
+	m := NewManager(tmpDir, compiler.Claude)
+
+	// Settings and hooks the user configured by hand must survive install and uninstall
+	settingsPath := filepath.Join(tmpDir, "settings.json")
+	userSettings := `{"model": "opus", "hooks": {"PostToolUse": [{"matcher": "Write", "hooks": [{"type": "command", "command": "./mine.sh"}]}]}}`
+	if err := os.WriteFile(settingsPath, []byte(userSettings), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	pkg := newHooksetPackage("quality", "1.0.0", testHookset)
+	if err := m.InstallHookset(pkg); err != nil {
+		t.Fatalf("InstallHookset failed: %v", err)
+	}
+	if !m.IsInstalled(&pkg.Metadata) {
+		t.Errorf("package should be installed")
+	}
+
+	content, _ := os.ReadFile(settingsPath)
+	if !strings.Contains(string(content), "make fmt") || !strings.Contains(string(content), "./mine.sh") {
+		t.Errorf("expected merged hooks, got %s", content)
+	}
+
+	// Reinstalling replaces the package's own entries instead of duplicating them
+	if err := m.InstallHookset(newHooksetPackage("quality", "1.1.0", testHookset)); err != nil {
+		t.Fatalf("InstallHookset upgrade failed: %v", err)
+	}
+	content, _ = os.ReadFile(settingsPath)
+	if strings.Count(string(content), "make fmt") != 1 {
+		t.Errorf("expected a single ARM hook after reinstall, got %s", content)
+	}
+
+	if err := m.Uninstall("test-reg", "quality"); err != nil {
+		t.Fatalf("Uninstall failed: %v", err)
+	}
+	content, _ = os.ReadFile(settingsPath)
+	if strings.Contains(string(content), "make fmt") || !strings.Contains(string(content), "./mine.sh") || !strings.Contains(string(content), `"opus"`) {
+		t.Errorf("expected only user settings to remain, got %s", content)
+	}
+}
+
+func TestInstallHooksetConflicts(t *testing.T) {
+	tmpDir := t.TempDir()
+	m := NewManager(tmpDir, compiler.Claude)
+
+	if err := m.InstallHookset(newHooksetPackage("quality", "1.0.0", testHookset)); err != nil {
+		t.Fatalf("InstallHookset failed: %v", err)
+	}
+	err := m.InstallHookset(newHooksetPackage("other", "1.0.0", testHookset))
+	if err == nil || !strings.Contains(err.Error(), "already installed by") {
+		t.Fatalf("expected ownership conflict error, got %v", err)
+	}
+
+	// Removing the last hook removes the settings file ARM created
+	if err := m.UninstallShared(); err != nil {
+		t.Fatalf("UninstallShared failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "settings.json")); !os.IsNotExist(err) {
+		t.Errorf("settings.json should be removed, got %v", err)
+	}
+}
+
+func TestInstallHooksetKiroFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	m := NewManager(tmpDir, compiler.Kiro)
+
+	if err := m.InstallHookset(newHooksetPackage("quality", "1.0.0", testHookset)); err != nil {
+		t.Fatalf("InstallHookset failed: %v", err)
+	}
+	hookPath := filepath.Join(tmpDir, "quality_format.kiro.hook")
+	content, err := os.ReadFile(hookPath)
+	if err != nil || !strings.Contains(string(content), `"fileEdited"`) {
+		t.Fatalf("expected Kiro hook file, got %s, %v", content, err)
+	}
+
+	if err := m.Uninstall("test-reg", "quality"); err != nil {
+		t.Fatalf("Uninstall failed: %v", err)
+	}
+	if _, err := os.Stat(hookPath); !os.IsNotExist(err) {
+		t.Errorf("hook file should be removed, got %v", err)
+	}
+}
+
+func TestInstallHooksetUnsupportedTool(t *testing.T) {
+	m := NewManager(t.TempDir(), compiler.Cursor)
+
+	err := m.InstallHookset(newHooksetPackage("quality", "1.0.0", testHookset))
+	if err == nil || !strings.Contains(err.Error(), "does not support hooks") {
+		t.Fatalf("expected unsupported tool error, got %v", err)
+	}
+}
+
+func TestInstallRulesetCompilesLegacyFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	m := NewManager(tmpDir, compiler.Cursor)
+
+	pkg := &core.Package{
+		Metadata: core.PackageMetadata{
+			RegistryName: "test-reg",
+			Name:         "python",
+			Version:      mustVersion("1.0.0"),
+		},
+		Files: []*core.File{
+			{Path: "rules-new/python.mdc", Content: []byte("---\ndescription: Python style\nglobs: **/*.py\n---\nUse black.\n")},
+			{Path: ".cursorrules", Content: []byte("Be concise.\n")},
+		},
+	}
+
+	if err := m.InstallRuleset(pkg, 100, nil, nil, nil); err != nil {
+		t.Fatalf("InstallRuleset failed: %v", err)
+	}
+
+	pkgDir := filepath.Join(tmpDir, "arm", "test-reg", "python", "1.0.0")
+	content, err := os.ReadFile(filepath.Join(pkgDir, "rules-new", "rules-new_python.mdc"))
+	if err != nil {
+		t.Fatalf("expected compiled rule: %v", err)
+	}
+	for _, want := range []string{"globs: **/*.py", "namespace: test-reg/python@1.0.0", "Use black."} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("compiled rule missing %q:\n%s", want, content)
+		}
+	}
+
+	content, err = os.ReadFile(filepath.Join(pkgDir, "cursorrules_cursorrules.mdc"))
+	if err != nil || !strings.Contains(string(content), "alwaysApply: true") {
+		t.Errorf("expected .cursorrules to compile to an always-applied rule, got %s, %v", content, err)
+	}
+
+	// Legacy rules take part in the priority index like any other ruleset
+	index, _ := os.ReadFile(m.rulesetIndexRulePath)
+	if !strings.Contains(string(index), "rules-new_python.mdc") {
+		t.Errorf("priority index should list legacy rules:\n%s", index)
+	}
+}
+
+func TestInstallRulesetFromMarkdownSources(t *testing.T) {
+	metadata := core.PackageMetadata{RegistryName: "test-reg", Name: "clean-code", Version: mustVersion("1.0.0")}
+
+	yamlDir := t.TempDir()
+	yamlPkg := &core.Package{Metadata: metadata, Files: []*core.File{
+		{Path: "rules/ruleset.yml", Content: []byte(`apiVersion: v1
+kind: Ruleset
+metadata:
+  id: clean-code
+spec:
+  rules:
+    naming:
+      enforcement: must
+      body: |
+        Use descriptive names.
+`)},
+	}}
+	if err := NewManager(yamlDir, compiler.Cursor).InstallRuleset(yamlPkg, 100, nil, nil, nil); err != nil {
+		t.Fatalf("InstallRuleset (yaml) failed: %v", err)
+	}
+
+	markdownDir := t.TempDir()
+	markdownPkg := &core.Package{Metadata: metadata, Files: []*core.File{
+		{Path: "rules/ruleset.yml", Content: []byte("metadata:\n  id: clean-code\n")},
+		{Path: "rules/naming.rule.md", Content: []byte("---\nenforcement: must\n---\n\nUse descriptive names.\n")},
+	}}
+	if err := NewManager(markdownDir, compiler.Cursor).InstallRuleset(markdownPkg, 100, nil, nil, nil); err != nil {
+		t.Fatalf("InstallRuleset (markdown) failed: %v", err)
+	}
+
+	rel := filepath.Join("arm", "test-reg", "clean-code", "1.0.0", "rules", "clean-code_naming.mdc")
+	want, err := os.ReadFile(filepath.Join(yamlDir, rel))
+	if err != nil {
+		t.Fatalf("expected compiled rule from yaml: %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(markdownDir, rel))
+	if err != nil {
+		t.Fatalf("expected compiled rule from markdown: %v", err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("markdown source compiled differently:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestInstallRulesetAppliesOverlays(t *testing.T) {
+	tmpDir := t.TempDir()
+	m := NewManager(tmpDir, compiler.Cursor)
+
+	pkg := &core.Package{
+		Metadata: core.PackageMetadata{RegistryName: "test-reg", Name: "clean-code", Version: mustVersion("1.0.0")},
+		Files: []*core.File{{Path: "rules/clean-code.yml", Content: []byte(`apiVersion: v1
+kind: Ruleset
+metadata:
+  id: clean-code
+spec:
+  rules:
+    naming:
+      enforcement: should
+      body: Use descriptive names.
+    comments:
+      body: Explain why, not what.
+`)}},
+	}
+
+	overlayFile := filepath.Join(t.TempDir(), "naming.md")
+	if err := os.WriteFile(overlayFile, []byte("Prefer Go initialisms.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	overlays := map[string]compiler.RuleOverlay{
+		"naming":   {Enforcement: "must", AppendFile: overlayFile},
+		"comments": {Disabled: true},
+	}
+	if err := m.InstallRuleset(pkg, 100, overlays, nil, nil); err != nil {
+		t.Fatalf("InstallRuleset failed: %v", err)
+	}
+
+	rulesDir := filepath.Join(tmpDir, "arm", "test-reg", "clean-code", "1.0.0", "rules")
+	content, err := os.ReadFile(filepath.Join(rulesDir, "clean-code_naming.mdc"))
+	if err != nil {
+		t.Fatalf("expected compiled rule: %v", err)
+	}
+	for _, want := range []string{"alwaysApply: true", "Use descriptive names.", "Prefer Go initialisms."} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("overlaid rule missing %q:\n%s", want, content)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(rulesDir, "clean-code_comments.mdc")); !os.IsNotExist(err) {
+		t.Errorf("disabled rule should not be installed, stat err = %v", err)
+	}
+
+	// Overlays for rules the package does not define are reported
+	err = m.InstallRuleset(pkg, 100, map[string]compiler.RuleOverlay{"renamed": {Disabled: true}}, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "overlay references rule renamed") {
+		t.Errorf("expected stale overlay error, got %v", err)
+	}
+}
+
+func TestInstallRulesetInterpolatesVars(t *testing.T) {
+	tmpDir := t.TempDir()
+	m := NewManager(tmpDir, compiler.Cursor)
+
+	pkg := &core.Package{
+		Metadata: core.PackageMetadata{RegistryName: "test-reg", Name: "testing", Version: mustVersion("1.0.0")},
+		Files: []*core.File{{Path: "rules/testing.yml", Content: []byte(`apiVersion: v1
+kind: Ruleset
+metadata:
+  id: testing
+spec:
+  rules:
+    run-tests:
+      body: Run {{ .Vars.testCommand }} before committing.
+`)}},
+	}
+
+	vars := map[string]string{"testCommand": "make test", "projectName": "arm"}
+	if err := m.InstallRuleset(pkg, 100, nil, vars, nil); err != nil {
+		t.Fatalf("InstallRuleset failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, "arm", "test-reg", "testing", "1.0.0", "rules", "testing_run-tests.mdc"))
+	if err != nil || !strings.Contains(string(content), "Run make test before committing.") {
+		t.Fatalf("expected interpolated rule, got %s, %v", content, err)
+	}
+
+	// Only the variables the package uses are recorded
+	index, _ := m.loadIndex()
+	if got := index.Rulesets["test-reg/testing@1.0.0"].Vars; len(got) != 1 || got["testCommand"] != "make test" {
+		t.Errorf("index vars = %v, want testCommand only", got)
+	}
+
+	if m.VarsChanged("test-reg", "testing", "1.0.0", map[string]string{"testCommand": "make test"}) {
+		t.Error("unchanged vars should not require a reinstall")
+	}
+	if !m.VarsChanged("test-reg", "testing", "1.0.0", map[string]string{"testCommand": "npm test"}) {
+		t.Error("changed vars should require a reinstall")
+	}
+	if !m.VarsChanged("test-reg", "testing", "1.0.0", nil) {
+		t.Error("removed vars should require a reinstall")
+	}
+
+	err = m.InstallRuleset(pkg, 100, nil, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "variable testCommand is not set") {
+		t.Errorf("expected unset variable error, got %v", err)
+	}
+}
+
+func TestInstallPromptsetInterpolatesVars(t *testing.T) {
+	tmpDir := t.TempDir()
+	m := NewManager(tmpDir, compiler.Cursor)
+
+	pkg := &core.Package{
+		Metadata: core.PackageMetadata{RegistryName: "test-reg", Name: "review", Version: mustVersion("1.0.0")},
+		Files: []*core.File{{Path: "prompts/review.yml", Content: []byte(`apiVersion: v1
+kind: Promptset
+metadata:
+  id: review
+spec:
+  prompts:
+    review:
+      body: Review this {{ .Vars.primaryLanguage }} change.
+`)}},
+	}
+
+	if err := m.InstallPromptset(pkg, map[string]string{"primaryLanguage": "Go"}); err != nil {
+		t.Fatalf("InstallPromptset failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, "arm", "test-reg", "review", "1.0.0", "prompts", "review_review.md"))
+	if err != nil || string(content) != "Review this Go change." {
+		t.Fatalf("expected interpolated prompt, got %q, %v", content, err)
+	}
+	if !m.VarsChanged("test-reg", "review", "1.0.0", map[string]string{"primaryLanguage": "Rust"}) {
+		t.Error("changed vars should require a reinstall")
+	}
+}
+
+func TestInstallRulesetResolvesExtends(t *testing.T) {
+	tmpDir := t.TempDir()
+	m := NewManager(tmpDir, compiler.Cursor)
+
+	pkg := &core.Package{
+		Metadata: core.PackageMetadata{RegistryName: "test-reg", Name: "python", Version: mustVersion("1.0.0")},
+		Files: []*core.File{
+			{Path: "rules/base.yml", Content: []byte(`apiVersion: v1
+kind: Ruleset
+metadata:
+  id: base
+spec:
+  rules:
+    naming:
+      body: Use descriptive names.
+    tests:
+      body: Write tests.
+`)},
+			{Path: "rules/python.yml", Content: []byte(`apiVersion: v1
+kind: Ruleset
+metadata:
+  id: python
+spec:
+  extends: [base, shared-reg/errors/errors]
+  rules:
+    tests:
+      body: Write pytest tests.
+`)},
+		},
+	}
+	bases := map[string]*resource.RulesetResource{
+		"shared-reg/errors/errors": {
+			Metadata: resource.ResourceMetadata{ID: "errors"},
+			Spec:     resource.RulesetSpec{Rules: map[string]resource.Rule{"wrap": {Body: "Wrap errors with context."}}},
+		},
+	}
+	if err := m.InstallRuleset(pkg, 100, nil, nil, bases); err != nil {
+		t.Fatalf("InstallRuleset failed: %v", err)
+	}
+
+	rulesDir := filepath.Join(tmpDir, "arm", "test-reg", "python", "1.0.0", "rules")
+	want := map[string]string{
+		"python_naming.mdc": "Use descriptive names.",
+		"python_tests.mdc":  "Write pytest tests.",
+		"python_wrap.mdc":   "Wrap errors with context.",
+	}
+	for name, body := range want {
+		content, err := os.ReadFile(filepath.Join(rulesDir, name))
+		if err != nil {
+			t.Fatalf("expected compiled rule %s: %v", name, err)
+		}
+		if !strings.Contains(string(content), body) {
+			t.Errorf("%s missing %q:\n%s", name, body, content)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(rulesDir, "base_naming.mdc")); !os.IsNotExist(err) {
+		t.Errorf("extended ruleset should only be installed through its extender, stat err = %v", err)
+	}
+
+	// Missing bases are reported
+	err := m.InstallRuleset(pkg, 100, nil, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "which is not available") {
+		t.Errorf("expected missing base error, got %v", err)
+	}
+}
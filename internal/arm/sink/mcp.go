@@ -0,0 +1,208 @@
+package sink
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jomadu/ai-resource-manager/internal/arm/compiler"
+	"github.com/jomadu/ai-resource-manager/internal/arm/core"
+	"github.com/jomadu/ai-resource-manager/internal/arm/filetype"
+	"github.com/jomadu/ai-resource-manager/internal/arm/parser"
+	"github.com/jomadu/ai-resource-manager/internal/arm/resource"
+)
+
+// McpsetIndexEntry records the MCP servers a package owns in the sink's MCP configuration
+type McpsetIndexEntry struct {
+	File    string   `json:"file"`
+	Servers []string `json:"servers"`
+}
+
+// InstallMcpset merges an mcpset package's servers into the sink's MCP configuration.
+// Servers already present in the file but not installed by ARM are never overwritten.
+func (m *Manager) InstallMcpset(pkg *core.Package) error {
+	if m.mcpGenerator == nil {
+		return fmt.Errorf("sink tool does not support mcp servers")
+	}
+
+	// Uninstall all existing versions of this package
+	if err := m.Uninstall(pkg.Metadata.RegistryName, pkg.Metadata.Name); err != nil {
+		return err
+	}
+
+	key := pkgKey(pkg.Metadata.RegistryName, pkg.Metadata.Name, pkg.Metadata.Version.Version)
+
+	servers := make(map[string]resource.McpServer)
+	for _, file := range pkg.Files {
+		if !filetype.IsMcpsetFile(file) {
+			continue
+		}
+		mcpset, err := parser.ParseMcpset(file)
+		if err != nil {
+			return err
+		}
+		for name, server := range mcpset.Spec.Servers {
+			if _, exists := servers[name]; exists {
+				return fmt.Errorf("mcp server %s is defined more than once in %s", name, key)
+			}
+			servers[name] = server
+		}
+	}
+
+	index, err := m.loadIndex()
+	if err != nil {
+		return err
+	}
+
+	filename := m.mcpGenerator.McpFilename()
+	names := make([]string, 0, len(servers))
+	for name := range servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if len(names) > 0 {
+		owners := make(map[string]string)
+		for ownerKey, entry := range index.Mcpsets {
+			if entry.File != filename {
+				continue
+			}
+			for _, name := range entry.Servers {
+				owners[name] = ownerKey
+			}
+		}
+
+		config, err := m.loadJSONConfig(filename)
+		if err != nil {
+			return err
+		}
+		entries := jsonObject(config, m.mcpGenerator.McpServersKey())
+
+		for _, name := range names {
+			if owner, ok := owners[name]; ok {
+				return fmt.Errorf("mcp server %s is already installed by %s", name, owner)
+			}
+			if _, exists := entries[name]; exists {
+				return fmt.Errorf("mcp server %s already exists in %s and is not managed by ARM", name, filename)
+			}
+		}
+
+		for _, name := range names {
+			entries[name] = m.mcpGenerator.GenerateMcpServer(servers[name])
+		}
+		config[m.mcpGenerator.McpServersKey()] = entries
+
+		if err := m.saveJSONConfig(filename, config); err != nil {
+			return err
+		}
+	}
+
+	index.Mcpsets[key] = McpsetIndexEntry{
+		File:    filename,
+		Servers: names,
+	}
+
+	return m.saveIndex(index)
+}
+
+// UninstallShared removes every ARM-owned entry that lives outside ARM's own
+// files: MCP servers in the tool configuration, agent files, skill directories and hooks
+func (m *Manager) UninstallShared() error {
+	index, err := m.loadIndex()
+	if err != nil {
+		return err
+	}
+	if len(index.Mcpsets) == 0 && len(index.Agentsets) == 0 && len(index.Skillsets) == 0 && len(index.Hooksets) == 0 {
+		return nil
+	}
+	if err := m.removeMcpServers(index, ""); err != nil {
+		return err
+	}
+	m.removeAgents(index, "")
+	m.removeSkills(index, "")
+	if err := m.removeHooks(index, ""); err != nil {
+		return err
+	}
+	return m.saveIndex(index)
+}
+
+// removeMcpServers removes the servers owned by packages whose key starts with prefix
+// from the MCP configuration and drops their index entries
+func (m *Manager) removeMcpServers(index *Index, prefix string) error {
+	for key, entry := range index.Mcpsets {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+
+		if len(entry.Servers) > 0 {
+			config, err := m.loadJSONConfig(entry.File)
+			if err != nil {
+				return err
+			}
+
+			serversKey := m.mcpServersKey()
+			entries := jsonObject(config, serversKey)
+			for _, name := range entry.Servers {
+				delete(entries, name)
+			}
+
+			if len(entries) == 0 {
+				delete(config, serversKey)
+			} else {
+				config[serversKey] = entries
+			}
+
+			if len(config) == 0 {
+				_ = os.Remove(filepath.Join(m.directory, entry.File))
+			} else if err := m.saveJSONConfig(entry.File, config); err != nil {
+				return err
+			}
+		}
+
+		delete(index.Mcpsets, key)
+	}
+	return nil
+}
+
+func (m *Manager) mcpServersKey() string {
+	if m.mcpGenerator == nil {
+		return "mcpServers"
+	}
+	return m.mcpGenerator.McpServersKey()
+}
+
+func (m *Manager) loadJSONConfig(filename string) (map[string]interface{}, error) {
+	path := filepath.Join(m.directory, filename)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return make(map[string]interface{}), nil
+		}
+		return nil, err
+	}
+
+	config := make(map[string]interface{})
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return config, nil
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return config, nil
+}
+
+func (m *Manager) saveJSONConfig(filename string, config map[string]interface{}) error {
+	data, err := compiler.MarshalJSONConfig(config)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(m.directory, filename)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
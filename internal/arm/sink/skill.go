@@ -0,0 +1,144 @@
+package sink
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jomadu/ai-resource-manager/internal/arm/core"
+	"github.com/jomadu/ai-resource-manager/internal/arm/filetype"
+	"github.com/jomadu/ai-resource-manager/internal/arm/parser"
+)
+
+// SkillsetIndexEntry records the skill directories a package owns in the sink directory
+type SkillsetIndexEntry struct {
+	Skills []string `json:"skills"`
+	Files  []string `json:"files"`
+	// Sources maps each installed file to the package file it was copied from
+	Sources map[string]string `json:"sources,omitempty"`
+}
+
+// InstallSkillset copies every skill directory in a skillset package intact into
+// <sink>/<skill-name>/, keeping the permissions files were published with so that
+// scripts stay executable. Skills are discovered by their SKILL.md, whose frontmatter is
+// validated first; files outside skill directories are ignored. Like agents, skills
+// are placed at the top level of the sink regardless of layout and never replace
+// directories ARM did not install.
+func (m *Manager) InstallSkillset(pkg *core.Package) error {
+	// Uninstall all existing versions of this package
+	if err := m.Uninstall(pkg.Metadata.RegistryName, pkg.Metadata.Name); err != nil {
+		return err
+	}
+
+	key := pkgKey(pkg.Metadata.RegistryName, pkg.Metadata.Name, pkg.Metadata.Version.Version)
+
+	// Map each skill's source directory to its name
+	skillDirs := make(map[string]string)
+	for _, file := range pkg.Files {
+		if !filetype.IsSkillFile(file) {
+			continue
+		}
+		skill, err := parser.ParseSkill(file)
+		if err != nil {
+			return err
+		}
+		skillDirs[path.Dir(filepath.ToSlash(file.Path))] = skill.Name
+	}
+
+	names := make([]string, 0, len(skillDirs))
+	seen := make(map[string]string)
+	for dir, name := range skillDirs {
+		if other, exists := seen[name]; exists {
+			return fmt.Errorf("skill %s is defined in both %s and %s in %s", name, other, dir, key)
+		}
+		seen[name] = dir
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	index, err := m.loadIndex()
+	if err != nil {
+		return err
+	}
+
+	owners := make(map[string]string)
+	for ownerKey, entry := range index.Skillsets {
+		for _, name := range entry.Skills {
+			owners[name] = ownerKey
+		}
+	}
+
+	for _, name := range names {
+		if owner, ok := owners[name]; ok {
+			return fmt.Errorf("skill %s is already installed by %s", name, owner)
+		}
+		if m.taken(name) {
+			return fmt.Errorf("skill %s already exists in %s and is not managed by ARM", name, m.directory)
+		}
+	}
+
+	var installedFiles []string
+	sources := make(map[string]string)
+	for _, file := range pkg.Files {
+		filePath := filepath.ToSlash(file.Path)
+		dir, name, ok := findSkillDir(skillDirs, filePath)
+		if !ok {
+			continue
+		}
+
+		relPath := filePath
+		if dir != "." {
+			relPath = strings.TrimPrefix(filePath, dir+"/")
+		}
+		fullPath := filepath.Join(m.directory, name, filepath.FromSlash(relPath))
+
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(fullPath, file.Content, file.Perm()); err != nil {
+			return err
+		}
+
+		installedPath, _ := filepath.Rel(m.directory, fullPath)
+		installedFiles = append(installedFiles, installedPath)
+		sources[installedPath] = file.Path
+	}
+	sort.Strings(installedFiles)
+
+	index.Skillsets[key] = SkillsetIndexEntry{
+		Skills:  names,
+		Files:   installedFiles,
+		Sources: nilIfEmpty(sources),
+	}
+
+	return m.saveIndex(index)
+}
+
+// findSkillDir returns the innermost skill directory containing filePath
+func findSkillDir(skillDirs map[string]string, filePath string) (dir, name string, ok bool) {
+	for candidate := path.Dir(filePath); ; candidate = path.Dir(candidate) {
+		if name, exists := skillDirs[candidate]; exists {
+			return candidate, name, true
+		}
+		if candidate == "." || candidate == "/" {
+			return "", "", false
+		}
+	}
+}
+
+// removeSkills deletes the skill directories owned by packages whose key starts with
+// prefix and drops their index entries
+func (m *Manager) removeSkills(index *Index, prefix string) {
+	for key, entry := range index.Skillsets {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		for _, name := range entry.Skills {
+			_ = os.RemoveAll(filepath.Join(m.directory, name)) // Ignore errors
+		}
+		delete(index.Skillsets, key)
+	}
+}
@@ -0,0 +1,123 @@
+package sink
+
+import (
+	"path/filepath"
+	"sort"
+)
+
+// InstalledFile is a file ARM installed in a sink, traced back to the package
+// that produced it
+type InstalledFile struct {
+	// Path is relative to the sink directory
+	Path string
+	// Package is REGISTRY/PACKAGE@VERSION
+	Package string
+	// Type is the package type: ruleset, promptset, mcpset, agentset, skillset or hookset
+	Type     string
+	Priority int // rulesets only
+	// Source is the package file the file was generated from; empty for files
+	// installed before sources were recorded and for shared configuration files
+	Source  string
+	Ruleset string // generated rules only
+	Rule    string // generated rules only
+	// Shared reports a configuration file other packages and the user may also write to
+	Shared bool
+	// Suppressed reports a rule withheld in dedupe mode, so the file is not on disk
+	Suppressed bool
+}
+
+// Files returns the files installed in the sink, ordered by package and then path
+func (m *Manager) Files() ([]InstalledFile, error) {
+	index, err := m.loadIndex()
+	if err != nil {
+		return nil, err
+	}
+	return installedFiles(index), nil
+}
+
+// installedFiles lists the files recorded in index, ordered by package and then path
+func installedFiles(index *Index) []InstalledFile {
+	var files []InstalledFile
+	for key, entry := range index.Rulesets {
+		rules := make(map[string]RuleIndexEntry)
+		for _, rule := range entry.Rules {
+			for _, file := range rule.Files {
+				rules[file] = rule
+			}
+		}
+		suppressed := suppressedFiles(entry)
+		for _, path := range entry.Files {
+			file := InstalledFile{
+				Path:       path,
+				Package:    key,
+				Type:       "ruleset",
+				Priority:   entry.Priority,
+				Source:     entry.Sources[path],
+				Suppressed: suppressed[path],
+			}
+			if rule, ok := rules[path]; ok {
+				file.Ruleset, file.Rule = rule.Ruleset, rule.ID
+			}
+			files = append(files, file)
+		}
+	}
+	for key, entry := range index.Promptsets {
+		files = appendInstalledFiles(files, key, "promptset", entry.Files, entry.Sources)
+	}
+	for key, entry := range index.Mcpsets {
+		files = append(files, InstalledFile{Path: entry.File, Package: key, Type: "mcpset", Shared: true})
+	}
+	for key, entry := range index.Agentsets {
+		files = appendInstalledFiles(files, key, "agentset", entry.Files, entry.Sources)
+	}
+	for key, entry := range index.Skillsets {
+		files = appendInstalledFiles(files, key, "skillset", entry.Files, entry.Sources)
+	}
+	for key, entry := range index.Hooksets {
+		if entry.File != "" {
+			files = append(files, InstalledFile{Path: entry.File, Package: key, Type: "hookset", Shared: true})
+		}
+		files = appendInstalledFiles(files, key, "hookset", entry.Files, nil)
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].Package != files[j].Package {
+			return files[i].Package < files[j].Package
+		}
+		return files[i].Path < files[j].Path
+	})
+	return files
+}
+
+// FindFile returns the installed files at path, relative to the sink directory.
+// Shared configuration files may belong to several packages; an empty result
+// means ARM did not install the file.
+func (m *Manager) FindFile(path string) ([]InstalledFile, error) {
+	files, err := m.Files()
+	if err != nil {
+		return nil, err
+	}
+
+	path = filepath.Clean(path)
+	var found []InstalledFile
+	for _, file := range files {
+		if filepath.Clean(file.Path) == path {
+			found = append(found, file)
+		}
+	}
+	return found, nil
+}
+
+// IsIndexFile reports whether path, relative to the sink directory, is one of
+// the index files ARM maintains for the sink itself
+func (m *Manager) IsIndexFile(path string) bool {
+	full := filepath.Join(m.directory, path)
+	return full == m.indexPath || full == m.rulesetIndexRulePath
+}
+
+func appendInstalledFiles(files []InstalledFile, key, pkgType string, paths []string, sources map[string]string) []InstalledFile {
+	for _, path := range paths {
+		files = append(files, InstalledFile{Path: path, Package: key, Type: pkgType, Source: sources[path]})
+	}
+	return files
+}
@@ -0,0 +1,63 @@
+package sink
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jomadu/ai-resource-manager/internal/arm/compiler"
+	"github.com/jomadu/ai-resource-manager/internal/arm/core"
+)
+
+func TestFindFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	m := NewManager(tmpDir, compiler.Cursor)
+
+	pkg := conflictTestPackage("team", `    naming:
+      body: Use descriptive names.
+`)
+	pkg.Files[0].Path = "rules/team.yml"
+	pkg.Files = append(pkg.Files, &core.File{Path: "scripts/check.sh", Content: []byte("#!/bin/sh")})
+	if err := m.InstallRuleset(pkg, 200, nil, nil, nil); err != nil {
+		t.Fatalf("InstallRuleset() error = %v", err)
+	}
+
+	rulePath := filepath.Join("arm", "test-reg", "team", "1.0.0", "rules", "team_naming.mdc")
+	found, err := m.FindFile(rulePath)
+	if err != nil {
+		t.Fatalf("FindFile() error = %v", err)
+	}
+	want := InstalledFile{
+		Path:     rulePath,
+		Package:  "test-reg/team@1.0.0",
+		Type:     "ruleset",
+		Priority: 200,
+		Source:   "rules/team.yml",
+		Ruleset:  "team",
+		Rule:     "naming",
+	}
+	if len(found) != 1 || found[0] != want {
+		t.Errorf("FindFile(%s) = %+v, want %+v", rulePath, found, want)
+	}
+
+	// Files copied as-is have no rule
+	script := filepath.Join("arm", "test-reg", "team", "1.0.0", "scripts", "check.sh")
+	found, err = m.FindFile("./" + script)
+	if err != nil {
+		t.Fatalf("FindFile() error = %v", err)
+	}
+	if len(found) != 1 || found[0].Source != "scripts/check.sh" || found[0].Rule != "" {
+		t.Errorf("FindFile(%s) = %+v", script, found)
+	}
+
+	found, err = m.FindFile("unrelated.mdc")
+	if err != nil {
+		t.Fatalf("FindFile() error = %v", err)
+	}
+	if len(found) != 0 {
+		t.Errorf("FindFile(unrelated.mdc) = %+v, want none", found)
+	}
+
+	if !m.IsIndexFile(filepath.Join("arm", "arm-index.json")) || !m.IsIndexFile(filepath.Join("arm", "arm_index.mdc")) {
+		t.Error("IsIndexFile() should recognize the sink's index files")
+	}
+}
@@ -0,0 +1,146 @@
+package sink
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DriftKind describes how a sink file differs from what ARM installed
+type DriftKind string
+
+const (
+	// DriftModified means an installed file was edited since ARM wrote it
+	DriftModified DriftKind = "modified"
+	// DriftMissing means an installed file was deleted
+	DriftMissing DriftKind = "missing"
+	// DriftUntracked means a file ARM did not install was added to its directory
+	DriftUntracked DriftKind = "untracked"
+)
+
+// Drift is a sink file that differs from what ARM installed
+type Drift struct {
+	Kind DriftKind
+	// Path is relative to the sink directory
+	Path string
+	// Package is REGISTRY/PACKAGE@VERSION; empty for untracked files
+	Package string
+}
+
+// Verify compares the sink with its index and returns the files that were
+// edited, deleted or added outside ARM, ordered by path. Shared configuration
+// files are not checked, and files installed before hashes were recorded are
+// only checked for existence. Untracked files are only detected where ARM owns
+// the directory: the arm directory of hierarchical sinks and arm_ files of flat ones.
+func (m *Manager) Verify() ([]Drift, error) {
+	index, err := m.loadIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	var drifts []Drift
+	tracked := make(map[string]bool)
+	for _, file := range installedFiles(index) {
+		if file.Shared {
+			continue
+		}
+		tracked[file.Path] = true
+		if file.Suppressed {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(m.directory, file.Path))
+		if os.IsNotExist(err) {
+			drifts = append(drifts, Drift{Kind: DriftMissing, Path: file.Path, Package: file.Package})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hash, ok := index.Hashes[file.Path]; ok && hash != hashContent(content) {
+			drifts = append(drifts, Drift{Kind: DriftModified, Path: file.Path, Package: file.Package})
+		}
+	}
+
+	untracked, err := m.untrackedFiles(tracked)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range untracked {
+		drifts = append(drifts, Drift{Kind: DriftUntracked, Path: path})
+	}
+
+	sort.Slice(drifts, func(i, j int) bool {
+		return drifts[i].Path < drifts[j].Path
+	})
+	return drifts, nil
+}
+
+// untrackedFiles returns the files in directories ARM owns that are neither
+// tracked nor one of the sink's index files
+func (m *Manager) untrackedFiles(tracked map[string]bool) ([]string, error) {
+	var untracked []string
+	if m.layout == LayoutFlat {
+		entries, err := os.ReadDir(m.directory)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if !entry.IsDir() && strings.HasPrefix(name, "arm_") && !tracked[name] && !m.IsIndexFile(name) {
+				untracked = append(untracked, name)
+			}
+		}
+		return untracked, nil
+	}
+
+	err := filepath.WalkDir(m.armDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		relPath, err := filepath.Rel(m.directory, path)
+		if err != nil {
+			return err
+		}
+		if !tracked[relPath] && !m.IsIndexFile(relPath) {
+			untracked = append(untracked, relPath)
+		}
+		return nil
+	})
+	return untracked, err
+}
+
+// updateHashes records the hash of every tracked file that has none yet and
+// forgets files that are no longer tracked. Recorded hashes are never
+// refreshed, so edits made outside ARM stay detectable; ARM itself only
+// rewrites a file after uninstalling it, which drops its hash.
+func (m *Manager) updateHashes(index *Index) {
+	hashes := make(map[string]string)
+	for _, file := range installedFiles(index) {
+		if file.Shared || file.Suppressed {
+			continue
+		}
+		if hash, ok := index.Hashes[file.Path]; ok {
+			hashes[file.Path] = hash
+			continue
+		}
+		if content, err := os.ReadFile(filepath.Join(m.directory, file.Path)); err == nil {
+			hashes[file.Path] = hashContent(content)
+		}
+	}
+	index.Hashes = nilIfEmpty(hashes)
+}
+
+func hashContent(content []byte) string {
+	hash := sha256.Sum256(content)
+	return "sha256-" + hex.EncodeToString(hash[:])
+}
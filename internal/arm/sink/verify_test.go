@@ -0,0 +1,89 @@
+package sink
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jomadu/ai-resource-manager/internal/arm/compiler"
+)
+
+func TestVerify(t *testing.T) {
+	tmpDir := t.TempDir()
+	m := NewManager(tmpDir, compiler.Cursor)
+	team := conflictTestPackage("team", `    naming:
+      body: Use descriptive names.
+    tests:
+      body: Write table-driven tests.
+`)
+	if err := m.InstallRuleset(team, 200, nil, nil, nil); err != nil {
+		t.Fatalf("InstallRuleset() error = %v", err)
+	}
+
+	drifts, err := m.Verify()
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if len(drifts) != 0 {
+		t.Fatalf("Verify() = %+v, want no drift after install", drifts)
+	}
+
+	pkgDir := filepath.Join("arm", "test-reg", "team", "1.0.0")
+	naming := filepath.Join(pkgDir, "team_naming.mdc")
+	tests := filepath.Join(pkgDir, "team_tests.mdc")
+	stray := filepath.Join(pkgDir, "mine.mdc")
+	if err := os.WriteFile(filepath.Join(tmpDir, naming), []byte("edited"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(tmpDir, tests)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, stray), []byte("mine"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// Files outside the arm directory belong to the user
+	if err := os.WriteFile(filepath.Join(tmpDir, "own.mdc"), []byte("own"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Installing another package must not accept the edit
+	other := conflictTestPackage("other", `    docs:
+      body: Document exported functions.
+`)
+	if err := m.InstallRuleset(other, 100, nil, nil, nil); err != nil {
+		t.Fatalf("InstallRuleset() error = %v", err)
+	}
+
+	drifts, err = m.Verify()
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	want := []Drift{
+		{Kind: DriftUntracked, Path: stray},
+		{Kind: DriftModified, Path: naming, Package: "test-reg/team@1.0.0"},
+		{Kind: DriftMissing, Path: tests, Package: "test-reg/team@1.0.0"},
+	}
+	if len(drifts) != len(want) {
+		t.Fatalf("Verify() = %+v, want %+v", drifts, want)
+	}
+	for i := range want {
+		if drifts[i] != want[i] {
+			t.Errorf("drift %d = %+v, want %+v", i, drifts[i], want[i])
+		}
+	}
+
+	// Reinstalling the package restores its files
+	if err := m.InstallRuleset(team, 200, nil, nil, nil); err != nil {
+		t.Fatalf("InstallRuleset() error = %v", err)
+	}
+	if err := os.Remove(filepath.Join(tmpDir, stray)); err != nil {
+		t.Fatal(err)
+	}
+	drifts, err = m.Verify()
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if len(drifts) != 0 {
+		t.Errorf("Verify() = %+v, want no drift after reinstall", drifts)
+	}
+}
@@ -64,7 +64,7 @@ func (p *PackageCache) SetPackageVersion(ctx context.Context, packageKey interfa
 		if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
 			return err
 		}
-		if err := os.WriteFile(filePath, file.Content, 0o644); err != nil {
+		if err := os.WriteFile(filePath, file.Content, file.Perm()); err != nil {
 			return err
 		}
 	}
@@ -145,6 +145,7 @@ func (p *PackageCache) GetPackageVersion(ctx context.Context, packageKey interfa
 			Path:    relPath,
 			Content: content,
 			Size:    info.Size(),
+			Mode:    info.Mode().Perm(),
 		})
 		return nil
 	})
@@ -246,12 +247,19 @@ func (p *PackageCache) Remove(ctx context.Context) error {
 }
 
 func (p *PackageCache) RemoveOldVersions(ctx context.Context, maxAge time.Duration) error {
-	entries, err := os.ReadDir(p.packagesDir)
+	dirs, err := p.OldVersionDirs(ctx, maxAge)
 	if err != nil {
 		return err
 	}
+	for _, dir := range dirs {
+		_ = os.RemoveAll(dir)
+	}
 
-	cutoff := time.Now().Add(-maxAge)
+	// Remove package directories with no versions left
+	entries, err := os.ReadDir(p.packagesDir)
+	if err != nil {
+		return err
+	}
 	for _, entry := range entries {
 		if !entry.IsDir() {
 			continue
@@ -261,22 +269,9 @@ func (p *PackageCache) RemoveOldVersions(ctx context.Context, maxAge time.Durati
 		hasVersions := false
 		for _, versionEntry := range versionEntries {
 			if versionEntry.IsDir() && strings.HasPrefix(versionEntry.Name(), "v") {
-				metadataPath := filepath.Join(packageDir, versionEntry.Name(), "metadata.json")
-				if data, err := os.ReadFile(metadataPath); err == nil {
-					var metadata struct {
-						UpdatedAt time.Time `json:"updatedAt"`
-					}
-					if json.Unmarshal(data, &metadata) == nil && metadata.UpdatedAt.Before(cutoff) {
-						_ = os.RemoveAll(filepath.Join(packageDir, versionEntry.Name()))
-					} else {
-						hasVersions = true
-					}
-				} else {
-					hasVersions = true
-				}
+				hasVersions = true
 			}
 		}
-		// Remove package directory if no versions remain
 		if !hasVersions {
 			_ = os.RemoveAll(packageDir)
 		}
@@ -284,6 +279,45 @@ func (p *PackageCache) RemoveOldVersions(ctx context.Context, maxAge time.Durati
 	return nil
 }
 
+// OldVersionDirs returns the version directories not updated within maxAge,
+// which RemoveOldVersions removes
+func (p *PackageCache) OldVersionDirs(ctx context.Context, maxAge time.Duration) ([]string, error) {
+	entries, err := os.ReadDir(p.packagesDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var dirs []string
+	cutoff := time.Now().Add(-maxAge)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		packageDir := filepath.Join(p.packagesDir, entry.Name())
+		versionEntries, _ := os.ReadDir(packageDir)
+		for _, versionEntry := range versionEntries {
+			if !versionEntry.IsDir() || !strings.HasPrefix(versionEntry.Name(), "v") {
+				continue
+			}
+			metadataPath := filepath.Join(packageDir, versionEntry.Name(), "metadata.json")
+			data, err := os.ReadFile(metadataPath)
+			if err != nil {
+				continue
+			}
+			var metadata struct {
+				UpdatedAt time.Time `json:"updatedAt"`
+			}
+			if json.Unmarshal(data, &metadata) == nil && metadata.UpdatedAt.Before(cutoff) {
+				dirs = append(dirs, filepath.Join(packageDir, versionEntry.Name()))
+			}
+		}
+	}
+	return dirs, nil
+}
+
 func (p *PackageCache) RemoveUnusedVersions(ctx context.Context, maxTimeSinceLastAccess time.Duration) error {
 	entries, err := os.ReadDir(p.packagesDir)
 	if err != nil {
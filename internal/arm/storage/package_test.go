@@ -119,6 +119,7 @@ func TestPackageCache_GetPackageVersion(t *testing.T) {
 	originalFiles := []*core.File{
 		{Path: "rules.yml", Content: []byte("rule: value"), Size: 11},
 		{Path: "nested/rule.yml", Content: []byte("nested: rule"), Size: 12},
+		{Path: "scripts/run.sh", Content: []byte("#!/bin/sh"), Size: 9, Mode: 0o755},
 	}
 
 	ctx := context.Background()
@@ -157,6 +158,9 @@ func TestPackageCache_GetPackageVersion(t *testing.T) {
 			t.Errorf("File content mismatch for %s: got %s, want %s",
 				retrievedFile.Path, string(retrievedFile.Content), string(originalFile.Content))
 		}
+		if retrievedFile.Perm() != originalFile.Perm() {
+			t.Errorf("File mode mismatch for %s: got %v, want %v", retrievedFile.Path, retrievedFile.Perm(), originalFile.Perm())
+		}
 	}
 }
 
@@ -373,6 +377,31 @@ func TestPackageCache_RemoveOldVersionsByTimestamp(t *testing.T) {
 	assert.Len(t, packages, 1)
 }
 
+func TestPackageCache_OldVersionDirs(t *testing.T) {
+	tempDir := t.TempDir()
+	pkg := NewPackageCache(tempDir)
+	ctx := context.Background()
+
+	packageKey := "test-package"
+	files := []*core.File{{Path: "test.txt", Content: []byte("content")}}
+
+	oldVersion := mustVersion("v1.0.0")
+	require.NoError(t, pkg.SetPackageVersion(ctx, packageKey, &oldVersion, files))
+	time.Sleep(50 * time.Millisecond)
+	newVersion := mustVersion("v1.1.0")
+	require.NoError(t, pkg.SetPackageVersion(ctx, packageKey, &newVersion, files))
+
+	dirs, err := pkg.OldVersionDirs(ctx, 25*time.Millisecond)
+	require.NoError(t, err)
+	require.Len(t, dirs, 1)
+	assert.Equal(t, "v1.0.0", filepath.Base(dirs[0]))
+
+	// Listing removes nothing
+	versions, err := pkg.ListPackageVersions(ctx, packageKey)
+	require.NoError(t, err)
+	assert.Len(t, versions, 2)
+}
+
 func TestPackageCache_RemoveUnusedVersionsByAccess(t *testing.T) {
 	tempDir := t.TempDir()
 	pkg := NewPackageCache(tempDir)
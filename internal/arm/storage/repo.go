@@ -6,6 +6,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/jomadu/ai-resource-manager/internal/arm/core"
 )
@@ -16,13 +17,15 @@ type RepoInterface interface {
 	GetBranches(ctx context.Context, url string) ([]string, error)
 	GetBranchHeadCommitHash(ctx context.Context, url, branch string) (string, error)
 	GetTagCommitHash(ctx context.Context, url, tag string) (string, error)
+	GetTagMessage(ctx context.Context, url, tag string) (string, error)
 	GetFilesFromCommit(ctx context.Context, url, commit string) ([]*core.File, error)
 }
 
 // Repo implements git operations using system git commands with cross-process locking
 type Repo struct {
 	repoDir string
-	lock    *FileLock // Protects git operations
+	mu      sync.Mutex // Serializes goroutines sharing the repo, so they wait rather than time out on lock
+	lock    *FileLock  // Protects git operations
 }
 
 // NewRepo creates new repo instance
@@ -33,12 +36,27 @@ func NewRepo(repoDir string) RepoInterface {
 	}
 }
 
+// acquire takes the in-process mutex and then the cross-process file lock
+func (r *Repo) acquire(ctx context.Context) error {
+	r.mu.Lock()
+	if err := r.lock.Lock(ctx); err != nil {
+		r.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (r *Repo) release() {
+	_ = r.lock.Unlock()
+	r.mu.Unlock()
+}
+
 // GetTags returns all git tags
 func (r *Repo) GetTags(ctx context.Context, url string) ([]string, error) {
-	if err := r.lock.Lock(ctx); err != nil {
+	if err := r.acquire(ctx); err != nil {
 		return nil, err
 	}
-	defer func() { _ = r.lock.Unlock() }()
+	defer r.release()
 
 	if err := r.ensureCloned(ctx, url); err != nil {
 		return nil, err
@@ -60,10 +78,10 @@ func (r *Repo) GetTags(ctx context.Context, url string) ([]string, error) {
 
 // GetBranches returns all git branches
 func (r *Repo) GetBranches(ctx context.Context, url string) ([]string, error) {
-	if err := r.lock.Lock(ctx); err != nil {
+	if err := r.acquire(ctx); err != nil {
 		return nil, err
 	}
-	defer func() { _ = r.lock.Unlock() }()
+	defer r.release()
 
 	if err := r.ensureCloned(ctx, url); err != nil {
 		return nil, err
@@ -92,10 +110,10 @@ func (r *Repo) GetBranches(ctx context.Context, url string) ([]string, error) {
 
 // GetBranchHeadCommitHash returns commit hash for branch head
 func (r *Repo) GetBranchHeadCommitHash(ctx context.Context, url, branch string) (string, error) {
-	if err := r.lock.Lock(ctx); err != nil {
+	if err := r.acquire(ctx); err != nil {
 		return "", err
 	}
-	defer func() { _ = r.lock.Unlock() }()
+	defer r.release()
 
 	if err := r.ensureCloned(ctx, url); err != nil {
 		return "", err
@@ -130,10 +148,10 @@ func (r *Repo) GetBranchHeadCommitHash(ctx context.Context, url, branch string)
 
 // GetTagCommitHash returns commit hash for tag
 func (r *Repo) GetTagCommitHash(ctx context.Context, url, tag string) (string, error) {
-	if err := r.lock.Lock(ctx); err != nil {
+	if err := r.acquire(ctx); err != nil {
 		return "", err
 	}
-	defer func() { _ = r.lock.Unlock() }()
+	defer r.release()
 
 	if err := r.ensureCloned(ctx, url); err != nil {
 		return "", err
@@ -149,36 +167,73 @@ func (r *Repo) GetTagCommitHash(ctx context.Context, url, tag string) (string, e
 	return strings.TrimSpace(string(output)), nil
 }
 
+// GetTagMessage returns the annotation message of a tag, or an empty string
+// for a lightweight tag
+func (r *Repo) GetTagMessage(ctx context.Context, url, tag string) (string, error) {
+	if err := r.acquire(ctx); err != nil {
+		return "", err
+	}
+	defer r.release()
+
+	if err := r.ensureCloned(ctx, url); err != nil {
+		return "", err
+	}
+
+	cmd := exec.Command("git", "cat-file", "-t", "refs/tags/"+tag)
+	cmd.Dir = r.repoDir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(string(output)) != "tag" {
+		return "", nil
+	}
+
+	cmd = exec.Command("git", "tag", "-l", "--format=%(contents)", tag)
+	cmd.Dir = r.repoDir
+	output, err = cmd.Output()
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
 // GetFilesFromCommit returns all files from specific commit
 func (r *Repo) GetFilesFromCommit(ctx context.Context, url, commit string) ([]*core.File, error) {
-	if err := r.lock.Lock(ctx); err != nil {
+	if err := r.acquire(ctx); err != nil {
 		return nil, err
 	}
-	defer func() { _ = r.lock.Unlock() }()
+	defer r.release()
 
 	if err := r.ensureCloned(ctx, url); err != nil {
 		return nil, err
 	}
 
-	// Get list of files in commit
-	cmd := exec.Command("git", "ls-tree", "-r", "--name-only", commit)
+	// Get list of files in commit, each line being "<mode> <type> <object>\t<path>"
+	cmd := exec.Command("git", "ls-tree", "-r", commit)
 	cmd.Dir = r.repoDir
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, err
 	}
 
-	filePaths := strings.Split(strings.TrimSpace(string(output)), "\n")
-	if len(filePaths) == 1 && filePaths[0] == "" {
+	entries := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(entries) == 1 && entries[0] == "" {
 		return []*core.File{}, nil
 	}
 
 	var files []*core.File
-	for _, path := range filePaths {
+	for _, entry := range entries {
+		info, path, ok := strings.Cut(entry, "\t")
 		path = strings.TrimSpace(path)
-		if path == "" {
+		if !ok || path == "" {
 			continue
 		}
+		mode := os.FileMode(0o644)
+		if strings.HasPrefix(info, "100755 ") {
+			mode = 0o755
+		}
 
 		// Get file content from commit
 		cmd := exec.Command("git", "show", commit+":"+path)
@@ -192,6 +247,7 @@ func (r *Repo) GetFilesFromCommit(ctx context.Context, url, commit string) ([]*c
 			Path:    path,
 			Content: content,
 			Size:    int64(len(content)),
+			Mode:    mode,
 		})
 	}
 
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -177,6 +178,38 @@ func TestGetTagCommitHash_InvalidTag(t *testing.T) {
 	assert.Empty(t, hash)
 }
 
+func TestGetTagMessage(t *testing.T) {
+	sourceDir := t.TempDir()
+	testRepo := NewTestRepo(t, sourceDir)
+
+	builder := testRepo.Builder().
+		Init().
+		AddFile("README.md", "# Test Repo").
+		Commit("Initial commit").
+		Tag("v1.0.0").
+		AddFile("feature.txt", "new feature").
+		Commit("Add feature").
+		AnnotatedTag("v1.1.0", "Add feature\n\nAdds feature.txt.")
+	err := builder.Build()
+	require.NoError(t, err)
+
+	targetDir := t.TempDir()
+	repo := NewRepo(targetDir)
+
+	ctx := context.Background()
+	message, err := repo.GetTagMessage(ctx, sourceDir, "v1.1.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "Add feature\n\nAdds feature.txt.", message)
+
+	// Lightweight tags have no annotation
+	message, err = repo.GetTagMessage(ctx, sourceDir, "v1.0.0")
+	assert.NoError(t, err)
+	assert.Empty(t, message)
+
+	_, err = repo.GetTagMessage(ctx, sourceDir, "nonexistent")
+	assert.Error(t, err)
+}
+
 func TestGetFilesFromCommit_ValidCommit(t *testing.T) {
 	sourceDir := t.TempDir()
 	testRepo := NewTestRepo(t, sourceDir)
@@ -185,6 +218,7 @@ func TestGetFilesFromCommit_ValidCommit(t *testing.T) {
 		Init().
 		AddFile("README.md", "# Test Repo").
 		AddFile("src/main.go", "package main").
+		AddExecutable("scripts/build.sh", "#!/bin/sh").
 		Commit("Initial commit").
 		Tag("v1.0.0")
 	err := builder.Build()
@@ -202,15 +236,16 @@ func TestGetFilesFromCommit_ValidCommit(t *testing.T) {
 	files, err := repo.GetFilesFromCommit(ctx, sourceDir, hash)
 
 	assert.NoError(t, err)
-	assert.Len(t, files, 2)
+	assert.Len(t, files, 3)
 
-	// Check files exist
-	fileNames := make([]string, len(files))
-	for i, f := range files {
-		fileNames[i] = f.Path
+	// Check files exist with their modes
+	fileModes := make(map[string]os.FileMode, len(files))
+	for _, f := range files {
+		fileModes[f.Path] = f.Mode
 	}
-	assert.Contains(t, fileNames, "README.md")
-	assert.Contains(t, fileNames, "src/main.go")
+	assert.Equal(t, os.FileMode(0o644), fileModes["README.md"])
+	assert.Equal(t, os.FileMode(0o644), fileModes["src/main.go"])
+	assert.Equal(t, os.FileMode(0o755), fileModes["scripts/build.sh"])
 }
 
 func TestGetFilesFromCommit_InvalidCommit(t *testing.T) {
@@ -15,9 +15,11 @@ type TestRepoBuilder interface {
 	Branch(name string) TestRepoBuilder
 	Checkout(branch string) TestRepoBuilder
 	AddFile(path, content string) TestRepoBuilder
+	AddExecutable(path, content string) TestRepoBuilder
 	RemoveFile(path string) TestRepoBuilder
 	Commit(message string) TestRepoBuilder
 	Tag(name string) TestRepoBuilder
+	AnnotatedTag(name, message string) TestRepoBuilder
 	Merge(branch string) TestRepoBuilder
 	Build() error
 }
@@ -93,6 +95,12 @@ func (b *testRepoBuilder) AddFile(path, content string) TestRepoBuilder {
 	return b
 }
 
+func (b *testRepoBuilder) AddExecutable(path, content string) TestRepoBuilder {
+	b.AddFile(path, content)
+	b.runGitCmd("update-index", "--chmod=+x", path)
+	return b
+}
+
 func (b *testRepoBuilder) RemoveFile(path string) TestRepoBuilder {
 	b.runGitCmd("rm", path)
 	return b
@@ -108,6 +116,11 @@ func (b *testRepoBuilder) Tag(name string) TestRepoBuilder {
 	return b
 }
 
+func (b *testRepoBuilder) AnnotatedTag(name, message string) TestRepoBuilder {
+	b.runGitCmd("tag", "-a", name, "-m", message)
+	return b
+}
+
 func (b *testRepoBuilder) Merge(branch string) TestRepoBuilder {
 	b.runGitCmd("merge", branch)
 	return b
@@ -0,0 +1,65 @@
+package e2e
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-manager/test/e2e/helpers"
+)
+
+func TestDiffBetweenVersions(t *testing.T) {
+	workDir := t.TempDir()
+	arm := helpers.NewARMRunner(t, workDir)
+
+	repoDir := t.TempDir()
+	repo := helpers.NewGitRepo(t, repoDir)
+	repo.WriteFile("test-ruleset.yml", helpers.MinimalRuleset)
+	repo.Commit("Initial commit")
+	repo.Tag("v1.0.0")
+	repo.WriteFile("test-ruleset.yml", `apiVersion: v1
+kind: Ruleset
+metadata:
+  id: "testRuleset"
+spec:
+  rules:
+    ruleOne:
+      enforcement: must
+      body: "This is rule one, now required."
+    ruleThree:
+      body: "This is rule three."
+`)
+	repo.Commit("Rework rules")
+	repo.Tag("v2.0.0")
+
+	arm.MustRun("add", "registry", "git", "--url", "file://"+repoDir, "test-registry")
+	arm.MustRun("add", "sink", "--tool", "cursor", "cursor-rules", ".cursor/rules")
+	arm.MustRun("install", "ruleset", "test-registry/test-ruleset@1.0.0", "cursor-rules")
+
+	t.Run("LockedToLatest", func(t *testing.T) {
+		output := arm.MustRun("diff", "test-registry/test-ruleset")
+		for _, want := range []string{
+			"test-registry/test-ruleset v1.0.0 -> v2.0.0",
+			"  ~ testRuleset/ruleOne\n      enforcement: - -> must\n      - This is rule one.\n      + This is rule one, now required.",
+			"  + testRuleset/ruleThree",
+			"  - testRuleset/ruleTwo\n      priority: 150 -> -",
+		} {
+			if !strings.Contains(output, want) {
+				t.Errorf("diff output missing %q:\n%s", want, output)
+			}
+		}
+	})
+
+	t.Run("SameVersion", func(t *testing.T) {
+		output := arm.MustRun("diff", "test-registry/test-ruleset", "1.0.0", "1.0.0")
+		if !strings.Contains(output, "No rule or prompt changes") {
+			t.Errorf("expected no changes, got:\n%s", output)
+		}
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		output := arm.MustRun("diff", "test-registry/test-ruleset", "--output", "json")
+		if !strings.Contains(output, `"id": "testRuleset/ruleThree"`) || !strings.Contains(output, `"action": "added"`) {
+			t.Errorf("unexpected JSON output:\n%s", output)
+		}
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -32,9 +33,16 @@ func NewARMRunner(t *testing.T, workDir string) *ARMRunner {
 // Run executes an ARM command and returns stdout, stderr, and error
 func (r *ARMRunner) Run(args ...string) (stdout, stderr string, err error) {
 	r.t.Helper()
+	return r.RunWithInput("", args...)
+}
+
+// RunWithInput executes an ARM command with input as its stdin
+func (r *ARMRunner) RunWithInput(input string, args ...string) (stdout, stderr string, err error) {
+	r.t.Helper()
 
 	cmd := exec.Command(r.BinaryPath, args...)
 	cmd.Dir = r.WorkDir
+	cmd.Stdin = strings.NewReader(input)
 
 	var stdoutBuf, stderrBuf bytes.Buffer
 	cmd.Stdout = &stdoutBuf
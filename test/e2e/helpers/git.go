@@ -43,6 +43,15 @@ func (r *GitRepo) WriteFile(path, content string) {
 	}
 }
 
+// WriteExecutable writes an executable file to the repository
+func (r *GitRepo) WriteExecutable(path, content string) {
+	r.t.Helper()
+	r.WriteFile(path, content)
+	if err := os.Chmod(filepath.Join(r.Path, path), 0o755); err != nil {
+		r.t.Fatalf("failed to make %s executable: %v", path, err)
+	}
+}
+
 // Commit commits all changes with the given message
 func (r *GitRepo) Commit(message string) {
 	r.t.Helper()
@@ -56,6 +65,12 @@ func (r *GitRepo) Tag(tag string) {
 	r.run("tag", tag)
 }
 
+// AnnotatedTag creates an annotated tag with the given message at the current commit
+func (r *GitRepo) AnnotatedTag(tag, message string) {
+	r.t.Helper()
+	r.run("tag", "-a", tag, "-m", message)
+}
+
 // Branch creates a new branch
 func (r *GitRepo) Branch(name string) {
 	r.t.Helper()
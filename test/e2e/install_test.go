@@ -1,6 +1,7 @@
 package e2e
 
 import (
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -281,6 +282,41 @@ func TestPromptsetInstallation(t *testing.T) {
 	}
 }
 
+func TestSkillsetInstallation(t *testing.T) {
+	workDir := t.TempDir()
+	arm := helpers.NewARMRunner(t, workDir)
+
+	// Setup: Create test Git repository with a skill that ships a script
+	repoDir := t.TempDir()
+	repo := helpers.NewGitRepo(t, repoDir)
+	repo.WriteFile("pdf/SKILL.md", "---\nname: pdf\ndescription: Extract text from PDFs.\n---\n\n# PDF\n")
+	repo.WriteFile("pdf/references/REFERENCE.md", "# Reference")
+	repo.WriteExecutable("pdf/scripts/extract.sh", "#!/bin/sh\necho extract\n")
+	repo.Commit("Initial commit")
+	repo.Tag("v1.0.0")
+
+	// Setup: Add registry and sink
+	repoURL := "file://" + repoDir
+	arm.MustRun("add", "registry", "git", "--url", repoURL, "test-registry")
+	arm.MustRun("add", "sink", "--tool", "claude", "claude-skills", ".claude/skills")
+
+	// Test: Install skillset
+	arm.MustRun("install", "skillset", "test-registry/test-skillset@1.0.0", "claude-skills")
+
+	// Verify the skill directory is copied intact, scripts still executable
+	skillDir := filepath.Join(workDir, ".claude", "skills", "pdf")
+	for rel, want := range map[string]os.FileMode{"SKILL.md": 0o644, "references/REFERENCE.md": 0o644, "scripts/extract.sh": 0o755} {
+		info, err := os.Stat(filepath.Join(skillDir, rel))
+		if err != nil {
+			t.Errorf("expected %s to be installed: %v", rel, err)
+			continue
+		}
+		if got := info.Mode().Perm(); got != want {
+			t.Errorf("%s mode = %v, want %v", rel, got, want)
+		}
+	}
+}
+
 func TestInstallWithPatterns(t *testing.T) {
 	workDir := t.TempDir()
 	arm := helpers.NewARMRunner(t, workDir)
@@ -0,0 +1,80 @@
+package e2e
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-manager/test/e2e/helpers"
+)
+
+// TestUpgradeInteractive verifies that upgrade --interactive moves each package
+// to the version chosen for it
+func TestUpgradeInteractive(t *testing.T) {
+	workDir := t.TempDir()
+	arm := helpers.NewARMRunner(t, workDir)
+
+	repoDir := t.TempDir()
+	repo := helpers.NewGitRepo(t, repoDir)
+	repo.WriteFile("test-ruleset.yml", helpers.MinimalRuleset)
+	repo.WriteFile("test-promptset.yml", helpers.MinimalPromptset)
+	repo.Commit("v1.0.0")
+	repo.Tag("v1.0.0")
+
+	arm.MustRun("add", "registry", "git", "--url", "file://"+repoDir, "test-registry")
+	arm.MustRun("add", "sink", "--tool", "cursor", "cursor-rules", ".cursor/rules")
+	arm.MustRun("add", "sink", "--tool", "cursor", "cursor-commands", ".cursor/commands")
+	arm.MustRun("install", "ruleset", "test-registry/test-ruleset@1", "cursor-rules")
+	arm.MustRun("install", "promptset", "test-registry/test-promptset@1", "cursor-commands")
+
+	repo.WriteFile("notes.txt", "minor")
+	repo.Commit("v1.1.0")
+	repo.Tag("v1.1.0")
+	repo.WriteFile("test-ruleset.yml", helpers.SecurityRuleset)
+	repo.Commit("v2.0.0")
+	repo.AnnotatedTag("v2.0.0", "Replace the rules with security rules")
+
+	t.Run("SkipAll", func(t *testing.T) {
+		stdout, stderr, err := arm.RunWithInput("\n\n", "upgrade", "--interactive")
+		if err != nil {
+			t.Fatalf("upgrade --interactive failed: %v\nStderr: %s", err, stderr)
+		}
+		if !strings.Contains(stdout, "No packages selected") {
+			t.Errorf("expected nothing to be selected, got:\n%s", stdout)
+		}
+	})
+
+	// Packages are offered in name order: the promptset, then the ruleset
+	stdout, stderr, err := arm.RunWithInput("w\nx\nl\n", "upgrade", "--interactive")
+	if err != nil {
+		t.Fatalf("upgrade --interactive failed: %v\nStderr: %s", err, stderr)
+	}
+	for _, want := range []string{
+		"test-registry/test-promptset  current v1.0.0  wanted v1.1.0  latest v2.0.0",
+		"    Replace the rules with security rules",
+		"Upgrade test-registry/test-ruleset to [w]anted v1.1.0, [l]atest v2.0.0, [s]kip?",
+		"Unknown choice: x",
+		"Selected packages upgraded successfully",
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("output missing %q:\n%s", want, stdout)
+		}
+	}
+
+	lock := helpers.ReadJSON(t, filepath.Join(workDir, "arm-lock.json"))
+	deps := lock["dependencies"].(map[string]interface{})
+	for _, key := range []string{"test-registry/test-promptset@v1.1.0", "test-registry/test-ruleset@v2.0.0"} {
+		if _, ok := deps[key]; !ok {
+			t.Errorf("lock file missing %s: %v", key, deps)
+		}
+	}
+
+	manifest := helpers.ReadJSON(t, filepath.Join(workDir, "arm.json"))
+	manifestDeps := manifest["dependencies"].(map[string]interface{})
+	if constraint := manifestDeps["test-registry/test-promptset"].(map[string]interface{})["version"]; constraint != "1" {
+		t.Errorf("promptset constraint should be kept, got %v", constraint)
+	}
+	if constraint := manifestDeps["test-registry/test-ruleset"].(map[string]interface{})["version"]; constraint != "^2.0.0" {
+		t.Errorf("ruleset constraint = %v, want ^2.0.0", constraint)
+	}
+}